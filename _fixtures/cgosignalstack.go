@@ -0,0 +1,37 @@
+package main
+
+// #cgo CFLAGS: -g -Wall -O0
+/*
+#define _GNU_SOURCE
+#include <signal.h>
+#include <string.h>
+#include <ucontext.h>
+
+int handled = 0;
+
+void sighandler(int sig, siginfo_t *info, void *ctx) {
+	ucontext_t *uc = (ucontext_t *)ctx;
+	// skip the ud2 instruction that raised the signal
+	uc->uc_mcontext.gregs[REG_RIP] += 2;
+	handled++;
+}
+
+void trap(void) {
+	handled = 0;
+	__asm__ volatile("ud2");
+}
+
+void testfn(void) {
+	struct sigaction sa;
+	memset(&sa, 0, sizeof(sa));
+	sa.sa_sigaction = sighandler;
+	sa.sa_flags = SA_SIGINFO;
+	sigaction(SIGILL, &sa, NULL);
+	trap();
+}
+*/
+import "C"
+
+func main() {
+	C.testfn()
+}
@@ -0,0 +1,21 @@
+package main
+
+import (
+	"fmt"
+	"runtime"
+)
+
+func makeAcc(scale int) func(x int) int {
+	a := 0
+	return func(x int) int {
+		a += x * scale
+		runtime.Breakpoint()
+		return a
+	}
+}
+
+func main() {
+	acc := makeAcc(3)
+	fmt.Println(acc(1))
+	fmt.Println(acc(2))
+}
@@ -0,0 +1,35 @@
+package main
+
+import (
+	"fmt"
+	"runtime"
+)
+
+type Inner struct {
+	Name string
+	N    int
+}
+
+type Outer struct {
+	ID    int
+	Inner *Inner
+	Items []int
+	Tags  map[string]int
+	Any   interface{}
+}
+
+type Other struct {
+	ID    int64
+	Inner *Inner
+	Items []int
+	Tags  map[string]int
+	Any   interface{}
+}
+
+func main() {
+	a := Outer{ID: 1, Inner: &Inner{"foo", 1}, Items: []int{1, 2, 3}, Tags: map[string]int{"x": 1, "y": 2}, Any: 1}
+	b := Outer{ID: 1, Inner: &Inner{"bar", 1}, Items: []int{1, 5, 3, 4}, Tags: map[string]int{"x": 1, "z": 3}, Any: "1"}
+	c := Other{ID: 1, Inner: a.Inner, Items: []int{1, 2, 3}, Tags: map[string]int{"x": 1, "y": 2}, Any: 1}
+	runtime.Breakpoint()
+	fmt.Println(a, b, c)
+}
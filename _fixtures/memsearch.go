@@ -0,0 +1,19 @@
+package main
+
+import (
+	"fmt"
+	"runtime"
+)
+
+var needle = [8]byte{0xde, 0xad, 0xbe, 0xef, 0x12, 0x34, 0x56, 0x78}
+
+type T struct {
+	A int64
+	S []byte
+}
+
+func main() {
+	t := &T{A: 0x1122334455667788, S: []byte("hidden treasure")}
+	runtime.Breakpoint()
+	fmt.Println(needle, t)
+}
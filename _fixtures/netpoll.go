@@ -0,0 +1,23 @@
+package main
+
+import (
+	"net"
+	"runtime"
+	"time"
+)
+
+func main() {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		panic(err)
+	}
+	go func() {
+		conn, err := ln.Accept()
+		if err == nil {
+			conn.Close()
+		}
+	}()
+	time.Sleep(100 * time.Millisecond)
+	runtime.Breakpoint()
+	ln.Close()
+}
@@ -0,0 +1,21 @@
+#include <stdio.h>
+
+struct point {
+	int x, y;
+};
+
+extern int sum(int *v, int n);
+
+struct point origin = { 1, 2 };
+int counter;
+
+static inline int square(int x) {
+	return x * x;
+}
+
+int main(int argc, char **argv) {
+	int v[] = { 1, 2, 3, 4 };
+	counter = sum(v, 4) + square(argc);
+	printf("%d %d %d\n", origin.x, origin.y, counter);
+	return 0;
+}
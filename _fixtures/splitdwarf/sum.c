@@ -0,0 +1,10 @@
+int sum_calls;
+
+int sum(int *v, int n) {
+	int i, s = 0;
+	sum_calls++;
+	for (i = 0; i < n; i++) {
+		s += v[i];
+	}
+	return s;
+}
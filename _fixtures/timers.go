@@ -0,0 +1,29 @@
+package main
+
+import (
+	"runtime"
+	"time"
+)
+
+func sleeper() {
+	time.Sleep(time.Hour)
+}
+
+func afterer(ch chan struct{}) {
+	<-time.After(2 * time.Hour)
+	close(ch)
+}
+
+func callback() {
+}
+
+func main() {
+	go sleeper()
+	go afterer(make(chan struct{}))
+	t := time.AfterFunc(3*time.Hour, callback)
+	tk := time.NewTicker(4 * time.Hour)
+	time.Sleep(100 * time.Millisecond)
+	runtime.Breakpoint()
+	t.Stop()
+	tk.Stop()
+}
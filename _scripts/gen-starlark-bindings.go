@@ -229,6 +229,7 @@ func genDocs(bindings []binding) []byte {
 	fmt.Fprintf(&buf, "write_file(path, contents) | Writes string to a file\n")
 	fmt.Fprintf(&buf, "cur_scope() | Returns the current evaluation scope\n")
 	fmt.Fprintf(&buf, "default_load_config() | Returns the current default load configuration\n")
+	fmt.Fprintf(&buf, "register_pretty_printer(type, fn) | Registers fn as the pretty printer for variables of the specified type, see [pretty printers](#pretty-printers)\n")
 
 	return buf.Bytes()
 }
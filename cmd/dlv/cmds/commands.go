@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
 	"net"
 	"os"
 	"os/exec"
@@ -12,9 +13,12 @@ import (
 	"runtime"
 	"strconv"
 	"strings"
+	"sync/atomic"
 	"syscall"
+	"text/tabwriter"
 
 	"github.com/go-delve/delve/pkg/config"
+	"github.com/go-delve/delve/pkg/dwarf/dwarfdump"
 	"github.com/go-delve/delve/pkg/gobuild"
 	"github.com/go-delve/delve/pkg/goversion"
 	"github.com/go-delve/delve/pkg/logflags"
@@ -81,6 +85,33 @@ var (
 	traceExecFile   string
 	traceTestBinary bool
 	traceStackDepth int
+	// traceOutputFormat is the format of the trace output, "text" or "json".
+	traceOutputFormat string
+	// traceOutputFile is the file the trace output is written to instead of stderr.
+	traceOutputFile string
+	// traceLatency is whether the duration of traced calls is measured.
+	traceLatency bool
+	// traceChromeFile is the file the traced calls are exported to in the
+	// Chrome trace event format.
+	traceChromeFile string
+	// traceGoroutine, traceLabels, traceMaxDepth and traceFollowCalls
+	// select the tracepoint hits that are reported.
+	traceGoroutine   int
+	traceLabels      []string
+	traceMaxDepth    int
+	traceFollowCalls int
+
+	coverAttachPid  int
+	coverExecFile   string
+	coverTestBinary bool
+	// coverPackages are the packages whose lines are recorded by the cover
+	// subcommand.
+	coverPackages []string
+	// coverProfile is the file the coverage profile is written to.
+	coverProfile string
+
+	// dwarfJSON is whether the dwarf subcommand should print JSON.
+	dwarfJSON bool
 
 	// redirect specifications for target process
 	redirects []string
@@ -274,7 +305,40 @@ is useful if you do not want to begin an entire debug session, but merely want
 to know what functions your process is executing.
 
 The output of the trace sub command is printed to stderr, so if you would like to
-only see the output of the trace operations you can redirect stdout.`,
+only see the output of the trace operations you can redirect stdout. Use
+--output-file to write it to a file instead.
+
+With --output-format json every tracepoint hit is printed as a line of JSON
+with the following fields:
+
+	time		time of the hit
+	kind		"call" for the entry of a function, "return" for its return
+	goroutineID	goroutine hitting the tracepoint
+	threadID	thread hitting the tracepoint
+	function	name of the traced function
+	file, line	source position of the tracepoint
+	depth		number of traced calls the goroutine is executing
+	args		arguments of the call (name, type and value)
+	returns		return values of the call (name, type and value)
+	stack		stack trace, if --stack is specified (pc, function, file and line of each frame)
+	duration	duration of the call in nanoseconds, on return events if --latency is specified
+
+With --latency the return of each traced call is paired with its entry and
+the duration of the call is printed. When the program exits a summary with
+the number of calls and the minimum, median, 99th percentile, maximum and
+total duration of the calls to each traced function is printed. Durations
+are measured by the debugger and include the overhead of stopping the
+program at each tracepoint. The calls can also be exported with
+--chrome-trace, which implies --latency, in the Chrome trace event format
+to be opened with a timeline viewer.
+
+The reported calls can be restricted to a single goroutine with --goroutine,
+to goroutines having a pprof label with --label (which can be repeated) and
+to the outermost nested traced calls with --max-depth. With --follow-calls N
+the functions called by the functions matching the regular expression are
+traced as well, up to N calls deep, but only when they are called by a
+function matching the regular expression. Functions of the runtime package
+are never followed.`,
 		Run: traceCmd,
 	}
 	traceCommand.Flags().IntVarP(&traceAttachPid, "pid", "p", 0, "Pid to attach to.")
@@ -282,8 +346,47 @@ only see the output of the trace operations you can redirect stdout.`,
 	traceCommand.Flags().BoolVarP(&traceTestBinary, "test", "t", false, "Trace a test binary.")
 	traceCommand.Flags().IntVarP(&traceStackDepth, "stack", "s", 0, "Show stack trace with given depth.")
 	traceCommand.Flags().String("output", "debug", "Output path for the binary.")
+	traceCommand.Flags().StringVar(&traceOutputFormat, "output-format", "text", "Format of the trace output, text or json.")
+	traceCommand.Flags().StringVar(&traceOutputFile, "output-file", "", "Write the trace output to this file instead of stderr.")
+	traceCommand.Flags().BoolVar(&traceLatency, "latency", false, "Measure the duration of traced calls and print a summary at the end.")
+	traceCommand.Flags().StringVar(&traceChromeFile, "chrome-trace", "", "Export the traced calls to this file in the Chrome trace event format.")
+	traceCommand.Flags().IntVar(&traceGoroutine, "goroutine", 0, "Only report calls made by the goroutine with this ID.")
+	traceCommand.Flags().StringArrayVar(&traceLabels, "label", []string{}, "Only report calls made by goroutines with this pprof label, specified as key=value.")
+	traceCommand.Flags().IntVar(&traceMaxDepth, "max-depth", 0, "Only report the outermost N nested traced calls.")
+	traceCommand.Flags().IntVar(&traceFollowCalls, "follow-calls", 0, "Also trace the functions called by the traced functions, up to N calls deep.")
 	rootCommand.AddCommand(traceCommand)
 
+	// 'cover' subcommand.
+	coverCommand := &cobra.Command{
+		Use:   "cover [package]",
+		Short: "Compile and record the lines executed by a program.",
+		Long: `Record which lines of a program are executed.
+
+The cover sub command sets a one-shot breakpoint on every statement line of
+the selected packages and runs the program. The first time a line is
+executed its breakpoints are removed and the line is recorded, without
+stopping the program. This is useful to know which lines of a package run
+during a manual reproduction of a problem, without rebuilding the program
+with -cover.
+
+When the program exits, or when Ctrl-C is pressed, the recorded lines are
+written to the file specified by --coverprofile as a coverage profile in
+the "set" mode, one block per line, that can be opened with 'go tool cover'.
+
+The packages to record are selected with --pkg, which can be repeated, a
+package path ending in "/..." also selects all the packages below it. By
+default the main package is recorded, or the tested package when used with
+--test.`,
+		Run: coverCmd,
+	}
+	coverCommand.Flags().IntVarP(&coverAttachPid, "pid", "p", 0, "Pid to attach to.")
+	coverCommand.Flags().StringVarP(&coverExecFile, "exec", "e", "", "Binary file to exec and record.")
+	coverCommand.Flags().BoolVarP(&coverTestBinary, "test", "t", false, "Record a test binary.")
+	coverCommand.Flags().String("output", "debug", "Output path for the binary.")
+	coverCommand.Flags().StringArrayVar(&coverPackages, "pkg", []string{}, "Import path of a package to record.")
+	coverCommand.Flags().StringVar(&coverProfile, "coverprofile", "coverage.out", "Write the coverage profile to this file.")
+	rootCommand.AddCommand(coverCommand)
+
 	coreCommand := &cobra.Command{
 		Use:   "core <executable> <core>",
 		Short: "Examine a core dump.",
@@ -304,6 +407,35 @@ Currently supports linux/amd64 and linux/arm64 core files and windows/amd64 mini
 	}
 	rootCommand.AddCommand(coreCommand)
 
+	// 'dwarf' subcommand.
+	dwarfCommand := &cobra.Command{
+		Use:   "dwarf <executable> <mode> [argument]",
+		Short: "Inspect the debug information of an executable.",
+		Long: `Prints the debug information of an executable, as read by Delve.
+
+This command is useful to find out if a problem is caused by the debug
+information emitted by the compiler or by the way Delve interprets it.
+The following modes are available:
+
+	cu			Compile units and their producers.
+	types <regexp>		Layout of the types with a name matching regexp.
+	lines <file>		Rows of the line table for file, including the is_stmt and prologue_end flags.
+	frame <pc>		Rules used to compute the CFA and the registers of the caller frame at pc.
+	vars <function>		Variables of function, with their location lists and coverage.
+
+Addresses are the ones written in the executable, they are not relocated.
+Registers are identified by their DWARF register number.`,
+		PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+			if len(args) < 2 {
+				return errors.New("you must provide an executable and a mode")
+			}
+			return nil
+		},
+		Run: dwarfCmd,
+	}
+	dwarfCommand.Flags().BoolVar(&dwarfJSON, "json", false, "Print output in JSON format.")
+	rootCommand.AddCommand(dwarfCommand)
+
 	// 'version' subcommand.
 	versionCommand := &cobra.Command{
 		Use:   "version",
@@ -442,8 +574,9 @@ func dapCmd(cmd *cobra.Command, args []string) {
 		}
 		disconnectChan := make(chan struct{})
 		server := dap.NewServer(&service.Config{
-			Listener:       listener,
-			DisconnectChan: disconnectChan,
+			Listener:            listener,
+			DisconnectChan:      disconnectChan,
+			PrettyPrinterScript: conf.PrettyPrinterScript,
 			Debugger: debugger.Config{
 				Backend:              backend,
 				Foreground:           headless && tty == "",
@@ -497,6 +630,32 @@ func traceCmd(cmd *cobra.Command, args []string) {
 		if acceptMulti {
 			fmt.Fprintf(os.Stderr, "Warning: accept multiclient mode not supported with trace")
 		}
+		if traceOutputFormat != "text" && traceOutputFormat != "json" {
+			fmt.Fprintf(os.Stderr, "Unknown output format %q, must be text or json\n", traceOutputFormat)
+			return 1
+		}
+		filter := terminal.TraceFilter{MaxDepth: traceMaxDepth, FollowCalls: traceFollowCalls}
+		for _, label := range traceLabels {
+			v := strings.SplitN(label, "=", 2)
+			if len(v) != 2 {
+				fmt.Fprintf(os.Stderr, "Wrong label %q, must be key=value\n", label)
+				return 1
+			}
+			if filter.Labels == nil {
+				filter.Labels = make(map[string]string)
+			}
+			filter.Labels[v[0]] = v[1]
+		}
+		traceOut := io.Writer(os.Stderr)
+		if traceOutputFile != "" {
+			f, err := os.Create(traceOutputFile)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "%v\n", err)
+				return 1
+			}
+			defer f.Close()
+			traceOut = f
+		}
 
 		var regexp string
 		var processArgs []string
@@ -571,50 +730,305 @@ func traceCmd(cmd *cobra.Command, args []string) {
 			fmt.Fprintln(os.Stderr, err)
 			return 1
 		}
-		for i := range funcs {
-			_, err = client.CreateBreakpoint(&api.Breakpoint{
-				FunctionName: funcs[i],
+		cond := ""
+		if traceGoroutine > 0 {
+			cond = fmt.Sprintf("runtime.curg.goid == %d", traceGoroutine)
+		}
+		setTracepoints := func(fn string) error {
+			_, err := client.CreateBreakpoint(&api.Breakpoint{
+				FunctionName: fn,
 				Tracepoint:   true,
 				Line:         -1,
+				Cond:         cond,
+				Goroutine:    len(filter.Labels) > 0,
 				Stacktrace:   traceStackDepth,
 				LoadArgs:     &terminal.ShortLoadConfig,
 			})
 			if err != nil && !isBreakpointExistsErr(err) {
-				fmt.Fprintln(os.Stderr, err)
-				return 1
+				return err
 			}
-			addrs, err := client.FunctionReturnLocations(funcs[i])
+			addrs, err := client.FunctionReturnLocations(fn)
 			if err != nil {
-				fmt.Fprintln(os.Stderr, err)
-				return 1
+				return err
 			}
 			for i := range addrs {
 				_, err = client.CreateBreakpoint(&api.Breakpoint{
 					Addr:        addrs[i],
 					TraceReturn: true,
+					Cond:        cond,
+					Goroutine:   len(filter.Labels) > 0,
 					Stacktrace:  traceStackDepth,
 					Line:        -1,
 					LoadArgs:    &terminal.ShortLoadConfig,
 				})
 				if err != nil && !isBreakpointExistsErr(err) {
-					fmt.Fprintln(os.Stderr, err)
-					return 1
+					return err
+				}
+			}
+			return nil
+		}
+		for i := range funcs {
+			if err := setTracepoints(funcs[i]); err != nil {
+				fmt.Fprintln(os.Stderr, err)
+				return 1
+			}
+		}
+		if traceFollowCalls > 0 {
+			filter.Roots = funcs
+			for _, fn := range traceCallees(client, funcs, traceFollowCalls) {
+				// not every function can be traced (for example assembly
+				// functions without debug information), those are skipped.
+				if err := setTracepoints(fn); err != nil {
+					fmt.Fprintf(os.Stderr, "Warning: could not trace %s: %v\n", fn, err)
 				}
 			}
 		}
 		cmds := terminal.DebugCommands(client)
 		t := terminal.New(client, nil)
 		defer t.Close()
+		t.SetTraceOutput(traceOut, traceOutputFormat == "json")
+		t.SetTraceLatency(traceLatency || traceChromeFile != "")
+		t.SetTraceFilter(filter)
 		cmds.Call("continue", t)
+		if traceLatency {
+			if traceOutputFormat == "json" {
+				t.PrintTraceLatencySummary(os.Stderr)
+			} else {
+				t.PrintTraceLatencySummary(traceOut)
+			}
+		}
+		if traceChromeFile != "" {
+			f, err := os.Create(traceChromeFile)
+			if err == nil {
+				err = t.WriteChromeTrace(f)
+				if err1 := f.Close(); err == nil {
+					err = err1
+				}
+			}
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "%v\n", err)
+				return 1
+			}
+		}
 		return 0
 	}()
 	os.Exit(status)
 }
 
+// traceCallees returns the functions called by funcs, directly or through
+// other functions, at most depth calls deep. Functions of the runtime
+// package are not followed.
+func traceCallees(client service.Client, funcs []string, depth int) []string {
+	seen := make(map[string]bool)
+	for _, fn := range funcs {
+		seen[fn] = true
+	}
+	var callees []string
+	cur := funcs
+	for d := 0; d < depth && len(cur) > 0; d++ {
+		var next []string
+		for _, fn := range cur {
+			locs, err := client.FindLocation(api.EvalScope{GoroutineID: -1}, fn, false, nil)
+			if err != nil || len(locs) != 1 {
+				continue
+			}
+			insts, err := client.DisassemblePC(api.EvalScope{GoroutineID: -1}, locs[0].PC, api.IntelFlavour)
+			if err != nil {
+				continue
+			}
+			for _, inst := range insts {
+				if inst.DestLoc == nil || inst.DestLoc.Function == nil {
+					continue
+				}
+				callee := inst.DestLoc.Function.Name()
+				if seen[callee] || strings.HasPrefix(callee, "runtime.") {
+					continue
+				}
+				seen[callee] = true
+				next = append(next, callee)
+			}
+		}
+		callees = append(callees, next...)
+		cur = next
+	}
+	return callees
+}
+
 func isBreakpointExistsErr(err error) bool {
 	return strings.Contains(err.Error(), "Breakpoint exists")
 }
 
+func coverCmd(cmd *cobra.Command, args []string) {
+	status := func() int {
+		err := logflags.Setup(log, logOutput, logDest)
+		defer logflags.Close()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%v\n", err)
+			return 1
+		}
+
+		if headless {
+			fmt.Fprintf(os.Stderr, "Warning: headless mode not supported with cover\n")
+		}
+
+		var processArgs []string
+		dlvArgs, targetArgs := splitArgs(cmd, args)
+
+		packages := coverPackages
+		if len(packages) == 0 {
+			if coverTestBinary {
+				pkg := "."
+				if len(dlvArgs) > 0 {
+					pkg = dlvArgs[0]
+				}
+				packages = []string{getPackageImportPath(pkg)}
+			} else {
+				packages = []string{"main"}
+			}
+		}
+
+		if coverAttachPid == 0 {
+			if len(dlvArgs) > 0 && coverExecFile != "" {
+				fmt.Fprintln(os.Stderr, "Cannot specify package when using exec.")
+				return 1
+			}
+
+			debugname := coverExecFile
+			if coverExecFile == "" {
+				debugname, err = filepath.Abs(cmd.Flag("output").Value.String())
+				if err != nil {
+					fmt.Fprintf(os.Stderr, "%v\n", err)
+					return 1
+				}
+				if coverTestBinary {
+					err = gobuild.GoTestBuild(debugname, dlvArgs, buildFlags)
+				} else {
+					err = gobuild.GoBuild(debugname, dlvArgs, buildFlags)
+				}
+				if err != nil {
+					fmt.Fprintf(os.Stderr, "%v\n", err)
+					return 1
+				}
+				defer gobuild.Remove(debugname)
+			}
+
+			processArgs = append([]string{debugname}, targetArgs...)
+		}
+
+		// Make a local in-memory connection that client and server use to communicate
+		listener, clientConn := service.ListenerPipe()
+		defer listener.Close()
+
+		if workingDir == "" {
+			workingDir = "."
+		}
+
+		// Create and start a debug server
+		server := rpccommon.NewServer(&service.Config{
+			Listener:    listener,
+			ProcessArgs: processArgs,
+			APIVersion:  2,
+			Debugger: debugger.Config{
+				AttachPid:      coverAttachPid,
+				WorkingDir:     workingDir,
+				Backend:        backend,
+				CheckGoVersion: checkGoVersion,
+			},
+		})
+		if err := server.Run(); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 1
+		}
+		client := rpc2.NewClientFromConn(clientConn)
+		n, err := client.StartCoverage(packages)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			client.Detach(coverAttachPid == 0)
+			return 1
+		}
+		fmt.Fprintf(os.Stderr, "Recording %d lines of %s, press Ctrl-C to stop.\n", n, strings.Join(packages, ", "))
+
+		var interrupted int32
+		sigch := make(chan os.Signal, 1)
+		signal.Notify(sigch, os.Interrupt)
+		defer signal.Stop(sigch)
+		go func() {
+			for range sigch {
+				atomic.StoreInt32(&interrupted, 1)
+				client.Halt()
+			}
+		}()
+
+		status := 0
+		for {
+			var state *api.DebuggerState
+			for state = range client.Continue() {
+			}
+			if state.Exited || atomic.LoadInt32(&interrupted) != 0 {
+				break
+			}
+			if state.Err != nil {
+				fmt.Fprintln(os.Stderr, state.Err)
+				status = 1
+				break
+			}
+			// the program stopped on a breakpoint (for example because of an
+			// unrecovered panic), keep recording until it exits.
+		}
+
+		lines, err := client.Coverage()
+		if err == nil {
+			var f *os.File
+			f, err = os.Create(coverProfile)
+			if err == nil {
+				err = writeCoverProfile(f, lines)
+				if err1 := f.Close(); err == nil {
+					err = err1
+				}
+			}
+		}
+		client.Detach(coverAttachPid == 0)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 1
+		}
+		covered := 0
+		for _, line := range lines {
+			if line.Covered {
+				covered++
+			}
+		}
+		fmt.Fprintf(os.Stderr, "%d of %d lines executed, coverage profile written to %s\n", covered, len(lines), coverProfile)
+		return status
+	}()
+	os.Exit(status)
+}
+
+// writeCoverProfile writes lines as a coverage profile in the "set" mode,
+// with a block spanning each line.
+// Files are named by import path and file name, like 'go test' does,
+// except for the files of main and external test packages, which can not
+// be found by import path and are written with their full path.
+func writeCoverProfile(w io.Writer, lines []api.CoverLine) error {
+	if _, err := fmt.Fprintf(w, "mode: set\n"); err != nil {
+		return err
+	}
+	for _, line := range lines {
+		name := line.File
+		if line.Package != "main" && !strings.HasSuffix(line.Package, "_test") {
+			name = line.Package + "/" + filepath.Base(line.File)
+		}
+		count := 0
+		if line.Covered {
+			count = 1
+		}
+		if _, err := fmt.Fprintf(w, "%s:%d.1,%d.1 1 %d\n", name, line.Line, line.Line+1, count); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 func testCmd(cmd *cobra.Command, args []string) {
 	status := func() int {
 		debugname, err := filepath.Abs(cmd.Flag("output").Value.String())
@@ -661,6 +1075,23 @@ func getPackageDir(pkg string) string {
 	return listout.Dir
 }
 
+// getPackageImportPath returns the import path of pkg, or pkg itself if
+// it can not be determined.
+func getPackageImportPath(pkg string) string {
+	out, err := exec.Command("go", "list", "--json", pkg).CombinedOutput()
+	if err != nil {
+		return pkg
+	}
+	type listOut struct {
+		ImportPath string `json:"ImportPath"`
+	}
+	var listout listOut
+	if err := json.Unmarshal(out, &listout); err != nil {
+		return pkg
+	}
+	return listout.ImportPath
+}
+
 func attachCmd(cmd *cobra.Command, args []string) {
 	pid, err := strconv.Atoi(args[0])
 	if err != nil {
@@ -674,6 +1105,136 @@ func coreCmd(cmd *cobra.Command, args []string) {
 	os.Exit(execute(0, []string{args[0]}, conf, args[1], debugger.ExecutingOther, args, buildFlags))
 }
 
+func dwarfCmd(cmd *cobra.Command, args []string) {
+	if err := dwarfDump(os.Stdout, args); err != nil {
+		fmt.Fprintf(os.Stderr, "%v\n", err)
+		os.Exit(1)
+	}
+}
+
+func dwarfDump(out io.Writer, args []string) error {
+	mode, arg := args[1], ""
+	switch mode {
+	case "cu":
+		if len(args) != 2 {
+			return errors.New("wrong number of arguments for cu")
+		}
+	case "types", "lines", "frame", "vars":
+		if len(args) != 3 {
+			return fmt.Errorf("wrong number of arguments for %s", mode)
+		}
+		arg = args[2]
+	default:
+		return fmt.Errorf("unknown mode %q", mode)
+	}
+
+	f, err := dwarfdump.Open(args[0])
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	var v interface{}
+	switch mode {
+	case "cu":
+		v = f.CompileUnits()
+	case "types":
+		v, err = f.Types(arg)
+	case "lines":
+		v = f.Lines(arg)
+	case "frame":
+		pc, perr := strconv.ParseUint(arg, 0, 64)
+		if perr != nil {
+			return fmt.Errorf("invalid address %q", arg)
+		}
+		v, err = f.Frame(pc)
+	case "vars":
+		v, err = f.Vars(arg)
+	}
+	if err != nil {
+		return err
+	}
+
+	if dwarfJSON {
+		enc := json.NewEncoder(out)
+		enc.SetIndent("", "\t")
+		return enc.Encode(v)
+	}
+
+	w := tabwriter.NewWriter(out, 0, 8, 1, ' ', 0)
+	defer w.Flush()
+	switch v := v.(type) {
+	case []dwarfdump.CompileUnit:
+		for _, cu := range v {
+			fmt.Fprintf(w, "%#x\tv%d\t%s\t%s\t%s\n", cu.Offset, cu.Version, cu.Language, cu.Name, cu.Producer)
+		}
+	case []dwarfdump.Type:
+		for _, typ := range v {
+			fmt.Fprintf(w, "%s\t%s\tsize %d\n", typ.Name, typ.Kind, typ.Size)
+			for _, field := range typ.Fields {
+				fmt.Fprintf(w, "\t%#x\t%s %s\tsize %d\n", field.Offset, field.Name, field.Type, field.Size)
+			}
+		}
+	case []dwarfdump.LineRow:
+		for _, row := range v {
+			var flags []string
+			if row.IsStmt {
+				flags = append(flags, "is_stmt")
+			}
+			if row.PrologueEnd {
+				flags = append(flags, "prologue_end")
+			}
+			if row.EpilogueBegin {
+				flags = append(flags, "epilogue_begin")
+			}
+			if row.EndSequence {
+				flags = append(flags, "end_sequence")
+			}
+			fmt.Fprintf(w, "%#x\t%s:%d:%d\t%s\n", row.Address, row.File, row.Line, row.Column, strings.Join(flags, " "))
+		}
+	case *dwarfdump.FrameRules:
+		fmt.Fprintf(w, "FDE [%#x, %#x)\n", v.FDEStart, v.FDEEnd)
+		fmt.Fprintf(w, "cfa\t= %s\n", v.CFA)
+		for _, rule := range v.Regs {
+			retaddr := ""
+			if rule.Reg == v.RetAddrReg {
+				retaddr = " (return address)"
+			}
+			fmt.Fprintf(w, "r%d%s\t= %s\n", rule.Reg, retaddr, rule)
+		}
+	case []dwarfdump.Function:
+		for _, fn := range v {
+			fmt.Fprintf(w, "%s\t%s\t%s\n", fn.Name, fn.CompUnit, formatRanges(fn.Ranges))
+			for _, vr := range fn.Variables {
+				kind := "var"
+				if vr.Param {
+					kind = "param"
+				}
+				fmt.Fprintf(w, "%s%s %s %s\tcoverage %.0f%%\t%s\n", strings.Repeat("\t", vr.Depth+1), kind, vr.Name, vr.Type, vr.Coverage*100, formatRanges(vr.Covered))
+				if vr.Error != "" {
+					fmt.Fprintf(w, "%s\terror: %s\n", strings.Repeat("\t", vr.Depth+1), vr.Error)
+				}
+				for _, loc := range vr.Location {
+					rng := "always"
+					if loc.LowPC != 0 || loc.HighPC != 0 {
+						rng = formatRanges([][2]uint64{{loc.LowPC, loc.HighPC}})
+					}
+					fmt.Fprintf(w, "%s\t%s\t%s\n", strings.Repeat("\t", vr.Depth+1), rng, loc.Expr)
+				}
+			}
+		}
+	}
+	return nil
+}
+
+func formatRanges(rngs [][2]uint64) string {
+	s := make([]string, len(rngs))
+	for i := range rngs {
+		s[i] = fmt.Sprintf("[%#x, %#x)", rngs[i][0], rngs[i][1])
+	}
+	return strings.Join(s, ", ")
+}
+
 func connectCmd(cmd *cobra.Command, args []string) {
 	addr := args[0]
 	if addr == "" {
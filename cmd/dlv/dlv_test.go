@@ -3,6 +3,8 @@ package main_test
 import (
 	"bufio"
 	"bytes"
+	"debug/elf"
+	"encoding/json"
 	"flag"
 	"fmt"
 	"go/ast"
@@ -13,12 +15,14 @@ import (
 	"os"
 	"os/exec"
 	"path/filepath"
+	"regexp"
 	"runtime"
 	"strconv"
 	"strings"
 	"testing"
 	"time"
 
+	"github.com/go-delve/delve/pkg/dwarf/dwarfdump"
 	protest "github.com/go-delve/delve/pkg/proc/test"
 	"github.com/go-delve/delve/pkg/terminal"
 	"github.com/go-delve/delve/service/dap/daptest"
@@ -266,6 +270,7 @@ func TestChildProcessExitWhenNoDebugInfo(t *testing.T) {
 	defer os.RemoveAll(tmpdir)
 
 	fix := protest.BuildFixture("http_server", protest.LinkStrip)
+	corruptPclntab(t, fix.Path)
 
 	// dlv exec the binary file and expect error.
 	if _, err := exec.Command(dlvbin, "exec", fix.Path).CombinedOutput(); err == nil {
@@ -296,6 +301,33 @@ func TestChildProcessExitWhenNoDebugInfo(t *testing.T) {
 	}
 }
 
+// corruptPclntab overwrites the header of the gopclntab section of the ELF
+// executable at path, stripped executables can be debugged using
+// gopclntab, without it they have no usable debug info.
+func corruptPclntab(t *testing.T, path string) {
+	exe, err := elf.Open(path)
+	if err != nil {
+		// not an ELF executable, stripping it is enough
+		return
+	}
+	var off uint64
+	for _, name := range []string{".gopclntab", ".data.rel.ro.gopclntab"} {
+		if sec := exe.Section(name); sec != nil {
+			off = sec.Offset
+			break
+		}
+	}
+	exe.Close()
+	if off == 0 {
+		t.Fatalf("could not find gopclntab section in %s", path)
+	}
+	fh, err := os.OpenFile(path, os.O_WRONLY, 0)
+	assertNoError(err, t, "open executable")
+	defer fh.Close()
+	_, err = fh.WriteAt([]byte{0, 0, 0, 0}, int64(off))
+	assertNoError(err, t, "write executable")
+}
+
 // TestRedirect verifies that redirecting stdin works
 func TestRedirect(t *testing.T) {
 	const listenAddr = "127.0.0.1:40573"
@@ -660,6 +692,155 @@ func TestTrace(t *testing.T) {
 	cmd.Wait()
 }
 
+func TestTraceJSON(t *testing.T) {
+	dlvbin, tmpdir := getDlvBin(t)
+	defer os.RemoveAll(tmpdir)
+
+	fixtures := protest.FindFixturesDir()
+	outfile := filepath.Join(tmpdir, "trace.json")
+	cmd := exec.Command(dlvbin, "trace", "--output", filepath.Join(tmpdir, "__debug"), "--output-format", "json", "--output-file", outfile, filepath.Join(fixtures, "issue573.go"), "foo")
+	cmd.Dir = filepath.Join(fixtures, "buildtest")
+	out, err := cmd.CombinedOutput()
+	t.Logf("output: %s", out)
+	assertNoError(err, t, "running trace")
+
+	buf, err := ioutil.ReadFile(outfile)
+	assertNoError(err, t, "reading trace output")
+	lines := strings.Split(strings.TrimSpace(string(buf)), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("expected 2 events, got:\n%s", buf)
+	}
+
+	type variable struct{ Name, Value string }
+	var call, ret struct {
+		Kind     string
+		Function string
+		Depth    int
+		Args     []variable
+		Returns  []variable
+	}
+	assertNoError(json.Unmarshal([]byte(lines[0]), &call), t, "decoding call event")
+	assertNoError(json.Unmarshal([]byte(lines[1]), &ret), t, "decoding return event")
+	if call.Kind != "call" || call.Function != "main.foo" || call.Depth != 0 || len(call.Args) != 2 || call.Args[0].Value != "99" || call.Args[1].Value != "9801" {
+		t.Errorf("wrong call event: %s", lines[0])
+	}
+	if ret.Kind != "return" || ret.Function != "main.foo" || ret.Depth != 0 || len(ret.Returns) != 1 || ret.Returns[0].Value != "9900" {
+		t.Errorf("wrong return event: %s", lines[1])
+	}
+}
+
+func TestTraceLatency(t *testing.T) {
+	dlvbin, tmpdir := getDlvBin(t)
+	defer os.RemoveAll(tmpdir)
+
+	fixtures := protest.FindFixturesDir()
+	chromefile := filepath.Join(tmpdir, "trace.chrome.json")
+	cmd := exec.Command(dlvbin, "trace", "--output", filepath.Join(tmpdir, "__debug"), "--latency", "--chrome-trace", chromefile, filepath.Join(fixtures, "issue573.go"), "foo")
+	cmd.Dir = filepath.Join(fixtures, "buildtest")
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	assertNoError(cmd.Run(), t, "running trace")
+	output := stderr.String()
+	t.Logf("output: %s", output)
+
+	if !regexp.MustCompile(`main\.foo\(99, 9801\) => \(9900\) \[\S+\]\n`).MatchString(output) {
+		t.Errorf("duration of main.foo not printed")
+	}
+	if !regexp.MustCompile(`(?m)^main\.foo\s+1\s`).MatchString(output) {
+		t.Errorf("summary of main.foo not printed")
+	}
+
+	buf, err := ioutil.ReadFile(chromefile)
+	assertNoError(err, t, "reading Chrome trace")
+	var trace struct {
+		TraceEvents []struct {
+			Name string
+			Ph   string
+			Tid  int
+		}
+	}
+	assertNoError(json.Unmarshal(buf, &trace), t, "decoding Chrome trace")
+	if len(trace.TraceEvents) != 1 || trace.TraceEvents[0].Name != "main.foo" || trace.TraceEvents[0].Ph != "X" || trace.TraceEvents[0].Tid != 1 {
+		t.Errorf("wrong Chrome trace: %s", buf)
+	}
+}
+
+func TestTraceFollowCalls(t *testing.T) {
+	dlvbin, tmpdir := getDlvBin(t)
+	defer os.RemoveAll(tmpdir)
+
+	fixtures := protest.FindFixturesDir()
+	trace := func(args ...string) string {
+		args = append([]string{"trace", "--output", filepath.Join(tmpdir, "__debug")}, args...)
+		args = append(args, filepath.Join(fixtures, "issue573.go"), "foo")
+		cmd := exec.Command(dlvbin, args...)
+		cmd.Dir = filepath.Join(fixtures, "buildtest")
+		var stderr bytes.Buffer
+		cmd.Stderr = &stderr
+		assertNoError(cmd.Run(), t, "running trace")
+		t.Logf("%v: %s", args, stderr.String())
+		return stderr.String()
+	}
+
+	out := trace("--follow-calls", "1")
+	if !strings.Contains(out, "> goroutine(1): main.foo(99, 9801)") || !strings.Contains(out, "> goroutine(1): fmt.Printf(") {
+		t.Errorf("main.foo and its callee fmt.Printf not traced")
+	}
+	if strings.Contains(out, "fmt.Fprintf(") {
+		t.Errorf("fmt.Fprintf traced beyond the follow-calls depth")
+	}
+
+	out = trace("--follow-calls", "1", "--max-depth", "1")
+	if !strings.Contains(out, "main.foo(99, 9801)") || strings.Contains(out, "fmt.Printf(") {
+		t.Errorf("max-depth did not exclude the nested call to fmt.Printf")
+	}
+
+	out = trace("--goroutine", "2")
+	if strings.Contains(out, "main.foo(") {
+		t.Errorf("call of goroutine 1 reported")
+	}
+}
+
+func TestCover(t *testing.T) {
+	dlvbin, tmpdir := getDlvBin(t)
+	defer os.RemoveAll(tmpdir)
+
+	fixtures := protest.FindFixturesDir()
+	source := filepath.Join(fixtures, "locationsprog.go")
+	profile := filepath.Join(tmpdir, "cover.out")
+	cmd := exec.Command(dlvbin, "cover", "--output", filepath.Join(tmpdir, "__debug"), "--coverprofile", profile, source)
+	cmd.Dir = filepath.Join(fixtures, "buildtest")
+	out, err := cmd.CombinedOutput()
+	t.Logf("output: %s", out)
+	assertNoError(err, t, "running cover")
+
+	buf, err := ioutil.ReadFile(profile)
+	assertNoError(err, t, "reading coverage profile")
+	lines := strings.Split(strings.TrimSpace(string(buf)), "\n")
+	if lines[0] != "mode: set" {
+		t.Fatalf("wrong mode line %q", lines[0])
+	}
+	got := make(map[int]string)
+	for _, line := range lines[1:] {
+		var ln, endln, numStmt, count int
+		colon := strings.LastIndex(line, ":")
+		if colon < 0 || line[:colon] != source {
+			t.Errorf("wrong file in %q", line)
+			continue
+		}
+		if _, err := fmt.Sscanf(line[colon+1:], "%d.1,%d.1 %d %d", &ln, &endln, &numStmt, &count); err != nil {
+			t.Errorf("could not parse %q: %v", line, err)
+			continue
+		}
+		got[ln] = fmt.Sprintf("%d %d %d", endln-ln, numStmt, count)
+	}
+	for ln, exp := range map[int]string{23: "1 1 1", 34: "1 1 1", 45: "1 1 0"} {
+		if got[ln] != exp {
+			t.Errorf("line %d: expected %q got %q", ln, exp, got[ln])
+		}
+	}
+}
+
 func TestTracePid(t *testing.T) {
 	if runtime.GOOS == "linux" {
 		bs, _ := ioutil.ReadFile("/proc/sys/kernel/yama/ptrace_scope")
@@ -752,6 +933,96 @@ func TestTracePrintStack(t *testing.T) {
 	}
 }
 
+func TestTraceOutputFile(t *testing.T) {
+	dlvbin, tmpdir := getDlvBin(t)
+	defer os.RemoveAll(tmpdir)
+
+	fixtures := protest.FindFixturesDir()
+	outfile := filepath.Join(tmpdir, "trace.txt")
+	cmd := exec.Command(dlvbin, "trace", "--output", filepath.Join(tmpdir, "__debug"), "--stack", "2", "--output-file", outfile, filepath.Join(fixtures, "issue573.go"), "foo")
+	cmd.Dir = filepath.Join(fixtures, "buildtest")
+	out, err := cmd.CombinedOutput()
+	assertNoError(err, t, "running trace")
+	if bytes.Contains(out, []byte("Stack:")) || bytes.Contains(out, []byte("main.foo")) {
+		t.Errorf("trace output written to the terminal:\n%s", out)
+	}
+
+	buf, err := ioutil.ReadFile(outfile)
+	assertNoError(err, t, "reading trace output")
+	if !bytes.Contains(buf, []byte("> goroutine(1): main.foo(99, 9801)")) || !bytes.Contains(buf, []byte(" => (9900)")) {
+		t.Errorf("tracepoint missing from the trace output:\n%s", buf)
+	}
+	// one stack for the call and one for the return
+	if n := bytes.Count(buf, []byte("Stack:")); n != 2 {
+		t.Errorf("expected 2 stacks, got %d:\n%s", n, buf)
+	}
+}
+
+func TestDwarf(t *testing.T) {
+	dlvbin, tmpdir := getDlvBin(t)
+	defer os.RemoveAll(tmpdir)
+
+	fix := protest.BuildFixture("testvariables2", 0)
+
+	dwarf := func(v interface{}, args ...string) {
+		t.Helper()
+		cmd := exec.Command(dlvbin, append([]string{"dwarf", "--json", fix.Path}, args...)...)
+		out, err := cmd.Output()
+		if err != nil {
+			t.Fatalf("error executing dlv dwarf %v: %v", args, err)
+		}
+		assertNoError(json.Unmarshal(out, v), t, "json.Unmarshal")
+	}
+
+	var cus []dwarfdump.CompileUnit
+	dwarf(&cus, "cu")
+	found := false
+	for _, cu := range cus {
+		if cu.Name == "main" && cu.Language == "Go" && strings.HasPrefix(cu.Producer, "Go cmd/compile") {
+			found = true
+		}
+	}
+	if !found {
+		t.Errorf("compile unit of package main not found in %#v", cus)
+	}
+
+	var types []dwarfdump.Type
+	dwarf(&types, "types", `^main\.a$`)
+	if len(types) != 1 || types[0].Kind != "struct" || len(types[0].Fields) != 1 || types[0].Fields[0].Name != "aas" || types[0].Fields[0].Type != "[]main.a" {
+		t.Errorf("wrong layout of main.a %#v", types)
+	}
+
+	var rows []dwarfdump.LineRow
+	dwarf(&rows, "lines", "testvariables2.go")
+	found = false
+	for _, row := range rows {
+		if row.PrologueEnd && row.IsStmt && row.Line == 48 {
+			found = true
+		}
+	}
+	if !found {
+		t.Errorf("prologue_end of main.afunc not found in %#v", rows)
+	}
+
+	var fns []dwarfdump.Function
+	dwarf(&fns, "vars", "main.afunc")
+	if len(fns) != 1 || len(fns[0].Variables) < 1 || fns[0].Variables[0].Name != "x" || !fns[0].Variables[0].Param || fns[0].Variables[0].Type != "int" || len(fns[0].Variables[0].Location) == 0 {
+		t.Fatalf("wrong variables of main.afunc %#v", fns)
+	}
+
+	var rules dwarfdump.FrameRules
+	dwarf(&rules, "frame", fmt.Sprintf("%#x", fns[0].Ranges[0][0]))
+	if rules.FDEStart != fns[0].Ranges[0][0] || rules.CFA.Rule != "cfa" {
+		t.Errorf("wrong frame rules at the entry point of main.afunc %#v", rules)
+	}
+
+	stripped := protest.BuildFixture("testvariables2", protest.LinkStrip)
+	out, err := exec.Command(dlvbin, "dwarf", stripped.Path, "cu").CombinedOutput()
+	if err == nil || !strings.Contains(string(out), "executable has no debug information") {
+		t.Errorf("unexpected output of dlv dwarf on a stripped executable: %v\n%s", err, out)
+	}
+}
+
 func TestDlvTestChdir(t *testing.T) {
 	dlvbin, tmpdir := getDlvBin(t)
 	defer os.RemoveAll(tmpdir)
@@ -64,6 +64,10 @@ type Config struct {
 	// DebugFileDirectories is the list of directories Delve will use
 	// in order to resolve external debug info files.
 	DebugInfoDirectories []string `yaml:"debug-info-directories"`
+
+	// PrettyPrinterScript is the path of a starlark script, executed when
+	// Delve starts, registering pretty printers with register_pretty_printer.
+	PrettyPrinterScript string `yaml:"pretty-printer-script,omitempty"`
 }
 
 func (c *Config) GetSourceListLineCount() int {
@@ -250,6 +254,9 @@ substitute-path:
 
 # List of directories to use when searching for separate debug info files.
 debug-info-directories: ["/usr/lib/debug/.build-id"]
+
+# Uncomment to load pretty printers from a starlark script when Delve starts.
+# pretty-printer-script: /path/to/prettyprinters.star
 `)
 	return err
 }
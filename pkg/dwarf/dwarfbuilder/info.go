@@ -233,6 +233,8 @@ func (b *Builder) makeAbbrevTable() []byte {
 		util.EncodeULEB128(&abbrev, 0)
 		util.EncodeULEB128(&abbrev, 0)
 	}
+	// the abbreviation table ends with an entry with a 0 code
+	util.EncodeULEB128(&abbrev, 0)
 
 	return abbrev.Bytes()
 }
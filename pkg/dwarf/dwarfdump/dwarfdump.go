@@ -0,0 +1,678 @@
+// Package dwarfdump reads the debug information of an executable file and
+// describes it in a form that can be inspected by a human or serialized to
+// JSON, it is used to implement the 'dlv dwarf' command.
+package dwarfdump
+
+import (
+	"bytes"
+	"debug/dwarf"
+	"debug/elf"
+	"debug/macho"
+	"debug/pe"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/go-delve/delve/pkg/dwarf/frame"
+	"github.com/go-delve/delve/pkg/dwarf/godwarf"
+	"github.com/go-delve/delve/pkg/dwarf/line"
+	"github.com/go-delve/delve/pkg/dwarf/loclist"
+	"github.com/go-delve/delve/pkg/dwarf/op"
+	"github.com/go-delve/delve/pkg/dwarf/util"
+)
+
+const (
+	dwarfGoLanguage       = 22 // DW_LANG_Go (from DWARF v5, section 7.12, page 231)
+	dwarfAttrAddrBase     = 0x73
+	dwarfAttrLoclistsBase = 0x8c
+)
+
+// ErrNoDebugInfo is returned by Open when the executable does not contain
+// debug information.
+var ErrNoDebugInfo = errors.New("executable has no debug information")
+
+// File is an executable file opened for inspection of its debug
+// information. All addresses reported by File are the addresses written
+// in the file, they are not relocated.
+type File struct {
+	Path string
+
+	dwarf   *dwarf.Data
+	ptrSize int
+	windows bool
+	closer  io.Closer
+
+	debugInfo    []byte
+	debugLine    []byte
+	debugLineStr []byte
+	loclist2     *loclist.Dwarf2Reader
+	loclist5     *loclist.Dwarf5Reader
+	debugAddr    *godwarf.DebugAddrSection
+
+	frameEntries frame.FrameDescriptionEntries
+
+	compileUnits []*compileUnit
+}
+
+type compileUnit struct {
+	entry    *dwarf.Entry
+	version  uint8
+	name     string
+	compdir  string
+	lowPC    uint64
+	ranges   [][2]uint64
+	lineInfo *line.DebugLineInfo
+}
+
+// Open opens the executable file at path, which can be an ELF, Mach-O or
+// PE file.
+func Open(path string) (*File, error) {
+	f := &File{Path: path}
+	var err error
+	if elfFile, elfErr := elf.Open(path); elfErr == nil {
+		f.closer = elfFile
+		err = f.loadElf(elfFile)
+	} else if machoFile, machoErr := macho.Open(path); machoErr == nil {
+		f.closer = machoFile
+		err = f.loadMacho(machoFile)
+	} else if peFile, peErr := pe.Open(path); peErr == nil {
+		f.closer = peFile
+		err = f.loadPE(peFile)
+	} else {
+		return nil, fmt.Errorf("could not open %s: unsupported executable format", path)
+	}
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+	return f, nil
+}
+
+// Close closes the executable file.
+func (f *File) Close() error {
+	return f.closer.Close()
+}
+
+func (f *File) loadElf(exe *elf.File) error {
+	f.ptrSize = 8
+	if exe.Class == elf.ELFCLASS32 {
+		f.ptrSize = 4
+	}
+	err := f.loadSections(exe.DWARF, func(name string) ([]byte, error) {
+		return godwarf.GetDebugSectionElf(exe, name)
+	})
+	if err != nil {
+		return err
+	}
+	if sec := exe.Section(".eh_frame"); sec != nil && sec.Type != elf.SHT_NOBITS {
+		data, err := sec.Data()
+		if err != nil {
+			return err
+		}
+		// entries parsed before an error are still used
+		fdes, _ := frame.ParseEhFrame(data, exe.ByteOrder, 0, f.ptrSize, sec.Addr, frame.EhFrameDataBaseElf(exe))
+		newfdes := make(frame.FrameDescriptionEntries, 0, len(fdes))
+		for _, fde := range fdes {
+			if _, err := f.frameEntries.FDEForPC(fde.Begin()); err == nil {
+				continue
+			}
+			newfdes = append(newfdes, fde)
+		}
+		f.frameEntries = f.frameEntries.Append(newfdes)
+	}
+	return nil
+}
+
+func (f *File) loadMacho(exe *macho.File) error {
+	f.ptrSize = 8
+	if exe.Magic == macho.Magic32 {
+		f.ptrSize = 4
+	}
+	return f.loadSections(exe.DWARF, func(name string) ([]byte, error) {
+		return godwarf.GetDebugSectionMacho(exe, name)
+	})
+}
+
+func (f *File) loadPE(exe *pe.File) error {
+	f.ptrSize = 8
+	if exe.Machine == pe.IMAGE_FILE_MACHINE_I386 {
+		f.ptrSize = 4
+	}
+	f.windows = true
+	return f.loadSections(exe.DWARF, func(name string) ([]byte, error) {
+		return godwarf.GetDebugSectionPE(exe, name)
+	})
+}
+
+func (f *File) loadSections(dwarfFn func() (*dwarf.Data, error), section func(name string) ([]byte, error)) error {
+	var err error
+	f.debugInfo, err = section("info")
+	if err != nil || len(f.debugInfo) == 0 {
+		return ErrNoDebugInfo
+	}
+	f.dwarf, err = dwarfFn()
+	if err != nil {
+		return err
+	}
+	f.debugLine, _ = section("line")
+	f.debugLineStr, _ = section("line_str")
+	debugLocBytes, _ := section("loc")
+	f.loclist2 = loclist.NewDwarf2Reader(debugLocBytes, f.ptrSize)
+	debugLoclistBytes, _ := section("loclists")
+	f.loclist5 = loclist.NewDwarf5Reader(debugLoclistBytes)
+	debugAddrBytes, _ := section("addr")
+	f.debugAddr = godwarf.ParseAddr(debugAddrBytes, frame.DwarfEndian(f.debugInfo), f.ptrSize)
+	if debugFrameBytes, err := section("frame"); err == nil {
+		f.frameEntries = f.frameEntries.Append(frame.Parse(debugFrameBytes, frame.DwarfEndian(f.debugInfo), 0, f.ptrSize))
+	}
+	return f.loadCompileUnits()
+}
+
+func (f *File) loadCompileUnits() error {
+	versions := util.ReadUnitVersions(f.debugInfo)
+	rdr := f.dwarf.Reader()
+	for {
+		entry, err := rdr.Next()
+		if err != nil {
+			return err
+		}
+		if entry == nil {
+			break
+		}
+		if entry.Tag != dwarf.TagCompileUnit && entry.Tag != dwarf.TagPartialUnit {
+			rdr.SkipChildren()
+			continue
+		}
+		cu := &compileUnit{entry: entry, version: versions[entry.Offset]}
+		cu.name, _ = entry.Val(dwarf.AttrName).(string)
+		cu.compdir, _ = entry.Val(dwarf.AttrCompDir).(string)
+		cu.ranges, _ = f.dwarf.Ranges(entry)
+		if lowpc, ok := entry.Val(dwarf.AttrLowpc).(uint64); ok {
+			cu.lowPC = lowpc
+		} else if len(cu.ranges) > 0 {
+			cu.lowPC = cu.ranges[0][0]
+		}
+		if off, ok := entry.Val(dwarf.AttrStmtList).(int64); ok && off >= 0 && off < int64(len(f.debugLine)) {
+			cu.lineInfo = line.Parse(cu.compdir, bytes.NewBuffer(f.debugLine[off:]), f.debugLineStr, nil, 0, f.windows, f.ptrSize)
+		}
+		f.compileUnits = append(f.compileUnits, cu)
+		rdr.SkipChildren()
+	}
+	return nil
+}
+
+// CompileUnit describes a compile unit.
+type CompileUnit struct {
+	Offset   dwarf.Offset `json:"offset"`
+	Version  uint8        `json:"version"`
+	Name     string       `json:"name"`
+	CompDir  string       `json:"compDir,omitempty"`
+	Producer string       `json:"producer,omitempty"`
+	Language string       `json:"language,omitempty"`
+	Ranges   [][2]uint64  `json:"ranges"`
+}
+
+// CompileUnits returns the list of compile units of the file.
+func (f *File) CompileUnits() []CompileUnit {
+	r := make([]CompileUnit, 0, len(f.compileUnits))
+	for _, cu := range f.compileUnits {
+		producer, _ := cu.entry.Val(dwarf.AttrProducer).(string)
+		r = append(r, CompileUnit{
+			Offset:   cu.entry.Offset,
+			Version:  cu.version,
+			Name:     cu.name,
+			CompDir:  cu.compdir,
+			Producer: producer,
+			Language: languageName(cu.entry),
+			Ranges:   cu.ranges,
+		})
+	}
+	return r
+}
+
+func languageName(entry *dwarf.Entry) string {
+	lang, ok := entry.Val(dwarf.AttrLanguage).(int64)
+	if !ok {
+		return ""
+	}
+	switch lang {
+	case 0x01, 0x02, 0x0c, 0x1d:
+		return "C"
+	case 0x04, 0x1a, 0x21:
+		return "C++"
+	case 0x1c:
+		return "Rust"
+	case dwarfGoLanguage:
+		return "Go"
+	case 0x8001:
+		return "Mips_Assembler"
+	default:
+		return fmt.Sprintf("%#x", lang)
+	}
+}
+
+// Type describes the layout of a type.
+type Type struct {
+	Offset dwarf.Offset `json:"offset"`
+	Name   string       `json:"name"`
+	Kind   string       `json:"kind"`
+	Size   int64        `json:"size"`
+	Fields []Field      `json:"fields,omitempty"`
+}
+
+// Field describes a field of a struct, union or class type.
+type Field struct {
+	Name      string `json:"name"`
+	Type      string `json:"type"`
+	Offset    int64  `json:"offset"`
+	Size      int64  `json:"size"`
+	BitOffset int64  `json:"bitOffset,omitempty"`
+	BitSize   int64  `json:"bitSize,omitempty"`
+	Embedded  bool   `json:"embedded,omitempty"`
+}
+
+// Types returns the layout of all types with a name matching the regular
+// expression filter. Types defined in more than one compile unit are only
+// returned once.
+func (f *File) Types(filter string) ([]Type, error) {
+	re, err := regexp.Compile(filter)
+	if err != nil {
+		return nil, fmt.Errorf("invalid filter argument: %s", err.Error())
+	}
+	r := []Type{}
+	seen := make(map[string]bool)
+	typeCache := make(map[dwarf.Offset]godwarf.Type)
+	rdr := f.dwarf.Reader()
+	for {
+		entry, err := rdr.Next()
+		if err != nil {
+			return nil, err
+		}
+		if entry == nil {
+			break
+		}
+		if !isTypeTag(entry.Tag) {
+			continue
+		}
+		if decl, _ := entry.Val(dwarf.AttrDeclaration).(bool); decl {
+			continue
+		}
+		name, _ := entry.Val(dwarf.AttrName).(string)
+		if name == "" || seen[name] || !re.MatchString(name) {
+			continue
+		}
+		typ, err := godwarf.ReadType(f.dwarf, 0, entry.Offset, typeCache)
+		if err != nil {
+			return nil, err
+		}
+		seen[name] = true
+		t := Type{Offset: entry.Offset, Name: name, Kind: typeKind(typ), Size: typ.Size()}
+		if st, isstruct := typ.(*godwarf.StructType); isstruct {
+			for _, field := range st.Field {
+				t.Fields = append(t.Fields, Field{
+					Name:      field.Name,
+					Type:      field.Type.String(),
+					Offset:    field.ByteOffset,
+					Size:      field.Type.Size(),
+					BitOffset: field.BitOffset,
+					BitSize:   field.BitSize,
+					Embedded:  field.Embedded,
+				})
+			}
+		}
+		r = append(r, t)
+	}
+	return r, nil
+}
+
+func isTypeTag(tag dwarf.Tag) bool {
+	switch tag {
+	case dwarf.TagArrayType, dwarf.TagBaseType, dwarf.TagClassType, dwarf.TagStructType, dwarf.TagUnionType, dwarf.TagEnumerationType, dwarf.TagPointerType, dwarf.TagSubroutineType, dwarf.TagTypedef, dwarf.TagUnspecifiedType:
+		return true
+	default:
+		return false
+	}
+}
+
+func typeKind(typ godwarf.Type) string {
+	if kind := typ.Common().ReflectKind; kind != 0 {
+		return kind.String()
+	}
+	switch typ := typ.(type) {
+	case *godwarf.StructType:
+		return typ.Kind
+	case *godwarf.PtrType:
+		return "pointer"
+	case *godwarf.ArrayType:
+		return "array"
+	case *godwarf.EnumType:
+		return "enum"
+	case *godwarf.FuncType:
+		return "func"
+	case *godwarf.TypedefType:
+		return "typedef"
+	case *godwarf.UnspecifiedType:
+		return "unspecified"
+	default:
+		return "base"
+	}
+}
+
+// LineRow is a row of the line number table.
+type LineRow struct {
+	Address       uint64 `json:"address"`
+	File          string `json:"file"`
+	Line          int    `json:"line"`
+	Column        uint   `json:"column"`
+	IsStmt        bool   `json:"isStmt"`
+	PrologueEnd   bool   `json:"prologueEnd"`
+	EpilogueBegin bool   `json:"epilogueBegin"`
+	EndSequence   bool   `json:"endSequence"`
+}
+
+// Lines returns the rows of the line number tables of all compile units
+// that belong to file. A row belongs to file if its path is file or ends
+// with file.
+func (f *File) Lines(file string) []LineRow {
+	file = strings.Replace(file, "\\", "/", -1)
+	r := []LineRow{}
+	for _, cu := range f.compileUnits {
+		for _, row := range cu.lineInfo.Rows() {
+			if row.File != file && !strings.HasSuffix(row.File, "/"+file) {
+				continue
+			}
+			r = append(r, LineRow{
+				Address:       row.Address,
+				File:          row.File,
+				Line:          row.Line,
+				Column:        row.Column,
+				IsStmt:        row.IsStmt,
+				PrologueEnd:   row.PrologueEnd,
+				EpilogueBegin: row.EpilogueBegin,
+				EndSequence:   row.EndSequence,
+			})
+		}
+	}
+	return r
+}
+
+// FrameRules describes how the canonical frame address (CFA) and the
+// registers of the caller frame are computed at a given address.
+type FrameRules struct {
+	PC         uint64    `json:"pc"`
+	FDEStart   uint64    `json:"fdeStart"`
+	FDEEnd     uint64    `json:"fdeEnd"`
+	RetAddrReg uint64    `json:"retAddrReg"`
+	CFA        RegRule   `json:"cfa"`
+	Regs       []RegRule `json:"regs"`
+}
+
+// RegRule is the rule used to compute the CFA or the value of a register
+// of the caller frame.
+type RegRule struct {
+	Reg        uint64 `json:"reg"`
+	Rule       string `json:"rule"`
+	Offset     int64  `json:"offset,omitempty"`
+	Register   uint64 `json:"register,omitempty"`
+	Expression string `json:"expression,omitempty"`
+}
+
+// Frame returns the rules to compute the CFA and the registers of the
+// caller frame at pc.
+func (f *File) Frame(pc uint64) (*FrameRules, error) {
+	fde, err := f.frameEntries.FDEForPC(pc)
+	if err != nil {
+		return nil, err
+	}
+	fctxt := fde.EstablishFrame(pc)
+	r := &FrameRules{
+		PC:         pc,
+		FDEStart:   fde.Begin(),
+		FDEEnd:     fde.End(),
+		RetAddrReg: fctxt.RetAddrReg,
+		CFA:        regRule(0, fctxt.CFA),
+		Regs:       []RegRule{},
+	}
+	for reg, rule := range fctxt.Regs {
+		r.Regs = append(r.Regs, regRule(reg, rule))
+	}
+	sort.Slice(r.Regs, func(i, j int) bool { return r.Regs[i].Reg < r.Regs[j].Reg })
+	return r, nil
+}
+
+func regRule(reg uint64, rule frame.DWRule) RegRule {
+	r := RegRule{Reg: reg, Offset: rule.Offset}
+	switch rule.Rule {
+	case frame.RuleUndefined:
+		r.Rule = "undefined"
+	case frame.RuleSameVal:
+		r.Rule = "same_value"
+	case frame.RuleOffset:
+		r.Rule = "offset"
+	case frame.RuleValOffset:
+		r.Rule = "val_offset"
+	case frame.RuleRegister:
+		r.Rule = "register"
+		r.Register = rule.Reg
+	case frame.RuleExpression:
+		r.Rule = "expression"
+	case frame.RuleValExpression:
+		r.Rule = "val_expression"
+	case frame.RuleArchitectural:
+		r.Rule = "architectural"
+	case frame.RuleCFA:
+		r.Rule = "cfa"
+		r.Register = rule.Reg
+	case frame.RuleFramePointer:
+		r.Rule = "frame_pointer"
+		r.Register = rule.Reg
+	default:
+		r.Rule = fmt.Sprintf("unknown(%d)", rule.Rule)
+	}
+	if rule.Expression != nil {
+		r.Expression = prettyPrint(rule.Expression)
+	}
+	return r
+}
+
+// String returns a description of the rule, registers are identified by
+// their DWARF register number.
+func (rule RegRule) String() string {
+	switch rule.Rule {
+	case "offset":
+		return fmt.Sprintf("[cfa%+d]", rule.Offset)
+	case "val_offset":
+		return fmt.Sprintf("cfa%+d", rule.Offset)
+	case "register":
+		return fmt.Sprintf("r%d", rule.Register)
+	case "cfa":
+		return fmt.Sprintf("r%d%+d", rule.Register, rule.Offset)
+	case "frame_pointer":
+		return fmt.Sprintf("[r%d%+d] (frame pointer)", rule.Register, rule.Offset)
+	case "expression":
+		return fmt.Sprintf("[%s]", rule.Expression)
+	case "val_expression":
+		return rule.Expression
+	default:
+		return rule.Rule
+	}
+}
+
+// Function describes the variables of a function.
+type Function struct {
+	Offset    dwarf.Offset `json:"offset"`
+	Name      string       `json:"name"`
+	CompUnit  string       `json:"compUnit"`
+	Ranges    [][2]uint64  `json:"ranges"`
+	Variables []Variable   `json:"variables"`
+}
+
+// Variable describes a formal parameter or a local variable of a function.
+type Variable struct {
+	Offset dwarf.Offset `json:"offset"`
+	Name   string       `json:"name"`
+	Type   string       `json:"type"`
+	Param  bool         `json:"param"`
+	// Depth is the nesting level of the lexical block declaring the
+	// variable, 0 for variables declared at function scope.
+	Depth int `json:"depth"`
+	// Location is the list of location expressions of the variable. A
+	// variable with a single location expression valid for its whole
+	// scope has a single entry with a zero address range.
+	Location []LocationEntry `json:"location"`
+	// Coverage is the fraction of the address range of the scope of the
+	// variable where its location is known.
+	Coverage float64 `json:"coverage"`
+	// Covered is the list of address ranges where the location of the
+	// variable is known.
+	Covered [][2]uint64 `json:"covered"`
+	Error   string      `json:"error,omitempty"`
+}
+
+// LocationEntry is an entry of a location list.
+type LocationEntry struct {
+	LowPC  uint64 `json:"lowPC"`
+	HighPC uint64 `json:"highPC"`
+	Expr   string `json:"expr"`
+}
+
+// Vars returns the variables of all functions named fn.
+func (f *File) Vars(fn string) ([]Function, error) {
+	r := []Function{}
+	for _, cu := range f.compileUnits {
+		rdr := f.dwarf.Reader()
+		rdr.Seek(cu.entry.Offset)
+		rdr.Next()
+		for {
+			entry, err := rdr.Next()
+			if err != nil {
+				return nil, err
+			}
+			if entry == nil || entry.Tag == dwarf.TagCompileUnit || entry.Tag == dwarf.TagPartialUnit {
+				break
+			}
+			if entry.Tag != dwarf.TagSubprogram {
+				continue
+			}
+			rdr.SkipChildren()
+			if name, _ := entry.Val(dwarf.AttrName).(string); name != fn {
+				continue
+			}
+			root, err := godwarf.LoadTree(entry.Offset, f.dwarf, 0)
+			if err != nil {
+				return nil, err
+			}
+			if len(root.Ranges) == 0 {
+				// declarations and abstract instances of inlined functions
+				continue
+			}
+			fnr := Function{Offset: entry.Offset, Name: fn, CompUnit: cu.name, Ranges: root.Ranges, Variables: []Variable{}}
+			fnr.Variables = f.variables(cu, fnr.Variables, root, 0)
+			r = append(r, fnr)
+		}
+	}
+	return r, nil
+}
+
+func (f *File) variables(cu *compileUnit, vars []Variable, scope *godwarf.Tree, depth int) []Variable {
+	for _, n := range scope.Children {
+		switch n.Tag {
+		case dwarf.TagFormalParameter, dwarf.TagVariable:
+			vars = append(vars, f.variable(cu, n, scope, depth))
+		case dwarf.TagLexDwarfBlock:
+			vars = f.variables(cu, vars, n, depth+1)
+		}
+	}
+	return vars
+}
+
+func (f *File) variable(cu *compileUnit, n, scope *godwarf.Tree, depth int) Variable {
+	v := Variable{Offset: n.Offset, Param: n.Tag == dwarf.TagFormalParameter, Depth: depth, Location: []LocationEntry{}, Covered: [][2]uint64{}}
+	v.Name, _ = n.Val(dwarf.AttrName).(string)
+	if typ, err := n.Type(f.dwarf, 0, make(map[dwarf.Offset]godwarf.Type)); err == nil {
+		v.Type = typ.String()
+	}
+	switch loc := n.Val(dwarf.AttrLocation).(type) {
+	case nil:
+		return v
+	case []byte:
+		v.Location = append(v.Location, LocationEntry{Expr: prettyPrint(loc)})
+		v.Covered = scope.Ranges
+		v.Coverage = 1
+		return v
+	default:
+		entries, err := f.locationList(cu, loc)
+		if err != nil {
+			v.Error = err.Error()
+			return v
+		}
+		for _, e := range entries {
+			v.Location = append(v.Location, LocationEntry{LowPC: e.LowPC, HighPC: e.HighPC, Expr: prettyPrint(e.Instr)})
+			if len(e.Instr) > 0 {
+				v.Covered = append(v.Covered, [2]uint64{e.LowPC, e.HighPC})
+			}
+		}
+	}
+	var covered, total uint64
+	for _, rng := range scope.Ranges {
+		total += rng[1] - rng[0]
+		for _, c := range v.Covered {
+			lo, hi := c[0], c[1]
+			if lo < rng[0] {
+				lo = rng[0]
+			}
+			if hi > rng[1] {
+				hi = rng[1]
+			}
+			if lo < hi {
+				covered += hi - lo
+			}
+		}
+	}
+	if total > 0 {
+		v.Coverage = float64(covered) / float64(total)
+	}
+	return v
+}
+
+// locationList returns the entries of the location list referenced by the
+// location attribute a of a variable of cu.
+func (f *File) locationList(cu *compileUnit, a interface{}) ([]loclist.Entry, error) {
+	var rdr loclist.Reader = f.loclist2
+	var debugAddr *godwarf.DebugAddr
+	if cu.version >= 5 && f.loclist5 != nil {
+		rdr = f.loclist5
+		addrBase, _ := cu.entry.Val(dwarfAttrAddrBase).(int64)
+		debugAddr = f.debugAddr.GetSubsection(uint64(addrBase))
+	}
+	if rdr.Empty() {
+		return nil, errors.New("location list section not found")
+	}
+	var off int64
+	switch a := a.(type) {
+	case int64:
+		off = a
+	case uint64:
+		if f.loclist5.Empty() {
+			return nil, errors.New("location list index without debug_loclists section")
+		}
+		locListsBase, _ := cu.entry.Val(dwarfAttrLoclistsBase).(int64)
+		off5, err := f.loclist5.Offset(uint64(locListsBase), a)
+		if err != nil {
+			return nil, err
+		}
+		off = int64(off5)
+	default:
+		return nil, fmt.Errorf("location attribute of unsupported type %T", a)
+	}
+	return rdr.Entries(int(off), 0, cu.lowPC, debugAddr)
+}
+
+func prettyPrint(instr []byte) string {
+	var buf bytes.Buffer
+	op.PrettyPrint(&buf, instr)
+	return strings.TrimSpace(buf.String())
+}
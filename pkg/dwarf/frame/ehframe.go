@@ -0,0 +1,186 @@
+package frame
+
+import (
+	"bytes"
+	"debug/elf"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/go-delve/delve/pkg/dwarf/util"
+)
+
+// ptrEnc is the encoding of a pointer in a .eh_frame or .eh_frame_hdr
+// section (the DW_EH_PE_* constants of the Linux Standard Base).
+type ptrEnc uint8
+
+const (
+	ptrEncAbs    ptrEnc = 0x00 // pointer-sized unsigned integer
+	ptrEncOmit   ptrEnc = 0xff // pointer not present
+	ptrEncUleb   ptrEnc = 0x01 // ULEB128
+	ptrEncUdata2 ptrEnc = 0x02 // 2 bytes
+	ptrEncUdata4 ptrEnc = 0x03 // 4 bytes
+	ptrEncUdata8 ptrEnc = 0x04 // 8 bytes
+	ptrEncSigned ptrEnc = 0x08 // pointer-sized signed integer
+	ptrEncSleb   ptrEnc = 0x09 // SLEB128
+	ptrEncSdata2 ptrEnc = 0x0a // 2 bytes, signed
+	ptrEncSdata4 ptrEnc = 0x0b // 4 bytes, signed
+	ptrEncSdata8 ptrEnc = 0x0c // 8 bytes, signed
+
+	ptrEncPCRel    ptrEnc = 0x10 // value is relative to the address of the pointer
+	ptrEncTextRel  ptrEnc = 0x20 // value is relative to the start of .text
+	ptrEncDataRel  ptrEnc = 0x30 // value is relative to the start of .got or .eh_frame_hdr
+	ptrEncFuncRel  ptrEnc = 0x40 // value is relative to the start of the function
+	ptrEncAligned  ptrEnc = 0x50 // value is aligned to the pointer size
+	ptrEncIndirect ptrEnc = 0x80 // pointer to the value
+)
+
+// Supported returns true if this pointer encoding can be decoded.
+func (ptrEnc ptrEnc) Supported() bool {
+	if ptrEnc == ptrEncOmit {
+		return true
+	}
+	switch ptrEnc & 0x0f {
+	case ptrEncAbs, ptrEncUleb, ptrEncUdata2, ptrEncUdata4, ptrEncUdata8, ptrEncSigned, ptrEncSleb, ptrEncSdata2, ptrEncSdata4, ptrEncSdata8:
+	default:
+		return false
+	}
+	switch ptrEnc & 0x70 {
+	case ptrEncAbs, ptrEncPCRel, ptrEncDataRel:
+	default:
+		return false
+	}
+	return ptrEnc&ptrEncIndirect == 0
+}
+
+// readEncodedPtr reads a pointer encoded as specified by ptrEnc from buf.
+// Addr is the address of the pointer, used by pc relative pointers,
+// dataBase is the address used by data relative pointers.
+func readEncodedPtr(addr, dataBase uint64, buf *bytes.Buffer, ptrEnc ptrEnc, order binary.ByteOrder, ptrSize int) (uint64, error) {
+	if ptrEnc == ptrEncOmit {
+		return 0, nil
+	}
+	if !ptrEnc.Supported() {
+		return 0, fmt.Errorf("pointer encoding not supported %#x", uint8(ptrEnc))
+	}
+
+	var ptr uint64
+	var err error
+	switch ptrEnc & 0x0f {
+	case ptrEncAbs, ptrEncSigned:
+		ptr, err = util.ReadUintRaw(buf, order, ptrSize)
+		if err == nil && ptrSize == 4 && ptrEnc&0x0f == ptrEncSigned {
+			ptr = uint64(int32(ptr))
+		}
+	case ptrEncUleb:
+		ptr, _ = util.DecodeULEB128(buf)
+	case ptrEncSleb:
+		n, _ := util.DecodeSLEB128(buf)
+		ptr = uint64(n)
+	case ptrEncUdata2, ptrEncSdata2:
+		ptr, err = util.ReadUintRaw(buf, order, 2)
+		if ptrEnc&0x0f == ptrEncSdata2 {
+			ptr = uint64(int16(ptr))
+		}
+	case ptrEncUdata4, ptrEncSdata4:
+		ptr, err = util.ReadUintRaw(buf, order, 4)
+		if ptrEnc&0x0f == ptrEncSdata4 {
+			ptr = uint64(int32(ptr))
+		}
+	case ptrEncUdata8, ptrEncSdata8:
+		ptr, err = util.ReadUintRaw(buf, order, 8)
+	}
+	if err != nil {
+		return 0, err
+	}
+
+	switch ptrEnc & 0x70 {
+	case ptrEncPCRel:
+		ptr += addr
+	case ptrEncDataRel:
+		if dataBase == 0 {
+			return 0, errors.New("data relative pointer without a base address")
+		}
+		ptr += dataBase
+	}
+	if ptrSize == 4 {
+		ptr &= 0xffffffff
+	}
+	return ptr, nil
+}
+
+// EhFrameDataBaseElf returns the address that data relative pointers in
+// the .eh_frame section of exe are relative to: the global offset table,
+// as in libgcc, or the .eh_frame_hdr section if there is no global offset
+// table.
+func EhFrameDataBaseElf(exe *elf.File) uint64 {
+	for _, name := range []string{".got.plt", ".got", ".eh_frame_hdr"} {
+		if sec := exe.Section(name); sec != nil {
+			return sec.Addr
+		}
+	}
+	return 0
+}
+
+// EhFrameHdr is the content of a .eh_frame_hdr section: the address of
+// the .eh_frame section and a search table of its FDEs.
+type EhFrameHdr struct {
+	// EhFrameAddr is the address of the .eh_frame section.
+	EhFrameAddr uint64
+	// Table contains the addresses of the FDEs in .eh_frame, sorted by the
+	// initial location of the FDE.
+	Table []EhFrameHdrEntry
+}
+
+// EhFrameHdrEntry is an entry of the search table of .eh_frame_hdr.
+type EhFrameHdrEntry struct {
+	InitialLoc uint64 // address of the first instruction covered by the FDE
+	FDEAddr    uint64 // address of the FDE
+}
+
+// ParseEhFrameHdr parses the contents of a .eh_frame_hdr section, hdrAddr
+// is the address of the section.
+func ParseEhFrameHdr(data []byte, order binary.ByteOrder, ptrSize int, hdrAddr uint64) (*EhFrameHdr, error) {
+	if len(data) < 4 {
+		return nil, errors.New("truncated .eh_frame_hdr")
+	}
+	if data[0] != 1 {
+		return nil, fmt.Errorf("unsupported .eh_frame_hdr version %d", data[0])
+	}
+	ehFramePtrEnc, fdeCountEnc, tableEnc := ptrEnc(data[1]), ptrEnc(data[2]), ptrEnc(data[3])
+	buf := bytes.NewBuffer(data[4:])
+
+	// pointers in .eh_frame_hdr that are relative to data are relative to
+	// the start of .eh_frame_hdr itself
+	read := func(enc ptrEnc) (uint64, error) {
+		addr := hdrAddr + uint64(len(data)-buf.Len())
+		return readEncodedPtr(addr, hdrAddr, buf, enc, order, ptrSize)
+	}
+
+	hdr := &EhFrameHdr{}
+	var err error
+	hdr.EhFrameAddr, err = read(ehFramePtrEnc)
+	if err != nil {
+		return nil, fmt.Errorf("could not read .eh_frame pointer: %v", err)
+	}
+	if fdeCountEnc == ptrEncOmit || tableEnc == ptrEncOmit {
+		return hdr, nil
+	}
+	fdeCount, err := read(fdeCountEnc)
+	if err != nil {
+		return nil, fmt.Errorf("could not read FDE count: %v", err)
+	}
+	for i := uint64(0); i < fdeCount && buf.Len() > 0; i++ {
+		var e EhFrameHdrEntry
+		if e.InitialLoc, err = read(tableEnc); err != nil {
+			return nil, err
+		}
+		if e.FDEAddr, err = read(tableEnc); err != nil {
+			return nil, err
+		}
+		hdr.Table = append(hdr.Table, e)
+	}
+	sort.Slice(hdr.Table, func(i, j int) bool { return hdr.Table[i].InitialLoc < hdr.Table[j].InitialLoc })
+	return hdr, nil
+}
@@ -7,7 +7,7 @@ import (
 )
 
 // Represents a Common Information Entry in
-// the Dwarf .debug_frame section.
+// the Dwarf .debug_frame or .eh_frame section.
 type CommonInformationEntry struct {
 	Length                uint32
 	CIE_id                uint32
@@ -18,10 +18,18 @@ type CommonInformationEntry struct {
 	ReturnAddressRegister uint64
 	InitialInstructions   []byte
 	staticBase            uint64
+
+	// SignalFrame is true if the FDEs using this CIE describe signal
+	// handler frames (the 'S' augmentation of .eh_frame).
+	SignalFrame bool
+
+	// ptrEncAddr is the encoding of the addresses of the FDEs using this
+	// CIE (the 'R' augmentation of .eh_frame).
+	ptrEncAddr ptrEnc
 }
 
 // Represents a Frame Descriptor Entry in the
-// Dwarf .debug_frame section.
+// Dwarf .debug_frame or .eh_frame section.
 type FrameDescriptionEntry struct {
 	Length       uint32
 	CIE          *CommonInformationEntry
@@ -1,11 +1,14 @@
 // Package frame contains data structures and
 // related functions for parsing and searching
-// through Dwarf .debug_frame data.
+// through Dwarf .debug_frame and .eh_frame data.
 package frame
 
 import (
 	"bytes"
 	"encoding/binary"
+	"errors"
+	"fmt"
+	"strings"
 
 	"github.com/go-delve/delve/pkg/dwarf/util"
 )
@@ -16,66 +19,163 @@ type parseContext struct {
 	staticBase uint64
 
 	buf     *bytes.Buffer
+	totsz   int
+	order   binary.ByteOrder
 	entries FrameDescriptionEntries
+	ciemap  map[int]*CommonInformationEntry
 	common  *CommonInformationEntry
 	frame   *FrameDescriptionEntry
 	length  uint32
 	ptrSize int
+	err     error
+
+	// ehFrameAddr is the address of the .eh_frame section, zero when parsing
+	// a .debug_frame section.
+	ehFrameAddr uint64
+	// dataBase is the address data relative pointers in .eh_frame are
+	// relative to.
+	dataBase uint64
 }
 
 // Parse takes in data (a byte slice) and returns a slice of
 // commonInformationEntry structures. Each commonInformationEntry
 // has a slice of frameDescriptionEntry structures.
 func Parse(data []byte, order binary.ByteOrder, staticBase uint64, ptrSize int) FrameDescriptionEntries {
-	var (
-		buf  = bytes.NewBuffer(data)
-		pctx = &parseContext{buf: buf, entries: newFrameIndex(), staticBase: staticBase, ptrSize: ptrSize}
-	)
+	pctx := newParseContext(data, order, staticBase, ptrSize, 0, 0)
+	pctx.parse()
+	return pctx.entries
+}
+
+// ParseEhFrame parses the contents of a .eh_frame section, ehFrameAddr is
+// the address of the section (before relocation), used to decode pc
+// relative pointers, dataBase is the address used to decode data relative
+// pointers (see EhFrameDataBaseElf).
+// The entries parsed before an error is encountered are returned along
+// with the error.
+func ParseEhFrame(data []byte, order binary.ByteOrder, staticBase uint64, ptrSize int, ehFrameAddr, dataBase uint64) (FrameDescriptionEntries, error) {
+	if ehFrameAddr == 0 {
+		return nil, errors.New("unknown .eh_frame address")
+	}
+	pctx := newParseContext(data, order, staticBase, ptrSize, ehFrameAddr, dataBase)
+	pctx.parse()
+	return pctx.entries, pctx.err
+}
+
+func newParseContext(data []byte, order binary.ByteOrder, staticBase uint64, ptrSize int, ehFrameAddr, dataBase uint64) *parseContext {
+	return &parseContext{buf: bytes.NewBuffer(data), totsz: len(data), order: order, entries: newFrameIndex(), ciemap: make(map[int]*CommonInformationEntry), staticBase: staticBase, ptrSize: ptrSize, ehFrameAddr: ehFrameAddr, dataBase: dataBase}
+}
 
-	for fn := parselength; buf.Len() != 0; {
-		fn = fn(pctx)
+func (ctx *parseContext) parse() {
+	for fn := parselength; ctx.buf.Len() != 0 && ctx.err == nil; {
+		fn = fn(ctx)
 	}
 
-	for i := range pctx.entries {
-		pctx.entries[i].order = order
+	for i := range ctx.entries {
+		ctx.entries[i].order = ctx.order
 	}
+}
 
-	return pctx.entries
+func (ctx *parseContext) parsingEHFrame() bool {
+	return ctx.ehFrameAddr > 0
 }
 
-func cieEntry(data []byte) bool {
-	return bytes.Equal(data, []byte{0xff, 0xff, 0xff, 0xff})
+// offset returns the offset of the parser from the start of the section.
+func (ctx *parseContext) offset() int {
+	return ctx.totsz - ctx.buf.Len()
+}
+
+func (ctx *parseContext) cieEntry(cieid uint32) bool {
+	if ctx.parsingEHFrame() {
+		return cieid == 0
+	}
+	return cieid == 0xffffffff
 }
 
 func parselength(ctx *parseContext) parsefunc {
-	binary.Read(ctx.buf, binary.LittleEndian, &ctx.length)
+	start := ctx.offset()
+	binary.Read(ctx.buf, ctx.order, &ctx.length)
 
 	if ctx.length == 0 {
+		if ctx.parsingEHFrame() {
+			// ZERO terminator of .eh_frame
+			ctx.buf.Reset()
+		}
 		// ZERO terminator
 		return parselength
 	}
 
-	var data = ctx.buf.Next(4)
+	if ctx.length == 0xffffffff {
+		ctx.err = fmt.Errorf("64bit DWARF frame entry at %#x not supported", start)
+		return parselength
+	}
+
+	var cieid uint32
+	binary.Read(ctx.buf, ctx.order, &cieid)
 
 	ctx.length -= 4 // take off the length of the CIE id / CIE pointer.
 
-	if cieEntry(data) {
-		ctx.common = &CommonInformationEntry{Length: ctx.length, staticBase: ctx.staticBase}
+	if ctx.cieEntry(cieid) {
+		ctx.common = &CommonInformationEntry{Length: ctx.length, CIE_id: cieid, staticBase: ctx.staticBase}
+		ctx.ciemap[start] = ctx.common
 		return parseCIE
 	}
 
-	ctx.frame = &FrameDescriptionEntry{Length: ctx.length, CIE: ctx.common}
+	ciepos := int(cieid)
+	if ctx.parsingEHFrame() {
+		// the CIE pointer of .eh_frame entries is relative to the position of
+		// the pointer itself
+		ciepos = start + 4 - int(cieid)
+	}
+	common := ctx.ciemap[ciepos]
+	if common == nil {
+		if ctx.parsingEHFrame() {
+			ctx.err = fmt.Errorf("unknown CIE at %#x for FDE at %#x", ciepos, start)
+			return parselength
+		}
+		common = ctx.common
+	}
+
+	ctx.frame = &FrameDescriptionEntry{Length: ctx.length, CIE: common}
 	return parseFDE
 }
 
 func parseFDE(ctx *parseContext) parsefunc {
-	var num uint64
+	startOff := ctx.offset()
 	r := ctx.buf.Next(int(ctx.length))
+	ctx.length = 0
+
+	if ctx.parsingEHFrame() {
+		buf := bytes.NewBuffer(r)
+		begin, err := ctx.readEncodedPtr(ctx.ehFrameAddr+uint64(startOff), buf, ctx.frame.CIE.ptrEncAddr)
+		if err != nil {
+			ctx.err = err
+			return parselength
+		}
+		// the size is encoded with the same format but is never relative
+		size, err := ctx.readEncodedPtr(0, buf, ctx.frame.CIE.ptrEncAddr&0x0f)
+		if err != nil {
+			ctx.err = err
+			return parselength
+		}
+		ctx.frame.begin = begin + ctx.staticBase
+		ctx.frame.size = size
 
+		if ctx.frame.CIE.Augmentation != "" && ctx.frame.CIE.Augmentation[0] == 'z' {
+			// skip augmentation data (the LSDA pointer)
+			n, _ := util.DecodeULEB128(buf)
+			buf.Next(int(n))
+		}
+
+		ctx.entries = append(ctx.entries, ctx.frame)
+		ctx.frame.Instructions = buf.Bytes()
+		return parselength
+	}
+
+	var num uint64
 	reader := bytes.NewReader(r)
-	num, _ = util.ReadUintRaw(reader, binary.LittleEndian, ctx.ptrSize)
+	num, _ = util.ReadUintRaw(reader, ctx.order, ctx.ptrSize)
 	ctx.frame.begin = num + ctx.staticBase
-	num, _ = util.ReadUintRaw(reader, binary.LittleEndian, ctx.ptrSize)
+	num, _ = util.ReadUintRaw(reader, ctx.order, ctx.ptrSize)
 	ctx.frame.size = num
 
 	// Insert into the tree after setting address range begin
@@ -85,13 +185,15 @@ func parseFDE(ctx *parseContext) parsefunc {
 	// The rest of this entry consists of the instructions
 	// so we can just grab all of the data from the buffer
 	// cursor to length.
-	ctx.frame.Instructions = r[2*ctx.ptrSize:]
-	ctx.length = 0
+	if len(r) >= 2*ctx.ptrSize {
+		ctx.frame.Instructions = r[2*ctx.ptrSize:]
+	}
 
 	return parselength
 }
 
 func parseCIE(ctx *parseContext) parsefunc {
+	startOff := ctx.offset()
 	data := ctx.buf.Next(int(ctx.length))
 	buf := bytes.NewBuffer(data)
 	// parse version
@@ -100,6 +202,13 @@ func parseCIE(ctx *parseContext) parsefunc {
 	// parse augmentation
 	ctx.common.Augmentation, _ = util.ParseString(buf)
 
+	if ctx.parsingEHFrame() {
+		if strings.Contains(ctx.common.Augmentation, "eh") {
+			// old GCC augmentation, followed by a pointer to the exception table
+			buf.Next(ctx.ptrSize)
+		}
+	}
+
 	// parse code alignment factor
 	ctx.common.CodeAlignmentFactor, _ = util.DecodeULEB128(buf)
 
@@ -107,7 +216,49 @@ func parseCIE(ctx *parseContext) parsefunc {
 	ctx.common.DataAlignmentFactor, _ = util.DecodeSLEB128(buf)
 
 	// parse return address register
-	ctx.common.ReturnAddressRegister, _ = util.DecodeULEB128(buf)
+	if ctx.parsingEHFrame() && ctx.common.Version == 1 {
+		b, _ := buf.ReadByte()
+		ctx.common.ReturnAddressRegister = uint64(b)
+	} else {
+		ctx.common.ReturnAddressRegister, _ = util.DecodeULEB128(buf)
+	}
+
+	ctx.common.ptrEncAddr = ptrEncAbs
+
+	if ctx.parsingEHFrame() && len(ctx.common.Augmentation) > 0 && ctx.common.Augmentation[0] == 'z' {
+		n, _ := util.DecodeULEB128(buf)
+		augdata := bytes.NewBuffer(buf.Next(int(n)))
+	augloop:
+		for _, ch := range ctx.common.Augmentation[1:] {
+			switch ch {
+			case 'L':
+				// encoding of the LSDA pointer in the FDE augmentation data
+				augdata.ReadByte()
+			case 'R':
+				b, _ := augdata.ReadByte()
+				ctx.common.ptrEncAddr = ptrEnc(b)
+				if !ctx.common.ptrEncAddr.Supported() {
+					ctx.err = fmt.Errorf("pointer encoding not supported %#x at %#x", b, startOff)
+					return parselength
+				}
+			case 'P':
+				// personality routine, its value isn't needed so the indirection
+				// can be ignored
+				b, _ := augdata.ReadByte()
+				addr := ctx.ehFrameAddr + uint64(startOff+len(data)-buf.Len()-augdata.Len())
+				if _, err := ctx.readEncodedPtr(addr, augdata, ptrEnc(b)&^ptrEncIndirect); err != nil {
+					ctx.err = err
+					return parselength
+				}
+			case 'S':
+				ctx.common.SignalFrame = true
+			default:
+				// the remaining augmentation data can not be interpreted but its
+				// length is known: stop here
+				break augloop
+			}
+		}
+	}
 
 	// parse initial instructions
 	// The rest of this entry consists of the instructions
@@ -119,6 +270,14 @@ func parseCIE(ctx *parseContext) parsefunc {
 	return parselength
 }
 
+// readEncodedPtr reads a pointer from buf encoded as specified by ptrEnc.
+// This function is used to read pointers from a .eh_frame section, when
+// the pointer is relative to its own position addr must be the address
+// of the pointer.
+func (ctx *parseContext) readEncodedPtr(addr uint64, buf *bytes.Buffer, ptrEnc ptrEnc) (uint64, error) {
+	return readEncodedPtr(addr, ctx.dataBase, buf, ptrEnc, ctx.order, ctx.ptrSize)
+}
+
 // DwarfEndian determines the endianness of the DWARF by using the version number field in the debug_info section
 // Trick borrowed from "debug/dwarf".New()
 func DwarfEndian(infoSec []byte) binary.ByteOrder {
@@ -39,6 +39,110 @@ func TestParseCIE(t *testing.T) {
 	}
 }
 
+func TestParseEhFrame(t *testing.T) {
+	const (
+		ehFrameAddr    = 0x1000
+		ehFrameHdrAddr = 0x900
+		fnAddr         = 0x2000
+		sigfnAddr      = 0x3000
+	)
+
+	var buf bytes.Buffer
+	w := func(v interface{}) { binary.Write(&buf, binary.LittleEndian, v) }
+
+	// CIE with augmentation "zR": FDE addresses are encoded as pc relative
+	// signed 4 byte integers
+	w(uint32(20))
+	w(uint32(0))
+	buf.Write([]byte{1, 'z', 'R', 0, 1, 0x78, 16, 1, byte(ptrEncPCRel | ptrEncSdata4)})
+	buf.Write([]byte{DW_CFA_def_cfa, 7, 8, DW_CFA_offset | 16, 1, DW_CFA_nop, DW_CFA_nop})
+
+	// FDE
+	fdeOff := buf.Len()
+	w(uint32(16))
+	w(uint32(fdeOff + 4)) // CIE pointer, relative to itself
+	w(int32(fnAddr - (ehFrameAddr + buf.Len())))
+	w(uint32(0x40))
+	buf.Write([]byte{0, DW_CFA_advance_loc | 1, DW_CFA_def_cfa_offset, 16})
+
+	// CIE with augmentation "zRS": a signal frame with FDE addresses encoded
+	// as data relative signed 4 byte integers
+	sigcieOff := buf.Len()
+	w(uint32(20))
+	w(uint32(0))
+	buf.Write([]byte{1, 'z', 'R', 'S', 0, 1, 0x78, 16, 1, byte(ptrEncDataRel | ptrEncSdata4)})
+	buf.Write([]byte{DW_CFA_def_cfa, 7, 8, DW_CFA_nop, DW_CFA_nop, DW_CFA_nop})
+
+	// FDE
+	sigfdeOff := buf.Len()
+	w(uint32(16))
+	w(uint32(sigfdeOff + 4 - sigcieOff))
+	w(int32(sigfnAddr - ehFrameHdrAddr))
+	w(uint32(0x10))
+	buf.Write([]byte{0, DW_CFA_nop, DW_CFA_nop, DW_CFA_nop})
+
+	// terminator
+	w(uint32(0))
+	// garbage after the terminator is not parsed
+	w(uint32(0xffffffff))
+
+	fdes, err := ParseEhFrame(buf.Bytes(), binary.LittleEndian, 0, 8, ehFrameAddr, ehFrameHdrAddr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(fdes) != 2 {
+		t.Fatalf("expected 2 FDEs, got %d", len(fdes))
+	}
+	if sigfde := fdes[1]; sigfde.Begin() != sigfnAddr || sigfde.End() != sigfnAddr+0x10 || !sigfde.CIE.SignalFrame {
+		t.Errorf("wrong signal frame FDE %#x-%#x %#v", sigfde.Begin(), sigfde.End(), sigfde.CIE)
+	}
+	fde := fdes[0]
+	if fde.CIE.SignalFrame {
+		t.Errorf("unexpected signal frame CIE %#v", fde.CIE)
+	}
+	if fde.Begin() != fnAddr || fde.End() != fnAddr+0x40 {
+		t.Errorf("wrong FDE range %#x-%#x", fde.Begin(), fde.End())
+	}
+	if fde.CIE.Augmentation != "zR" || fde.CIE.DataAlignmentFactor != -8 || fde.CIE.ReturnAddressRegister != 16 {
+		t.Errorf("wrong CIE %#v", fde.CIE)
+	}
+	for _, tc := range []struct {
+		pc     uint64
+		cfaoff int64
+	}{
+		{fnAddr, 8},
+		{fnAddr + 1, 16},
+	} {
+		fctx := fde.EstablishFrame(tc.pc)
+		if fctx.CFA.Reg != 7 || fctx.CFA.Offset != tc.cfaoff {
+			t.Errorf("%#x: wrong CFA rule %#v", tc.pc, fctx.CFA)
+		}
+		if ra := fctx.Regs[16]; ra.Rule != RuleOffset || ra.Offset != -8 {
+			t.Errorf("%#x: wrong return address rule %#v", tc.pc, ra)
+		}
+	}
+
+	// .eh_frame_hdr pointing to the section above, with a data relative
+	// search table
+	buf.Reset()
+	buf.Write([]byte{1, byte(ptrEncPCRel | ptrEncSdata4), byte(ptrEncUdata4), byte(ptrEncDataRel | ptrEncSdata4)})
+	w(int32(ehFrameAddr - (ehFrameHdrAddr + buf.Len())))
+	w(uint32(1))
+	w(int32(fnAddr - ehFrameHdrAddr))
+	w(int32(ehFrameAddr + fdeOff - ehFrameHdrAddr))
+
+	hdr, err := ParseEhFrameHdr(buf.Bytes(), binary.LittleEndian, 8, ehFrameHdrAddr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if hdr.EhFrameAddr != ehFrameAddr {
+		t.Errorf("wrong .eh_frame address %#x", hdr.EhFrameAddr)
+	}
+	if len(hdr.Table) != 1 || hdr.Table[0].InitialLoc != fnAddr || hdr.Table[0].FDEAddr != uint64(ehFrameAddr+fdeOff) {
+		t.Errorf("wrong search table %#v", hdr.Table)
+	}
+}
+
 func BenchmarkParse(b *testing.B) {
 	f, err := os.Open("testdata/frame")
 	if err != nil {
@@ -22,7 +22,7 @@ type FrameContext struct {
 	CFA           DWRule
 	Regs          map[uint64]DWRule
 	initialRegs   map[uint64]DWRule
-	prevRegs      map[uint64]DWRule
+	stateStack    []rowState
 	buf           *bytes.Buffer
 	cie           *CommonInformationEntry
 	RetAddrReg    uint64
@@ -30,6 +30,12 @@ type FrameContext struct {
 	dataAlignment int64
 }
 
+// rowState is a row of the table saved by DW_CFA_remember_state.
+type rowState struct {
+	cfa  DWRule
+	regs map[uint64]DWRule
+}
+
 // Instructions used to recreate the table from the .debug_frame data.
 const (
 	DW_CFA_nop                = 0x0        // No ops
@@ -62,6 +68,13 @@ const (
 	DW_CFA_restore            = (0x3 << 6) // High 2 bits: 0x3, low 6: register
 )
 
+// GNU extensions, used in .eh_frame sections.
+const (
+	DW_CFA_GNU_window_save              = 0x2d // No ops
+	DW_CFA_GNU_args_size                = 0x2e // op1: ULEB128 size
+	DW_CFA_GNU_negative_offset_extended = 0x2f // op1: ULEB128 register, op2: ULEB128 offset
+)
+
 // Rules defined for register values.
 type Rule byte
 
@@ -111,6 +124,10 @@ var fnlookup = map[byte]instruction{
 	DW_CFA_val_expression:     valexpression,
 	DW_CFA_lo_user:            louser,
 	DW_CFA_hi_user:            hiuser,
+
+	DW_CFA_GNU_window_save:              gnuwindowsave,
+	DW_CFA_GNU_args_size:                gnuargssize,
+	DW_CFA_GNU_negative_offset_extended: gnunegativeoffsetextended,
 }
 
 func executeCIEInstructions(cie *CommonInformationEntry) *FrameContext {
@@ -121,13 +138,15 @@ func executeCIEInstructions(cie *CommonInformationEntry) *FrameContext {
 		Regs:          make(map[uint64]DWRule),
 		RetAddrReg:    cie.ReturnAddressRegister,
 		initialRegs:   make(map[uint64]DWRule),
-		prevRegs:      make(map[uint64]DWRule),
 		codeAlignment: cie.CodeAlignmentFactor,
 		dataAlignment: cie.DataAlignmentFactor,
 		buf:           bytes.NewBuffer(initialInstructions),
 	}
 
 	frame.executeDwarfProgram()
+	for reg, rule := range frame.Regs {
+		frame.initialRegs[reg] = rule
+	}
 	return frame
 }
 
@@ -267,7 +286,7 @@ func restore(frame *FrameContext) {
 	reg := uint64(b & low_6_offset)
 	oldrule, ok := frame.initialRegs[reg]
 	if ok {
-		frame.Regs[reg] = DWRule{Offset: oldrule.Offset, Rule: RuleOffset}
+		frame.Regs[reg] = oldrule
 	} else {
 		frame.Regs[reg] = DWRule{Rule: RuleUndefined}
 	}
@@ -306,11 +325,22 @@ func register(frame *FrameContext) {
 }
 
 func rememberstate(frame *FrameContext) {
-	frame.prevRegs = frame.Regs
+	regs := make(map[uint64]DWRule, len(frame.Regs))
+	for reg, rule := range frame.Regs {
+		regs[reg] = rule
+	}
+	frame.stateStack = append(frame.stateStack, rowState{cfa: frame.CFA, regs: regs})
 }
 
 func restorestate(frame *FrameContext) {
-	frame.Regs = frame.prevRegs
+	if len(frame.stateStack) == 0 {
+		return
+	}
+	state := frame.stateStack[len(frame.stateStack)-1]
+	frame.stateStack = frame.stateStack[:len(frame.stateStack)-1]
+	// the saved row includes the CFA rule, like libgcc and GDB do
+	frame.CFA = state.cfa
+	frame.Regs = state.regs
 }
 
 func restoreextended(frame *FrameContext) {
@@ -318,7 +348,7 @@ func restoreextended(frame *FrameContext) {
 
 	oldrule, ok := frame.initialRegs[reg]
 	if ok {
-		frame.Regs[reg] = DWRule{Offset: oldrule.Offset, Rule: RuleOffset}
+		frame.Regs[reg] = oldrule
 	} else {
 		frame.Regs[reg] = DWRule{Rule: RuleUndefined}
 	}
@@ -422,3 +452,21 @@ func louser(frame *FrameContext) {
 func hiuser(frame *FrameContext) {
 	frame.buf.Next(1)
 }
+
+func gnuwindowsave(frame *FrameContext) {
+	// SPARC register window save, on arm64 this is used to toggle return
+	// address signing which does not affect unwinding.
+}
+
+func gnuargssize(frame *FrameContext) {
+	util.DecodeULEB128(frame.buf)
+}
+
+func gnunegativeoffsetextended(frame *FrameContext) {
+	var (
+		reg, _    = util.DecodeULEB128(frame.buf)
+		offset, _ = util.DecodeULEB128(frame.buf)
+	)
+
+	frame.Regs[reg] = DWRule{Offset: -int64(offset) * frame.dataAlignment, Rule: RuleOffset}
+}
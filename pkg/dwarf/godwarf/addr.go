@@ -17,13 +17,20 @@ type DebugAddrSection struct {
 }
 
 // ParseAddr parses the header of a debug_addr section.
-func ParseAddr(data []byte) *DebugAddrSection {
+// The debug_addr section written by the GNU split DWARF extension to DWARF
+// 4 does not have a header, byteOrder and ptrSize are used for it.
+func ParseAddr(data []byte, byteOrder binary.ByteOrder, ptrSize int) *DebugAddrSection {
 	if len(data) == 0 {
 		return nil
 	}
 	r := &DebugAddrSection{data: data}
-	_, dwarf64, _, byteOrder := util.ReadDwarfLengthVersion(data)
-	r.byteOrder = byteOrder
+	_, dwarf64, version, byteOrder5 := util.ReadDwarfLengthVersion(data)
+	if version != 5 {
+		r.byteOrder = byteOrder
+		r.ptrSz = ptrSize
+		return r
+	}
+	r.byteOrder = byteOrder5
 	data = data[6:]
 	if dwarf64 {
 		data = data[8:]
@@ -56,5 +63,16 @@ func (addr *DebugAddr) Get(idx uint64) (uint64, error) {
 		return 0, errors.New("debug_addr section not present")
 	}
 	off := idx*uint64(addr.ptrSz) + addr.addrBase
+	if off+uint64(addr.ptrSz) > uint64(len(addr.data)) {
+		return 0, errors.New("debug_addr index out of range")
+	}
 	return util.ReadUintRaw(bytes.NewReader(addr.data[off:]), addr.byteOrder, addr.ptrSz)
 }
+
+// Bytes returns the contents of the debug_addr section starting at addrBase.
+func (addr *DebugAddr) Bytes() []byte {
+	if addr == nil || addr.DebugAddrSection == nil || addr.addrBase > uint64(len(addr.data)) {
+		return nil
+	}
+	return addr.data[addr.addrBase:]
+}
@@ -43,6 +43,10 @@ type DebugLineInfo struct {
 	// if normalizeBackslash is true all backslashes (\) will be converted into forward slashes (/)
 	normalizeBackslash bool
 	ptrSize            int
+
+	// debugLineStr is the contents of the debug_line_str section, used by
+	// DWARF 5 file tables.
+	debugLineStr []byte
 }
 
 type FileEntry struct {
@@ -55,7 +59,7 @@ type FileEntry struct {
 type DebugLines []*DebugLineInfo
 
 // ParseAll parses all debug_line segments found in data
-func ParseAll(data []byte, logfn func(string, ...interface{}), staticBase uint64, normalizeBackslash bool, ptrSize int) DebugLines {
+func ParseAll(data []byte, debugLineStr []byte, logfn func(string, ...interface{}), staticBase uint64, normalizeBackslash bool, ptrSize int) DebugLines {
 	var (
 		lines = make(DebugLines, 0)
 		buf   = bytes.NewBuffer(data)
@@ -63,7 +67,7 @@ func ParseAll(data []byte, logfn func(string, ...interface{}), staticBase uint64
 
 	// We have to parse multiple file name tables here.
 	for buf.Len() > 0 {
-		lines = append(lines, Parse("", buf, logfn, staticBase, normalizeBackslash, ptrSize))
+		lines = append(lines, Parse("", buf, debugLineStr, logfn, staticBase, normalizeBackslash, ptrSize))
 	}
 
 	return lines
@@ -71,7 +75,9 @@ func ParseAll(data []byte, logfn func(string, ...interface{}), staticBase uint64
 
 // Parse parses a single debug_line segment from buf. Compdir is the
 // DW_AT_comp_dir attribute of the associated compile unit.
-func Parse(compdir string, buf *bytes.Buffer, logfn func(string, ...interface{}), staticBase uint64, normalizeBackslash bool, ptrSize int) *DebugLineInfo {
+// DebugLineStr is the contents of the debug_line_str section, it can be
+// nil for DWARF 4 and earlier.
+func Parse(compdir string, buf *bytes.Buffer, debugLineStr []byte, logfn func(string, ...interface{}), staticBase uint64, normalizeBackslash bool, ptrSize int) *DebugLineInfo {
 	dbl := new(DebugLineInfo)
 	dbl.Logf = logfn
 	dbl.staticBase = staticBase
@@ -82,6 +88,7 @@ func Parse(compdir string, buf *bytes.Buffer, logfn func(string, ...interface{})
 	dbl.stateMachineCache = make(map[uint64]*StateMachine)
 	dbl.lastMachineCache = make(map[uint64]*StateMachine)
 	dbl.normalizeBackslash = normalizeBackslash
+	dbl.debugLineStr = debugLineStr
 
 	parseDebugLinePrologue(dbl, buf)
 	if dbl.Prologue.Version >= 5 {
@@ -96,7 +103,12 @@ func Parse(compdir string, buf *bytes.Buffer, logfn func(string, ...interface{})
 	//   - dbl.Prologue.UnitLength is the length of the entire unit, not including the 4 bytes to represent that length.
 	//   - dbl.Prologue.Length is the length of the prologue not including unit length, version or prologue length itself.
 	//   - So you have UnitLength - PrologueLength - (version_length_bytes(2) + prologue_length_bytes(4)).
-	dbl.Instructions = buf.Next(int(dbl.Prologue.UnitLength - dbl.Prologue.Length - 6))
+	//   - DWARF 5 adds address_size(1) and segment_selector_size(1) after the version.
+	headerSize := uint32(6)
+	if dbl.Prologue.Version >= 5 {
+		headerSize += 2
+	}
+	dbl.Instructions = buf.Next(int(dbl.Prologue.UnitLength - dbl.Prologue.Length - headerSize))
 
 	return dbl
 }
@@ -111,10 +123,9 @@ func parseDebugLinePrologue(dbl *DebugLineInfo, buf *bytes.Buffer) {
 		dbl.ptrSize += int(buf.Next(1)[0]) // segment_selector_size
 	}
 
-	// Version 4 or earlier
 	p.Length = binary.LittleEndian.Uint32(buf.Next(4))
 	p.MinInstrLength = uint8(buf.Next(1)[0])
-	if p.Version == 4 {
+	if p.Version >= 4 {
 		p.MaxOpPerInstr = uint8(buf.Next(1)[0])
 	} else {
 		p.MaxOpPerInstr = 1
@@ -144,18 +155,18 @@ func parseIncludeDirs2(info *DebugLineInfo, buf *bytes.Buffer) {
 
 // parseIncludeDirs5 parses the directory table for DWARF version 5.
 func parseIncludeDirs5(info *DebugLineInfo, buf *bytes.Buffer) {
-	dirEntryFormReader := readEntryFormat(buf, info.Logf)
+	dirEntryFormReader := readEntryFormat(buf, info.debugLineStr, info.Logf)
 	dirCount, _ := util.DecodeULEB128(buf)
 	info.IncludeDirs = make([]string, 0, dirCount)
 	for i := uint64(0); i < dirCount; i++ {
 		dirEntryFormReader.reset()
+		var dir string
 		for dirEntryFormReader.next(buf) {
 			switch dirEntryFormReader.contentType {
 			case _DW_LNCT_path:
-				if dirEntryFormReader.formCode != _DW_FORM_string {
-					info.IncludeDirs = append(info.IncludeDirs, dirEntryFormReader.str)
-				} else {
-					//TODO(aarzilli): support debug_string, debug_line_str
+				if dirEntryFormReader.isString() {
+					dir = dirEntryFormReader.str
+				} else if info.Logf != nil {
 					info.Logf("unsupported string form %#x", dirEntryFormReader.formCode)
 				}
 			case _DW_LNCT_directory_index:
@@ -164,6 +175,10 @@ func parseIncludeDirs5(info *DebugLineInfo, buf *bytes.Buffer) {
 			case _DW_LNCT_MD5:
 			}
 		}
+		if info.normalizeBackslash {
+			dir = strings.Replace(dir, "\\", "/", -1)
+		}
+		info.IncludeDirs = append(info.IncludeDirs, dir)
 	}
 }
 
@@ -206,26 +221,23 @@ func readFileEntry(info *DebugLineInfo, buf *bytes.Buffer, exitOnEmptyPath bool)
 
 // parseFileEntries5 parses the file table for DWARF 5
 func parseFileEntries5(info *DebugLineInfo, buf *bytes.Buffer) {
-	fileEntryFormReader := readEntryFormat(buf, info.Logf)
+	fileEntryFormReader := readEntryFormat(buf, info.debugLineStr, info.Logf)
 	fileCount, _ := util.DecodeULEB128(buf)
 	info.FileNames = make([]*FileEntry, 0, fileCount)
 	for i := 0; i < int(fileCount); i++ {
 		fileEntryFormReader.reset()
+		entry := new(FileEntry)
+		var path string
 		for fileEntryFormReader.next(buf) {
-			entry := new(FileEntry)
-			var path string
-			var diridx int = -1
-
 			switch fileEntryFormReader.contentType {
 			case _DW_LNCT_path:
-				if fileEntryFormReader.formCode != _DW_FORM_string {
+				if fileEntryFormReader.isString() {
 					path = fileEntryFormReader.str
-				} else {
-					//TODO(aarzilli): support debug_string, debug_line_str
+				} else if info.Logf != nil {
 					info.Logf("unsupported string form %#x", fileEntryFormReader.formCode)
 				}
 			case _DW_LNCT_directory_index:
-				diridx = int(fileEntryFormReader.u64)
+				entry.DirIdx = fileEntryFormReader.u64
 			case _DW_LNCT_timestamp:
 				entry.LastModTime = fileEntryFormReader.u64
 			case _DW_LNCT_size:
@@ -233,13 +245,42 @@ func parseFileEntries5(info *DebugLineInfo, buf *bytes.Buffer) {
 			case _DW_LNCT_MD5:
 				// not implemented
 			}
+		}
 
-			if diridx >= 0 && !filepath.IsAbs(path) && diridx < len(info.IncludeDirs) {
-				path = filepath.Join(info.IncludeDirs[diridx], path)
-			}
-			entry.Path = path
-			info.FileNames = append(info.FileNames, entry)
-			info.Lookup[entry.Path] = entry
+		if info.normalizeBackslash {
+			path = strings.Replace(path, "\\", "/", -1)
 		}
+		if !filepath.IsAbs(path) && entry.DirIdx < uint64(len(info.IncludeDirs)) {
+			path = filepath.Join(info.IncludeDirs[entry.DirIdx], path)
+		}
+		entry.Path = path
+		info.FileNames = append(info.FileNames, entry)
+		info.Lookup[entry.Path] = entry
+	}
+}
+
+// FileName returns the path of the file with index idx in the file table,
+// as referenced by the DW_AT_decl_file and DW_AT_call_file attributes.
+// Files are numbered starting from 1 in DWARF 4 and earlier and starting
+// from 0 in DWARF 5.
+func (dbl *DebugLineInfo) FileName(idx int64) (string, bool) {
+	if dbl.Prologue.Version < 5 {
+		idx--
+	}
+	if idx < 0 || idx >= int64(len(dbl.FileNames)) {
+		return "", false
+	}
+	return dbl.FileNames[idx].Path, true
+}
+
+// defaultFile returns the initial value of the file register of the state
+// machine, file number 1.
+func (dbl *DebugLineInfo) defaultFile() string {
+	if dbl.Prologue.Version >= 5 && len(dbl.FileNames) > 1 {
+		return dbl.FileNames[1].Path
+	}
+	if len(dbl.FileNames) == 0 {
+		return ""
 	}
+	return dbl.FileNames[0].Path
 }
@@ -62,6 +62,7 @@ const (
 	lineRangeGo18   uint8  = 10
 	versionGo14     uint16 = 2
 	versionGo111    uint16 = 3
+	versionDwarf5   uint16 = 5
 	opcodeBaseGo14  uint8  = 10
 	opcodeBaseGo111 uint8  = 11
 )
@@ -72,13 +73,13 @@ func ptrSizeByRuntimeArch() int {
 
 func testDebugLinePrologueParser(p string, t *testing.T) {
 	data := grabDebugLineSection(p, t)
-	debugLines := ParseAll(data, nil, 0, true, ptrSizeByRuntimeArch())
+	debugLines := ParseAll(data, nil, nil, 0, true, ptrSizeByRuntimeArch())
 	mainFileFound := false
 
 	for _, dbl := range debugLines {
 		prologue := dbl.Prologue
 
-		if prologue.Version != versionGo14 && prologue.Version != versionGo111 {
+		if prologue.Version != versionGo14 && prologue.Version != versionGo111 && prologue.Version != versionDwarf5 {
 			t.Fatal("Version not parsed correctly", prologue.Version)
 		}
 
@@ -113,12 +114,12 @@ func testDebugLinePrologueParser(p string, t *testing.T) {
 			}
 		}
 
-		if len(dbl.IncludeDirs) != 1 {
+		if prologue.Version < 5 && len(dbl.IncludeDirs) != 1 {
 			t.Fatal("Include dirs not parsed correctly")
 		}
 
 		for _, ln := range dbl.Lookup {
-			if ln.Path == "<autogenerated>" || strings.HasPrefix(ln.Path, "<missing>_") || ln.Path == "_gomod_.go" {
+			if ln.Path == "<autogenerated>" || ln.Path == "?" || strings.HasPrefix(ln.Path, "<missing>_") || ln.Path == "_gomod_.go" {
 				continue
 			}
 			if _, err := os.Stat(ln.Path); err != nil {
@@ -176,7 +177,7 @@ func BenchmarkLineParser(b *testing.B) {
 
 	b.ResetTimer()
 	for i := 0; i < b.N; i++ {
-		_ = ParseAll(data, nil, 0, true, ptrSizeByRuntimeArch())
+		_ = ParseAll(data, nil, nil, 0, true, ptrSizeByRuntimeArch())
 	}
 }
 
@@ -191,7 +192,7 @@ func loadBenchmarkData(tb testing.TB) DebugLines {
 		tb.Fatal("Could not read test data", err)
 	}
 
-	return ParseAll(data, nil, 0, true, ptrSizeByRuntimeArch())
+	return ParseAll(data, nil, nil, 0, true, ptrSizeByRuntimeArch())
 }
 
 func BenchmarkStateMachine(b *testing.B) {
@@ -311,7 +312,7 @@ func TestDebugLineC(t *testing.T) {
 		t.Fatal("Could not read test data", err)
 	}
 
-	parsed := ParseAll(data, nil, 0, true, ptrSizeByRuntimeArch())
+	parsed := ParseAll(data, nil, nil, 0, true, ptrSizeByRuntimeArch())
 
 	if len(parsed) == 0 {
 		t.Fatal("Parser result is empty")
@@ -360,7 +361,7 @@ func TestDebugLineDwarf4(t *testing.T) {
 		t.Fatal("Could not read test data", err)
 	}
 
-	debugLines := ParseAll(data, nil, 0, true, 8)
+	debugLines := ParseAll(data, nil, nil, 0, true, 8)
 
 	for _, dbl := range debugLines {
 		if dbl.Prologue.Version == 4 {
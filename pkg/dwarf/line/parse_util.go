@@ -41,6 +41,7 @@ const (
 
 type formReader struct {
 	logf         func(string, ...interface{})
+	debugLineStr []byte
 	contentTypes []uint64
 	formCodes    []uint64
 
@@ -55,10 +56,11 @@ type formReader struct {
 	nexti int
 }
 
-func readEntryFormat(buf *bytes.Buffer, logf func(string, ...interface{})) *formReader {
+func readEntryFormat(buf *bytes.Buffer, debugLineStr []byte, logf func(string, ...interface{})) *formReader {
 	count := buf.Next(1)[0]
 	r := &formReader{
 		logf:         logf,
+		debugLineStr: debugLineStr,
 		contentTypes: make([]uint64, count),
 		formCodes:    make([]uint64, count),
 	}
@@ -101,9 +103,18 @@ func (rdr *formReader) next(buf *bytes.Buffer) bool {
 	case _DW_FORM_data2, _DW_FORM_strx2:
 		rdr.u64 = uint64(binary.LittleEndian.Uint16(buf.Next(2)))
 
-	case _DW_FORM_data4, _DW_FORM_line_strp, _DW_FORM_sec_offset, _DW_FORM_strp, _DW_FORM_strx4:
+	case _DW_FORM_data4, _DW_FORM_sec_offset, _DW_FORM_strp, _DW_FORM_strx4:
 		rdr.u64 = uint64(binary.LittleEndian.Uint32(buf.Next(4)))
 
+	case _DW_FORM_line_strp:
+		rdr.u64 = uint64(binary.LittleEndian.Uint32(buf.Next(4)))
+		rdr.str = ""
+		if rdr.u64 < uint64(len(rdr.debugLineStr)) {
+			rdr.str, _ = util.ParseString(bytes.NewBuffer(rdr.debugLineStr[rdr.u64:]))
+		} else if rdr.logf != nil {
+			rdr.logf("debug_line_str offset %#x out of range", rdr.u64)
+		}
+
 	case _DW_FORM_data8:
 		rdr.u64 = binary.LittleEndian.Uint64(buf.Next(8))
 
@@ -135,6 +146,12 @@ func (rdr *formReader) next(buf *bytes.Buffer) bool {
 	return true
 }
 
+// isString returns true if the last value read was a string that could be
+// resolved.
+func (rdr *formReader) isString() bool {
+	return rdr.formCode == _DW_FORM_string || (rdr.formCode == _DW_FORM_line_strp && rdr.debugLineStr != nil)
+}
+
 func (rdr *formReader) readBlock(buf *bytes.Buffer, n uint64) {
 	if cap(rdr.block) < int(n) {
 		rdr.block = make([]byte, 0, n)
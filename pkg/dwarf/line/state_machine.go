@@ -113,7 +113,7 @@ func newStateMachine(dbl *DebugLineInfo, instructions []byte, ptrSize int) *Stat
 	}
 	sm := &StateMachine{
 		dbl:         dbl,
-		file:        dbl.FileNames[0].Path,
+		file:        dbl.defaultFile(),
 		line:        1,
 		buf:         bytes.NewBuffer(instructions),
 		opcodes:     opcodes,
@@ -155,6 +155,53 @@ func (lineInfo *DebugLineInfo) AllPCsForFileLines(f string, m map[int][]uint64)
 	return
 }
 
+// Row is a row of the line number table.
+type Row struct {
+	Address       uint64
+	File          string
+	Line          int
+	Column        uint
+	IsStmt        bool
+	PrologueEnd   bool
+	EpilogueBegin bool
+	EndSequence   bool
+}
+
+// Rows returns all the rows of the line number table, in the order they
+// are emitted by the line number program.
+func (lineInfo *DebugLineInfo) Rows() []Row {
+	if lineInfo == nil {
+		return nil
+	}
+
+	var (
+		rows []Row
+		sm   = newStateMachine(lineInfo, lineInfo.Instructions, lineInfo.ptrSize)
+	)
+
+	for {
+		if err := sm.next(); err != nil {
+			if err != io.EOF && lineInfo.Logf != nil {
+				lineInfo.Logf("Rows error: %v", err)
+			}
+			break
+		}
+		if sm.valid {
+			rows = append(rows, Row{
+				Address:       sm.address,
+				File:          sm.file,
+				Line:          sm.line,
+				Column:        sm.column,
+				IsStmt:        sm.isStmt,
+				PrologueEnd:   sm.prologueEnd,
+				EpilogueBegin: sm.epilogueBegin,
+				EndSequence:   sm.endSeq,
+			})
+		}
+	}
+	return rows
+}
+
 var NoSourceError = errors.New("no source available")
 
 // AllPCsBetween returns all PC addresses between begin and end (including both begin and end) that have the is_stmt flag set and do not belong to excludeFile:excludeLine
@@ -433,7 +480,7 @@ func (sm *StateMachine) next() error {
 	}
 	if sm.endSeq {
 		sm.endSeq = false
-		sm.file = sm.dbl.FileNames[0].Path
+		sm.file = sm.dbl.defaultFile()
 		sm.line = 1
 		sm.column = 0
 		sm.isa = 0
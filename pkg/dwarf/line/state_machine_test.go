@@ -79,7 +79,7 @@ func TestGrafana(t *testing.T) {
 		}
 		cuname, _ := e.Val(dwarf.AttrName).(string)
 
-		lineInfo := Parse(e.Val(dwarf.AttrCompDir).(string), debugLineBuffer, t.Logf, 0, false, 8)
+		lineInfo := Parse(e.Val(dwarf.AttrCompDir).(string), debugLineBuffer, nil, t.Logf, 0, false, 8)
 		sm := newStateMachine(lineInfo, lineInfo.Instructions, 8)
 
 		lnrdr, err := data.LineReader(e)
@@ -267,4 +267,22 @@ func TestMultipleSequences(t *testing.T) {
 			t.Errorf("AllPCsBetween(%#x, %#x): expected: %#x got: %#x", testCase.start, testCase.end, testCase.tgt, out)
 		}
 	}
+
+	// Test that Rows returns the rows of all three sequences, including the
+	// end of each sequence
+	rows := lines.Rows()
+	if len(rows) != 12 {
+		t.Fatalf("wrong number of rows %d: %#v", len(rows), rows)
+	}
+	for i, row := range rows {
+		if row.EndSequence != (i%4 == 3) {
+			t.Errorf("wrong end_sequence flag for row %d %#v", i, row)
+		}
+		if row.File != thefile || !row.IsStmt {
+			t.Errorf("wrong row %d %#v", i, row)
+		}
+	}
+	if rows[4].Address != 0x600000 || rows[4].Line != 11 || rows[11].Address != 0x500006 || rows[11].Line != 23 {
+		t.Errorf("wrong rows %#v %#v", rows[4], rows[11])
+	}
 }
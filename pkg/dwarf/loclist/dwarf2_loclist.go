@@ -9,6 +9,7 @@ import (
 // Reader represents a loclist reader.
 type Reader interface {
 	Find(off int, staticBase, base, pc uint64, debugAddr *godwarf.DebugAddr) (*Entry, error)
+	Entries(off int, staticBase, base uint64, debugAddr *godwarf.DebugAddr) ([]Entry, error)
 	Empty() bool
 }
 
@@ -72,6 +73,22 @@ func (rdr *Dwarf2Reader) Find(off int, staticBase, base, pc uint64, debugAddr *g
 	return nil, nil
 }
 
+// Entries returns all the entries of the loclist starting at off, with
+// absolute addresses.
+func (rdr *Dwarf2Reader) Entries(off int, staticBase, base uint64, debugAddr *godwarf.DebugAddr) ([]Entry, error) {
+	rdr.Seek(off)
+	var r []Entry
+	var e Entry
+	for rdr.Next(&e) {
+		if e.BaseAddressSelection() {
+			base = e.HighPC + staticBase
+			continue
+		}
+		r = append(r, Entry{e.LowPC + base, e.HighPC + base, e.Instr})
+	}
+	return r, nil
+}
+
 func (rdr *Dwarf2Reader) read(sz int) []byte {
 	r := rdr.data[rdr.cur : rdr.cur+sz]
 	rdr.cur += sz
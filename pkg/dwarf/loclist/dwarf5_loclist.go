@@ -14,6 +14,7 @@ import (
 type Dwarf5Reader struct {
 	byteOrder binary.ByteOrder
 	ptrSz     int
+	dwarf64   bool
 	data      []byte
 }
 
@@ -25,6 +26,7 @@ func NewDwarf5Reader(data []byte) *Dwarf5Reader {
 
 	_, dwarf64, _, byteOrder := util.ReadDwarfLengthVersion(data)
 	r.byteOrder = byteOrder
+	r.dwarf64 = dwarf64
 
 	data = data[6:]
 	if dwarf64 {
@@ -46,6 +48,35 @@ func (rdr *Dwarf5Reader) Empty() bool {
 	return rdr == nil
 }
 
+// Offset returns the offset of the loclist with index idx in the offset
+// table starting at locListsBase, this is used to resolve attributes with
+// form DW_FORM_loclistx.
+// A locListsBase of 0 refers to the offset table immediately following the
+// header of the section, which is the case for split compile units.
+func (rdr *Dwarf5Reader) Offset(locListsBase, idx uint64) (int, error) {
+	offSz := uint64(4)
+	if rdr.dwarf64 {
+		offSz = 8
+	}
+	if locListsBase == 0 {
+		// unit_length, version, address_size, segment_selector_size and
+		// offset_entry_count
+		locListsBase = 4 + 2 + 1 + 1 + 4
+		if rdr.dwarf64 {
+			locListsBase += 8
+		}
+	}
+	off := locListsBase + idx*offSz
+	if off+offSz > uint64(len(rdr.data)) {
+		return 0, fmt.Errorf("loclist index %d out of range", idx)
+	}
+	v, err := util.ReadUintRaw(bytes.NewReader(rdr.data[off:]), rdr.byteOrder, int(offSz))
+	if err != nil {
+		return 0, err
+	}
+	return int(locListsBase + v), nil
+}
+
 // Find returns the loclist entry for the specified PC address, inside the
 // loclist stating at off. Base is the base address of the compile unit and
 // staticBase is the static base at which the image is loaded.
@@ -73,6 +104,21 @@ func (rdr *Dwarf5Reader) Find(off int, staticBase, base, pc uint64, debugAddr *g
 	return nil, nil
 }
 
+// Entries returns all the entries of the loclist starting at off, with
+// absolute addresses. The default location, if any, is not returned.
+func (rdr *Dwarf5Reader) Entries(off int, staticBase, base uint64, debugAddr *godwarf.DebugAddr) ([]Entry, error) {
+	it := &loclistsIterator{rdr: rdr, debugAddr: debugAddr, buf: bytes.NewBuffer(rdr.data), base: base, staticBase: staticBase}
+	it.buf.Next(off)
+
+	var r []Entry
+	for it.next() {
+		if it.onRange {
+			r = append(r, Entry{it.start, it.end, it.instr})
+		}
+	}
+	return r, it.err
+}
+
 type loclistsIterator struct {
 	rdr        *Dwarf5Reader
 	debugAddr  *godwarf.DebugAddr
@@ -134,6 +180,8 @@ func (it *loclistsIterator) next() bool {
 		if it.err == nil {
 			it.end, it.err = it.debugAddr.Get(endIdx)
 		}
+		it.start += it.staticBase
+		it.end += it.staticBase
 		it.onRange = true
 
 	case _DW_LLE_startx_length:
@@ -142,6 +190,7 @@ func (it *loclistsIterator) next() bool {
 		it.readInstr()
 
 		it.start, it.err = it.debugAddr.Get(startIdx)
+		it.start += it.staticBase
 		it.end = it.start + length
 		it.onRange = true
 
@@ -168,12 +217,15 @@ func (it *loclistsIterator) next() bool {
 		it.start, it.err = util.ReadUintRaw(it.buf, it.rdr.byteOrder, it.rdr.ptrSz)
 		it.end, it.err = util.ReadUintRaw(it.buf, it.rdr.byteOrder, it.rdr.ptrSz)
 		it.readInstr()
+		it.start += it.staticBase
+		it.end += it.staticBase
 		it.onRange = true
 
 	case _DW_LLE_start_length:
 		it.start, it.err = util.ReadUintRaw(it.buf, it.rdr.byteOrder, it.rdr.ptrSz)
 		length, _ := util.DecodeULEB128(it.buf)
 		it.readInstr()
+		it.start += it.staticBase
 		it.end = it.start + length
 		it.onRange = true
 
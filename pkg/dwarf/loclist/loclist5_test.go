@@ -5,6 +5,7 @@ import (
 	"encoding/binary"
 	"testing"
 
+	"github.com/go-delve/delve/pkg/dwarf/godwarf"
 	"github.com/go-delve/delve/pkg/dwarf/util"
 )
 
@@ -117,3 +118,89 @@ func TestLoclist5(t *testing.T) {
 		}
 	}
 }
+
+func TestLoclist5Loclistx(t *testing.T) {
+	addrbuf := new(bytes.Buffer)
+	binary.Write(addrbuf, binary.LittleEndian, uint32(0)) // length (ignored)
+	binary.Write(addrbuf, binary.LittleEndian, uint16(5)) // version
+	binary.Write(addrbuf, binary.LittleEndian, uint8(4))  // address size
+	binary.Write(addrbuf, binary.LittleEndian, uint8(0))  // segment selector size
+	addrBase := uint64(addrbuf.Len())
+	for _, addr := range []uint32{0x1000, 0x1100, 0x1200} {
+		binary.Write(addrbuf, binary.LittleEndian, addr)
+	}
+	debugAddr := godwarf.ParseAddr(addrbuf.Bytes(), binary.LittleEndian, 4).GetSubsection(addrBase)
+
+	buf := new(bytes.Buffer)
+
+	p32 := func(n uint32) { binary.Write(buf, binary.LittleEndian, n) }
+	p16 := func(n uint16) { binary.Write(buf, binary.LittleEndian, n) }
+	p8 := func(n uint8) { binary.Write(buf, binary.LittleEndian, n) }
+	uleb := func(n uint64) { util.EncodeULEB128(buf, n) }
+
+	p32(0x0) // length (use 0 because it is ignored)
+	p16(0x5) // version
+	p8(4)    // address size
+	p8(0)    // segment selector size
+	p32(2)   // offset_entry_count
+	p32(8)   // offset of list 0
+	p32(19)  // offset of list 1
+
+	// list 0
+	// (startx length) 0x1000 .. 0x1010: 1
+	p8(_DW_LLE_startx_length)
+	uleb(0)
+	uleb(0x10)
+	uleb(1)
+	p8(1)
+	// (startx endx) 0x1100 .. 0x1200: 2
+	p8(_DW_LLE_startx_endx)
+	uleb(1)
+	uleb(2)
+	uleb(1)
+	p8(2)
+	p8(_DW_LLE_end_of_list)
+
+	// list 1
+	// base address -> 0x1200
+	p8(_DW_LLE_base_addressx)
+	uleb(2)
+	// (offset) 0x1210 .. 0x1220: 3
+	p8(_DW_LLE_offset_pair)
+	uleb(0x10)
+	uleb(0x20)
+	uleb(1)
+	p8(3)
+	p8(_DW_LLE_end_of_list)
+
+	const staticBase = 0x400000
+
+	ll := NewDwarf5Reader(buf.Bytes())
+
+	for _, tc := range []struct {
+		idx uint64
+		pc  uint64
+		tgt Entry
+	}{
+		{0, staticBase + 0x1008, Entry{staticBase + 0x1000, staticBase + 0x1010, []byte{1}}},
+		{0, staticBase + 0x1180, Entry{staticBase + 0x1100, staticBase + 0x1200, []byte{2}}},
+		{1, staticBase + 0x1210, Entry{staticBase + 0x1210, staticBase + 0x1220, []byte{3}}},
+	} {
+		off, err := ll.Offset(0, tc.idx)
+		if err != nil {
+			t.Fatalf("error resolving index %d: %v", tc.idx, err)
+		}
+		e, err := ll.Find(off, staticBase, 0, tc.pc, debugAddr)
+		if err != nil {
+			t.Errorf("error returned for %#x: %v", tc.pc, err)
+			continue
+		}
+		if e == nil || e.LowPC != tc.tgt.LowPC || e.HighPC != tc.tgt.HighPC || !bytes.Equal(e.Instr, tc.tgt.Instr) {
+			t.Errorf("output mismatch for %#x,\nexpected %#v,\ngot     %#v", tc.pc, tc.tgt, e)
+		}
+	}
+
+	if _, err := ll.Offset(0, 100); err == nil {
+		t.Errorf("expected error for out of range loclist index")
+	}
+}
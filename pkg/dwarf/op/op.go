@@ -23,19 +23,37 @@ type context struct {
 	stack   []int64
 	pieces  []Piece
 	reg     bool
+	imm     bool
 	ptrSize int
 
 	DwarfRegisters
 }
 
-// Piece is a piece of memory stored either at an address or in a register.
+// Piece is a piece of memory stored either at an address or in a register,
+// or a value that is not stored anywhere in the target.
 type Piece struct {
-	Size       int
-	Addr       int64
-	RegNum     uint64
-	IsRegister bool
+	Size   int
+	Kind   PieceKind
+	Addr   int64  // address of the piece, for AddrPiece
+	RegNum uint64 // register containing the piece, for RegPiece
+	Bytes  []byte // contents of the piece, for ImmPiece
+
+	// Ref and Offset describe an ImplicitPtrPiece, a pointer to byte Offset
+	// of the variable described by the DIE at offset Ref of debug_info.
+	Ref    uint64
+	Offset int64
 }
 
+// PieceKind describes where the contents of a Piece are stored.
+type PieceKind uint8
+
+const (
+	AddrPiece        PieceKind = iota // the piece is stored in memory
+	RegPiece                          // the piece is stored in a register
+	ImmPiece                          // the piece is a value computed by the expression (DW_OP_stack_value)
+	ImplicitPtrPiece                  // the piece is a pointer to a value that is not in memory (DW_OP_implicit_pointer)
+)
+
 // ExecuteStackProgram executes a DWARF location expression and returns
 // either an address (int64), or a slice of Pieces for location expressions
 // that don't evaluate to an address (such as register and composite expressions).
@@ -53,6 +71,11 @@ func ExecuteStackProgram(regs DwarfRegisters, instructions []byte, ptrSize int)
 			break
 		}
 		opcode := Opcode(opcodeByte)
+		if ctxt.imm && opcode != DW_OP_piece {
+			// DW_OP_stack_value and DW_OP_implicit_pointer terminate the
+			// description of a piece.
+			return 0, nil, fmt.Errorf("invalid instruction %s after %s", opcodeName[opcode], pieceKindName(ctxt.pieces[len(ctxt.pieces)-1].Kind))
+		}
 		if ctxt.reg && opcode != DW_OP_piece {
 			// last opcode was DW_OP_regN and next one isn't DW_OP_piece so convert
 			// the register piece into a stack value.
@@ -72,8 +95,13 @@ func ExecuteStackProgram(regs DwarfRegisters, instructions []byte, ptrSize int)
 	}
 
 	if ctxt.pieces != nil {
-		if len(ctxt.pieces) == 1 && ctxt.pieces[0].IsRegister {
-			return int64(regs.Uint64Val(ctxt.pieces[0].RegNum)), ctxt.pieces, nil
+		if len(ctxt.pieces) == 1 {
+			switch ctxt.pieces[0].Kind {
+			case RegPiece:
+				return int64(regs.Uint64Val(ctxt.pieces[0].RegNum)), ctxt.pieces, nil
+			case ImmPiece:
+				return int64(binary.LittleEndian.Uint64(ctxt.pieces[0].Bytes)), ctxt.pieces, nil
+			}
 		}
 		return 0, ctxt.pieces, nil
 	}
@@ -153,6 +181,23 @@ func addr(opcode Opcode, ctxt *context) error {
 	return nil
 }
 
+func addrx(opcode Opcode, ctxt *context) error {
+	idx, _ := util.DecodeULEB128(ctxt.buf)
+	if ctxt.DebugAddr == nil {
+		return fmt.Errorf("%s without debug_addr section", opcodeName[opcode])
+	}
+	v, err := ctxt.DebugAddr(idx)
+	if err != nil {
+		return err
+	}
+	if opcode == DW_OP_addrx || opcode == DW_OP_GNU_addr_index {
+		// constants read from debug_addr (DW_OP_constx) are not relocated
+		v += ctxt.StaticBase
+	}
+	ctxt.stack = append(ctxt.stack, int64(v))
+	return nil
+}
+
 func plus(opcode Opcode, ctxt *context) error {
 	var (
 		slen   = len(ctxt.stack)
@@ -187,17 +232,18 @@ func register(opcode Opcode, ctxt *context) error {
 	ctxt.reg = true
 	if opcode == DW_OP_regx {
 		n, _ := util.DecodeSLEB128(ctxt.buf)
-		ctxt.pieces = append(ctxt.pieces, Piece{IsRegister: true, RegNum: uint64(n)})
+		ctxt.pieces = append(ctxt.pieces, Piece{Kind: RegPiece, RegNum: uint64(n)})
 	} else {
-		ctxt.pieces = append(ctxt.pieces, Piece{IsRegister: true, RegNum: uint64(opcode - DW_OP_reg0)})
+		ctxt.pieces = append(ctxt.pieces, Piece{Kind: RegPiece, RegNum: uint64(opcode - DW_OP_reg0)})
 	}
 	return nil
 }
 
 func piece(opcode Opcode, ctxt *context) error {
 	sz, _ := util.DecodeULEB128(ctxt.buf)
-	if ctxt.reg {
+	if ctxt.reg || ctxt.imm {
 		ctxt.reg = false
+		ctxt.imm = false
 		ctxt.pieces[len(ctxt.pieces)-1].Size = int(sz)
 		return nil
 	}
@@ -207,7 +253,162 @@ func piece(opcode Opcode, ctxt *context) error {
 	}
 
 	addr := ctxt.stack[len(ctxt.stack)-1]
-	ctxt.pieces = append(ctxt.pieces, Piece{Size: int(sz), Addr: addr})
+	ctxt.pieces = append(ctxt.pieces, Piece{Size: int(sz), Kind: AddrPiece, Addr: addr})
 	ctxt.stack = ctxt.stack[:0]
 	return nil
 }
+
+func literal(opcode Opcode, ctxt *context) error {
+	ctxt.stack = append(ctxt.stack, int64(opcode-DW_OP_lit0))
+	return nil
+}
+
+func constant(opcode Opcode, ctxt *context) error {
+	if opcode == DW_OP_constu {
+		num, _ := util.DecodeULEB128(ctxt.buf)
+		ctxt.stack = append(ctxt.stack, int64(num))
+		return nil
+	}
+	var sz int
+	switch opcode {
+	case DW_OP_const1u, DW_OP_const1s:
+		sz = 1
+	case DW_OP_const2u, DW_OP_const2s:
+		sz = 2
+	case DW_OP_const4u, DW_OP_const4s:
+		sz = 4
+	case DW_OP_const8u, DW_OP_const8s:
+		sz = 8
+	}
+	buf := ctxt.buf.Next(sz)
+	if len(buf) != sz {
+		return io.ErrUnexpectedEOF
+	}
+	var numbuf [8]byte
+	copy(numbuf[:], buf)
+	num := binary.LittleEndian.Uint64(numbuf[:])
+	switch opcode {
+	case DW_OP_const1s:
+		ctxt.stack = append(ctxt.stack, int64(int8(num)))
+	case DW_OP_const2s:
+		ctxt.stack = append(ctxt.stack, int64(int16(num)))
+	case DW_OP_const4s:
+		ctxt.stack = append(ctxt.stack, int64(int32(num)))
+	default:
+		ctxt.stack = append(ctxt.stack, int64(num))
+	}
+	return nil
+}
+
+func bregister(opcode Opcode, ctxt *context) error {
+	var regnum uint64
+	if opcode == DW_OP_bregx {
+		regnum, _ = util.DecodeULEB128(ctxt.buf)
+	} else {
+		regnum = uint64(opcode - DW_OP_breg0)
+	}
+	offset, _ := util.DecodeSLEB128(ctxt.buf)
+	if ctxt.Reg(regnum) == nil {
+		return fmt.Errorf("register %d not available", regnum)
+	}
+	ctxt.stack = append(ctxt.stack, int64(ctxt.Uint64Val(regnum))+offset)
+	return nil
+}
+
+func deref(opcode Opcode, ctxt *context) error {
+	sz := ctxt.ptrSize
+	if opcode == DW_OP_deref_size {
+		n, err := ctxt.buf.ReadByte()
+		if err != nil {
+			return err
+		}
+		sz = int(n)
+	}
+	if sz <= 0 || sz > 8 {
+		return fmt.Errorf("invalid size %d for %s", sz, opcodeName[opcode])
+	}
+	if len(ctxt.stack) == 0 {
+		return errors.New("empty OP stack")
+	}
+	if ctxt.ReadMemory == nil {
+		return fmt.Errorf("%s not supported in this context", opcodeName[opcode])
+	}
+	// the value is zero extended to the size of an address
+	var order binary.ByteOrder = binary.LittleEndian
+	buf := make([]byte, 8)
+	valbuf := buf[:sz]
+	if ctxt.ByteOrder == binary.BigEndian {
+		order = binary.BigEndian
+		valbuf = buf[8-sz:]
+	}
+	if _, err := ctxt.ReadMemory(valbuf, uint64(ctxt.stack[len(ctxt.stack)-1])); err != nil {
+		return err
+	}
+	ctxt.stack[len(ctxt.stack)-1] = int64(order.Uint64(buf))
+	return nil
+}
+
+func stackvalue(opcode Opcode, ctxt *context) error {
+	if len(ctxt.stack) == 0 {
+		return errors.New("empty OP stack")
+	}
+	// values on the stack are always 64bit, pieces of smaller variables will
+	// only use the first bytes.
+	buf := make([]byte, 8)
+	binary.LittleEndian.PutUint64(buf, uint64(ctxt.stack[len(ctxt.stack)-1]))
+	ctxt.pieces = append(ctxt.pieces, Piece{Kind: ImmPiece, Bytes: buf})
+	ctxt.stack = ctxt.stack[:0]
+	ctxt.imm = true
+	return nil
+}
+
+func implicitpointer(opcode Opcode, ctxt *context) error {
+	ref, err := util.ReadUintRaw(bytes.NewReader(ctxt.buf.Next(4)), binary.LittleEndian, 4)
+	if err != nil {
+		return err
+	}
+	off, _ := util.DecodeSLEB128(ctxt.buf)
+	ctxt.pieces = append(ctxt.pieces, Piece{Kind: ImplicitPtrPiece, Ref: ref, Offset: off})
+	ctxt.imm = true
+	return nil
+}
+
+func entryvalue(opcode Opcode, ctxt *context) error {
+	sz, _ := util.DecodeULEB128(ctxt.buf)
+	expr := ctxt.buf.Next(int(sz))
+	if ctxt.EntryValue == nil {
+		return fmt.Errorf("%s not supported in this context", opcodeName[opcode])
+	}
+	v, err := ctxt.EntryValue(expr)
+	if err != nil {
+		return err
+	}
+	ctxt.stack = append(ctxt.stack, v)
+	return nil
+}
+
+func parameterref(opcode Opcode, ctxt *context) error {
+	off, err := util.ReadUintRaw(bytes.NewReader(ctxt.buf.Next(4)), binary.LittleEndian, 4)
+	if err != nil {
+		return err
+	}
+	if ctxt.ParameterRef == nil {
+		return fmt.Errorf("%s not supported in this context", opcodeName[opcode])
+	}
+	v, err := ctxt.ParameterRef(off)
+	if err != nil {
+		return err
+	}
+	ctxt.stack = append(ctxt.stack, v)
+	return nil
+}
+
+func pieceKindName(kind PieceKind) string {
+	switch kind {
+	case ImmPiece:
+		return "DW_OP_stack_value"
+	case ImplicitPtrPiece:
+		return "DW_OP_implicit_pointer"
+	}
+	return fmt.Sprintf("piece kind %d", kind)
+}
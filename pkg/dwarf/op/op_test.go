@@ -1,6 +1,7 @@
 package op
 
 import (
+	"encoding/binary"
 	"testing"
 	"unsafe"
 )
@@ -23,3 +24,124 @@ func TestExecuteStackProgram(t *testing.T) {
 		t.Fatalf("actual %d != expected %d", actual, expected)
 	}
 }
+
+func TestExecuteStackProgramAddrx(t *testing.T) {
+	debugAddr := []uint64{0x1000, 0x2000, 0x10}
+	regs := DwarfRegisters{StaticBase: 0x400000, DebugAddr: func(idx uint64) (uint64, error) {
+		return debugAddr[idx], nil
+	}}
+
+	for _, tc := range []struct {
+		instr    []byte
+		expected int64
+	}{
+		{[]byte{byte(DW_OP_addrx), 0x1}, 0x402000},
+		{[]byte{byte(DW_OP_GNU_addr_index), 0x0}, 0x401000},
+		{[]byte{byte(DW_OP_constx), 0x2}, 0x10},
+		{[]byte{byte(DW_OP_addrx), 0x0, byte(DW_OP_plus_uconst), 0x8}, 0x401008},
+	} {
+		actual, _, err := ExecuteStackProgram(regs, tc.instr, ptrSizeByRuntimeArch())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if actual != tc.expected {
+			t.Errorf("%x: actual %#x != expected %#x", tc.instr, actual, tc.expected)
+		}
+	}
+
+	if _, _, err := ExecuteStackProgram(DwarfRegisters{}, []byte{byte(DW_OP_addrx), 0x0}, ptrSizeByRuntimeArch()); err == nil {
+		t.Errorf("expected error executing DW_OP_addrx without debug_addr")
+	}
+}
+
+func TestExecuteStackProgramDeref(t *testing.T) {
+	mem := []byte{0x10, 0x32, 0x54, 0x76, 0x98, 0xba, 0xdc, 0xfe}
+	regs := DwarfRegisters{ByteOrder: binary.LittleEndian, ReadMemory: func(buf []byte, addr uint64) (int, error) {
+		return copy(buf, mem[addr-0x1000:]), nil
+	}}
+
+	for _, tc := range []struct {
+		instr    []byte
+		expected int64
+	}{
+		{[]byte{byte(DW_OP_constu), 0x80, 0x20, byte(DW_OP_deref_size), 0x1}, 0x10},
+		{[]byte{byte(DW_OP_constu), 0x82, 0x20, byte(DW_OP_deref_size), 0x2}, 0x7654},
+		{[]byte{byte(DW_OP_constu), 0x80, 0x20, byte(DW_OP_deref_size), 0x4, byte(DW_OP_plus_uconst), 0x1}, 0x76543211},
+	} {
+		actual, _, err := ExecuteStackProgram(regs, tc.instr, ptrSizeByRuntimeArch())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if actual != tc.expected {
+			t.Errorf("%x: actual %#x != expected %#x", tc.instr, actual, tc.expected)
+		}
+	}
+
+	if _, _, err := ExecuteStackProgram(DwarfRegisters{}, []byte{byte(DW_OP_lit1), byte(DW_OP_deref)}, ptrSizeByRuntimeArch()); err == nil {
+		t.Errorf("expected error executing DW_OP_deref without memory")
+	}
+}
+
+func TestExecuteStackProgramEntryValue(t *testing.T) {
+	regs := DwarfRegisters{
+		EntryValue: func(expr []byte) (int64, error) {
+			if len(expr) != 1 || Opcode(expr[0]) != DW_OP_reg5 {
+				t.Fatalf("unexpected entry value expression %x", expr)
+			}
+			return 40, nil
+		},
+		ParameterRef: func(off uint64) (int64, error) {
+			if off != 0x2a {
+				t.Fatalf("unexpected parameter reference %#x", off)
+			}
+			return 7, nil
+		},
+	}
+
+	for _, tc := range []struct {
+		instr    []byte
+		expected int64
+	}{
+		{[]byte{byte(DW_OP_entry_value), 0x1, byte(DW_OP_reg5), byte(DW_OP_lit2), byte(DW_OP_plus), byte(DW_OP_stack_value)}, 42},
+		{[]byte{byte(DW_OP_GNU_entry_value), 0x1, byte(DW_OP_reg5), byte(DW_OP_stack_value)}, 40},
+		{[]byte{byte(DW_OP_GNU_parameter_ref), 0x2a, 0x0, 0x0, 0x0, byte(DW_OP_stack_value)}, 7},
+	} {
+		actual, pieces, err := ExecuteStackProgram(regs, tc.instr, ptrSizeByRuntimeArch())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if actual != tc.expected {
+			t.Errorf("%x: actual %d != expected %d", tc.instr, actual, tc.expected)
+		}
+		if len(pieces) != 1 || pieces[0].Kind != ImmPiece {
+			t.Errorf("%x: expected a single value piece, got %#v", tc.instr, pieces)
+		}
+	}
+
+	if _, _, err := ExecuteStackProgram(DwarfRegisters{}, []byte{byte(DW_OP_entry_value), 0x1, byte(DW_OP_reg5), byte(DW_OP_stack_value)}, ptrSizeByRuntimeArch()); err == nil {
+		t.Errorf("expected error executing DW_OP_entry_value without entry values")
+	}
+}
+
+func TestExecuteStackProgramPieces(t *testing.T) {
+	_, pieces, err := ExecuteStackProgram(DwarfRegisters{}, []byte{
+		byte(DW_OP_const2s), 0xfe, 0xff, byte(DW_OP_stack_value), byte(DW_OP_piece), 0x4,
+		byte(DW_OP_implicit_pointer), 0x10, 0x0, 0x0, 0x0, 0x8, byte(DW_OP_piece), 0x8,
+	}, ptrSizeByRuntimeArch())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(pieces) != 2 {
+		t.Fatalf("wrong number of pieces %#v", pieces)
+	}
+	if pieces[0].Kind != ImmPiece || pieces[0].Size != 4 || binary.LittleEndian.Uint16(pieces[0].Bytes) != 0xfffe {
+		t.Errorf("wrong value piece %#v", pieces[0])
+	}
+	if pieces[1].Kind != ImplicitPtrPiece || pieces[1].Size != 8 || pieces[1].Ref != 0x10 || pieces[1].Offset != 8 {
+		t.Errorf("wrong implicit pointer piece %#v", pieces[1])
+	}
+
+	if _, _, err := ExecuteStackProgram(DwarfRegisters{}, []byte{byte(DW_OP_lit1), byte(DW_OP_stack_value), byte(DW_OP_lit2)}, ptrSizeByRuntimeArch()); err == nil {
+		t.Errorf("expected error for operation following DW_OP_stack_value")
+	}
+}
@@ -20,6 +20,21 @@ type DwarfRegisters struct {
 	BPRegNum  uint64
 	LRRegNum  uint64
 
+	// DebugAddr returns the entry idx of the debug_addr section of the
+	// current compile unit, it is used by DW_OP_addrx and DW_OP_constx.
+	DebugAddr func(idx uint64) (uint64, error)
+
+	// EntryValue returns the value that the expression expr had on entry to
+	// the current function, it is used by DW_OP_entry_value.
+	EntryValue func(expr []byte) (int64, error)
+	// ParameterRef returns the value that the formal parameter described by
+	// the DIE at offset off of the current compile unit had on entry to the
+	// current function, it is used by DW_OP_GNU_parameter_ref.
+	ParameterRef func(off uint64) (int64, error)
+	// ReadMemory reads memory of the target, it is used by DW_OP_deref and
+	// DW_OP_deref_size.
+	ReadMemory func(buf []byte, addr uint64) (int, error)
+
 	FloatLoadError   error // error produced when loading floating point registers
 	loadMoreCallback func()
 }
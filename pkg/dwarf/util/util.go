@@ -131,6 +131,12 @@ func ParseString(data *bytes.Buffer) (string, uint32) {
 // ReadUintRaw reads an integer of ptrSize bytes, with the specified byte order, from reader.
 func ReadUintRaw(reader io.Reader, order binary.ByteOrder, ptrSize int) (uint64, error) {
 	switch ptrSize {
+	case 2:
+		var n uint16
+		if err := binary.Read(reader, order, &n); err != nil {
+			return 0, err
+		}
+		return uint64(n), nil
 	case 4:
 		var n uint32
 		if err := binary.Read(reader, order, &n); err != nil {
@@ -235,7 +241,7 @@ func ReadUnitVersions(data []byte) map[dwarf.Offset]uint8 {
 
 			switch unitType {
 			case _DW_UT_compile, _DW_UT_partial:
-				headerSize = 5 + secoffsz
+				headerSize = 4 + secoffsz
 
 			case _DW_UT_skeleton, _DW_UT_split_compile:
 				headerSize = 4 + secoffsz + 8
@@ -71,3 +71,16 @@ func TestParseString(t *testing.T) {
 		t.Fatalf("String was not parsed correctly %#v", str)
 	}
 }
+
+func TestReadUnitVersions(t *testing.T) {
+	info := []byte{
+		// DWARFv4 unit: unit_length, version, debug_abbrev_offset, address_size, one byte of DIE
+		0x8, 0x0, 0x0, 0x0, 0x4, 0x0, 0x0, 0x0, 0x0, 0x0, 0x8, 0x1,
+		// DWARFv5 compile unit: unit_length, version, unit_type, address_size, debug_abbrev_offset, one byte of DIE
+		0x9, 0x0, 0x0, 0x0, 0x5, 0x0, 0x1, 0x8, 0x0, 0x0, 0x0, 0x0, 0x1,
+	}
+	versions := ReadUnitVersions(info)
+	if len(versions) != 2 || versions[0xb] != 4 || versions[0x18] != 5 {
+		t.Errorf("wrong unit versions %v", versions)
+	}
+}
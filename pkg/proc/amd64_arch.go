@@ -39,6 +39,7 @@ func AMD64Arch(goos string) *Arch {
 		DwarfRegisterToString:            amd64DwarfRegisterToString,
 		inhibitStepInto:                  func(*BinaryInfo, uint64) bool { return false },
 		asmDecode:                        amd64AsmDecode,
+		contextRegNum:                    1,
 	}
 }
 
@@ -189,6 +190,7 @@ func amd64SwitchStack(it *stackIterator, _ *op.DwarfRegisters) bool {
 		frameOnSystemStack := it.newStackframe(ret, retaddr)
 		it.pc = frameOnSystemStack.Ret
 		it.regs = callFrameRegs
+		it.unwinder = it.callFrameUnwinder
 		it.systemstack = true
 		return true
 
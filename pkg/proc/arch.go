@@ -16,7 +16,8 @@ type Arch struct {
 	altBreakpointInstruction []byte
 	breakInstrMovesPC        bool
 	derefTLS                 bool
-	usesLR                   bool // architecture uses a link register, also called RA on some architectures
+	usesLR                   bool   // architecture uses a link register, also called RA on some architectures
+	contextRegNum            uint64 // DWARF number of the register holding the closure context on function entry
 
 	// asmDecode decodes the assembly instruction starting at mem[0:] into asmInst.
 	// It assumes that the Loc and AtPC fields of asmInst have already been filled.
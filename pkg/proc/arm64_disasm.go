@@ -28,6 +28,11 @@ func arm64AsmDecode(asmInst *AsmInstruction, mem []byte, regs Registers, memrw M
 		asmInst.Kind = RetInstruction
 	case arm64asm.B, arm64asm.BR:
 		asmInst.Kind = JmpInstruction
+		if _, cond := inst.Args[0].(arm64asm.Cond); cond {
+			asmInst.Kind = CondJmpInstruction
+		}
+	case arm64asm.CBZ, arm64asm.CBNZ, arm64asm.TBZ, arm64asm.TBNZ:
+		asmInst.Kind = CondJmpInstruction
 	case arm64asm.BRK:
 		asmInst.Kind = HardBreakInstruction
 	}
@@ -38,9 +43,18 @@ func arm64AsmDecode(asmInst *AsmInstruction, mem []byte, regs Registers, memrw M
 }
 
 func resolveCallArgARM64(inst *arm64asm.Inst, instAddr uint64, currentGoroutine bool, regs Registers, mem MemoryReadWriter, bininfo *BinaryInfo) *Location {
+	var dest arm64asm.Arg
+
 	switch inst.Op {
 	case arm64asm.BL, arm64asm.BLR, arm64asm.B, arm64asm.BR:
-		//ok
+		dest = inst.Args[0]
+		if _, cond := dest.(arm64asm.Cond); cond {
+			dest = inst.Args[1]
+		}
+	case arm64asm.CBZ, arm64asm.CBNZ:
+		dest = inst.Args[1]
+	case arm64asm.TBZ, arm64asm.TBNZ:
+		dest = inst.Args[2]
 	default:
 		return nil
 	}
@@ -48,7 +62,7 @@ func resolveCallArgARM64(inst *arm64asm.Inst, instAddr uint64, currentGoroutine
 	var pc uint64
 	var err error
 
-	switch arg := inst.Args[0].(type) {
+	switch arg := dest.(type) {
 	case arm64asm.Imm:
 		pc = uint64(arg.Imm)
 	case arm64asm.Reg:
@@ -36,8 +36,21 @@ import (
 
 const (
 	dwarfGoLanguage    = 22   // DW_LANG_Go (from DWARF v5, section 7.12, page 231)
-	dwarfAttrAddrBase  = 0x74 // debug/dwarf.AttrAddrBase in Go 1.14, defined here for compatibility with Go < 1.14
+	dwarfAttrAddrBase  = 0x73 // debug/dwarf.AttrAddrBase in Go 1.14, defined here for compatibility with Go < 1.14
 	dwarfTreeCacheSize = 512  // size of the dwarfTree cache of each image
+
+	dwarfTagSkeletonUnit  = 0x4a // debug/dwarf.TagSkeletonUnit in Go 1.14, defined here for compatibility with Go < 1.14
+	dwarfAttrDwoName      = 0x76 // debug/dwarf.AttrDwoName in Go 1.14, defined here for compatibility with Go < 1.14
+	dwarfAttrLoclistsBase = 0x8c // debug/dwarf.AttrLoclistsBase in Go 1.14, defined here for compatibility with Go < 1.14
+
+	dwarfTagCallSite             = 0x48   // debug/dwarf.TagCallSite in Go 1.14, defined here for compatibility with Go < 1.14
+	dwarfTagCallSiteParameter    = 0x49   // debug/dwarf.TagCallSiteParameter in Go 1.14, defined here for compatibility with Go < 1.14
+	dwarfTagGNUCallSite          = 0x4109 // DW_TAG_GNU_call_site, the DWARF 4 extension equivalent to DW_TAG_call_site
+	dwarfTagGNUCallSiteParameter = 0x410a // DW_TAG_GNU_call_site_parameter
+	dwarfAttrCallReturnPC        = 0x7d   // debug/dwarf.AttrCallReturnPC in Go 1.14, defined here for compatibility with Go < 1.14
+	dwarfAttrCallValue           = 0x7e   // debug/dwarf.AttrCallValue in Go 1.14, defined here for compatibility with Go < 1.14
+	dwarfAttrCallParameter       = 0x80   // debug/dwarf.AttrCallParameter in Go 1.14, defined here for compatibility with Go < 1.14
+	dwarfAttrGNUCallSiteValue    = 0x2111 // DW_AT_GNU_call_site_value
 )
 
 // BinaryInfo holds information on the binaries being executed (this
@@ -90,6 +103,12 @@ type BinaryInfo struct {
 
 	gStructOffset uint64
 
+	// moduleDataNoDwarf and runtimeGNoDwarf are runtime.moduledata and the
+	// type of runtime.g of an executable without DWARF, they are read from
+	// the target the first time they are needed.
+	moduleDataNoDwarf *moduleData
+	runtimeGNoDwarf   godwarf.Type
+
 	// nameOfRuntimeType maps an address of a runtime._type struct to its
 	// decoded name. Used with versions of Go <= 1.10 to figure out the DIE of
 	// the concrete type of interfaces.
@@ -275,6 +294,7 @@ type compileUnit struct {
 	ranges  [][2]uint64
 
 	entry     *dwarf.Entry        // debug_info entry describing this compile unit
+	skeleton  *dwarf.Entry        // skeleton compile unit entry, for split compile units
 	isgo      bool                // true if this is the go compile unit
 	lineInfo  *line.DebugLineInfo // debug_line segment associated with this compile unit
 	optimized bool                // this compile unit is optimized
@@ -630,6 +650,46 @@ func (bi *BinaryInfo) PCToInlineFunc(pc uint64) *Function {
 	return bi.LookupFunc[fnname]
 }
 
+// InlinedCallSite is an inlined call containing a PC address.
+type InlinedCallSite struct {
+	// Fn is the inlined function.
+	Fn *Function
+	// File and Line are the position of the call site.
+	File string
+	Line int
+}
+
+// InlinedCallSites returns the inlined calls containing the given PC
+// address, from the outermost to the innermost.
+func (bi *BinaryInfo) InlinedCallSites(pc uint64) []InlinedCallSite {
+	fn := bi.PCToFunc(pc)
+	if fn == nil || fn.cu == nil || fn.cu.image == nil {
+		return nil
+	}
+	dwarfTree, err := fn.cu.image.getDwarfTree(fn.offset)
+	if err != nil {
+		return nil
+	}
+	entries := reader.InlineStack(dwarfTree, pc)
+	r := make([]InlinedCallSite, 0, len(entries))
+	for i := len(entries) - 1; i >= 0; i-- {
+		entry := entries[i]
+		fnname, okname := entry.Val(dwarf.AttrName).(string)
+		fileidx, okfileidx := entry.Val(dwarf.AttrCallFile).(int64)
+		line, okline := entry.Val(dwarf.AttrCallLine).(int64)
+		if !okname || !okfileidx || !okline {
+			break
+		}
+		callfile, okcallfile := fn.cu.lineInfo.FileName(fileidx)
+		if !okcallfile {
+			break
+		}
+		inlfn := &Function{Name: fnname, Entry: fn.Entry, End: fn.End, offset: entry.Offset, cu: fn.cu}
+		r = append(r, InlinedCallSite{Fn: inlfn, File: callfile, Line: int(line)})
+	}
+	return r
+}
+
 // PCToImage returns the image containing the given PC address.
 func (bi *BinaryInfo) PCToImage(pc uint64) *Image {
 	fn := bi.PCToFunc(pc)
@@ -644,6 +704,10 @@ type Image struct {
 
 	index int // index of this object in BinaryInfo.SharedObjects
 
+	// skeleton is the image containing the skeleton compile unit, for
+	// images holding a split compile unit loaded from a .dwo or .dwp file.
+	skeleton *Image
+
 	closer         io.Closer
 	sepDebugCloser io.Closer
 
@@ -652,6 +716,9 @@ type Image struct {
 	loclist2    *loclist.Dwarf2Reader
 	loclist5    *loclist.Dwarf5Reader
 	debugAddr   *godwarf.DebugAddrSection
+	// debugLineStr is the contents of debug_line_str, referenced by the
+	// file tables of DWARF 5 line programs.
+	debugLineStr []byte
 
 	typeCache map[dwarf.Offset]godwarf.Type
 
@@ -665,6 +732,14 @@ type Image struct {
 	// which was added in go 1.11.
 	runtimeTypeToDIE map[uint64]runtimeTypeDIE
 
+	// pclntabAddr and pclntabMagic are the address and the magic number
+	// of the gopclntab section of images loaded without DWARF,
+	// dataSections the address ranges of their initialized data sections.
+	// They are used to find runtime.moduledata, see loadModuleDataNoDwarf.
+	pclntabAddr  uint64
+	pclntabMagic uint32
+	dataSections [][2]uint64
+
 	loadErrMu sync.Mutex
 	loadErr   error
 }
@@ -701,7 +776,7 @@ func (bi *BinaryInfo) AddImage(path string, addr uint64) error {
 	bi.Images = append(bi.Images, image)
 	err := loadBinaryInfo(bi, image, path, addr)
 	if err != nil {
-		bi.Images[len(bi.Images)-1].loadErr = err
+		image.loadErr = err
 	}
 	return err
 }
@@ -782,6 +857,9 @@ func (image *Image) LoadError() error {
 }
 
 func (image *Image) getDwarfTree(off dwarf.Offset) (*godwarf.Tree, error) {
+	if image.dwarf == nil {
+		return nil, errNoDwarf
+	}
 	if r, ok := image.dwarfTreeCache.Get(off); ok {
 		return r.(*godwarf.Tree), nil
 	}
@@ -819,25 +897,88 @@ func (bi *BinaryInfo) LoadImageFromData(dwdata *dwarf.Data, debugFrameBytes, deb
 }
 
 func (bi *BinaryInfo) locationExpr(entry godwarf.Entry, attr dwarf.Attr, pc uint64) ([]byte, *locationExpr, error) {
-	//TODO(aarzilli): handle DW_FORM_loclistx attribute form new in DWARFv5
 	a := entry.Val(attr)
 	if a == nil {
-		return nil, nil, fmt.Errorf("no location attribute %s", attr)
+		// C compilers omit the location of variables that have been optimized
+		// out entirely
+		return nil, nil, &OptimizedOutError{}
 	}
 	if instr, ok := a.([]byte); ok {
 		return instr, &locationExpr{isBlock: true, instr: instr}, nil
 	}
-	off, ok := a.(int64)
-	if !ok {
+	var off int64
+	switch a := a.(type) {
+	case int64:
+		off = a
+	case uint64:
+		// DW_FORM_loclistx, an index in the offset table of debug_loclists
+		var err error
+		off, err = bi.loclistxOffset(a, pc)
+		if err != nil {
+			return nil, nil, err
+		}
+	default:
 		return nil, nil, fmt.Errorf("could not interpret location attribute %s", attr)
 	}
 	instr := bi.loclistEntry(off, pc)
-	if instr == nil {
-		return nil, nil, fmt.Errorf("could not find loclist entry at %#x for address %#x", off, pc)
+	if len(instr) == 0 {
+		// either no entry of the location list covers pc or the entry for pc
+		// is empty, which also means that the value is not available.
+		return nil, nil, &OptimizedOutError{Available: bi.locationAvailable(entry, attr, pc)}
 	}
 	return instr, &locationExpr{pc: pc, off: off, instr: instr}, nil
 }
 
+// locationAvailable returns the ranges of addresses, other than the one
+// containing pc, where the location list attribute attr of entry
+// describes a location.
+func (bi *BinaryInfo) locationAvailable(entry godwarf.Entry, attr dwarf.Attr, pc uint64) [][2]uint64 {
+	cu := bi.findCompileUnit(pc)
+	if cu == nil {
+		return nil
+	}
+	covers, err := bi.locationCovers(cu, entry.Val(attr))
+	if err != nil {
+		return nil
+	}
+	r := [][2]uint64{}
+	for _, rng := range covers {
+		if pc >= rng[0] && pc < rng[1] {
+			continue
+		}
+		if len(r) > 0 && r[len(r)-1][1] == rng[0] {
+			r[len(r)-1][1] = rng[1]
+			continue
+		}
+		r = append(r, rng)
+	}
+	return r
+}
+
+// OptimizedOutError is the error used for variables whose value isn't
+// available at the current PC, because it was optimized out by the
+// compiler.
+type OptimizedOutError struct {
+	// Available is the list of PC ranges where the value is available, if
+	// any.
+	Available [][2]uint64
+}
+
+func (err *OptimizedOutError) Error() string {
+	if len(err.Available) == 0 {
+		return "optimized out"
+	}
+	var buf bytes.Buffer
+	buf.WriteString("optimized out, available at")
+	for i, rng := range err.Available {
+		if i > 0 {
+			buf.WriteString(",")
+		}
+		fmt.Fprintf(&buf, " [%#x, %#x)", rng[0], rng[1])
+	}
+	return buf.String()
+}
+
 type locationExpr struct {
 	isBlock   bool
 	isEscaped bool
@@ -877,33 +1018,57 @@ func (bi *BinaryInfo) LocationCovers(entry *dwarf.Entry, attr dwarf.Attr) ([][2]
 		return [][2]uint64{[2]uint64{0, ^uint64(0)}}, nil
 	}
 
-	off, ok := a.(int64)
-	if !ok {
-		return nil, fmt.Errorf("attribute %s of unsupported type %T", attr, a)
-	}
 	cu := bi.Images[0].findCompileUnitForOffset(entry.Offset)
 	if cu == nil {
 		return nil, errors.New("could not find compile unit")
 	}
-	if cu.Version >= 5 && cu.image.loclist5 != nil {
-		return nil, errors.New("LocationCovers does not support DWARFv5")
+	return bi.locationCovers(cu, a)
+}
+
+// locationCovers returns the list of PC addresses where the location list
+// a, the value of a location attribute of an entry of cu, describes a
+// location.
+func (bi *BinaryInfo) locationCovers(cu *compileUnit, a interface{}) ([][2]uint64, error) {
+	var off int64
+	switch a := a.(type) {
+	case int64:
+		off = a
+	case uint64:
+		var err error
+		off, err = cu.loclistxOffset(a)
+		if err != nil {
+			return nil, err
+		}
+	default:
+		return nil, fmt.Errorf("location attribute of unsupported type %T", a)
 	}
 
 	image := cu.image
-	base := cu.lowPC
-	if image == nil || image.loclist2.Empty() {
+	if image == nil {
+		return nil, errors.New("malformed executable")
+	}
+	var rdr loclist.Reader = image.loclist2
+	var debugAddr *godwarf.DebugAddr
+	if cu.Version >= 5 && image.loclist5 != nil {
+		rdr = image.loclist5
+		debugAddr = cu.debugAddr()
+	}
+	if rdr.Empty() {
 		return nil, errors.New("malformed executable")
 	}
 
+	entries, err := rdr.Entries(int(off), image.StaticBase, cu.lowPC, debugAddr)
+	if err != nil {
+		return nil, err
+	}
 	r := [][2]uint64{}
-	var e loclist.Entry
-	image.loclist2.Seek(int(off))
-	for image.loclist2.Next(&e) {
-		if e.BaseAddressSelection() {
-			base = e.HighPC
+	for _, e := range entries {
+		if len(e.Instr) == 0 {
+			// empty location descriptions mean that the value is not
+			// available
 			continue
 		}
-		r = append(r, [2]uint64{e.LowPC + base, e.HighPC + base})
+		r = append(r, [2]uint64{e.LowPC, e.HighPC})
 	}
 	return r, nil
 }
@@ -918,6 +1083,11 @@ func (bi *BinaryInfo) Location(entry godwarf.Entry, attr dwarf.Attr, pc uint64,
 		return 0, nil, nil, err
 	}
 	addr, pieces, err := op.ExecuteStackProgram(regs, instr, bi.Arch.PtrSize())
+	if ooerr, ok := err.(*OptimizedOutError); ok && ooerr.Available == nil && !descr.isBlock {
+		// a value computed from the entry value of a parameter that the
+		// caller frame can not supply
+		err = &OptimizedOutError{Available: bi.locationAvailable(entry, attr, pc)}
+	}
 	return addr, pieces, descr, err
 }
 
@@ -939,9 +1109,7 @@ func (bi *BinaryInfo) loclistEntry(off int64, pc uint64) []byte {
 	var debugAddr *godwarf.DebugAddr
 	if cu != nil && cu.Version >= 5 && image.loclist5 != nil {
 		loclist = image.loclist5
-		if addrBase, ok := cu.entry.Val(dwarfAttrAddrBase).(int64); ok {
-			debugAddr = image.debugAddr.GetSubsection(uint64(addrBase))
-		}
+		debugAddr = cu.debugAddr()
 	}
 
 	if loclist.Empty() {
@@ -960,6 +1128,71 @@ func (bi *BinaryInfo) loclistEntry(off int64, pc uint64) []byte {
 	return nil
 }
 
+// loclistxOffset returns the offset in debug_loclists of the location list
+// with index idx, for the compile unit containing pc.
+func (bi *BinaryInfo) loclistxOffset(idx uint64, pc uint64) (int64, error) {
+	cu := bi.findCompileUnit(pc)
+	if cu == nil {
+		return 0, fmt.Errorf("could not find debug_loclists section for address %#x", pc)
+	}
+	return cu.loclistxOffset(idx)
+}
+
+// loclistxOffset returns the offset in debug_loclists of the location list
+// with index idx of the compile unit.
+func (cu *compileUnit) loclistxOffset(idx uint64) (int64, error) {
+	if cu.image.loclist5.Empty() {
+		return 0, fmt.Errorf("could not find debug_loclists section for compile unit %s", cu.name)
+	}
+	// split compile units do not have a DW_AT_loclists_base attribute, their
+	// offset table immediately follows the header of debug_loclists.dwo
+	locListsBase, _ := cu.entry.Val(dwarfAttrLoclistsBase).(int64)
+	off, err := cu.image.loclist5.Offset(uint64(locListsBase), idx)
+	return int64(off), err
+}
+
+// unitOffset returns the offset of the header of the compile unit.
+func (cu *compileUnit) unitOffset() dwarf.Offset {
+	// unit_length, version, debug_abbrev_offset and address_size
+	hdrsz := dwarf.Offset(4 + 2 + 4 + 1)
+	if cu.Version >= 5 {
+		// unit_type
+		hdrsz++
+		if cu.skeleton != nil {
+			// dwo_id
+			hdrsz += 8
+		}
+	}
+	return cu.offset - hdrsz
+}
+
+// debugAddr returns the subsection of debug_addr used by the compile unit,
+// for split compile units this is specified by the skeleton compile unit.
+func (cu *compileUnit) debugAddr() *godwarf.DebugAddr {
+	entry := cu.entry
+	if cu.skeleton != nil {
+		entry = cu.skeleton
+	}
+	addrBase, ok := addrBase(entry)
+	if !ok {
+		return nil
+	}
+	return cu.image.debugAddr.GetSubsection(uint64(addrBase))
+}
+
+// debugAddrFunc returns a function reading the debug_addr section used by
+// the compile unit containing the entry at offset off, or nil.
+func (image *Image) debugAddrFunc(off dwarf.Offset) func(uint64) (uint64, error) {
+	if len(image.compileUnits) == 0 {
+		return nil
+	}
+	debugAddr := image.findCompileUnitForOffset(off).debugAddr()
+	if debugAddr == nil {
+		return nil
+	}
+	return debugAddr.Get
+}
+
 // findCompileUnit returns the compile unit containing address pc.
 func (bi *BinaryInfo) findCompileUnit(pc uint64) *compileUnit {
 	for _, image := range bi.Images {
@@ -996,6 +1229,9 @@ func (bi *BinaryInfo) Producer() string {
 
 // Type returns the Dwarf type entry at `offset`.
 func (image *Image) Type(offset dwarf.Offset) (godwarf.Type, error) {
+	if image.dwarf == nil {
+		return nil, errNoDwarf
+	}
 	return godwarf.ReadType(image.dwarf, image.index, offset, image.typeCache)
 }
 
@@ -1145,6 +1381,13 @@ func loadBinaryInfoElf(bi *BinaryInfo, image *Image, path string, addr uint64, w
 		var serr error
 		sepFile, dwarfFile, serr = bi.openSeparateDebugInfo(image, elfFile, bi.debugInfoDirectories)
 		if serr != nil {
+			perr := bi.loadPclntabElf(image, elfFile, wg)
+			// C code, like shared libraries without debug info, can still be
+			// unwound using .eh_frame
+			bi.parseEhFrameElf(image, elfFile)
+			if perr == nil {
+				return nil
+			}
 			return serr
 		}
 		image.sepDebugCloser = sepFile
@@ -1170,10 +1413,11 @@ func loadBinaryInfoElf(bi *BinaryInfo, image *Image, path string, addr uint64, w
 	debugLoclistBytes, _ := godwarf.GetDebugSectionElf(dwarfFile, "loclists")
 	image.loclist5 = loclist.NewDwarf5Reader(debugLoclistBytes)
 	debugAddrBytes, _ := godwarf.GetDebugSectionElf(dwarfFile, "addr")
-	image.debugAddr = godwarf.ParseAddr(debugAddrBytes)
+	image.debugAddr = godwarf.ParseAddr(debugAddrBytes, frame.DwarfEndian(debugInfoBytes), bi.Arch.PtrSize())
+	image.debugLineStr, _ = godwarf.GetDebugSectionElf(dwarfFile, "line_str")
 
 	wg.Add(3)
-	go bi.parseDebugFrameElf(image, dwarfFile, debugInfoBytes, wg)
+	go bi.parseDebugFrameElf(image, dwarfFile, elfFile, debugInfoBytes, wg)
 	go bi.loadDebugInfoMaps(image, debugInfoBytes, debugLineBytes, wg, nil)
 	go bi.loadSymbolName(image, elfFile, wg)
 	if image.index == 0 {
@@ -1184,7 +1428,7 @@ func loadBinaryInfoElf(bi *BinaryInfo, image *Image, path string, addr uint64, w
 	return nil
 }
 
-//  STT_FUNC is a code object, see /usr/include/elf.h for a full definition.
+// STT_FUNC is a code object, see /usr/include/elf.h for a full definition.
 const STT_FUNC = 2
 
 func (bi *BinaryInfo) loadSymbolName(image *Image, file *elf.File, wg *sync.WaitGroup) {
@@ -1203,18 +1447,112 @@ func (bi *BinaryInfo) loadSymbolName(image *Image, file *elf.File, wg *sync.Wait
 	}
 }
 
-func (bi *BinaryInfo) parseDebugFrameElf(image *Image, exe *elf.File, debugInfoBytes []byte, wg *sync.WaitGroup) {
+// parseDebugFrameElf loads the .debug_frame section of dwarfFile and the
+// .eh_frame section of exe, dwarfFile is either exe or its separate debug
+// info file.
+func (bi *BinaryInfo) parseDebugFrameElf(image *Image, dwarfFile, exe *elf.File, debugInfoBytes []byte, wg *sync.WaitGroup) {
 	defer wg.Done()
+	defer bi.parseEhFrameElf(image, exe)
 
-	debugFrameData, err := godwarf.GetDebugSectionElf(exe, "frame")
+	debugFrameData, err := godwarf.GetDebugSectionElf(dwarfFile, "frame")
 	if err != nil {
-		image.setLoadError("could not get .debug_frame section: %v", err)
+		// C compilers only emit .eh_frame by default
+		if exe.Section(".eh_frame") == nil {
+			image.setLoadError("could not get .debug_frame section: %v", err)
+		}
 		return
 	}
 
 	bi.frameEntries = bi.frameEntries.Append(frame.Parse(debugFrameData, frame.DwarfEndian(debugInfoBytes), image.StaticBase, bi.Arch.PtrSize()))
 }
 
+// parseEhFrameElf adds the frame descriptor entries of the .eh_frame
+// section of exe for the functions that aren't already described by
+// .debug_frame (or gopclntab). The Go linker does not emit .eh_frame but C
+// code (cgo, libc and most other shared libraries) usually has it, even
+// when it has no other debug info.
+func (bi *BinaryInfo) parseEhFrameElf(image *Image, exe *elf.File) {
+	data, addr, err := ehFrameSectionElf(exe, bi.Arch.PtrSize())
+	if err != nil {
+		bi.logger.Warnf("could not read .eh_frame section of %s: %v", image.Path, err)
+		return
+	}
+	if data == nil {
+		return
+	}
+	fdes, err := frame.ParseEhFrame(data, exe.ByteOrder, image.StaticBase, bi.Arch.PtrSize(), addr, frame.EhFrameDataBaseElf(exe))
+	if err != nil {
+		// entries parsed before the error are still used
+		bi.logger.Warnf("could not parse .eh_frame section of %s: %v", image.Path, err)
+	}
+	newfdes := make(frame.FrameDescriptionEntries, 0, len(fdes))
+	for _, fde := range fdes {
+		if fde.End() == fde.Begin() {
+			continue
+		}
+		if _, err := bi.frameEntries.FDEForPC(fde.Begin()); err == nil {
+			continue
+		}
+		newfdes = append(newfdes, fde)
+	}
+	bi.frameEntries = bi.frameEntries.Append(newfdes)
+}
+
+// ehFrameSectionElf returns the contents and address of the .eh_frame
+// section of exe. If exe has no section header for .eh_frame its address
+// is read from .eh_frame_hdr.
+func ehFrameSectionElf(exe *elf.File, ptrSize int) ([]byte, uint64, error) {
+	if sec := exe.Section(".eh_frame"); sec != nil && sec.Type != elf.SHT_NOBITS {
+		data, err := sec.Data()
+		return data, sec.Addr, err
+	}
+	hdrsec := exe.Section(".eh_frame_hdr")
+	if hdrsec == nil || hdrsec.Type == elf.SHT_NOBITS {
+		return nil, 0, nil
+	}
+	hdrdata, err := hdrsec.Data()
+	if err != nil {
+		return nil, 0, err
+	}
+	hdr, err := frame.ParseEhFrameHdr(hdrdata, exe.ByteOrder, ptrSize, hdrsec.Addr)
+	if err != nil {
+		return nil, 0, err
+	}
+	// The size of .eh_frame is unknown, read until the end of the segment
+	// containing it, the parser will stop at its zero terminator.
+	for _, prog := range exe.Progs {
+		if prog.Type != elf.PT_LOAD || hdr.EhFrameAddr < prog.Vaddr || hdr.EhFrameAddr >= prog.Vaddr+prog.Filesz {
+			continue
+		}
+		data := make([]byte, prog.Vaddr+prog.Filesz-hdr.EhFrameAddr)
+		if _, err := prog.ReadAt(data, int64(hdr.EhFrameAddr-prog.Vaddr)); err != nil {
+			return nil, 0, err
+		}
+		return data, hdr.EhFrameAddr, nil
+	}
+	return nil, 0, fmt.Errorf("could not find .eh_frame at %#x", hdr.EhFrameAddr)
+}
+
+// loadPclntabElf loads image from the gopclntab section of exe, used
+// when the executable has no DWARF sections.
+func (bi *BinaryInfo) loadPclntabElf(image *Image, exe *elf.File, wg *sync.WaitGroup) error {
+	sec, err := findPclntabElf(exe)
+	if err != nil {
+		return err
+	}
+	if err := bi.loadPclntab(image, sec); err != nil {
+		return err
+	}
+	bi.logger.Warnf("%s has no DWARF debug info, using gopclntab: variables will be unavailable", image.Path)
+	wg.Add(1)
+	go bi.loadSymbolName(image, exe, wg)
+	if image.index == 0 {
+		wg.Add(1)
+		go bi.setGStructOffsetElf(image, exe, wg)
+	}
+	return nil
+}
+
 func (bi *BinaryInfo) setGStructOffsetElf(image *Image, exe *elf.File, wg *sync.WaitGroup) {
 	defer wg.Done()
 
@@ -1227,7 +1565,7 @@ func (bi *BinaryInfo) setGStructOffsetElf(image *Image, exe *elf.File, wg *sync.
 	//   emitting runtime.tlsg, a TLS symbol, which is relocated to the chosen
 	//   offset in libc's TLS block.
 	symbols, err := exe.Symbols()
-	if err != nil {
+	if err != nil && err != elf.ErrNoSymbols {
 		image.setLoadError("could not parse ELF symbols: %v", err)
 		return
 	}
@@ -1277,15 +1615,6 @@ func loadBinaryInfoPE(bi *BinaryInfo, image *Image, path string, entryPoint uint
 	if !supportedWindowsArch[cpuArch] {
 		return &ErrUnsupportedArch{os: "windows", cpuArch: cpuArch}
 	}
-	image.dwarf, err = peFile.DWARF()
-	if err != nil {
-		return err
-	}
-	debugInfoBytes, err := godwarf.GetDebugSectionPE(peFile, "info")
-	if err != nil {
-		return err
-	}
-
 	//TODO(aarzilli): actually test this when Go supports PIE buildmode on Windows.
 	opth := peFile.OptionalHeader.(*pe.OptionalHeader64)
 	if entryPoint != 0 {
@@ -1296,6 +1625,27 @@ func loadBinaryInfoPE(bi *BinaryInfo, image *Image, path string, entryPoint uint
 		}
 	}
 
+	// Use ArbitraryUserPointer (0x28) as pointer to pointer
+	// to G struct per:
+	// https://golang.org/src/runtime/cgo/gcc_windows_amd64.c
+
+	bi.gStructOffset = 0x28
+
+	image.dwarf, err = peFile.DWARF()
+	if err != nil {
+		if sec, perr := findPclntabPE(peFile, opth.ImageBase); perr == nil {
+			if perr := bi.loadPclntab(image, sec); perr == nil {
+				bi.logger.Warnf("%s has no DWARF debug info, using gopclntab: variables will be unavailable", image.Path)
+				return nil
+			}
+		}
+		return err
+	}
+	debugInfoBytes, err := godwarf.GetDebugSectionPE(peFile, "info")
+	if err != nil {
+		return err
+	}
+
 	image.dwarfReader = image.dwarf.Reader()
 
 	debugLineBytes, err := godwarf.GetDebugSectionPE(peFile, "line")
@@ -1307,17 +1657,12 @@ func loadBinaryInfoPE(bi *BinaryInfo, image *Image, path string, entryPoint uint
 	debugLoclistBytes, _ := godwarf.GetDebugSectionPE(peFile, "loclists")
 	image.loclist5 = loclist.NewDwarf5Reader(debugLoclistBytes)
 	debugAddrBytes, _ := godwarf.GetDebugSectionPE(peFile, "addr")
-	image.debugAddr = godwarf.ParseAddr(debugAddrBytes)
+	image.debugAddr = godwarf.ParseAddr(debugAddrBytes, frame.DwarfEndian(debugInfoBytes), bi.Arch.PtrSize())
+	image.debugLineStr, _ = godwarf.GetDebugSectionPE(peFile, "line_str")
 
 	wg.Add(2)
 	go bi.parseDebugFramePE(image, peFile, debugInfoBytes, wg)
 	go bi.loadDebugInfoMaps(image, debugInfoBytes, debugLineBytes, wg, nil)
-
-	// Use ArbitraryUserPointer (0x28) as pointer to pointer
-	// to G struct per:
-	// https://golang.org/src/runtime/cgo/gcc_windows_amd64.c
-
-	bi.gStructOffset = 0x28
 	return nil
 }
 
@@ -1377,6 +1722,13 @@ func loadBinaryInfoMacho(bi *BinaryInfo, image *Image, path string, entryPoint u
 	}
 	image.dwarf, err = exe.DWARF()
 	if err != nil {
+		if sec, perr := findPclntabMacho(exe); perr == nil {
+			if perr := bi.loadPclntab(image, sec); perr == nil {
+				bi.logger.Warnf("%s has no DWARF debug info, using gopclntab: variables will be unavailable", image.Path)
+				bi.setGStructOffsetMacho()
+				return nil
+			}
+		}
 		return err
 	}
 	debugInfoBytes, err := godwarf.GetDebugSectionMacho(exe, "info")
@@ -1395,7 +1747,8 @@ func loadBinaryInfoMacho(bi *BinaryInfo, image *Image, path string, entryPoint u
 	debugLoclistBytes, _ := godwarf.GetDebugSectionMacho(exe, "loclists")
 	image.loclist5 = loclist.NewDwarf5Reader(debugLoclistBytes)
 	debugAddrBytes, _ := godwarf.GetDebugSectionMacho(exe, "addr")
-	image.debugAddr = godwarf.ParseAddr(debugAddrBytes)
+	image.debugAddr = godwarf.ParseAddr(debugAddrBytes, frame.DwarfEndian(debugInfoBytes), bi.Arch.PtrSize())
+	image.debugLineStr, _ = godwarf.GetDebugSectionMacho(exe, "line_str")
 
 	wg.Add(2)
 	go bi.parseDebugFrameMacho(image, exe, debugInfoBytes, wg)
@@ -1564,7 +1917,7 @@ func (bi *BinaryInfo) loadDebugInfoMaps(image *Image, debugInfoBytes, debugLineB
 			break
 		}
 		switch entry.Tag {
-		case dwarf.TagCompileUnit:
+		case dwarf.TagCompileUnit, dwarfTagSkeletonUnit:
 			cu := &compileUnit{}
 			cu.image = image
 			cu.entry = entry
@@ -1596,7 +1949,7 @@ func (bi *BinaryInfo) loadDebugInfoMaps(image *Image, debugInfoBytes, debugLineB
 						logger.Printf(fmt, args)
 					}
 				}
-				cu.lineInfo = line.Parse(compdir, bytes.NewBuffer(debugLineBytes[lineInfoOffset:]), logfn, image.StaticBase, bi.GOOS == "windows", bi.Arch.PtrSize())
+				cu.lineInfo = line.Parse(compdir, bytes.NewBuffer(debugLineBytes[lineInfoOffset:]), image.debugLineStr, logfn, image.StaticBase, bi.GOOS == "windows", bi.Arch.PtrSize())
 			}
 			cu.producer, _ = entry.Val(dwarf.AttrProducer).(string)
 			if cu.isgo && cu.producer != "" {
@@ -1612,6 +1965,11 @@ func (bi *BinaryInfo) loadDebugInfoMaps(image *Image, debugInfoBytes, debugLineB
 			if cu.isgo && gopkg != "" {
 				bi.PackageMap[gopkg] = append(bi.PackageMap[gopkg], escapePackagePath(strings.Replace(cu.name, "\\", "/", -1)))
 			}
+			if isSkeletonUnit(entry) {
+				bi.loadSplitUnit(ctxt, image, debugInfoBytes, cu)
+				reader.SkipChildren()
+				continue
+			}
 			image.compileUnits = append(image.compileUnits, cu)
 			if entry.Children {
 				bi.loadDebugInfoMapsCompileUnit(ctxt, image, reader, cu)
@@ -1693,6 +2051,11 @@ func (bi *BinaryInfo) loadDebugInfoMapsCompileUnit(ctxt *loadDebugInfoMapsContex
 				if loc, ok := entry.Val(dwarf.AttrLocation).([]byte); ok {
 					if len(loc) == bi.Arch.PtrSize()+1 && op.Opcode(loc[0]) == op.DW_OP_addr {
 						addr, _ = util.ReadUintRaw(bytes.NewReader(loc[1:]), binary.LittleEndian, bi.Arch.PtrSize())
+					} else if len(loc) > 1 && (op.Opcode(loc[0]) == op.DW_OP_addrx || op.Opcode(loc[0]) == op.DW_OP_GNU_addr_index) {
+						idx, n := util.DecodeULEB128(bytes.NewBuffer(loc[1:]))
+						if int(n)+1 == len(loc) {
+							addr, _ = cu.debugAddr().Get(idx)
+						}
 					}
 				}
 				if !cu.isgo {
@@ -1921,12 +2284,12 @@ func (bi *BinaryInfo) loadDebugInfoMapsInlinedCalls(ctxt *loadDebugInfoMapsConte
 				reader.SkipChildren()
 				continue
 			}
-			if int(callfileidx-1) >= len(cu.lineInfo.FileNames) {
+			callfile, ok := cu.lineInfo.FileName(callfileidx)
+			if !ok {
 				bi.logger.Warnf("reading debug_info: CallFile (%d) of inlined call does not exist in compile unit file table at %#x", callfileidx, entry.Offset)
 				reader.SkipChildren()
 				continue
 			}
-			callfile := cu.lineInfo.FileNames[callfileidx-1].Path
 
 			fn.InlinedCalls = append(fn.InlinedCalls, InlinedCall{
 				cu:     cu,
@@ -6,6 +6,7 @@ import (
 	"go/ast"
 	"go/constant"
 	"reflect"
+	"time"
 )
 
 const (
@@ -38,7 +39,7 @@ type Breakpoint struct {
 	// stepping).
 	// A single breakpoint can be both a UserBreakpoint and some kind of
 	// internal breakpoint, but it can not be two different kinds of internal
-	// breakpoint.
+	// breakpoint, except CoverBreakpoint which can overlap any other kind.
 	Kind BreakpointKind
 
 	// Breakpoint information
@@ -91,6 +92,10 @@ const (
 	// Continue will set a new breakpoint (of NextBreakpoint kind) on the
 	// destination of CALL, delete this breakpoint and then continue again
 	StepBreakpoint
+	// CoverBreakpoint is a breakpoint set by SetCoverBreakpoints, Continue
+	// will record that its line was executed, delete it and continue
+	// again. It is not cleared by ClearInternalBreakpoints.
+	CoverBreakpoint
 )
 
 func (bp *Breakpoint) String() string {
@@ -251,10 +256,14 @@ func (t *Target) SetBreakpoint(addr uint64, kind BreakpointKind, cond ast.Expr)
 		// We can overlap one internal breakpoint with one user breakpoint, we
 		// need to support this otherwise a conditional breakpoint can mask a
 		// breakpoint set by next or step.
-		if (kind != UserBreakpoint && bp.Kind != UserBreakpoint) || (kind == UserBreakpoint && bp.IsUser()) {
+		// Coverage breakpoints can overlap with any other breakpoint.
+		if (kind != UserBreakpoint && kind != CoverBreakpoint && bp.Kind&^(UserBreakpoint|CoverBreakpoint) != 0) || (kind == UserBreakpoint && bp.IsUser()) {
 			return bp, BreakpointExistsError{bp.File, bp.Line, bp.Addr}
 		}
 		bp.Kind |= kind
+		if kind == CoverBreakpoint {
+			return bp, nil
+		}
 		if kind != UserBreakpoint {
 			bp.internalCond = cond
 		} else {
@@ -341,7 +350,7 @@ func (t *Target) ClearInternalBreakpoints() error {
 	bpmap := t.Breakpoints()
 	threads := t.ThreadList()
 	for addr, bp := range bpmap.M {
-		bp.Kind = bp.Kind & UserBreakpoint
+		bp.Kind = bp.Kind & (UserBreakpoint | CoverBreakpoint)
 		bp.internalCond = nil
 		bp.returnInfo = nil
 		if bp.Kind != 0 {
@@ -361,10 +370,10 @@ func (t *Target) ClearInternalBreakpoints() error {
 }
 
 // HasInternalBreakpoints returns true if bpmap has at least one internal
-// breakpoint set.
+// breakpoint set, coverage breakpoints are not counted.
 func (bpmap *BreakpointMap) HasInternalBreakpoints() bool {
 	for _, bp := range bpmap.M {
-		if bp.IsInternal() {
+		if bp.Kind&^(UserBreakpoint|CoverBreakpoint) != 0 {
 			return true
 		}
 	}
@@ -382,6 +391,9 @@ type BreakpointState struct {
 	// CondError contains any error encountered while evaluating the
 	// breakpoint's condition.
 	CondError error
+	// HitTime is the time at which the target process stopped at the
+	// breakpoint.
+	HitTime time.Time
 }
 
 // Clear zeros the struct.
@@ -390,6 +402,7 @@ func (bpstate *BreakpointState) Clear() {
 	bpstate.Active = false
 	bpstate.Internal = false
 	bpstate.CondError = nil
+	bpstate.HitTime = time.Time{}
 }
 
 func (bpstate *BreakpointState) String() string {
@@ -0,0 +1,139 @@
+package proc
+
+import "sort"
+
+// BasicBlock is a sequence of instructions that can only be entered at its
+// first instruction and only be left after its last instruction.
+type BasicBlock struct {
+	// Insts are the instructions of the block, a subslice of the
+	// instructions the graph was built from.
+	Insts []AsmInstruction
+	// Succs are the edges leaving the block.
+	Succs []BasicBlockEdge
+}
+
+// BasicBlockEdge is an edge of the control flow graph.
+type BasicBlockEdge struct {
+	// To is the index of the destination block.
+	To   int
+	Kind BasicBlockEdgeKind
+}
+
+// BasicBlockEdgeKind describes how control is transferred between two
+// basic blocks.
+type BasicBlockEdgeKind uint8
+
+const (
+	// FallthroughEdge continues with the instruction following the last
+	// instruction of the block, either because the last instruction is a
+	// call, a conditional jump that isn't taken or the first instruction of
+	// the next block is the destination of a jump.
+	FallthroughEdge BasicBlockEdgeKind = iota
+	// JumpEdge is an unconditional jump.
+	JumpEdge
+	// BranchEdge is a conditional jump that is taken.
+	BranchEdge
+)
+
+func (kind BasicBlockEdgeKind) String() string {
+	switch kind {
+	case FallthroughEdge:
+		return "fallthrough"
+	case JumpEdge:
+		return "jump"
+	case BranchEdge:
+		return "branch"
+	default:
+		return "unknown"
+	}
+}
+
+// ControlFlowGraph splits text, the instructions of a function as returned
+// by Disassemble, into basic blocks.
+// A block ends after every jump, call and return instruction and before
+// every instruction that is the destination of a jump. Jumps to
+// instructions outside of text, indirect jumps and returns leave the
+// function and have no successors.
+func ControlFlowGraph(text []AsmInstruction) []BasicBlock {
+	if len(text) == 0 {
+		return nil
+	}
+
+	instIndex := func(pc uint64) int {
+		i := sort.Search(len(text), func(i int) bool { return text[i].Loc.PC >= pc })
+		if i < len(text) && text[i].Loc.PC == pc {
+			return i
+		}
+		return -1
+	}
+
+	leaders := make([]bool, len(text))
+	leaders[0] = true
+	for i := range text {
+		inst := &text[i]
+		switch {
+		case inst.IsJmp(), inst.IsCondJmp():
+			if inst.DestLoc != nil {
+				if j := instIndex(inst.DestLoc.PC); j >= 0 {
+					leaders[j] = true
+				}
+			}
+		case inst.IsCall(), inst.IsRet(), inst.IsHardBreak():
+		default:
+			continue
+		}
+		if i+1 < len(text) {
+			leaders[i+1] = true
+		}
+	}
+
+	blockOf := make([]int, len(text))
+	var blocks []BasicBlock
+	for i := range text {
+		if leaders[i] {
+			blocks = append(blocks, BasicBlock{})
+		}
+		blockOf[i] = len(blocks) - 1
+	}
+	start := 0
+	for i := range blocks {
+		end := start + 1
+		for end < len(text) && !leaders[end] {
+			end++
+		}
+		blocks[i].Insts = text[start:end]
+		start = end
+	}
+
+	for i := range blocks {
+		last := &blocks[i].Insts[len(blocks[i].Insts)-1]
+		next := -1
+		if i+1 < len(blocks) {
+			next = i + 1
+		}
+		dest := -1
+		if last.DestLoc != nil {
+			if j := instIndex(last.DestLoc.PC); j >= 0 {
+				dest = blockOf[j]
+			}
+		}
+		switch {
+		case last.IsRet(), last.IsHardBreak():
+			continue
+		case last.IsJmp():
+			if dest >= 0 {
+				blocks[i].Succs = append(blocks[i].Succs, BasicBlockEdge{To: dest, Kind: JumpEdge})
+			}
+			continue
+		case last.IsCondJmp():
+			if dest >= 0 {
+				blocks[i].Succs = append(blocks[i].Succs, BasicBlockEdge{To: dest, Kind: BranchEdge})
+			}
+		}
+		if next >= 0 {
+			blocks[i].Succs = append(blocks[i].Succs, BasicBlockEdge{To: next, Kind: FallthroughEdge})
+		}
+	}
+
+	return blocks
+}
@@ -0,0 +1,149 @@
+package proc
+
+import (
+	"debug/dwarf"
+	"sort"
+	"strings"
+
+	"github.com/go-delve/delve/pkg/dwarf/godwarf"
+)
+
+// closureField is a variable captured by a closure.
+type closureField struct {
+	// entry is the DIE of the variable in the closure function, the name
+	// of variables captured by reference starts with '&'.
+	entry *godwarf.Tree
+	name  string
+	typ   godwarf.Type
+	// off is the offset of the variable in the funcval object.
+	off int64
+}
+
+// closureLayout returns the variables captured by the closure whose
+// function is described by dwarfTree and their position in the funcval
+// object of the closure.
+// Starting with Go 1.23 the compiler attaches the offset of captured
+// variables to their DIE. Older versions of Go copy captured variables
+// into local variables of the closure function, their offsets are
+// recovered from the struct type the compiler uses to allocate the
+// funcval, see closureLayoutFromTypes.
+func closureLayout(bi *BinaryInfo, image *Image, dwarfTree *godwarf.Tree) []closureField {
+	var fields []closureField
+	for _, entry := range dwarfTree.Children {
+		off, ok := entry.Val(godwarf.AttrGoClosureOffset).(int64)
+		if !ok {
+			continue
+		}
+		name, typ, err := readVarEntry(entry, image)
+		if err != nil {
+			continue
+		}
+		fields = append(fields, closureField{entry: entry, name: name, typ: typ, off: off})
+	}
+	if fields != nil {
+		return fields
+	}
+	return closureLayoutFromTypes(bi, image, dwarfTree)
+}
+
+// closureLayoutFromTypes finds the layout of the funcval object of the
+// closure described by dwarfTree among the struct types used by the
+// compiler for funcval objects allocated on the heap:
+//
+//	struct { .F uintptr; x *T; y U }
+//
+// where x is captured by reference and y by value. The closure function
+// of such a funcval has a local variable named &x of type *T and one
+// named y of type U. The type with the most fields matching the local
+// variables of the function is chosen.
+// These types are only described in the debug information of executables
+// whose linker emits a DIE for every runtime type (Go 1.15 and earlier).
+func closureLayoutFromTypes(bi *BinaryInfo, image *Image, dwarfTree *godwarf.Tree) []closureField {
+	if fnname, _ := dwarfTree.Val(dwarf.AttrName).(string); !strings.Contains(fnname, ".func") {
+		return nil
+	}
+	locals := make(map[string]*godwarf.Tree)
+	for _, entry := range dwarfTree.Children {
+		if entry.Tag != dwarf.TagVariable {
+			continue
+		}
+		if name, ok := entry.Val(dwarf.AttrName).(string); ok {
+			locals[name] = entry
+		}
+	}
+	if len(locals) == 0 {
+		return nil
+	}
+
+	var names []string
+	for name := range bi.types {
+		if strings.HasPrefix(name, "struct { .F uintptr; ") || strings.HasPrefix(name, "struct { F uintptr; ") {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	var best []closureField
+	for _, name := range names {
+		typ, err := bi.findType(name)
+		if err != nil {
+			continue
+		}
+		styp, ok := typ.(*godwarf.StructType)
+		if !ok || len(styp.Field) <= len(best)+1 {
+			continue
+		}
+		fields := make([]closureField, 0, len(styp.Field)-1)
+		for _, field := range styp.Field[1:] {
+			entry := locals[field.Name]
+			if entry == nil {
+				entry = locals["&"+field.Name]
+			}
+			if entry == nil {
+				break
+			}
+			lname, ltyp, err := readVarEntry(entry, image)
+			if err != nil || ltyp.String() != field.Type.String() {
+				break
+			}
+			fields = append(fields, closureField{entry: entry, name: lname, typ: ltyp, off: field.ByteOffset})
+		}
+		if len(fields) == len(styp.Field)-1 {
+			best = fields
+		}
+	}
+	return best
+}
+
+// closureVariables returns the variables captured by a closure with the
+// given layout, read from its funcval object at closureAddr.
+func closureVariables(bi *BinaryInfo, mem MemoryReadWriter, layout []closureField, closureAddr uint64) []*Variable {
+	if closureAddr == 0 {
+		return nil
+	}
+	vars := make([]*Variable, 0, len(layout))
+	for _, field := range layout {
+		v := newVariable(field.name, closureAddr+uint64(field.off), field.typ, bi, mem)
+		if len(field.name) > 1 && field.name[0] == '&' {
+			// captured by reference
+			v = v.maybeDereference()
+			v.Name = field.name[1:]
+			v.Flags |= VariableEscaped
+		}
+		v.Flags |= VariableClosure
+		v.DeclLine, _ = field.entry.Val(dwarf.AttrDeclLine).(int64)
+		vars = append(vars, v)
+	}
+	return vars
+}
+
+// isCaptured returns true if entry is the DIE of one of the variables of
+// layout.
+func isCaptured(layout []closureField, entry *godwarf.Tree) bool {
+	for _, field := range layout {
+		if field.entry.Offset == entry.Offset {
+			return true
+		}
+	}
+	return false
+}
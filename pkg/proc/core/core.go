@@ -148,9 +148,10 @@ func (r *offsetReaderAt) ReadMemory(buf []byte, addr uint64) (n int, err error)
 
 // process represents a core file.
 type process struct {
-	mem     proc.MemoryReader
-	Threads map[int]*thread
-	pid     int
+	mem       proc.MemoryReader
+	memoryMap []proc.MemoryMapEntry
+	Threads   map[int]*thread
+	pid       int
 
 	entryPoint uint64
 
@@ -270,6 +271,14 @@ func (p *process) ReadMemory(data []byte, addr uint64) (n int, err error) {
 	return n, err
 }
 
+// MemoryMap returns the memory mappings saved in the core file.
+func (p *process) MemoryMap() ([]proc.MemoryMapEntry, error) {
+	if p.memoryMap == nil {
+		return nil, proc.ErrMemoryMapNotSupported
+	}
+	return p.memoryMap, nil
+}
+
 // WriteMemory will only return an error for core files, you cannot write
 // to the memory of a core process.
 func (p *process) WriteMemory(addr uint64, data []byte) (int, error) {
@@ -6,7 +6,9 @@ import (
 	"encoding/binary"
 	"fmt"
 	"io"
+	"io/ioutil"
 	"os"
+	"sort"
 	"strings"
 
 	"github.com/go-delve/delve/pkg/proc"
@@ -119,7 +121,7 @@ func readLinuxCore(corePath, exePath string) (*process, proc.Thread, error) {
 	if err != nil {
 		return nil, nil, err
 	}
-	memory := buildMemory(coreFile, exeELF, exe, notes)
+	memory, memoryMap := buildMemory(coreFile, exeELF, exe, notes)
 
 	// TODO support 386
 	var bi *proc.BinaryInfo
@@ -136,6 +138,7 @@ func readLinuxCore(corePath, exePath string) (*process, proc.Thread, error) {
 
 	p := &process{
 		mem:         memory,
+		memoryMap:   memoryMap,
 		Threads:     map[int]*thread{},
 		entryPoint:  entryPoint,
 		bi:          bi,
@@ -264,7 +267,7 @@ func readNote(r io.ReadSeeker, machineType elf.Machine) (*note, error) {
 		// No good documentation reference, but the structure is
 		// simply a header, including entry count, followed by that
 		// many entries, and then the file name of each entry,
-		// null-delimited. Not reading the names here.
+		// null-delimited.
 		data := &linuxNTFile{}
 		if err := binary.Read(descReader, binary.LittleEndian, &data.linuxNTFileHdr); err != nil {
 			return nil, fmt.Errorf("reading NT_FILE header: %v", err)
@@ -276,6 +279,8 @@ func readNote(r io.ReadSeeker, machineType elf.Machine) (*note, error) {
 			}
 			data.entries = append(data.entries, entry)
 		}
+		names, _ := ioutil.ReadAll(descReader)
+		data.names = strings.Split(string(names), "\x00")
 		note.Desc = data
 	case _NT_X86_XSTATE:
 		if machineType == _EM_X86_64 {
@@ -318,13 +323,14 @@ func skipPadding(r io.ReadSeeker, pad int64) error {
 	return nil
 }
 
-func buildMemory(core, exeELF *elf.File, exe io.ReaderAt, notes []*note) proc.MemoryReader {
+func buildMemory(core, exeELF *elf.File, exe io.ReaderAt, notes []*note) (proc.MemoryReader, []proc.MemoryMapEntry) {
 	memory := &splicedMemory{}
+	var fileNote *linuxNTFile
 
 	// For now, assume all file mappings are to the exe.
 	for _, note := range notes {
 		if note.Type == _NT_FILE {
-			fileNote := note.Desc.(*linuxNTFile)
+			fileNote = note.Desc.(*linuxNTFile)
 			for _, entry := range fileNote.entries {
 				r := &offsetReaderAt{
 					reader: exe,
@@ -352,7 +358,38 @@ func buildMemory(core, exeELF *elf.File, exe io.ReaderAt, notes []*note) proc.Me
 			}
 		}
 	}
-	return memory
+	return memory, coreMemoryMap(core, fileNote)
+}
+
+// coreMemoryMap returns the memory mappings of the process that produced
+// the core file, described by its PT_LOAD segments. The name of mapped
+// files is taken from the NT_FILE note, if there is one.
+func coreMemoryMap(core *elf.File, fileNote *linuxNTFile) []proc.MemoryMapEntry {
+	var mappings []proc.MemoryMapEntry
+	for _, prog := range core.Progs {
+		if prog.Type != elf.PT_LOAD || prog.Memsz == 0 {
+			continue
+		}
+		m := proc.MemoryMapEntry{
+			Addr:  prog.Vaddr,
+			Size:  prog.Memsz,
+			Read:  prog.Flags&elf.PF_R != 0,
+			Write: prog.Flags&elf.PF_W != 0,
+			Exec:  prog.Flags&elf.PF_X != 0,
+		}
+		if fileNote != nil {
+			for i, entry := range fileNote.entries {
+				if entry.Start <= m.Addr && m.Addr < entry.End && i < len(fileNote.names) {
+					m.Filename = fileNote.names[i]
+					m.Offset = entry.FileOfs*fileNote.PageSize + (m.Addr - entry.Start)
+					break
+				}
+			}
+		}
+		mappings = append(mappings, m)
+	}
+	sort.Slice(mappings, func(i, j int) bool { return mappings[i].Addr < mappings[j].Addr })
+	return mappings
 }
 
 func findEntryPoint(notes []*note, ptrSize int) uint64 {
@@ -419,6 +456,7 @@ type linuxSiginfo struct {
 type linuxNTFile struct {
 	linuxNTFileHdr
 	entries []*linuxNTFileEntry
+	names   []string // file name of each entry
 }
 
 // LinuxNTFileHdr is a header struct for NTFile.
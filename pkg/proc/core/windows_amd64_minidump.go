@@ -1,6 +1,8 @@
 package core
 
 import (
+	"sort"
+
 	"github.com/go-delve/delve/pkg/logflags"
 	"github.com/go-delve/delve/pkg/proc"
 	"github.com/go-delve/delve/pkg/proc/core/minidump"
@@ -22,11 +24,14 @@ func readAMD64Minidump(minidumpPath, exePath string) (*process, proc.Thread, err
 	}
 
 	memory := &splicedMemory{}
+	var memoryMap []proc.MemoryMapEntry
 
 	for i := range mdmp.MemoryRanges {
 		m := &mdmp.MemoryRanges[i]
 		memory.Add(m, m.Addr, uint64(len(m.Data)))
+		memoryMap = append(memoryMap, proc.MemoryMapEntry{Addr: m.Addr, Size: uint64(len(m.Data)), Read: true})
 	}
+	sort.Slice(memoryMap, func(i, j int) bool { return memoryMap[i].Addr < memoryMap[j].Addr })
 
 	entryPoint := uint64(0)
 	if len(mdmp.Modules) > 0 {
@@ -35,6 +40,7 @@ func readAMD64Minidump(minidumpPath, exePath string) (*process, proc.Thread, err
 
 	p := &process{
 		mem:         memory,
+		memoryMap:   memoryMap,
 		Threads:     map[int]*thread{},
 		bi:          proc.NewBinaryInfo("windows", "amd64"),
 		entryPoint:  entryPoint,
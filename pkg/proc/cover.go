@@ -0,0 +1,192 @@
+package proc
+
+import (
+	"errors"
+	"path/filepath"
+	"sort"
+)
+
+// StatementLine is a source line containing at least one statement.
+type StatementLine struct {
+	// Package is the import path of the package the line belongs to.
+	Package string
+	File    string
+	Line    int
+	// PCs are the addresses of the line marked with the is_stmt flag,
+	// including the addresses of the copies of the line inlined into other
+	// functions.
+	PCs []uint64
+}
+
+// StatementLines returns the lines of the go packages whose import path
+// is accepted by match that have at least one instruction marked with the
+// is_stmt flag, sorted by package, file and line.
+func (bi *BinaryInfo) StatementLines(match func(pkg string) bool) []StatementLine {
+	// The line table of a compile unit also contains the lines of the
+	// functions inlined into it, which belong to other packages. Files are
+	// attributed to a package using the directory of the files containing
+	// the package's functions.
+	pkgOfDir := make(map[string]string)
+	for i := range bi.Functions {
+		fn := &bi.Functions[i]
+		if fn.cu == nil || !fn.cu.isgo || fn.cu.lineInfo == nil || fn.Entry == 0 || !match(fn.cu.name) {
+			continue
+		}
+		file, _ := fn.cu.lineInfo.PCToLine(fn.Entry, fn.Entry)
+		if file == "" || file == "<autogenerated>" {
+			continue
+		}
+		pkgOfDir[filepath.Dir(file)] = fn.cu.name
+	}
+	if len(pkgOfDir) == 0 {
+		return nil
+	}
+
+	lineIdx := make(map[fileLine]int)
+	var lines []StatementLine
+	for _, image := range bi.Images {
+		for _, cu := range image.compileUnits {
+			if !cu.isgo || cu.lineInfo == nil || !cu.containsFileOf(pkgOfDir) {
+				continue
+			}
+			for _, row := range cu.lineInfo.Rows() {
+				if !row.IsStmt || row.EndSequence || row.Line <= 0 {
+					continue
+				}
+				pkg, ok := pkgOfDir[filepath.Dir(row.File)]
+				if !ok {
+					continue
+				}
+				k := fileLine{row.File, row.Line}
+				i, ok := lineIdx[k]
+				if !ok {
+					i = len(lines)
+					lineIdx[k] = i
+					lines = append(lines, StatementLine{Package: pkg, File: row.File, Line: row.Line})
+				}
+				lines[i].PCs = append(lines[i].PCs, row.Address)
+			}
+		}
+	}
+
+	sort.Slice(lines, func(i, j int) bool {
+		if lines[i].Package != lines[j].Package {
+			return lines[i].Package < lines[j].Package
+		}
+		if lines[i].File != lines[j].File {
+			return lines[i].File < lines[j].File
+		}
+		return lines[i].Line < lines[j].Line
+	})
+	return lines
+}
+
+// containsFileOf returns true if the line table of cu references a file
+// in one of the directories of pkgOfDir.
+func (cu *compileUnit) containsFileOf(pkgOfDir map[string]string) bool {
+	for _, fileEntry := range cu.lineInfo.FileNames {
+		if _, ok := pkgOfDir[filepath.Dir(fileEntry.Path)]; ok {
+			return true
+		}
+	}
+	return false
+}
+
+// coverState records which lines set with SetCoverBreakpoints have been
+// executed.
+type coverState struct {
+	lines  []StatementLine
+	hit    []bool
+	lineOf map[uint64]int
+}
+
+// SetCoverBreakpoints sets a breakpoint of CoverBreakpoint kind on every
+// address of lines. The first time one of the addresses of a line is
+// reached Continue will record that the line was executed and remove the
+// breakpoints of the line, without stopping.
+func (t *Target) SetCoverBreakpoints(lines []StatementLine) error {
+	if t.cover != nil {
+		return errors.New("coverage is already being recorded")
+	}
+	cover := &coverState{lines: lines, hit: make([]bool, len(lines)), lineOf: make(map[uint64]int)}
+	for i := range lines {
+		for _, pc := range lines[i].PCs {
+			if _, ok := cover.lineOf[pc]; ok {
+				continue
+			}
+			if _, err := t.SetBreakpoint(pc, CoverBreakpoint, nil); err != nil {
+				for pc := range cover.lineOf {
+					t.clearCoverBreakpoint(pc)
+				}
+				return err
+			}
+			cover.lineOf[pc] = i
+		}
+	}
+	t.cover = cover
+	return nil
+}
+
+// Coverage returns the lines passed to SetCoverBreakpoints and, for each
+// one, whether it was executed.
+func (t *Target) Coverage() ([]StatementLine, []bool) {
+	if t.cover == nil {
+		return nil, nil
+	}
+	return t.cover.lines, t.cover.hit
+}
+
+// clearCoverBreakpoints records the lines of the coverage breakpoints
+// threads are stopped at, removes their breakpoints and updates the
+// breakpoint state of threads. Returns true if at least one thread was
+// stopped at a coverage breakpoint.
+func (t *Target) clearCoverBreakpoints(threads []Thread) (bool, error) {
+	if t.cover == nil {
+		return false, nil
+	}
+	var covered []Thread
+	for _, th := range threads {
+		bp := th.Breakpoint().Breakpoint
+		if bp == nil || bp.Kind&CoverBreakpoint == 0 {
+			continue
+		}
+		covered = append(covered, th)
+		i, ok := t.cover.lineOf[bp.Addr]
+		if !ok || t.cover.hit[i] {
+			continue
+		}
+		t.cover.hit[i] = true
+		for _, pc := range t.cover.lines[i].PCs {
+			if err := t.clearCoverBreakpoint(pc); err != nil {
+				return true, err
+			}
+		}
+	}
+	for _, th := range covered {
+		bpstate := th.Breakpoint()
+		if bpstate.Kind == 0 {
+			bpstate.Clear()
+		} else {
+			*bpstate = bpstate.CheckCondition(th)
+		}
+	}
+	return len(covered) > 0, nil
+}
+
+// clearCoverBreakpoint removes the coverage breakpoint at addr, if any.
+func (t *Target) clearCoverBreakpoint(addr uint64) error {
+	bpmap := t.Breakpoints()
+	bp, ok := bpmap.M[addr]
+	if !ok || bp.Kind&CoverBreakpoint == 0 {
+		return nil
+	}
+	bp.Kind &^= CoverBreakpoint
+	if bp.Kind != 0 {
+		return nil
+	}
+	if err := t.proc.EraseBreakpoint(bp); err != nil {
+		return err
+	}
+	delete(bpmap.M, addr)
+	return nil
+}
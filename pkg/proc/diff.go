@@ -0,0 +1,248 @@
+package proc
+
+import (
+	"fmt"
+	"go/constant"
+	"go/token"
+	"reflect"
+	"strings"
+)
+
+// maxVariableDiffs is the maximum number of differences reported by
+// DiffVariables.
+const maxVariableDiffs = 1000
+
+// VariableDiff is a difference between two values found by DiffVariables.
+type VariableDiff struct {
+	// Path is the path of the differing values, relative to the values
+	// being compared, for example ".Field[2]", or the empty string if the
+	// values themselves differ.
+	Path string
+	// X and Y are the differing values, one of them is nil if the value
+	// only exists on one side (for example a map key missing from one of the
+	// maps).
+	X, Y *Variable
+	// Reason describes the difference when it isn't just a different value,
+	// for example "different types" or "different lengths".
+	Reason string
+}
+
+// DiffVariables compares x and y, two loaded variables, field by field and
+// returns the differences between them. Structs, arrays, slices, maps and
+// pointers are compared recursively, up to the depth they were loaded to.
+// Elements that weren't loaded are not compared.
+func DiffVariables(x, y *Variable) []VariableDiff {
+	d := &variableDiffer{}
+	d.diff("", x, y)
+	return d.diffs
+}
+
+type variableDiffer struct {
+	diffs []VariableDiff
+}
+
+func (d *variableDiffer) add(path string, x, y *Variable, reason string) {
+	if len(d.diffs) < maxVariableDiffs {
+		d.diffs = append(d.diffs, VariableDiff{Path: path, X: x, Y: y, Reason: reason})
+	}
+}
+
+func (d *variableDiffer) diff(path string, x, y *Variable) {
+	if len(d.diffs) >= maxVariableDiffs {
+		return
+	}
+	if x.Unreadable != nil || y.Unreadable != nil {
+		if x.Unreadable == nil || y.Unreadable == nil || x.Unreadable.Error() != y.Unreadable.Error() {
+			d.add(path, x, y, "unreadable")
+		}
+		return
+	}
+	if !diffCompatibleKinds(x.Kind, y.Kind) {
+		d.add(path, x, y, "different types")
+		return
+	}
+
+	switch x.Kind {
+	case reflect.Bool, reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr,
+		reflect.Float32, reflect.Float64, reflect.Complex64, reflect.Complex128, reflect.String:
+		eql, err := compareOp(token.EQL, x, y)
+		if err != nil {
+			// strings too long to be loaded, compare what was loaded
+			eql = x.Len == y.Len && x.Value != nil && y.Value != nil && constant.Compare(x.Value, token.EQL, y.Value)
+		}
+		if !eql {
+			d.add(path, x, y, "")
+		}
+
+	case reflect.Ptr:
+		if len(x.Children) == 0 || len(y.Children) == 0 {
+			return
+		}
+		xp, yp := &x.Children[0], &y.Children[0]
+		switch {
+		case xp.Addr == yp.Addr:
+			// same object
+		case xp.Addr == 0 || yp.Addr == 0 || xp.OnlyAddr || yp.OnlyAddr || xp.Kind == reflect.Invalid || yp.Kind == reflect.Invalid:
+			d.add(path, x, y, "")
+		default:
+			d.diff(path, xp, yp)
+		}
+
+	case reflect.UnsafePointer:
+		if len(x.Children) > 0 && len(y.Children) > 0 && x.Children[0].Addr != y.Children[0].Addr {
+			d.add(path, x, y, "")
+		}
+
+	case reflect.Chan, reflect.Func:
+		if x.Base != y.Base {
+			d.add(path, x, y, "")
+		}
+
+	case reflect.Interface:
+		if len(x.Children) == 0 || len(y.Children) == 0 {
+			return
+		}
+		xd, yd := &x.Children[0], &y.Children[0]
+		if x.isNil() || y.isNil() {
+			if x.isNil() != y.isNil() {
+				d.add(path, x, y, "")
+			}
+			return
+		}
+		if xd.TypeString() != yd.TypeString() {
+			d.add(path, x, y, "different dynamic types")
+			return
+		}
+		d.diff(path, xd, yd)
+
+	case reflect.Struct:
+		ychildren := make(map[string]*Variable, len(y.Children))
+		for i := range y.Children {
+			ychildren[y.Children[i].Name] = &y.Children[i]
+		}
+		for i := range x.Children {
+			xf := &x.Children[i]
+			yf := ychildren[xf.Name]
+			if yf == nil {
+				d.add(path+"."+xf.Name, xf, nil, "missing field")
+				continue
+			}
+			delete(ychildren, xf.Name)
+			d.diff(path+"."+xf.Name, xf, yf)
+		}
+		for i := range y.Children {
+			if yf := &y.Children[i]; ychildren[yf.Name] != nil {
+				d.add(path+"."+yf.Name, nil, yf, "missing field")
+			}
+		}
+
+	case reflect.Array, reflect.Slice:
+		if x.Kind == reflect.Slice && (x.isNil() || y.isNil()) {
+			if x.isNil() != y.isNil() {
+				d.add(path, x, y, "")
+			}
+			return
+		}
+		if x.Len != y.Len {
+			d.add(path, x, y, fmt.Sprintf("different lengths %d and %d", x.Len, y.Len))
+		}
+		n := len(x.Children)
+		if len(y.Children) < n {
+			n = len(y.Children)
+		}
+		for i := 0; i < n; i++ {
+			d.diff(fmt.Sprintf("%s[%d]", path, i), &x.Children[i], &y.Children[i])
+		}
+
+	case reflect.Map:
+		if x.isNil() || y.isNil() {
+			if x.isNil() != y.isNil() {
+				d.add(path, x, y, "")
+			}
+			return
+		}
+		if x.Len != y.Len {
+			d.add(path, x, y, fmt.Sprintf("different lengths %d and %d", x.Len, y.Len))
+		}
+		yvals := make(map[string]*Variable, len(y.Children)/2)
+		for i := 0; i+1 < len(y.Children); i += 2 {
+			yvals[diffMapKey(&y.Children[i])] = &y.Children[i+1]
+		}
+		for i := 0; i+1 < len(x.Children); i += 2 {
+			key := diffMapKey(&x.Children[i])
+			kpath := path + "[" + key + "]"
+			yv := yvals[key]
+			if yv == nil {
+				if int64(len(y.Children)/2) == y.Len {
+					d.add(kpath, &x.Children[i+1], nil, "missing key")
+				}
+				continue
+			}
+			delete(yvals, key)
+			d.diff(kpath, &x.Children[i+1], yv)
+		}
+		if int64(len(x.Children)/2) == x.Len {
+			for i := 0; i+1 < len(y.Children); i += 2 {
+				key := diffMapKey(&y.Children[i])
+				if yvals[key] != nil {
+					d.add(path+"["+key+"]", nil, &y.Children[i+1], "missing key")
+				}
+			}
+		}
+	}
+}
+
+// diffCompatibleKinds returns true if values of kind xk and yk can be
+// compared by DiffVariables.
+func diffCompatibleKinds(xk, yk reflect.Kind) bool {
+	if xk == yk {
+		return true
+	}
+	class := func(k reflect.Kind) int {
+		switch k {
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+			reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+			return 1
+		case reflect.Float32, reflect.Float64:
+			return 2
+		case reflect.Complex64, reflect.Complex128:
+			return 3
+		case reflect.Array, reflect.Slice:
+			return 4
+		}
+		return -int(k) - 1
+	}
+	return class(xk) == class(yk)
+}
+
+// diffMapKey returns a string representation of a map key, used to match
+// the keys of the two maps compared by DiffVariables.
+func diffMapKey(v *Variable) string {
+	switch {
+	case v.Unreadable != nil:
+		return fmt.Sprintf("(unreadable %v)", v.Unreadable)
+	case v.Value != nil:
+		return v.Value.ExactString()
+	case v.Kind == reflect.Ptr || v.Kind == reflect.UnsafePointer || v.Kind == reflect.Chan:
+		if len(v.Children) > 0 {
+			return fmt.Sprintf("(%s)(%#x)", v.TypeString(), v.Children[0].Addr)
+		}
+		return fmt.Sprintf("(%s)(%#x)", v.TypeString(), v.Base)
+	}
+	var buf strings.Builder
+	buf.WriteString(v.TypeString())
+	buf.WriteString("{")
+	for i := range v.Children {
+		if i > 0 {
+			buf.WriteString(", ")
+		}
+		if v.Kind == reflect.Struct {
+			buf.WriteString(v.Children[i].Name)
+			buf.WriteString(": ")
+		}
+		buf.WriteString(diffMapKey(&v.Children[i]))
+	}
+	buf.WriteString("}")
+	return buf.String()
+}
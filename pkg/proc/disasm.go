@@ -23,6 +23,7 @@ const (
 	CallInstruction
 	RetInstruction
 	JmpInstruction
+	CondJmpInstruction
 	HardBreakInstruction
 )
 
@@ -41,6 +42,11 @@ func (instr *AsmInstruction) IsJmp() bool {
 	return instr.Kind == JmpInstruction
 }
 
+// IsCondJmp is true if instr is a conditional jump instruction.
+func (instr *AsmInstruction) IsCondJmp() bool {
+	return instr.Kind == CondJmpInstruction
+}
+
 // IsHardBreak is true if instr is a hardcoded breakpoint instruction.
 func (instr *AsmInstruction) IsHardBreak() bool {
 	return instr.Kind == HardBreakInstruction
@@ -1,6 +1,20 @@
 package proc
 
+import "github.com/go-delve/delve/pkg/dwarf/op"
+
 // PackageVars returns bi.packageVars (for tests)
 func (bi *BinaryInfo) PackageVars() []packageVar {
 	return bi.packageVars
 }
+
+// NewStackframe returns a stack frame stopped at loc, with registers regs,
+// the frames of a stacktrace must be linked with LinkCallerFrames (for
+// tests)
+func NewStackframe(loc Location, regs op.DwarfRegisters) Stackframe {
+	return Stackframe{Current: loc, Call: loc, Regs: regs, lastpc: loc.PC}
+}
+
+// LinkCallerFrames links each frame of frames to its caller (for tests)
+func LinkCallerFrames(frames []Stackframe) {
+	linkCallerFrames(frames)
+}
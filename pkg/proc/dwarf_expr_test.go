@@ -353,3 +353,90 @@ func TestNestedCompileUnts(t *testing.T) {
 		t.Errorf("expected 2 variables, got %d", n)
 	}
 }
+
+func TestDwarfExprEntryValue(t *testing.T) {
+	// Tests that parameters described by DW_OP_entry_value are read from the
+	// call site parameters of the caller frame, that implicit pointers are
+	// resolved and that optimized out variables report where they are
+	// available.
+	const (
+		dwarfTagGNUCallSite          = dwarf.Tag(0x4109)
+		dwarfTagGNUCallSiteParameter = dwarf.Tag(0x410a)
+		dwarfAttrGNUCallSiteValue    = dwarf.Attr(0x2111)
+	)
+
+	dwb := dwarfbuilder.New()
+	dwb.Attr(dwarf.AttrLowpc, dwarfbuilder.Address(0x40100))
+	dwb.Attr(dwarf.AttrHighpc, dwarfbuilder.Address(0x40300))
+
+	intoff := dwb.AddBaseType("int", dwarfbuilder.DW_ATE_signed, 8)
+	intptroff := dwb.AddPointerType("*int", intoff)
+
+	dwb.AddSubprogram("main.f", 0x40100, 0x40200)
+	dwb.TagOpen(dwarf.TagFormalParameter, "x")
+	dwb.Attr(dwarf.AttrType, intoff)
+	dwb.Attr(dwarf.AttrLocation, []dwarfbuilder.LocEntry{
+		{Lowpc: 0x40100, Highpc: 0x40110, Loc: dwarfbuilder.LocationBlock(op.DW_OP_reg5)},
+		{Lowpc: 0x40110, Highpc: 0x40200, Loc: dwarfbuilder.LocationBlock(op.DW_OP_GNU_entry_value, uint(1), op.DW_OP_reg5, op.DW_OP_stack_value)},
+	})
+	dwb.TagClose()
+	dwb.TagOpen(dwarf.TagFormalParameter, "y")
+	dwb.Attr(dwarf.AttrType, intoff)
+	dwb.Attr(dwarf.AttrLocation, []dwarfbuilder.LocEntry{
+		{Lowpc: 0x40100, Highpc: 0x40110, Loc: dwarfbuilder.LocationBlock(op.DW_OP_reg4)},
+		{Lowpc: 0x40110, Highpc: 0x40120, Loc: dwarfbuilder.LocationBlock(op.DW_OP_reg0)},
+		{Lowpc: 0x40180, Highpc: 0x40200, Loc: dwarfbuilder.LocationBlock(op.DW_OP_reg1)},
+	})
+	dwb.TagClose()
+	woff := dwb.AddVariable("w", intoff, dwarfbuilder.LocationBlock(op.DW_OP_call_frame_cfa))
+	var implicitPtr bytes.Buffer
+	implicitPtr.WriteByte(byte(op.DW_OP_GNU_implicit_pointer))
+	binary.Write(&implicitPtr, binary.LittleEndian, uint32(woff))
+	implicitPtr.WriteByte(0)
+	dwb.AddVariable("p", intptroff, implicitPtr.Bytes())
+	dwb.TagClose()
+
+	dwb.AddSubprogram("main.main", 0x40200, 0x40300)
+	dwb.TagOpen(dwarfTagGNUCallSite, "")
+	dwb.Attr(dwarf.AttrLowpc, dwarfbuilder.Address(0x40250))
+	dwb.TagOpen(dwarfTagGNUCallSiteParameter, "")
+	dwb.Attr(dwarf.AttrLocation, dwarfbuilder.LocationBlock(op.DW_OP_reg5))
+	dwb.Attr(dwarfAttrGNUCallSiteValue, dwarfbuilder.LocationBlock(op.DW_OP_breg3, int(2)))
+	dwb.TagClose()
+	dwb.TagClose()
+	dwb.TagClose()
+
+	bi, _ := fakeBinaryInfo(t, dwb)
+
+	const w = 0x1234
+	mem := newFakeMemory(fakeCFA(), uint64(w))
+
+	calleeRegs := linutil.AMD64Registers{Regs: &linutil.AMD64PtraceRegs{Rip: 0x40150, Rax: 1, Rdx: 2}}
+	callerRegs := linutil.AMD64Registers{Regs: &linutil.AMD64PtraceRegs{Rip: 0x40250, Rbx: 40}}
+	frames := []proc.Stackframe{
+		proc.NewStackframe(proc.Location{PC: 0x40150, Fn: bi.LookupFunc["main.f"]}, dwarfRegisters(bi, &calleeRegs)),
+		proc.NewStackframe(proc.Location{PC: 0x40250, Fn: bi.LookupFunc["main.main"]}, dwarfRegisters(bi, &callerRegs)),
+	}
+	proc.LinkCallerFrames(frames)
+	scope := proc.FrameToScope(bi, mem, nil, frames...)
+
+	uintExprCheck(t, scope, "x", 42)
+	uintExprCheck(t, scope, "*p", w)
+
+	y, err := scope.EvalExpression("y", normalLoadConfig)
+	assertNoError(err, t, "EvalExpression(y)")
+	if _, isOptimizedOut := y.Unreadable.(*proc.OptimizedOutError); !isOptimizedOut {
+		t.Fatalf("expected y to be optimized out, got %v", y.Unreadable)
+	}
+	if msg := y.Unreadable.Error(); msg != "optimized out, available at [0x40100, 0x40120), [0x40180, 0x40200)" {
+		t.Errorf("wrong error for y: %q", msg)
+	}
+
+	// without the caller frame the entry value of x isn't available
+	scope = proc.FrameToScope(bi, mem, nil, proc.NewStackframe(frames[0].Current, frames[0].Regs))
+	x, err := scope.EvalExpression("x", normalLoadConfig)
+	assertNoError(err, t, "EvalExpression(x)")
+	if msg := fmt.Sprint(x.Unreadable); msg != "optimized out, available at [0x40100, 0x40110)" {
+		t.Errorf("wrong error for x: %q", msg)
+	}
+}
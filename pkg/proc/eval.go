@@ -25,6 +25,8 @@ import (
 
 var errOperationOnSpecialFloat = errors.New("operations on non-finite floats not implemented")
 
+var errKeyNotFound = errors.New("key not found")
+
 // EvalScope is the scope for variable evaluation. Contains the thread,
 // current location (PC), and canonical frame address.
 type EvalScope struct {
@@ -126,6 +128,7 @@ func FrameToScope(bi *BinaryInfo, thread MemoryReadWriter, g *G, frames ...Stack
 
 	s := &EvalScope{Location: frames[0].Call, Regs: frames[0].Regs, Mem: thread, g: g, BinInfo: bi, frameOffset: frames[0].FrameOffset()}
 	s.PC = frames[0].lastpc
+	frames[0].setEntryValueFuncs(bi, &s.Regs)
 	return s
 }
 
@@ -218,15 +221,31 @@ func (scope *EvalScope) Locals() ([]*Variable, error) {
 		variablesFlags |= reader.VariablesTrustDeclLine
 	}
 
+	// Variables captured by a closure are read from the closure context when
+	// it is available, otherwise the copies described by their location
+	// expressions are used.
+	layout := closureLayout(scope.BinInfo, scope.image(), dwarfTree)
+	closureVars := closureVariables(scope.BinInfo, scope.Mem, layout, scope.closureContext(dwarfTree, layout))
+
 	varEntries := reader.Variables(dwarfTree, scope.PC, scope.Line, variablesFlags)
 	vars := make([]*Variable, 0, len(varEntries))
 	depths := make([]int, 0, len(varEntries))
 	for _, entry := range varEntries {
+		if name, _ := entry.Val(dwarf.AttrName).(string); name == closurePtrName {
+			continue
+		}
+		captured := isCaptured(layout, entry.Tree)
+		if captured && closureVars != nil {
+			continue
+		}
 		val, err := extractVarInfoFromEntry(scope.BinInfo, scope.image(), scope.Regs, scope.Mem, entry.Tree)
 		if err != nil {
 			// skip variables that we can't parse yet
 			continue
 		}
+		if captured {
+			val.Flags |= VariableClosure
+		}
 		if trustArgOrder && ((val.Unreadable != nil && val.Addr == 0) || val.Flags&VariableFakeAddress != 0) && entry.Tag == dwarf.TagFormalParameter {
 			addr := afterLastArgAddr(vars)
 			if addr == 0 {
@@ -252,23 +271,28 @@ func (scope *EvalScope) Locals() ([]*Variable, error) {
 	}
 
 	if len(vars) <= 0 {
-		return vars, nil
+		return closureVars, nil
 	}
 
 	sort.Stable(&variablesByDepthAndDeclLine{vars, depths})
 
+	// Captured variables are declared in an enclosing function and can be
+	// shadowed by any local variable.
+	vars = append(closureVars, vars...)
+
 	lvn := map[string]*Variable{} // lvn[n] is the last variable we saw named n
 
 	for i, v := range vars {
 		if name := v.Name; len(name) > 1 && name[0] == '&' {
 			locationExpr := v.LocationExpr
 			declLine := v.DeclLine
+			closure := v.Flags & VariableClosure
 			v = v.maybeDereference()
 			if v.Addr == 0 && v.Unreadable == nil {
 				v.Unreadable = fmt.Errorf("no address for escaped variable")
 			}
 			v.Name = name[1:]
-			v.Flags |= VariableEscaped
+			v.Flags |= closure | VariableEscaped
 			// See https://github.com/go-delve/delve/issues/2049 for details
 			if locationExpr != nil {
 				locationExpr.isEscaped = true
@@ -286,6 +310,50 @@ func (scope *EvalScope) Locals() ([]*Variable, error) {
 	return vars, nil
 }
 
+// closurePtrName is the name of the variable used by the compiler to
+// save the closure context register (Go 1.23 and later).
+const closurePtrName = ".closureptr"
+
+// closureContext returns the address of the funcval object of the closure
+// executing in scope, or 0 if it can not be determined.
+// The address is read from the variable where the compiler saves the
+// closure context register or, on function entry, from the register
+// itself.
+func (scope *EvalScope) closureContext(dwarfTree *godwarf.Tree, layout []closureField) uint64 {
+	if len(layout) == 0 {
+		return 0
+	}
+	var addr uint64
+	for _, entry := range dwarfTree.Children {
+		if name, _ := entry.Val(dwarf.AttrName).(string); name != closurePtrName {
+			continue
+		}
+		v, err := extractVarInfoFromEntry(scope.BinInfo, scope.image(), scope.Regs, scope.Mem, entry)
+		if err != nil {
+			break
+		}
+		v.loadValue(loadSingleValue)
+		if v.Unreadable == nil && v.Value != nil {
+			addr, _ = constant.Uint64Val(v.Value)
+		}
+		break
+	}
+	if addr == 0 && scope.PC == scope.Fn.Entry {
+		if reg := scope.Regs.Reg(scope.BinInfo.Arch.contextRegNum); reg != nil {
+			addr = reg.Uint64Val
+		}
+	}
+	if addr == 0 {
+		return 0
+	}
+	// check that addr points to a funcval object for the current function.
+	pc, err := readUintRaw(scope.Mem, addr, int64(scope.BinInfo.Arch.PtrSize()))
+	if err != nil || scope.BinInfo.PCToFunc(pc) != scope.Fn {
+		return 0
+	}
+	return addr
+}
+
 func afterLastArgAddr(vars []*Variable) uint64 {
 	for i := len(vars) - 1; i >= 0; i-- {
 		v := vars[i]
@@ -374,19 +442,325 @@ func (scope *EvalScope) setValue(dstv, srcv *Variable, srcExpr string) error {
 	return fmt.Errorf("can not set variables of type %s (not implemented)", dstv.Kind.String())
 }
 
+// isCompositeLit returns true if t is a composite literal or the address
+// of a composite literal.
+func isCompositeLit(t ast.Expr) bool {
+	if unary, isunary := t.(*ast.UnaryExpr); isunary && unary.Op == token.AND {
+		t = unary.X
+	}
+	_, iscomplit := t.(*ast.CompositeLit)
+	return iscomplit
+}
+
+// setCompositeElem writes the value of expression t, which can be a
+// composite literal, to dstv.
+// If check is set nothing is written and no memory is allocated, the
+// literal is only checked for errors and errFuncCallNotAllowedAlloc is
+// returned if writing it would need to allocate memory and function calls
+// are not allowed.
+func (scope *EvalScope) setCompositeElem(dstv *Variable, t ast.Expr, check bool) error {
+	if unary, isunary := t.(*ast.UnaryExpr); isunary && unary.Op == token.AND {
+		if lit, iscomplit := unary.X.(*ast.CompositeLit); iscomplit {
+			return scope.setCompositeLitPtr(dstv, lit, check)
+		}
+	}
+	lit, iscomplit := t.(*ast.CompositeLit)
+	if !iscomplit {
+		srcv, err := scope.evalAST(t)
+		if err != nil {
+			return err
+		}
+		if check {
+			return scope.checkValue(dstv, srcv, exprToString(t))
+		}
+		if srcv.Kind == reflect.String {
+			// allocate the contents of the string before checking the write
+			// barrier, the target doesn't run between the check and the write
+			if err := allocString(scope, srcv); err != nil {
+				return err
+			}
+		}
+		if typeHasPointers(dstv.RealType) {
+			if err := checkWriteBarrier(scope); err != nil {
+				return err
+			}
+		}
+		return scope.setValue(dstv, srcv, exprToString(t))
+	}
+	if _, isptr := dstv.RealType.(*godwarf.PtrType); isptr && lit.Type == nil {
+		// elided &T in a composite literal of type []*T
+		return scope.setCompositeLitPtr(dstv, lit, check)
+	}
+	return scope.setCompositeLit(dstv, lit, check)
+}
+
+// setCompositeLitPtr allocates a new object in the target process, writes
+// its address to dstv and the value of lit to it.
+// The address is written first so that the object is reachable by the
+// garbage collector while memory for its contents is allocated.
+func (scope *EvalScope) setCompositeLitPtr(dstv *Variable, lit *ast.CompositeLit, check bool) error {
+	ptrtyp, isptr := dstv.RealType.(*godwarf.PtrType)
+	if !isptr {
+		return fmt.Errorf("can not assign pointer to composite literal to variable of type %s", dstv.TypeString())
+	}
+	if check {
+		if scope.callCtx == nil {
+			return errFuncCallNotAllowedAlloc
+		}
+		return scope.setCompositeLit(newVariable("", 0, ptrtyp.Type, scope.BinInfo, scope.Mem), lit, check)
+	}
+	addr, err := allocMemory(scope, ptrtyp.Type.Size(), ptrtyp.Type, true)
+	if err != nil {
+		return err
+	}
+	if err := checkWriteBarrier(scope); err != nil {
+		return err
+	}
+	if err := dstv.writeUint(addr, ptrtyp.ByteSize); err != nil {
+		return err
+	}
+	return scope.setCompositeLit(newVariable("", addr, ptrtyp.Type, scope.BinInfo, scope.Mem), lit, check)
+}
+
+// setCompositeLit writes the value of the composite literal lit to dstv.
+// Struct and array literals are written in place, the backing array of
+// slice literals is allocated on the heap of the target process and
+// written to dstv before its elements, like in setCompositeLitPtr.
+func (scope *EvalScope) setCompositeLit(dstv *Variable, lit *ast.CompositeLit, check bool) error {
+	if lit.Type != nil {
+		typ, err := scope.BinInfo.findTypeExpr(lit.Type)
+		if err != nil {
+			return err
+		}
+		if !sameType(typ, dstv.RealType) {
+			return fmt.Errorf("can not use %s literal as value of type %s", exprToString(lit.Type), dstv.TypeString())
+		}
+	}
+
+	switch typ := dstv.RealType.(type) {
+	case *godwarf.StructType:
+		if !check {
+			if err := scope.writeZeroLit(dstv); err != nil {
+				return err
+			}
+		}
+		for i, elt := range lit.Elts {
+			var field *godwarf.StructField
+			if kv, iskv := elt.(*ast.KeyValueExpr); iskv {
+				name, isident := kv.Key.(*ast.Ident)
+				if !isident {
+					return fmt.Errorf("invalid field name %s in struct literal", exprToString(kv.Key))
+				}
+				for _, f := range typ.Field {
+					if f.Name == name.Name {
+						field = f
+						break
+					}
+				}
+				if field == nil {
+					return fmt.Errorf("unknown field %s in struct literal of type %s", name.Name, dstv.TypeString())
+				}
+				elt = kv.Value
+			} else {
+				if i >= len(typ.Field) {
+					return fmt.Errorf("too many values in struct literal of type %s", dstv.TypeString())
+				}
+				field = typ.Field[i]
+			}
+			fv := newVariable(field.Name, dstv.Addr+uint64(field.ByteOffset), field.Type, scope.BinInfo, dstv.mem)
+			if err := scope.setCompositeElem(fv, elt, check); err != nil {
+				return err
+			}
+		}
+		return nil
+
+	case *godwarf.ArrayType:
+		if !check {
+			if err := scope.writeZeroLit(dstv); err != nil {
+				return err
+			}
+		}
+		_, err := scope.setCompositeLitElems(dstv.Addr, typ.Type, typ.Count, lit, check)
+		return err
+
+	case *godwarf.SliceType:
+		n, err := scope.setCompositeLitElems(0, typ.ElemType, -1, lit, check)
+		if err != nil {
+			return err
+		}
+		if check {
+			if n > 0 {
+				if scope.callCtx == nil {
+					return errFuncCallNotAllowedAlloc
+				}
+				_, err = scope.setCompositeLitElems(0, typ.ElemType, n, lit, check)
+			}
+			return err
+		}
+		if n > 0 {
+			base, err := allocMemory(scope, n*typ.ElemType.Size(), typ.ElemType, true)
+			if err != nil {
+				return err
+			}
+			if err := checkWriteBarrier(scope); err != nil {
+				return err
+			}
+			if err := dstv.writeSlice(n, n, base); err != nil {
+				return err
+			}
+			_, err = scope.setCompositeLitElems(base, typ.ElemType, n, lit, check)
+			return err
+		}
+		var base uint64
+		if zerobase, err := scope.findGlobal("runtime", "zerobase"); err == nil {
+			// empty slice literals are not nil
+			base = zerobase.Addr
+		}
+		return dstv.writeSlice(0, 0, base)
+
+	default:
+		return fmt.Errorf("can not assign composite literal to variable of type %s", dstv.TypeString())
+	}
+}
+
+// setCompositeLitElems writes the elements of the array or slice literal
+// lit to the array of elemType starting at base, of length n, and returns
+// the length of lit.
+// If n is negative the elements are not written or checked.
+func (scope *EvalScope) setCompositeLitElems(base uint64, elemType godwarf.Type, n int64, lit *ast.CompositeLit, check bool) (int64, error) {
+	var idx, length int64
+	for _, elt := range lit.Elts {
+		if kv, iskv := elt.(*ast.KeyValueExpr); iskv {
+			idxv, err := scope.evalAST(kv.Key)
+			if err != nil {
+				return 0, err
+			}
+			idx, err = idxv.asInt()
+			if err != nil {
+				return 0, err
+			}
+			elt = kv.Value
+		}
+		if idx < 0 || (n >= 0 && idx >= n) {
+			return 0, fmt.Errorf("index %d out of bounds in composite literal", idx)
+		}
+		if n >= 0 {
+			elemv := newVariable("", base+uint64(idx*elemType.Size()), elemType, scope.BinInfo, scope.Mem)
+			if err := scope.setCompositeElem(elemv, elt, check); err != nil {
+				return 0, err
+			}
+		}
+		idx++
+		if idx > length {
+			length = idx
+		}
+	}
+	return length, nil
+}
+
+// writeZeroLit zeroes dstv before a struct or array literal is written to
+// it.
+func (scope *EvalScope) writeZeroLit(dstv *Variable) error {
+	if typeHasPointers(dstv.RealType) {
+		if err := checkWriteBarrier(scope); err != nil {
+			return err
+		}
+	}
+	return dstv.writeZero()
+}
+
+// checkValue returns the errors that setValue would return when writing
+// srcv to dstv, without writing anything.
+func (scope *EvalScope) checkValue(dstv, srcv *Variable, srcExpr string) error {
+	srcv.loadValue(loadSingleValue)
+	typerr := srcv.isType(dstv.RealType, dstv.Kind)
+	if _, isTypeConvErr := typerr.(*typeConvErr); isTypeConvErr {
+		return nil
+	}
+	if typerr != nil {
+		return typerr
+	}
+	if srcv.Unreadable != nil {
+		return fmt.Errorf("Expression \"%s\" is unreadable: %v", srcExpr, srcv.Unreadable)
+	}
+	if srcv.Kind == reflect.String && srcv.Base == 0 && srcv.Len > 0 && scope.callCtx == nil {
+		return errFuncCallNotAllowedStrAlloc
+	}
+	return nil
+}
+
+// evalMapAssign creates a new element in a map for the index expression
+// node and returns it.
+// Value is the expression that will be assigned to the new element, it is
+// checked before the element is created so that a failed assignment doesn't
+// leave a zero element in the map. If value isn't a composite literal its
+// result is also returned.
+func (scope *EvalScope) evalMapAssign(node *ast.IndexExpr, value ast.Expr) (*Variable, *Variable, error) {
+	xev, err := scope.evalAST(node.X)
+	if err != nil {
+		return nil, nil, err
+	}
+	xev = xev.maybeDereference()
+	if xev.Kind != reflect.Map {
+		return nil, nil, errKeyNotFound
+	}
+	idxev, err := scope.evalAST(node.Index)
+	if err != nil {
+		return nil, nil, err
+	}
+	idxev.loadValue(loadFullValue)
+	if idxev.Unreadable != nil {
+		return nil, nil, idxev.Unreadable
+	}
+	idxev.Name = exprToString(node.Index)
+
+	mt, ok := xev.RealType.(*godwarf.MapType)
+	if !ok {
+		return nil, nil, fmt.Errorf("can not assign to map element of %s", xev.TypeString())
+	}
+	elemv := newVariable("", 0, mt.ElemType, scope.BinInfo, scope.Mem)
+	var yv *Variable
+	if isCompositeLit(value) {
+		err = scope.setCompositeElem(elemv, value, true)
+	} else {
+		yv, err = scope.evalAST(value)
+		if err == nil {
+			err = scope.checkValue(elemv, yv, exprToString(value))
+		}
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+
+	elemv, err = mapAssign(scope, xev, idxev)
+	return elemv, yv, err
+}
+
 // EvalVariable returns the value of the given expression (backwards compatibility).
 func (scope *EvalScope) EvalVariable(name string, cfg LoadConfig) (*Variable, error) {
 	return scope.EvalExpression(name, cfg)
 }
 
-// SetVariable sets the value of the named variable
+// SetVariable sets the value of the named variable.
+// If value is a composite literal it is written directly into the
+// variable, assigning to a map element that doesn't exist creates it.
 func (scope *EvalScope) SetVariable(name, value string) error {
 	t, err := parser.ParseExpr(name)
 	if err != nil {
 		return err
 	}
 
+	vt, err := parser.ParseExpr(value)
+	if err != nil {
+		return err
+	}
+
+	var yv *Variable
 	xv, err := scope.evalAST(t)
+	if idx, isindex := t.(*ast.IndexExpr); isindex && err == errKeyNotFound {
+		// assignment to a new map element
+		xv, yv, err = scope.evalMapAssign(idx, vt)
+	}
 	if err != nil {
 		return err
 	}
@@ -399,14 +773,37 @@ func (scope *EvalScope) SetVariable(name, value string) error {
 		return fmt.Errorf("Expression \"%s\" is unreadable: %v", name, xv.Unreadable)
 	}
 
-	t, err = parser.ParseExpr(value)
-	if err != nil {
-		return err
+	if isCompositeLit(vt) {
+		// check the literal before writing anything, so that the caller can
+		// retry with function calls if memory needs to be allocated and
+		// writing it can only fail because of the target
+		if err := scope.setCompositeElem(xv, vt, true); err != nil {
+			return err
+		}
+		var orig []byte
+		if !typeHasPointers(xv.RealType) {
+			orig = make([]byte, xv.RealType.Size())
+			if _, err := scope.Mem.ReadMemory(orig, xv.Addr); err != nil {
+				return err
+			}
+		}
+		if err := scope.setCompositeElem(xv, vt, false); err != nil {
+			// restore the previous value. Values containing pointers can
+			// not be restored, the objects they pointed to are no longer
+			// referenced and could have been freed by the garbage collector.
+			if orig != nil {
+				scope.Mem.WriteMemory(xv.Addr, orig)
+			}
+			return err
+		}
+		return nil
 	}
 
-	yv, err := scope.evalAST(t)
-	if err != nil {
-		return err
+	if yv == nil {
+		yv, err = scope.evalAST(vt)
+		if err != nil {
+			return err
+		}
 	}
 
 	return scope.setValue(xv, yv, value)
@@ -1917,7 +2314,7 @@ func (v *Variable) mapAccess(idx *Variable) (*Variable, error) {
 		return nil, v.Unreadable
 	}
 	// go would return zero for the map value type here, we do not have the ability to create zeroes
-	return nil, fmt.Errorf("key not found")
+	return nil, errKeyNotFound
 }
 
 func (v *Variable) reslice(low int64, high int64) (*Variable, error) {
@@ -1966,6 +2363,9 @@ func (v *Variable) reslice(low int64, high int64) (*Variable, error) {
 // findMethod finds method mname in the type of variable v
 func (v *Variable) findMethod(mname string) (*Variable, error) {
 	if _, isiface := v.RealType.(*godwarf.InterfaceType); isiface {
+		if r, err := v.findMethodInItab(mname); err == nil && r != nil {
+			return r, nil
+		}
 		v.loadInterface(0, false, loadFullValue)
 		if v.Unreadable != nil {
 			return nil, v.Unreadable
@@ -2017,6 +2417,117 @@ func (v *Variable) findMethod(mname string) (*Variable, error) {
 	return v.tryFindMethodInEmbeddedFields(mname)
 }
 
+// findMethodInItab returns the method mname of the dynamic type of the
+// non-empty interface v, resolved through the function pointers stored in
+// the itab of v.
+// Returns nil if v is an empty interface or its itab doesn't have a method
+// named mname.
+func (v *Variable) findMethodInItab(mname string) (*Variable, error) {
+	ityp := resolveTypedef(&v.RealType.(*godwarf.InterfaceType).TypedefType).(*godwarf.StructType)
+	var tab *Variable
+	for _, f := range ityp.Field {
+		if f.Name == "tab" {
+			tab, _ = v.toField(f)
+		}
+	}
+	if tab == nil {
+		// empty interface
+		return nil, nil
+	}
+	tab = tab.maybeDereference()
+	if tab.Unreadable != nil {
+		return nil, tab.Unreadable
+	}
+	if tab.Addr == 0 {
+		return nil, nil
+	}
+
+	inter, err := tab.structMember("inter")
+	if err != nil {
+		return nil, err
+	}
+	inter = inter.maybeDereference()
+	methods, err := inter.structMember(interfacetypeFieldMhdr)
+	if err != nil {
+		return nil, err
+	}
+	methods.loadArrayValues(0, LoadConfig{false, 1, 0, 4096, -1, 0})
+	if methods.Unreadable != nil {
+		return nil, methods.Unreadable
+	}
+
+	mds, err := loadModuleData(v.bi, v.mem)
+	if err != nil {
+		return nil, err
+	}
+
+	// The methods of an interface are sorted by name, the function pointer
+	// of the i-th method is stored in the i-th element of the fun array of
+	// the itab.
+	idx := -1
+	for i := range methods.Children {
+		namev := methods.Children[i].fieldVariable(imethodFieldName)
+		if namev == nil || namev.Value == nil {
+			return nil, errors.New("could not read interface method name")
+		}
+		nameoff, _ := constant.Int64Val(namev.Value)
+		name, _, _, err := resolveNameOff(v.bi, mds, inter.Addr, uint64(nameoff), v.mem)
+		if err != nil {
+			return nil, err
+		}
+		if name == mname {
+			idx = i
+			break
+		}
+	}
+	if idx < 0 {
+		return nil, nil
+	}
+
+	fun, err := tab.structMember("fun")
+	if err != nil {
+		return nil, err
+	}
+	ptrSize := int64(v.bi.Arch.PtrSize())
+	pc, err := readUintRaw(v.mem, fun.Addr+uint64(int64(idx)*ptrSize), ptrSize)
+	if err != nil {
+		return nil, err
+	}
+	fn := v.bi.PCToFunc(pc)
+	if fn == nil {
+		return nil, fmt.Errorf("could not find function for method %s at %#x", mname, pc)
+	}
+
+	v.loadInterface(0, false, loadFullValue)
+	if v.Unreadable != nil {
+		return nil, v.Unreadable
+	}
+	recv := &v.Children[0]
+
+	// The itab always refers to methods that take the data word of the
+	// interface as receiver, for value receivers stored indirectly this is
+	// an autogenerated pointer receiver wrapper, which doesn't have debug
+	// symbols for its arguments, call the wrapped method directly instead.
+	if file, _, _ := v.bi.PCToLine(fn.Entry); file == "<autogenerated>" {
+		wrapped := v.bi.LookupFunc[strings.Replace(strings.Replace(fn.Name, ".(*", ".", 1), ").", ".", 1)]
+		if wrapped == nil || recv.Kind == reflect.Ptr {
+			// promoted method, let findMethod search the embedded fields
+			return nil, nil
+		}
+		fn = wrapped
+	}
+
+	r, err := functionToVariable(fn, v.bi, v.mem)
+	if err != nil {
+		return nil, err
+	}
+	if recv.Kind != reflect.Ptr && strings.Contains(fn.Name, ".(*") {
+		recv = recv.pointerToVariable()
+	}
+	r.Children = append(r.Children, *recv)
+	return r, nil
+}
+
 func (v *Variable) tryFindMethodInEmbeddedFields(mname string) (*Variable, error) {
 	structVar := v.maybeDereference()
 	structVar.Name = v.Name
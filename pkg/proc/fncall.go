@@ -60,8 +60,18 @@ var (
 	errNotAGoFunction             = errors.New("not a Go function")
 	errFuncCallNotAllowed         = errors.New("function calls not allowed without using 'call'")
 	errFuncCallNotAllowedStrAlloc = errors.New("literal string can not be allocated because function calls are not allowed without using 'call'")
+	errFuncCallNotAllowedAlloc    = errors.New("memory can not be allocated because function calls are not allowed without using 'call'")
+	errWriteBarrierEnabled        = errors.New("pointers can not be written while the garbage collector of the target is running")
 )
 
+// IsErrFuncCallNotAllowed returns true if err was returned because
+// evaluating an expression needed to inject a function call into the
+// target process (for example to allocate memory for a literal string) and
+// function calls were not allowed.
+func IsErrFuncCallNotAllowed(err error) bool {
+	return err == errFuncCallNotAllowed || err == errFuncCallNotAllowedStrAlloc || err == errFuncCallNotAllowedAlloc
+}
+
 type functionCallState struct {
 	// savedRegs contains the saved registers
 	savedRegs Registers
@@ -148,7 +158,49 @@ func (callCtx *callContext) doReturn(ret *Variable, err error) {
 // Because this can only be done in the current goroutine, unlike
 // EvalExpression, EvalExpressionWithCalls is not a method of EvalScope.
 func EvalExpressionWithCalls(t *Target, g *G, expr string, retLoadCfg LoadConfig, checkEscape bool) error {
-	bi := t.BinInfo()
+	if err := canInjectCalls(t, g); err != nil {
+		return err
+	}
+
+	scope, err := GoroutineScope(g.Thread)
+	if err != nil {
+		return err
+	}
+
+	return startEvalWithCalls(t, g, scope, retLoadCfg, checkEscape, func() {
+		scope.EvalExpression(expr, retLoadCfg)
+	})
+}
+
+// SetVariableWithCalls is like EvalScope.SetVariable but allows function
+// calls to be injected into goroutine gid to allocate the memory needed by
+// the new value, for example for literal strings, slice literals and new
+// map entries.
+func SetVariableWithCalls(t *Target, gid, frame, deferredCall int, symbol, value string) error {
+	g, err := FindGoroutine(t, gid)
+	if err != nil {
+		return err
+	}
+	if err := canInjectCalls(t, g); err != nil {
+		return err
+	}
+
+	scope, err := ConvertEvalScope(t, g.ID, frame, deferredCall)
+	if err != nil {
+		return err
+	}
+
+	return startEvalWithCalls(t, g, scope, loadSingleValue, true, func() {
+		// makes sure that the other goroutine won't wait forever if we make a mistake
+		defer close(scope.callCtx.continueRequest)
+		err := scope.SetVariable(symbol, value)
+		scope.callCtx.doReturn(nil, err)
+	})
+}
+
+// canInjectCalls returns an error if function calls can not be injected
+// into goroutine g.
+func canInjectCalls(t *Target, g *G) error {
 	if !t.SupportsFunctionCalls() {
 		return errFuncCallUnsupportedBackend
 	}
@@ -165,16 +217,17 @@ func EvalExpressionWithCalls(t *Target, g *G, expr string, retLoadCfg LoadConfig
 		return errFuncCallInProgress
 	}
 
-	dbgcallfn := bi.LookupFunc[debugCallFunctionName]
-	if dbgcallfn == nil {
+	if t.BinInfo().LookupFunc[debugCallFunctionName] == nil {
 		return errFuncCallUnsupported
 	}
+	return nil
+}
 
-	scope, err := GoroutineScope(g.Thread)
-	if err != nil {
-		return err
-	}
-
+// startEvalWithCalls sets up the call context of scope and runs eval, which
+// must evaluate something in scope and then call doReturn, on a separate
+// goroutine. The target process is resumed as many times as needed to
+// execute the function calls injected by eval.
+func startEvalWithCalls(t *Target, g *G, scope *EvalScope, retLoadCfg LoadConfig, checkEscape bool, eval func()) error {
 	continueRequest := make(chan continueRequest)
 	continueCompleted := make(chan *G)
 
@@ -192,7 +245,7 @@ func EvalExpressionWithCalls(t *Target, g *G, expr string, retLoadCfg LoadConfig
 		startThreadID:     0,
 	}
 
-	go scope.EvalExpression(expr, retLoadCfg)
+	go eval()
 
 	contReq, ok := <-continueRequest
 	if contReq.cont {
@@ -905,6 +958,44 @@ func allocString(scope *EvalScope, v *Variable) error {
 	if scope.callCtx == nil {
 		return errFuncCallNotAllowedStrAlloc
 	}
+	base, err := allocMemory(scope, v.Len, nil, false)
+	if err != nil {
+		return err
+	}
+	v.Base = base
+	_, err = scope.Mem.WriteMemory(v.Base, []byte(constant.StringVal(v.Value)))
+	return err
+}
+
+// allocMemory allocates size bytes of memory on the heap of the target
+// process by calling runtime.mallocgc. If typ is not nil the memory is
+// allocated as an object of type typ (or as an array of objects of type
+// typ) so that the garbage collector can scan its pointers, memory
+// allocated with a nil typ must not contain pointers.
+func allocMemory(scope *EvalScope, size int64, typ godwarf.Type, needzero bool) (uint64, error) {
+	if scope.callCtx == nil {
+		return 0, errFuncCallNotAllowedAlloc
+	}
+
+	var typarg ast.Expr = &ast.Ident{Name: "nil"}
+	if typ != nil {
+		typaddr, err := runtimeTypeAddr(scope.BinInfo, scope.Mem, typ)
+		if err != nil {
+			if typeHasPointers(typ) {
+				return 0, fmt.Errorf("can not allocate memory for %s: %v", typ.String(), err)
+			}
+		} else {
+			// (*runtime._type)(typaddr)
+			typarg = &ast.CallExpr{
+				Fun: &ast.ParenExpr{X: &ast.StarExpr{X: &ast.SelectorExpr{
+					X:   &ast.Ident{Name: "runtime"},
+					Sel: &ast.Ident{Name: "_type"},
+				}}},
+				Args: []ast.Expr{&ast.BasicLit{Kind: token.INT, Value: fmt.Sprintf("%#x", typaddr)}},
+			}
+		}
+	}
+
 	savedLoadCfg := scope.callCtx.retLoadCfg
 	scope.callCtx.retLoadCfg = loadFullValue
 	defer func() {
@@ -916,26 +1007,133 @@ func allocString(scope *EvalScope, v *Variable) error {
 			Sel: &ast.Ident{Name: "mallocgc"},
 		},
 		Args: []ast.Expr{
-			&ast.BasicLit{Kind: token.INT, Value: strconv.Itoa(int(v.Len))},
-			&ast.Ident{Name: "nil"},
-			&ast.Ident{Name: "false"},
+			&ast.BasicLit{Kind: token.INT, Value: strconv.Itoa(int(size))},
+			typarg,
+			&ast.Ident{Name: strconv.FormatBool(needzero)},
 		},
 	})
 	if err != nil {
-		return err
+		return 0, err
 	}
 	if mallocv.Unreadable != nil {
-		return mallocv.Unreadable
+		return 0, mallocv.Unreadable
 	}
 	if mallocv.DwarfType.String() != "*void" {
-		return fmt.Errorf("unexpected return type for mallocgc call: %v", mallocv.DwarfType.String())
+		return 0, fmt.Errorf("unexpected return type for mallocgc call: %v", mallocv.DwarfType.String())
 	}
 	if len(mallocv.Children) != 1 {
-		return errors.New("internal error, could not interpret return value of mallocgc call")
+		return 0, errors.New("internal error, could not interpret return value of mallocgc call")
 	}
-	v.Base = mallocv.Children[0].Addr
-	_, err = scope.Mem.WriteMemory(v.Base, []byte(constant.StringVal(v.Value)))
-	return err
+	return mallocv.Children[0].Addr, nil
+}
+
+// checkWriteBarrier returns errWriteBarrierEnabled if the write barrier of
+// the target process is enabled. Pointers are written to the memory of the
+// target without a write barrier, which is only correct while the garbage
+// collector isn't marking.
+func checkWriteBarrier(scope *EvalScope) error {
+	wb, err := scope.findGlobal("runtime", "writeBarrier")
+	if err != nil {
+		return nil
+	}
+	enabled, err := wb.structMember("enabled")
+	if err != nil {
+		return nil
+	}
+	enabled.loadValue(loadSingleValue)
+	if enabled.Unreadable != nil {
+		return enabled.Unreadable
+	}
+	if enabled.Value != nil && constant.BoolVal(enabled.Value) {
+		return errWriteBarrierEnabled
+	}
+	return nil
+}
+
+// mapAssign inserts key into map m, by calling runtime.mapassign, and
+// returns the variable for the corresponding map element.
+func mapAssign(scope *EvalScope, m *Variable, key *Variable) (*Variable, error) {
+	mt, ok := m.RealType.(*godwarf.MapType)
+	if !ok || m.Addr == 0 {
+		return nil, fmt.Errorf("can not assign to map element of %s", m.TypeString())
+	}
+	hmapaddr, err := readUintRaw(m.mem, m.Addr, int64(scope.BinInfo.Arch.PtrSize()))
+	if err != nil {
+		return nil, err
+	}
+	if hmapaddr == 0 {
+		return nil, errors.New("assignment to entry in nil map")
+	}
+	if scope.callCtx == nil {
+		return nil, errFuncCallNotAllowedAlloc
+	}
+	maptypaddr, err := runtimeTypeAddr(scope.BinInfo, scope.Mem, m.DwarfType)
+	if err != nil {
+		return nil, fmt.Errorf("can not assign to map element of %s: %v", m.TypeString(), err)
+	}
+
+	// the key must be passed by reference, copy it to the heap
+	var keytyp godwarf.Type = mt.KeyType
+	var strlen int64
+	if _, isstr := resolveTypedef(mt.KeyType).(*godwarf.StringType); isstr && key.Kind == reflect.String && key.Base == 0 && key.Len > 0 {
+		// The contents of the string are stored in the same object as the
+		// key, a second allocation would let the garbage collector free the
+		// first one while the target runs. The object is only referenced by
+		// the arguments of runtime.mapassign and then by the map, it doesn't
+		// need to be scanned.
+		keytyp = nil
+		strlen = key.Len
+	}
+	keyaddr, err := allocMemory(scope, mt.KeyType.Size()+strlen, keytyp, true)
+	if err != nil {
+		return nil, err
+	}
+	if strlen > 0 {
+		key.Base = keyaddr + uint64(mt.KeyType.Size())
+		if _, err := scope.Mem.WriteMemory(key.Base, []byte(constant.StringVal(key.Value))); err != nil {
+			return nil, err
+		}
+	}
+	keyv := newVariable("", keyaddr, mt.KeyType, scope.BinInfo, scope.Mem)
+	if err := scope.setValue(keyv, key, key.Name); err != nil {
+		return nil, err
+	}
+
+	cast := func(pkg, typ string, addr uint64) ast.Expr {
+		var typexpr ast.Expr = &ast.SelectorExpr{X: &ast.Ident{Name: pkg}, Sel: &ast.Ident{Name: typ}}
+		if pkg == "runtime" {
+			typexpr = &ast.ParenExpr{X: &ast.StarExpr{X: typexpr}}
+		}
+		return &ast.CallExpr{Fun: typexpr, Args: []ast.Expr{&ast.BasicLit{Kind: token.INT, Value: fmt.Sprintf("%#x", addr)}}}
+	}
+
+	savedLoadCfg := scope.callCtx.retLoadCfg
+	scope.callCtx.retLoadCfg = loadFullValue
+	defer func() {
+		scope.callCtx.retLoadCfg = savedLoadCfg
+	}()
+	// runtime.mapassign((*runtime.maptype)(maptypaddr), (*runtime.hmap)(hmapaddr), unsafe.Pointer(keyaddr))
+	elemptrv, err := evalFunctionCall(scope, &ast.CallExpr{
+		Fun: &ast.SelectorExpr{
+			X:   &ast.Ident{Name: "runtime"},
+			Sel: &ast.Ident{Name: "mapassign"},
+		},
+		Args: []ast.Expr{
+			cast("runtime", "maptype", maptypaddr),
+			cast("runtime", "hmap", hmapaddr),
+			cast("unsafe", "Pointer", keyaddr),
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+	if elemptrv.Unreadable != nil {
+		return nil, elemptrv.Unreadable
+	}
+	if len(elemptrv.Children) != 1 || elemptrv.Children[0].Addr == 0 {
+		return nil, errors.New("internal error, could not interpret return value of mapassign call")
+	}
+	return newVariable("", elemptrv.Children[0].Addr, mt.ElemType, scope.BinInfo, scope.Mem), nil
 }
 
 func isCallInjectionStop(t *Target, thread Thread, loc *Location) bool {
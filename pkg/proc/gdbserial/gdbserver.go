@@ -542,6 +542,30 @@ func (p *gdbProcess) EntryPoint() (uint64, error) {
 	return entryPoint, nil
 }
 
+// MemoryMap returns the memory mappings of the target process, using the
+// qMemoryRegionInfo packet. Not all stubs support it.
+func (p *gdbProcess) MemoryMap() ([]proc.MemoryMapEntry, error) {
+	var r []proc.MemoryMapEntry
+	addr := uint64(0)
+	for {
+		m, mapped, err := p.conn.memoryRegionInfo(addr)
+		if err != nil {
+			if len(r) == 0 {
+				return nil, proc.ErrMemoryMapNotSupported
+			}
+			return r, nil
+		}
+		if m.Size == 0 || m.Addr+m.Size <= addr {
+			break
+		}
+		if mapped {
+			r = append(r, m)
+		}
+		addr = m.Addr + m.Size
+	}
+	return r, nil
+}
+
 // initialize uses qProcessInfo to load the inferior's PID and
 // executable path. This command is not supported by all stubs and not all
 // stubs will report both the PID and executable path.
@@ -4,6 +4,7 @@ import (
 	"bufio"
 	"bytes"
 	"debug/macho"
+	"encoding/hex"
 	"encoding/json"
 	"encoding/xml"
 	"errors"
@@ -943,6 +944,51 @@ func (conn *gdbConn) allocMemory(sz uint64) (uint64, error) {
 	return strconv.ParseUint(string(resp), 16, 64)
 }
 
+// memoryRegionInfo executes a 'qMemoryRegionInfo' command and returns the
+// memory region containing addr, or the unmapped region starting at addr.
+// This is an lldb extension, see:
+// https://github.com/llvm/llvm-project/blob/main/lldb/docs/lldb-gdb-remote.txt
+func (conn *gdbConn) memoryRegionInfo(addr uint64) (proc.MemoryMapEntry, bool, error) {
+	conn.outbuf.Reset()
+	fmt.Fprintf(&conn.outbuf, "$qMemoryRegionInfo:%x", addr)
+	resp, err := conn.exec(conn.outbuf.Bytes(), "memory region info")
+	if err != nil {
+		return proc.MemoryMapEntry{}, false, err
+	}
+	if len(resp) == 0 {
+		return proc.MemoryMapEntry{}, false, proc.ErrMemoryMapNotSupported
+	}
+
+	var m proc.MemoryMapEntry
+	mapped := false
+	for _, keyval := range strings.Split(string(resp), ";") {
+		colon := strings.Index(keyval, ":")
+		if colon < 0 {
+			continue
+		}
+		key, value := keyval[:colon], keyval[colon+1:]
+		switch key {
+		case "start":
+			m.Addr, err = strconv.ParseUint(value, 16, 64)
+		case "size":
+			m.Size, err = strconv.ParseUint(value, 16, 64)
+		case "permissions":
+			mapped = value != ""
+			m.Read = strings.Contains(value, "r")
+			m.Write = strings.Contains(value, "w")
+			m.Exec = strings.Contains(value, "x")
+		case "name":
+			var name []byte
+			name, err = hex.DecodeString(value)
+			m.Filename = string(name)
+		}
+		if err != nil {
+			return proc.MemoryMapEntry{}, false, fmt.Errorf("malformed memory region info %q: %v", resp, err)
+		}
+	}
+	return m, mapped, nil
+}
+
 // threadStopInfo executes a 'qThreadStopInfo' and returns the reason the
 // thread stopped.
 func (conn *gdbConn) threadStopInfo(threadID string) (sig uint8, reason string, err error) {
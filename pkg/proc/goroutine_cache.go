@@ -1,5 +1,7 @@
 package proc
 
+import "github.com/go-delve/delve/pkg/dwarf/godwarf"
+
 type goroutineCache struct {
 	partialGCache map[int]*G
 	allGCache     []*G
@@ -11,6 +13,10 @@ func (gcache *goroutineCache) init(bi *BinaryInfo) {
 	var err error
 
 	exeimage := bi.Images[0]
+	if exeimage.dwarf == nil {
+		// runtime.allgs is found by getRuntimeAllg, see findAllgsNoDwarf
+		return
+	}
 	rdr := exeimage.DwarfReader()
 
 	gcache.allglenAddr, _ = rdr.AddrFor("runtime.allglen", exeimage.StaticBase, bi.Arch.PtrSize())
@@ -24,6 +30,12 @@ func (gcache *goroutineCache) init(bi *BinaryInfo) {
 }
 
 func (gcache *goroutineCache) getRuntimeAllg(bi *BinaryInfo, mem MemoryReadWriter) (uint64, uint64, error) {
+	if (gcache.allglenAddr == 0 || gcache.allgentryAddr == 0) && bi.Images[0].dwarf == nil {
+		if allgs, err := findAllgsNoDwarf(bi, mem); err == nil {
+			// allglen is read from the length of the allgs slice
+			gcache.allgentryAddr, gcache.allglenAddr = allgs, allgs+uint64(bi.Arch.PtrSize())
+		}
+	}
 	if gcache.allglenAddr == 0 || gcache.allgentryAddr == 0 {
 		return 0, 0, ErrNoRuntimeAllG
 	}
@@ -51,3 +63,63 @@ func (gcache *goroutineCache) Clear() {
 	gcache.partialGCache = nil
 	gcache.allGCache = nil
 }
+
+// findAllgsNoDwarf returns the address of runtime.allgs in an executable
+// without DWARF, whose symbol table could also have been stripped. The
+// data sections listed in runtime.moduledata are searched for a slice
+// whose first element is the main goroutine, the first goroutine created
+// by the runtime, which runs runtime.main.
+// No goroutine exists before the runtime is initialized, in that case
+// an error is returned and the search must be repeated later.
+func findAllgsNoDwarf(bi *BinaryInfo, mem MemoryReadWriter) (uint64, error) {
+	md, err := loadModuleDataNoDwarf(bi, mem)
+	if err != nil {
+		return 0, err
+	}
+	typ, err := runtimeGTypeNoDwarf(bi, mem)
+	if err != nil {
+		return 0, err
+	}
+	mainfn := bi.LookupFunc["runtime.main"]
+	if mainfn == nil {
+		return 0, ErrNoRuntimeAllG
+	}
+	var goidField, startpcField *godwarf.StructField
+	for _, field := range typ.(*godwarf.StructType).Field {
+		switch field.Name {
+		case "goid":
+			goidField = field
+		case "startpc":
+			startpcField = field
+		}
+	}
+	if goidField == nil || startpcField == nil {
+		return 0, ErrNoRuntimeAllG
+	}
+
+	const maxAllglen = 1 << 24
+	ptrSize := bi.Arch.PtrSize()
+	buf := make([]byte, md.edata-md.data)
+	if _, err := mem.ReadMemory(buf, md.data); err != nil {
+		return 0, err
+	}
+	for off := 0; off+3*ptrSize <= len(buf); off += ptrSize {
+		ptr, n, c := readUintPtr(buf[off:], ptrSize), readUintPtr(buf[off+ptrSize:], ptrSize), readUintPtr(buf[off+2*ptrSize:], ptrSize)
+		if ptr == 0 || n == 0 || n > c || c > maxAllglen {
+			continue
+		}
+		g, err := readUintRaw(mem, ptr, int64(ptrSize))
+		if err != nil || g == 0 {
+			continue
+		}
+		goid, err := readUintRaw(mem, g+uint64(goidField.ByteOffset), goidField.ByteSize)
+		if err != nil || goid != 1 {
+			continue
+		}
+		startpc, err := readUintRaw(mem, g+uint64(startpcField.ByteOffset), startpcField.ByteSize)
+		if err == nil && startpc == mainfn.Entry {
+			return md.data + uint64(off), nil
+		}
+	}
+	return 0, ErrNoRuntimeAllG
+}
@@ -0,0 +1,19 @@
+package proc
+
+import (
+	"syscall"
+	"unsafe"
+
+	"golang.org/x/sys/unix"
+)
+
+// hostNanotime returns the value of the clock used by runtime.nanotime on
+// this operating system.
+func hostNanotime() (int64, error) {
+	var ts unix.Timespec
+	_, _, errno := syscall.Syscall(unix.SYS_CLOCK_GETTIME, unix.CLOCK_MONOTONIC, uintptr(unsafe.Pointer(&ts)), 0)
+	if errno != 0 {
+		return 0, errno
+	}
+	return ts.Nano(), nil
+}
@@ -0,0 +1,13 @@
+package proc
+
+import "golang.org/x/sys/unix"
+
+// hostNanotime returns the value of the clock used by runtime.nanotime on
+// this operating system.
+func hostNanotime() (int64, error) {
+	var ts unix.Timespec
+	if err := unix.ClockGettime(unix.CLOCK_MONOTONIC, &ts); err != nil {
+		return 0, err
+	}
+	return ts.Nano(), nil
+}
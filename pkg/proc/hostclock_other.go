@@ -0,0 +1,11 @@
+// +build !linux,!darwin,!freebsd
+
+package proc
+
+import "errors"
+
+// hostNanotime returns the value of the clock used by runtime.nanotime on
+// this operating system.
+func hostNanotime() (int64, error) {
+	return 0, errors.New("could not read the monotonic clock")
+}
@@ -0,0 +1,199 @@
+package proc
+
+import (
+	"bytes"
+	"errors"
+
+	"github.com/go-delve/delve/pkg/dwarf/op"
+	"golang.org/x/arch/x86/x86asm"
+)
+
+// TracedInstruction is an instruction executed by TraceInstructions.
+type TracedInstruction struct {
+	Inst AsmInstruction
+	// Regs contains the registers changed by the instruction, other than
+	// the program counter, with their new value.
+	Regs *op.DwarfRegisters
+	// MemWrites are the memory locations written by the instruction.
+	MemWrites []MemoryWrite
+}
+
+// MemoryWrite is a memory location written by an instruction and its new
+// contents.
+type MemoryWrite struct {
+	Addr uint64
+	Data []byte
+}
+
+// TraceInstructionsFlags selects the information collected by
+// TraceInstructions for each instruction.
+type TraceInstructionsFlags uint8
+
+const (
+	// TraceRegisters collects the registers changed by each instruction.
+	TraceRegisters TraceInstructionsFlags = 1 << iota
+	// TraceMemoryWrites collects the memory written by each instruction.
+	// Only the destination operand of the instruction and the stack slot
+	// written by PUSH and CALL instructions are considered and only on
+	// x86 architectures.
+	TraceMemoryWrites
+)
+
+// TraceInstructions single-steps the thread running the selected goroutine
+// (or the current thread if no goroutine is selected) until it reaches
+// one of the addresses in stopPCs after executing at least one
+// instruction, maxInsts instructions have been executed or a manual stop
+// is requested. Returns the instructions executed and true if one of
+// stopPCs was reached.
+func TraceInstructions(dbp *Target, stopPCs []uint64, maxInsts int, flags TraceInstructionsFlags) ([]TracedInstruction, bool, error) {
+	if _, err := dbp.Valid(); err != nil {
+		return nil, false, err
+	}
+	thread := dbp.CurrentThread()
+	if g := dbp.SelectedGoroutine(); g != nil {
+		if g.Thread == nil {
+			return nil, false, errors.New("the selected goroutine is not running on a thread")
+		}
+		thread = g.Thread
+	}
+	stop := make(map[uint64]bool)
+	for _, pc := range stopPCs {
+		stop[pc] = true
+	}
+	bi := dbp.BinInfo()
+
+	defer func() {
+		dbp.ClearAllGCache()
+		dbp.currentThread = thread
+		if tg, _ := GetG(thread); tg != nil {
+			dbp.selectedGoroutine = tg
+		}
+	}()
+
+	var r []TracedInstruction
+	dbp.CheckAndClearManualStopRequest()
+	for len(r) < maxInsts {
+		if dbp.CheckAndClearManualStopRequest() {
+			dbp.StopReason = StopManual
+			return r, false, nil
+		}
+		regs, err := thread.Registers()
+		if err != nil {
+			return r, false, err
+		}
+		regs, err = regs.Copy()
+		if err != nil {
+			return r, false, err
+		}
+		if len(r) > 0 && stop[regs.PC()] {
+			return r, true, nil
+		}
+		text, err := disassemble(dbp.Memory(), regs, dbp.Breakpoints(), bi, regs.PC(), regs.PC()+uint64(bi.Arch.MaxInstructionLength()), true)
+		if err != nil {
+			return r, false, err
+		}
+		ti := TracedInstruction{Inst: text[0]}
+		var memAddr uint64
+		var memSize int
+		if flags&TraceMemoryWrites != 0 {
+			memAddr, memSize = memoryWritten(&text[0], regs, bi)
+		}
+
+		thread.Breakpoint().Clear()
+		if err := thread.StepInstruction(); err != nil {
+			return r, false, err
+		}
+		if err := thread.SetCurrentBreakpoint(true); err != nil {
+			return r, false, err
+		}
+
+		if flags&TraceRegisters != 0 {
+			newregs, err := thread.Registers()
+			if err != nil {
+				return r, false, err
+			}
+			ti.Regs = changedRegisters(bi.Arch.RegistersToDwarfRegisters(0, regs), bi.Arch.RegistersToDwarfRegisters(0, newregs))
+		}
+		if memSize > 0 {
+			data := make([]byte, memSize)
+			if _, err := dbp.Memory().ReadMemory(data, memAddr); err == nil {
+				ti.MemWrites = append(ti.MemWrites, MemoryWrite{Addr: memAddr, Data: data})
+			}
+		}
+		r = append(r, ti)
+	}
+	return r, false, nil
+}
+
+// changedRegisters returns the registers, other than the program counter,
+// that have a different value in before and after.
+func changedRegisters(before, after op.DwarfRegisters) *op.DwarfRegisters {
+	r := op.NewDwarfRegisters(0, nil, after.ByteOrder, after.PCRegNum, after.SPRegNum, after.BPRegNum, after.LRRegNum)
+	n := before.CurrentSize()
+	if after.CurrentSize() < n {
+		n = after.CurrentSize()
+	}
+	for i := 0; i < n; i++ {
+		if uint64(i) == after.PCRegNum {
+			continue
+		}
+		a, b := before.Reg(uint64(i)), after.Reg(uint64(i))
+		if b == nil || (a != nil && a.Uint64Val == b.Uint64Val && bytes.Equal(a.Bytes, b.Bytes)) {
+			continue
+		}
+		r.AddReg(uint64(i), b)
+	}
+	return r
+}
+
+// memoryWritten returns the address and size of the memory that inst will
+// write when executed with registers regs, the size is 0 if inst does not
+// write memory or the address can not be determined.
+func memoryWritten(inst *AsmInstruction, regs Registers, bi *BinaryInfo) (uint64, int) {
+	xinst, ok := inst.Inst.(*x86Inst)
+	if !ok || xinst == nil {
+		return 0, 0
+	}
+	switch xinst.Op {
+	case x86asm.PUSH, x86asm.PUSHF, x86asm.PUSHFD, x86asm.PUSHFQ, x86asm.CALL:
+		ptrSize := uint64(bi.Arch.PtrSize())
+		return regs.SP() - ptrSize, int(ptrSize)
+	case x86asm.MOV, x86asm.MOVD, x86asm.MOVQ, x86asm.MOVSS, x86asm.MOVSD_XMM, x86asm.MOVBE,
+		x86asm.MOVAPS, x86asm.MOVAPD, x86asm.MOVUPS, x86asm.MOVUPD, x86asm.MOVDQA, x86asm.MOVDQU,
+		x86asm.MOVLPS, x86asm.MOVHPS, x86asm.MOVLPD, x86asm.MOVHPD,
+		x86asm.MOVNTI, x86asm.MOVNTDQ, x86asm.MOVNTPS, x86asm.MOVNTPD,
+		x86asm.PEXTRB, x86asm.PEXTRW, x86asm.PEXTRD, x86asm.PEXTRQ, x86asm.EXTRACTPS,
+		x86asm.ADD, x86asm.ADC, x86asm.SUB, x86asm.SBB, x86asm.AND, x86asm.OR, x86asm.XOR,
+		x86asm.NOT, x86asm.NEG, x86asm.INC, x86asm.DEC,
+		x86asm.SHL, x86asm.SHR, x86asm.SAR, x86asm.ROL, x86asm.ROR, x86asm.RCL, x86asm.RCR, x86asm.SHLD, x86asm.SHRD,
+		x86asm.BTS, x86asm.BTR, x86asm.BTC,
+		x86asm.XCHG, x86asm.XADD, x86asm.CMPXCHG, x86asm.CMPXCHG8B, x86asm.CMPXCHG16B,
+		x86asm.SETA, x86asm.SETAE, x86asm.SETB, x86asm.SETBE, x86asm.SETE, x86asm.SETNE,
+		x86asm.SETG, x86asm.SETGE, x86asm.SETL, x86asm.SETLE, x86asm.SETO, x86asm.SETNO,
+		x86asm.SETP, x86asm.SETNP, x86asm.SETS, x86asm.SETNS,
+		x86asm.FST, x86asm.FSTP, x86asm.FIST, x86asm.FISTP, x86asm.FISTTP, x86asm.FNSTCW, x86asm.FNSTSW,
+		x86asm.STMXCSR:
+		// instructions that write their first operand
+	default:
+		return 0, 0
+	}
+	arg, ok := xinst.Args[0].(x86asm.Mem)
+	if !ok || arg.Segment != 0 || xinst.MemBytes == 0 {
+		return 0, 0
+	}
+	get := func(reg x86asm.Reg) (uint64, error) {
+		switch reg {
+		case 0:
+			return 0, nil
+		case x86asm.RIP:
+			return inst.Loc.PC + uint64(inst.Size), nil
+		}
+		return regs.Get(int(reg))
+	}
+	base, err1 := get(arg.Base)
+	index, err2 := get(arg.Index)
+	if err1 != nil || err2 != nil {
+		return 0, 0
+	}
+	return uint64(int64(base) + int64(index*uint64(arg.Scale)) + arg.Disp), xinst.MemBytes
+}
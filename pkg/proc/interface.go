@@ -35,6 +35,10 @@ type ProcessInternal interface {
 
 	WriteBreakpoint(addr uint64) (file string, line int, fn *Function, originalData []byte, err error)
 	EraseBreakpoint(*Breakpoint) error
+
+	// MemoryMap returns the memory mappings of the target process, sorted
+	// by address, or ErrMemoryMapNotSupported.
+	MemoryMap() ([]MemoryMapEntry, error)
 }
 
 // RecordingManipulation is an interface for manipulating process recordings.
@@ -0,0 +1,73 @@
+package linutil
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/go-delve/delve/pkg/proc"
+)
+
+// ReadProcMaps returns the memory mappings of process pid, as listed by
+// /proc/<pid>/maps.
+func ReadProcMaps(pid int) ([]proc.MemoryMapEntry, error) {
+	fh, err := os.Open(fmt.Sprintf("/proc/%d/maps", pid))
+	if err != nil {
+		return nil, err
+	}
+	defer fh.Close()
+	return ParseProcMaps(fh)
+}
+
+// ParseProcMaps parses the contents of a /proc/<pid>/maps file.
+// Each line has the format:
+//
+//	start-end perms offset dev inode [pathname]
+//
+// See proc(5).
+func ParseProcMaps(r io.Reader) ([]proc.MemoryMapEntry, error) {
+	var mappings []proc.MemoryMapEntry
+	scan := bufio.NewScanner(r)
+	for scan.Scan() {
+		fields := strings.Fields(scan.Text())
+		if len(fields) < 5 {
+			continue
+		}
+		addrs := strings.SplitN(fields[0], "-", 2)
+		if len(addrs) != 2 {
+			return nil, fmt.Errorf("malformed mapping %q", scan.Text())
+		}
+		start, err := strconv.ParseUint(addrs[0], 16, 64)
+		if err != nil {
+			return nil, fmt.Errorf("malformed mapping %q: %v", scan.Text(), err)
+		}
+		end, err := strconv.ParseUint(addrs[1], 16, 64)
+		if err != nil {
+			return nil, fmt.Errorf("malformed mapping %q: %v", scan.Text(), err)
+		}
+		off, err := strconv.ParseUint(fields[2], 16, 64)
+		if err != nil {
+			return nil, fmt.Errorf("malformed mapping %q: %v", scan.Text(), err)
+		}
+		perms := fields[1]
+		m := proc.MemoryMapEntry{
+			Addr:   start,
+			Size:   end - start,
+			Read:   strings.Contains(perms, "r"),
+			Write:  strings.Contains(perms, "w"),
+			Exec:   strings.Contains(perms, "x"),
+			Offset: off,
+		}
+		if len(fields) >= 6 {
+			m.Filename = strings.Join(fields[5:], " ")
+		}
+		mappings = append(mappings, m)
+	}
+	if err := scan.Err(); err != nil {
+		return nil, err
+	}
+	return mappings, nil
+}
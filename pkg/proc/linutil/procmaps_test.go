@@ -0,0 +1,28 @@
+package linutil
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseProcMaps(t *testing.T) {
+	const maps = `00400000-00452000 r-xp 00000000 08:02 173521      /usr/bin/dbus-daemon
+00651000-00652000 r--p 00051000 08:02 173521      /usr/bin/dbus-daemon
+00e03000-00e24000 rw-p 00000000 00:00 0           [heap]
+7ffc4a4c5000-7ffc4a4e6000 rw-p 00000000 00:00 0
+`
+	mappings, err := ParseProcMaps(strings.NewReader(maps))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(mappings) != 4 {
+		t.Fatalf("wrong number of mappings %d", len(mappings))
+	}
+	m := mappings[1]
+	if m.Addr != 0x651000 || m.Size != 0x1000 || !m.Read || m.Write || m.Exec || m.Offset != 0x51000 || m.Filename != "/usr/bin/dbus-daemon" {
+		t.Errorf("wrong mapping %#v", m)
+	}
+	if !mappings[0].Exec || mappings[2].Filename != "[heap]" || !mappings[3].Write || mappings[3].Filename != "" {
+		t.Errorf("wrong mappings %#v", mappings)
+	}
+}
@@ -97,7 +97,8 @@ type compositeMemory struct {
 func newCompositeMemory(mem MemoryReadWriter, regs op.DwarfRegisters, pieces []op.Piece) (*compositeMemory, error) {
 	cmem := &compositeMemory{realmem: mem, regs: regs, pieces: pieces, data: []byte{}}
 	for _, piece := range pieces {
-		if piece.IsRegister {
+		switch piece.Kind {
+		case op.RegPiece:
 			reg := regs.Bytes(piece.RegNum)
 			sz := piece.Size
 			if sz == 0 && len(pieces) == 1 {
@@ -110,7 +111,18 @@ func newCompositeMemory(mem MemoryReadWriter, regs op.DwarfRegisters, pieces []o
 				return nil, fmt.Errorf("could not read %d bytes from register %d (size: %d)", sz, piece.RegNum, len(reg))
 			}
 			cmem.data = append(cmem.data, reg[:sz]...)
-		} else {
+		case op.ImmPiece:
+			sz := piece.Size
+			if sz == 0 && len(pieces) == 1 {
+				sz = len(piece.Bytes)
+			}
+			if sz > len(piece.Bytes) {
+				return nil, fmt.Errorf("could not read %d bytes from a %d bytes value", sz, len(piece.Bytes))
+			}
+			cmem.data = append(cmem.data, piece.Bytes[:sz]...)
+		case op.ImplicitPtrPiece:
+			return nil, errors.New("implicit pointer in composite location not supported")
+		default:
 			buf := make([]byte, piece.Size)
 			mem.ReadMemory(buf, uint64(piece.Addr))
 			cmem.data = append(cmem.data, buf...)
@@ -146,3 +158,28 @@ func DereferenceMemory(mem MemoryReadWriter) MemoryReadWriter {
 	}
 	return mem
 }
+
+// ErrMemoryMapNotSupported is returned by MemoryMap when the backend can
+// not list the memory mappings of the target process.
+var ErrMemoryMapNotSupported = errors.New("memory map not supported")
+
+// MemoryMapEntry represents a memory mapping of the target process.
+type MemoryMapEntry struct {
+	Addr uint64
+	Size uint64
+
+	Read, Write, Exec bool
+
+	Filename string // name of the mapped file, empty for anonymous mappings
+	Offset   uint64 // offset of the mapping in Filename
+}
+
+// End returns the address immediately after the end of the mapping.
+func (m *MemoryMapEntry) End() uint64 {
+	return m.Addr + m.Size
+}
+
+// Contains returns true if addr is inside the mapping.
+func (m *MemoryMapEntry) Contains(addr uint64) bool {
+	return addr >= m.Addr && addr < m.End()
+}
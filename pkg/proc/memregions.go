@@ -0,0 +1,89 @@
+package proc
+
+import (
+	"sort"
+)
+
+// MemoryRegion is a memory mapping of the target process, classified by
+// what the Go runtime uses it for.
+type MemoryRegion struct {
+	MemoryMapEntry
+
+	Heap       bool  // the region contains spans of the Go heap
+	Text       bool  // the region contains executable code of the target
+	ModuleData bool  // the region contains the data or bss sections of a Go module
+	Goroutines []int // IDs of the goroutines whose stack is inside the region
+}
+
+// MemoryRegions returns the memory mappings of the target process, sorted
+// by address. If classify is true each mapping is also classified using
+// the state of the Go runtime, this is done on a best effort basis: errors
+// reading the runtime data structures are ignored.
+func (t *Target) MemoryRegions(classify bool) ([]MemoryRegion, error) {
+	mappings, err := t.MemoryMap()
+	if err != nil {
+		return nil, err
+	}
+	r := make([]MemoryRegion, len(mappings))
+	for i := range mappings {
+		r[i].MemoryMapEntry = mappings[i]
+	}
+	if !classify {
+		return r, nil
+	}
+
+	find := func(addr uint64) *MemoryRegion {
+		i := sort.Search(len(r), func(i int) bool { return r[i].End() > addr })
+		if i < len(r) && r[i].Addr <= addr {
+			return &r[i]
+		}
+		return nil
+	}
+
+	bi := t.BinInfo()
+	for i := range r {
+		// functions are sorted by entry point, check if one of them starts
+		// inside the region
+		j := sort.Search(len(bi.Functions), func(j int) bool { return bi.Functions[j].Entry >= r[i].Addr })
+		if j < len(bi.Functions) && bi.Functions[j].Entry < r[i].End() {
+			r[i].Text = true
+		}
+	}
+
+	if mds, err := loadModuleData(bi, t.Memory()); err == nil {
+		for _, md := range mds {
+			if md.data == 0 || md.edata <= md.data {
+				continue
+			}
+			for i := range r {
+				if r[i].Addr < md.edata && md.data < r[i].End() {
+					r[i].ModuleData = true
+				}
+			}
+		}
+	}
+
+	if spans, err := t.heapSpans(); err == nil {
+		for _, span := range spans {
+			if region := find(span.base); region != nil {
+				region.Heap = true
+			}
+		}
+	}
+
+	if gs, _, err := GoroutinesInfo(t, 0, 0); err == nil {
+		for _, g := range gs {
+			if g.stack.lo == 0 {
+				continue
+			}
+			if region := find(g.stack.lo); region != nil {
+				region.Goroutines = append(region.Goroutines, g.ID)
+			}
+		}
+		for i := range r {
+			sort.Ints(r[i].Goroutines)
+		}
+	}
+
+	return r, nil
+}
@@ -0,0 +1,281 @@
+package proc
+
+import (
+	"bytes"
+	"debug/dwarf"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+
+	"github.com/go-delve/delve/pkg/dwarf/godwarf"
+)
+
+// searchChunkSize is the size of the blocks of memory read by SearchMemory.
+const searchChunkSize = 1 << 20
+
+// Go heap constants, from: src/runtime/malloc.go and src/runtime/mheap.go.
+const (
+	heapPageSize = 8192 // _PageSize
+	mSpanInUse   = 1    // mSpanInUse state of runtime.mspan
+)
+
+// MemorySearchResult is an occurrence of the pattern searched by
+// SearchMemory.
+type MemorySearchResult struct {
+	Addr uint64
+
+	// Heap is true if Addr is inside an object allocated on the Go heap,
+	// Base and Size are the address and size of the object.
+	Heap bool
+
+	// Symbol is the name of the function or package variable containing
+	// Addr, Base and Size are its address and size.
+	Symbol string
+
+	Base uint64
+	Size uint64
+}
+
+// heapSpan is a span of memory used by the Go heap to allocate objects of
+// size elemSize (a runtime.mspan struct in the mspanInUse state).
+type heapSpan struct {
+	base, end uint64
+	elemSize  uint64
+}
+
+var errNoHeapSpans = errors.New("could not find heap spans (unsupported version of Go?)")
+
+// SearchMemory searches the regions of memory for pattern, returning at
+// most max matches (all matches if max is 0). Regions that can not be read
+// are skipped.
+// Each match is described by the heap object or symbol that contains it,
+// if any.
+func (t *Target) SearchMemory(regions []MemoryMapEntry, pattern []byte, max int) ([]MemorySearchResult, error) {
+	if _, err := t.Valid(); err != nil {
+		return nil, err
+	}
+	if len(pattern) == 0 {
+		return nil, errors.New("empty search pattern")
+	}
+	mem := t.Memory()
+	var r []MemorySearchResult
+	buf := make([]byte, searchChunkSize+len(pattern)-1)
+	for _, region := range regions {
+		for addr := region.Addr; addr < region.End(); addr += searchChunkSize {
+			sz := region.End() - addr
+			if sz > uint64(len(buf)) {
+				sz = uint64(len(buf))
+			}
+			if sz < uint64(len(pattern)) {
+				break
+			}
+			chunk := buf[:sz]
+			if _, err := mem.ReadMemory(chunk, addr); err != nil {
+				continue
+			}
+			for off := 0; ; {
+				i := bytes.Index(chunk[off:], pattern)
+				if i < 0 {
+					break
+				}
+				r = append(r, MemorySearchResult{Addr: addr + uint64(off+i)})
+				if max > 0 && len(r) >= max {
+					t.describeMatches(r)
+					return r, nil
+				}
+				off += i + 1
+				if uint64(off) >= searchChunkSize {
+					// matches starting after the end of the chunk will be found
+					// while searching the next chunk
+					break
+				}
+			}
+		}
+	}
+	t.describeMatches(r)
+	return r, nil
+}
+
+// describeMatches fills the Heap, Symbol, Base and Size fields of the
+// search results in r.
+func (t *Target) describeMatches(r []MemorySearchResult) {
+	if len(r) == 0 {
+		return
+	}
+	spans, _ := t.heapSpans()
+	bi := t.BinInfo()
+	for i := range r {
+		if span := findHeapSpan(spans, r[i].Addr); span != nil {
+			r[i].Heap = true
+			r[i].Base = span.base
+			r[i].Size = span.end - span.base
+			if span.elemSize != 0 {
+				r[i].Base += (r[i].Addr - span.base) / span.elemSize * span.elemSize
+				r[i].Size = span.elemSize
+			}
+			continue
+		}
+		r[i].Symbol, r[i].Base, r[i].Size = bi.symbolContaining(r[i].Addr)
+	}
+}
+
+// HeapRegions returns the regions of memory used by the Go heap to
+// allocate objects.
+func (t *Target) HeapRegions() ([]MemoryMapEntry, error) {
+	if _, err := t.Valid(); err != nil {
+		return nil, err
+	}
+	spans, err := t.heapSpans()
+	if err != nil {
+		return nil, err
+	}
+	var r []MemoryMapEntry
+	for _, span := range spans {
+		if len(r) > 0 && r[len(r)-1].End() == span.base {
+			r[len(r)-1].Size += span.end - span.base
+			continue
+		}
+		r = append(r, MemoryMapEntry{Addr: span.base, Size: span.end - span.base, Read: true, Write: true})
+	}
+	return r, nil
+}
+
+// heapSpans returns the spans of the Go heap that are in use, sorted by
+// address, reading them from runtime.mheap_.allspans.
+func (t *Target) heapSpans() ([]heapSpan, error) {
+	bi := t.BinInfo()
+	mem := t.Memory()
+	scope := globalScope(bi, bi.Images[0], mem)
+	mheap, err := scope.findGlobal("runtime", "mheap_")
+	if err != nil {
+		return nil, errNoHeapSpans
+	}
+	allspans, err := mheap.structMember("allspans")
+	if err != nil || allspans.Kind != reflect.Slice {
+		return nil, errNoHeapSpans
+	}
+	allspans.loadValue(LoadConfig{})
+	if allspans.Unreadable != nil {
+		return nil, allspans.Unreadable
+	}
+
+	typ, err := bi.findType("runtime.mspan")
+	if err != nil {
+		return nil, errNoHeapSpans
+	}
+	st, ok := resolveTypedef(typ).(*godwarf.StructType)
+	if !ok {
+		return nil, errNoHeapSpans
+	}
+	var startAddrOff, npagesOff, elemsizeOff, stateOff int64 = -1, -1, -1, -1
+	for _, field := range st.Field {
+		switch field.Name {
+		case "startAddr", "start":
+			startAddrOff = field.ByteOffset
+		case "npages":
+			npagesOff = field.ByteOffset
+		case "elemsize":
+			elemsizeOff = field.ByteOffset
+		case "state":
+			// state is a uint8 or, in Go 1.14 and later, a struct containing a
+			// single uint8 field.
+			stateOff = field.ByteOffset
+		}
+	}
+	if startAddrOff < 0 || npagesOff < 0 || stateOff < 0 {
+		return nil, errNoHeapSpans
+	}
+
+	ptrSize := int64(bi.Arch.PtrSize())
+	ptrs := make([]byte, allspans.Len*ptrSize)
+	if _, err := mem.ReadMemory(ptrs, allspans.Base); err != nil {
+		return nil, err
+	}
+	readPtr := func(b []byte) uint64 {
+		if ptrSize == 4 {
+			return uint64(binary.LittleEndian.Uint32(b))
+		}
+		return binary.LittleEndian.Uint64(b)
+	}
+	buf := make([]byte, st.Size())
+	readWord := func(off int64) uint64 {
+		if off < 0 {
+			return 0
+		}
+		return readPtr(buf[off : off+ptrSize])
+	}
+
+	var spans []heapSpan
+	for i := int64(0); i < allspans.Len; i++ {
+		sp := readPtr(ptrs[i*ptrSize : (i+1)*ptrSize])
+		if sp == 0 {
+			continue
+		}
+		if _, err := mem.ReadMemory(buf, sp); err != nil {
+			continue
+		}
+		if buf[stateOff] != mSpanInUse {
+			continue
+		}
+		base := readWord(startAddrOff)
+		spans = append(spans, heapSpan{
+			base:     base,
+			end:      base + readWord(npagesOff)*heapPageSize,
+			elemSize: readWord(elemsizeOff),
+		})
+	}
+	sort.Slice(spans, func(i, j int) bool { return spans[i].base < spans[j].base })
+	return spans, nil
+}
+
+// findHeapSpan returns the span containing addr.
+func findHeapSpan(spans []heapSpan, addr uint64) *heapSpan {
+	i := sort.Search(len(spans), func(i int) bool { return spans[i].end > addr })
+	if i < len(spans) && spans[i].base <= addr {
+		return &spans[i]
+	}
+	return nil
+}
+
+// symbolContaining returns the name, address and size of the function or
+// package variable containing addr.
+func (bi *BinaryInfo) symbolContaining(addr uint64) (string, uint64, uint64) {
+	if fn := bi.PCToFunc(addr); fn != nil {
+		return fn.Name, fn.Entry, fn.End - fn.Entry
+	}
+	i := sort.Search(len(bi.packageVars), func(i int) bool {
+		return bi.packageVars[i].addr > addr
+	}) - 1
+	if i < 0 || bi.packageVars[i].addr == 0 {
+		return "", 0, 0
+	}
+	pkgvar := &bi.packageVars[i]
+	reader := pkgvar.cu.image.dwarfReader
+	reader.Seek(pkgvar.offset)
+	entry, err := reader.Next()
+	if err != nil {
+		return "", 0, 0
+	}
+	typeOff, ok := entry.Val(dwarf.AttrType).(dwarf.Offset)
+	if !ok {
+		return "", 0, 0
+	}
+	typ, err := pkgvar.cu.image.Type(typeOff)
+	if err != nil || addr >= pkgvar.addr+uint64(typ.Size()) {
+		return "", 0, 0
+	}
+	return pkgvar.name, pkgvar.addr, uint64(typ.Size())
+}
+
+// String returns a description of the location of a search result.
+func (r *MemorySearchResult) String() string {
+	switch {
+	case r.Heap:
+		return fmt.Sprintf("heap object %#x+%d (size %d)", r.Base, r.Addr-r.Base, r.Size)
+	case r.Symbol != "":
+		return fmt.Sprintf("%s+%d", r.Symbol, r.Addr-r.Base)
+	}
+	return ""
+}
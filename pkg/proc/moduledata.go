@@ -1,6 +1,8 @@
 package proc
 
 import (
+	"encoding/binary"
+	"errors"
 	"go/constant"
 	"unsafe"
 )
@@ -9,6 +11,7 @@ import (
 type moduleData struct {
 	text, etext   uint64
 	types, etypes uint64
+	data, edata   uint64 // data and bss sections, from noptrdata to enoptrbss
 	typemapVar    *Variable
 }
 
@@ -61,6 +64,12 @@ func loadModuleData(bi *BinaryInfo, mem MemoryReadWriter) ([]moduleData, error)
 		if err != nil {
 			return nil, err
 		}
+		if noptrdata, err := md.structMember("noptrdata"); err == nil {
+			r[len(r)-1].data, _ = noptrdata.asUint()
+		}
+		if enoptrbss, err := md.structMember("enoptrbss"); err == nil {
+			r[len(r)-1].edata, _ = enoptrbss.asUint()
+		}
 
 		md = vars[nextField].maybeDereference()
 		if md.Unreadable != nil {
@@ -203,3 +212,79 @@ func loadName(bi *BinaryInfo, addr uint64, mem MemoryReadWriter) (name, tag stri
 
 	return name, tag, pkgpathoff, nil
 }
+
+// moduleDataLayout is the position of some fields of runtime.moduledata,
+// in pointer sized words.
+type moduleDataLayout struct {
+	text, noptrdata, types int
+}
+
+// moduleDataLayoutFor returns the layout of runtime.moduledata in the
+// version of Go that produced a gopclntab section with the given magic
+// number.
+func moduleDataLayoutFor(magic uint32) moduleDataLayout {
+	switch magic {
+	case pclntab12Magic:
+		// Go 1.15 and earlier: pclntable, ftab and filetab are slices.
+		return moduleDataLayout{text: 12, noptrdata: 14, types: 25}
+	case pclntab116Magic, pclntab118Magic:
+		// Go 1.16 to 1.19: pcHeader followed by 6 slices.
+		return moduleDataLayout{text: 22, noptrdata: 24, types: 35}
+	default:
+		// Go 1.20 and later: covctrs and ecovctrs follow enoptrbss.
+		return moduleDataLayout{text: 22, noptrdata: 24, types: 37}
+	}
+}
+
+// loadModuleDataNoDwarf finds runtime.firstmoduledata in the memory of an
+// executable without DWARF. In every version of Go the first field of
+// runtime.moduledata points to the gopclntab section, the data sections of
+// the executable are searched for it.
+func loadModuleDataNoDwarf(bi *BinaryInfo, mem MemoryReadWriter) (*moduleData, error) {
+	if bi.moduleDataNoDwarf != nil {
+		return bi.moduleDataNoDwarf, nil
+	}
+	image := bi.Images[0]
+	if image.pclntabAddr == 0 {
+		return nil, errNoDwarf
+	}
+	layout := moduleDataLayoutFor(image.pclntabMagic)
+	ptrSize := bi.Arch.PtrSize()
+	size := (layout.types + 3) * ptrSize
+
+	for _, sec := range image.dataSections {
+		buf := make([]byte, sec[1]-sec[0])
+		if _, err := mem.ReadMemory(buf, sec[0]); err != nil {
+			continue
+		}
+		for off := 0; off+size <= len(buf); off += ptrSize {
+			word := func(i int) uint64 {
+				return readUintPtr(buf[off+i*ptrSize:], ptrSize)
+			}
+			if word(0) != image.pclntabAddr {
+				continue
+			}
+			md := &moduleData{
+				text: word(layout.text), etext: word(layout.text + 1),
+				data: word(layout.noptrdata), edata: word(layout.noptrdata + 7), // enoptrbss
+				types: word(layout.types), etypes: word(layout.types + 1),
+			}
+			if md.etypes < md.types {
+				// starting with Go 1.26 typedesclen is between types and etypes
+				md.etypes = word(layout.types + 2)
+			}
+			if md.text < md.etext && md.data < md.edata && md.types < md.etypes {
+				bi.moduleDataNoDwarf = md
+				return md, nil
+			}
+		}
+	}
+	return nil, errors.New("could not find runtime.moduledata")
+}
+
+func readUintPtr(buf []byte, ptrSize int) uint64 {
+	if ptrSize == 4 {
+		return uint64(binary.LittleEndian.Uint32(buf))
+	}
+	return binary.LittleEndian.Uint64(buf)
+}
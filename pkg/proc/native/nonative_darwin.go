@@ -81,6 +81,11 @@ func (dbp *nativeProcess) EntryPoint() (uint64, error) {
 	panic(ErrNativeBackendDisabled)
 }
 
+// MemoryMap returns the memory mappings of the process.
+func (dbp *nativeProcess) MemoryMap() ([]proc.MemoryMapEntry, error) {
+	panic(ErrNativeBackendDisabled)
+}
+
 // SetPC sets the value of the PC register.
 func (t *nativeThread) SetPC(pc uint64) error {
 	panic(ErrNativeBackendDisabled)
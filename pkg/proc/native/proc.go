@@ -291,7 +291,8 @@ func (dbp *nativeProcess) initialize(path string, debugInfoDirs []string) (*proc
 		Path:                path,
 		DebugInfoDirs:       debugInfoDirs,
 		DisableAsyncPreempt: runtime.GOOS == "windows" || runtime.GOOS == "freebsd",
-		StopReason:          stopReason})
+		StopReason:          stopReason,
+		HostClock:           true})
 }
 
 func (dbp *nativeProcess) handlePtraceFuncs() {
@@ -462,4 +462,9 @@ func (dbp *nativeProcess) EntryPoint() (uint64, error) {
 	return 0, nil
 }
 
+// MemoryMap is not supported on macOS.
+func (dbp *nativeProcess) MemoryMap() ([]proc.MemoryMapEntry, error) {
+	return nil, proc.ErrMemoryMapNotSupported
+}
+
 func initialize(dbp *nativeProcess) error { return nil }
@@ -374,6 +374,11 @@ func (dbp *nativeProcess) EntryPoint() (uint64, error) {
 	return uint64(ep), err
 }
 
+// MemoryMap is not supported on FreeBSD.
+func (dbp *nativeProcess) MemoryMap() ([]proc.MemoryMapEntry, error) {
+	return nil, proc.ErrMemoryMapNotSupported
+}
+
 // Usedy by Detach
 func killProcess(pid int) error {
 	return sys.Kill(pid, sys.SIGINT)
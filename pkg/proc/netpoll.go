@@ -0,0 +1,369 @@
+package proc
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"go/constant"
+	"io/ioutil"
+	"net"
+	"os"
+	"reflect"
+	"runtime"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// PollDesc represents a file descriptor registered with the network poller
+// of the Go runtime (a runtime.pollDesc struct).
+type PollDesc struct {
+	Addr    uint64 // Address of the runtime.pollDesc struct
+	Fd      int    // File descriptor
+	Closing bool   // The file descriptor is being closed
+
+	// ReadGoroutineID and WriteGoroutineID are the IDs of the goroutines
+	// blocked waiting for the file descriptor to become readable or
+	// writable, 0 if there is no such goroutine.
+	ReadGoroutineID, WriteGoroutineID int
+
+	// ReadDeadline and WriteDeadline are the values of runtime.nanotime()
+	// at which pending reads and writes will time out, 0 if there is no
+	// deadline and a negative number if the deadline has expired.
+	ReadDeadline, WriteDeadline int64
+
+	// Description describes the object the file descriptor refers to, for
+	// sockets it contains the local and remote endpoints. Only available
+	// for live processes on Linux.
+	Description string
+
+	Unreadable error // could not read the pollDesc
+}
+
+const (
+	pdReady = 1 // runtime.pdReady
+	pdWait  = 2 // runtime.pdWait
+
+	// pollWaitMaxDepth is the maximum stack depth at which the frame of
+	// runtime.netpollblock or internal/poll.runtime_pollWait is searched
+	// for in the stack of a parked goroutine.
+	pollWaitMaxDepth = 6
+)
+
+var errNoNetpoll = errors.New("could not find runtime.pollDesc type (network poller not used by the target?)")
+
+// PollDescs returns the file descriptors registered with the network
+// poller of the target process, sorted by file descriptor.
+//
+// The pollDesc structs are collected from the stacks of goroutines parked
+// in the network poller and, for live processes on Linux, from the list of
+// file descriptors registered with the runtime's epoll instance.
+func PollDescs(p *Target) ([]*PollDesc, error) {
+	if _, err := p.Valid(); err != nil {
+		return nil, err
+	}
+	bi := p.BinInfo()
+	pdtyp, err := bi.findType("runtime.pollDesc")
+	if err != nil {
+		return nil, errNoNetpoll
+	}
+
+	gs, _, err := GoroutinesInfo(p, 0, 0)
+	if err != nil {
+		return nil, err
+	}
+	gaddrs := make(map[uint64]int)
+	for _, g := range gs {
+		if g.Unreadable == nil && g.variable != nil {
+			gaddrs[g.variable.Addr] = g.ID
+		}
+	}
+
+	seen := make(map[uint64]bool)
+	r := []*PollDesc{}
+	add := func(addr uint64, fd int) {
+		if addr == 0 || seen[addr] {
+			return
+		}
+		pd := parsePollDesc(newVariable("", addr, pdtyp, bi, p.Memory()), gaddrs)
+		if fd >= 0 && (pd.Unreadable != nil || pd.Fd != fd) {
+			// epoll data that doesn't point to the pollDesc of fd
+			return
+		}
+		seen[addr] = true
+		r = append(r, pd)
+	}
+
+	for _, g := range gs {
+		if g.Unreadable != nil || g.Status != Gwaiting {
+			continue
+		}
+		add(pollDescOfParkedG(p, g), -1)
+	}
+
+	pid := p.Pid()
+	live := false
+	if recorded, _ := p.Recorded(); !recorded && bi.GOOS == "linux" && runtime.GOOS == "linux" {
+		live = true
+	}
+
+	if live {
+		scope := globalScope(bi, bi.Images[0], p.Memory())
+		if epfdv, err := scope.findGlobal("runtime", "epfd"); err == nil {
+			epfdv.loadValue(loadSingleValue)
+			if epfdv.Unreadable == nil && epfdv.Value != nil {
+				// The runtime registers a pipe used to wake up epoll_wait
+				// using the address of runtime.netpollBreakRd as data.
+				var breakAddr uint64
+				if breakv, err := scope.findGlobal("runtime", "netpollBreakRd"); err == nil {
+					breakAddr = breakv.Addr
+				}
+				epfd, _ := constant.Int64Val(epfdv.Value)
+				for _, item := range epollItems(pid, int(epfd)) {
+					if item.data == breakAddr {
+						continue
+					}
+					add(item.data, item.fd)
+					if !seen[item.data] {
+						// Go 1.21 and later store a tagged pointer in the
+						// epoll data, see runtime/tagptr_64bit.go.
+						add(uint64(int64(item.data)>>19<<3), item.fd)
+					}
+				}
+			}
+		}
+	}
+
+	for _, pd := range r {
+		if live && pd.Unreadable == nil {
+			pd.Description = describeFd(pid, pd.Fd)
+		}
+	}
+
+	sort.SliceStable(r, func(i, j int) bool {
+		return r[i].Fd < r[j].Fd
+	})
+	return r, nil
+}
+
+// pollDescOfParkedG returns the address of the runtime.pollDesc that g is
+// parked on, or 0 if g isn't parked in the network poller.
+func pollDescOfParkedG(p *Target, g *G) uint64 {
+	frames, err := g.Stacktrace(pollWaitMaxDepth, 0)
+	if err != nil {
+		return 0
+	}
+	for i := range frames {
+		if frames[i].Current.Fn == nil {
+			continue
+		}
+		switch frames[i].Current.Fn.Name {
+		case "runtime.netpollblock", "runtime.poll_runtime_pollWait", "internal/poll.runtime_pollWait":
+		default:
+			continue
+		}
+		scope := FrameToScope(p.BinInfo(), p.Memory(), g, frames[i:]...)
+		pdv, err := scope.EvalVariable("pd", loadSingleValue)
+		if err != nil || pdv.Unreadable != nil || pdv.Kind != reflect.Ptr || len(pdv.Children) != 1 {
+			continue
+		}
+		return pdv.Children[0].Addr
+	}
+	return 0
+}
+
+// parsePollDesc reads the runtime.pollDesc struct pdv, gaddrs maps the
+// addresses of runtime.g structs to goroutine IDs.
+func parsePollDesc(pdv *Variable, gaddrs map[uint64]int) *PollDesc {
+	pd := &PollDesc{Addr: pdv.Addr}
+
+	loadInt := func(name string) (int64, bool) {
+		fv, err := pdv.structMember(name)
+		if err != nil {
+			return 0, false
+		}
+		if fv.Kind == reflect.Struct {
+			// Go 1.19 and later wrap some fields in sync/atomic types.
+			fv, err = fv.structMember("value")
+			if err != nil {
+				return 0, false
+			}
+		}
+		fv.loadValue(loadSingleValue)
+		if fv.Unreadable != nil || fv.Value == nil {
+			if fv.Unreadable != nil && pd.Unreadable == nil {
+				pd.Unreadable = fv.Unreadable
+			}
+			return 0, false
+		}
+		switch fv.Value.Kind() {
+		case constant.Bool:
+			if constant.BoolVal(fv.Value) {
+				return 1, true
+			}
+			return 0, true
+		default:
+			n, _ := constant.Int64Val(fv.Value)
+			if fv.Kind >= reflect.Uint && fv.Kind <= reflect.Uintptr {
+				u, _ := constant.Uint64Val(fv.Value)
+				n = int64(u)
+			}
+			return n, true
+		}
+	}
+
+	fd, ok := loadInt("fd")
+	if !ok {
+		if pd.Unreadable == nil {
+			pd.Unreadable = errors.New("could not read fd field of runtime.pollDesc")
+		}
+		return pd
+	}
+	pd.Fd = int(fd)
+
+	if closing, _ := loadInt("closing"); closing != 0 {
+		pd.Closing = true
+	}
+
+	waiter := func(name string) int {
+		g, _ := loadInt(name)
+		if g == 0 || g == pdReady || g == pdWait {
+			return 0
+		}
+		return gaddrs[uint64(g)]
+	}
+
+	pd.ReadGoroutineID = waiter("rg")
+	pd.WriteGoroutineID = waiter("wg")
+	pd.ReadDeadline, _ = loadInt("rd")
+	pd.WriteDeadline, _ = loadInt("wd")
+
+	return pd
+}
+
+type epollItem struct {
+	fd   int
+	data uint64
+}
+
+// epollItems returns the file descriptors registered with the epoll
+// instance epfd of process pid, as listed by /proc/<pid>/fdinfo/<epfd>.
+func epollItems(pid, epfd int) []epollItem {
+	buf, err := ioutil.ReadFile(fmt.Sprintf("/proc/%d/fdinfo/%d", pid, epfd))
+	if err != nil {
+		return nil
+	}
+	r := []epollItem{}
+	s := bufio.NewScanner(bytes.NewReader(buf))
+	for s.Scan() {
+		// tfd:        5 events:       19 data:     7f9b5c8a1e28  pos:0 ino:0 sdev:0
+		fields := strings.Fields(s.Text())
+		if len(fields) < 6 || fields[0] != "tfd:" || fields[4] != "data:" {
+			continue
+		}
+		fd, err1 := strconv.Atoi(fields[1])
+		data, err2 := strconv.ParseUint(fields[5], 16, 64)
+		if err1 != nil || err2 != nil {
+			continue
+		}
+		r = append(r, epollItem{fd, data})
+	}
+	return r
+}
+
+// describeFd returns a description of file descriptor fd of process pid.
+// Sockets are resolved to their endpoints using the tables in
+// /proc/<pid>/net, other file descriptors are described by the target of
+// the /proc/<pid>/fd/<fd> symlink.
+func describeFd(pid, fd int) string {
+	link, err := os.Readlink(fmt.Sprintf("/proc/%d/fd/%d", pid, fd))
+	if err != nil {
+		return ""
+	}
+	if !strings.HasPrefix(link, "socket:[") || !strings.HasSuffix(link, "]") {
+		return link
+	}
+	inode := link[len("socket:[") : len(link)-1]
+	for _, proto := range []string{"tcp", "tcp6", "udp", "udp6"} {
+		if d := findInetSocket(pid, proto, inode); d != "" {
+			return d
+		}
+	}
+	if d := findUnixSocket(pid, inode); d != "" {
+		return d
+	}
+	return link
+}
+
+// findInetSocket searches /proc/<pid>/net/<proto> for the socket with the
+// specified inode and returns a description of its endpoints.
+func findInetSocket(pid int, proto, inode string) string {
+	buf, err := ioutil.ReadFile(fmt.Sprintf("/proc/%d/net/%s", pid, proto))
+	if err != nil {
+		return ""
+	}
+	s := bufio.NewScanner(bytes.NewReader(buf))
+	for s.Scan() {
+		//  sl  local_address rem_address   st tx_queue rx_queue tr tm->when retrnsmt   uid  timeout inode
+		fields := strings.Fields(s.Text())
+		if len(fields) < 10 || fields[9] != inode {
+			continue
+		}
+		local, remote := parseProcNetAddr(fields[1]), parseProcNetAddr(fields[2])
+		name := strings.TrimSuffix(proto, "6")
+		if proto[:3] == "tcp" && fields[3] == "0A" {
+			return fmt.Sprintf("%s %s (listen)", name, local)
+		}
+		return fmt.Sprintf("%s %s -> %s", name, local, remote)
+	}
+	return ""
+}
+
+// parseProcNetAddr parses an address in the format used by /proc/net/tcp
+// and /proc/net/udp: a hexadecimal IP address, stored as a sequence of
+// 32bit words in host byte order, followed by a colon and a hexadecimal
+// port number.
+func parseProcNetAddr(s string) string {
+	colon := strings.Index(s, ":")
+	if colon < 0 {
+		return s
+	}
+	ipbuf, err := hex.DecodeString(s[:colon])
+	if err != nil || len(ipbuf)%4 != 0 {
+		return s
+	}
+	port, err := strconv.ParseUint(s[colon+1:], 16, 16)
+	if err != nil {
+		return s
+	}
+	ip := make(net.IP, len(ipbuf))
+	for i := 0; i < len(ipbuf); i += 4 {
+		binary.BigEndian.PutUint32(ip[i:], binary.LittleEndian.Uint32(ipbuf[i:]))
+	}
+	return net.JoinHostPort(ip.String(), strconv.Itoa(int(port)))
+}
+
+// findUnixSocket searches /proc/<pid>/net/unix for the socket with the
+// specified inode.
+func findUnixSocket(pid int, inode string) string {
+	buf, err := ioutil.ReadFile(fmt.Sprintf("/proc/%d/net/unix", pid))
+	if err != nil {
+		return ""
+	}
+	s := bufio.NewScanner(bytes.NewReader(buf))
+	for s.Scan() {
+		// Num       RefCount Protocol Flags    Type St Inode Path
+		fields := strings.Fields(s.Text())
+		if len(fields) < 7 || fields[6] != inode {
+			continue
+		}
+		if len(fields) >= 8 {
+			return "unix " + fields[7]
+		}
+		return "unix"
+	}
+	return ""
+}
@@ -0,0 +1,668 @@
+package proc
+
+import (
+	"bytes"
+	"debug/dwarf"
+	"debug/elf"
+	"debug/macho"
+	"debug/pe"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/go-delve/delve/pkg/dwarf/frame"
+	"github.com/go-delve/delve/pkg/dwarf/line"
+	"github.com/go-delve/delve/pkg/dwarf/util"
+)
+
+// This file implements loading functions, line tables and frame
+// descriptions from the gopclntab section, the table used by the Go
+// runtime to produce stack traces, which is present in every Go
+// executable, even when it was built without DWARF (for example with
+// -ldflags='-s -w').
+// The contents of gopclntab are converted to debug_line and debug_frame
+// sections, so that the rest of the debugger doesn't need to know where
+// they came from. Since gopclntab doesn't describe types or variables
+// those aren't available.
+
+// Magic numbers of the different versions of gopclntab, see
+// $GOROOT/src/debug/gosym/pclntab.go.
+const (
+	pclntab12Magic  = 0xfffffffb
+	pclntab116Magic = 0xfffffffa
+	pclntab118Magic = 0xfffffff0
+	pclntab120Magic = 0xfffffff1
+)
+
+// errNoDwarf is returned when trying to access the debug_info section of
+// an image that was loaded from gopclntab.
+var errNoDwarf = errors.New("variables unavailable: the executable has no DWARF debug info")
+
+var errBadPclntab = errors.New("malformed gopclntab section")
+
+// pclntab is a parsed gopclntab section.
+type pclntab struct {
+	data    []byte
+	magic   uint32
+	quantum uint64
+	ptrSize int
+	nfunc   int
+
+	// textStart is the address of the first function, used to compute
+	// entry points starting with Go 1.18.
+	textStart uint64
+
+	funcnametab, cutab, filetab, pctab, funcdata []byte
+}
+
+// pclntabFunc is a function described by gopclntab.
+type pclntabFunc struct {
+	name       string
+	entry, end uint64
+
+	// offsets of the pc-value tables inside pctab
+	pcsp, pcfile, pcln uint32
+	cuOffset           uint32
+}
+
+// parsePclntab parses the gopclntab section in data, textStart is the
+// address of the text section of the executable.
+func parsePclntab(data []byte, textStart uint64) (*pclntab, error) {
+	if len(data) < 8 || data[4] != 0 || data[5] != 0 {
+		return nil, errBadPclntab
+	}
+	t := &pclntab{data: data, magic: binary.LittleEndian.Uint32(data), quantum: uint64(data[6]), ptrSize: int(data[7])}
+	if t.ptrSize != 4 && t.ptrSize != 8 {
+		return nil, errBadPclntab
+	}
+	word := func(i int) (uint64, error) {
+		off := 8 + i*t.ptrSize
+		if off+t.ptrSize > len(data) {
+			return 0, errBadPclntab
+		}
+		return t.uintptr(data[off:]), nil
+	}
+	sub := func(i int) ([]byte, error) {
+		off, err := word(i)
+		if err != nil || off > uint64(len(data)) {
+			return nil, errBadPclntab
+		}
+		return data[off:], nil
+	}
+
+	nfunc, err := word(0)
+	if err != nil {
+		return nil, err
+	}
+	t.nfunc = int(nfunc)
+
+	switch t.magic {
+	case pclntab12Magic:
+		t.funcnametab = data
+		t.pctab = data
+		t.funcdata = data
+		ftab := data[8+t.ptrSize:]
+		off := (t.nfunc*2 + 1) * t.ptrSize
+		if off+4 > len(ftab) {
+			return nil, errBadPclntab
+		}
+		filetabOff := binary.LittleEndian.Uint32(ftab[off:])
+		if int(filetabOff) > len(data) {
+			return nil, errBadPclntab
+		}
+		t.filetab = data[filetabOff:]
+	case pclntab116Magic, pclntab118Magic, pclntab120Magic:
+		first := 2
+		if t.magic != pclntab116Magic {
+			// The text start address in the header is unrelocated, use the
+			// one we were given.
+			t.textStart = textStart
+			first = 3
+		}
+		for i, p := range []*[]byte{&t.funcnametab, &t.cutab, &t.filetab, &t.pctab, &t.funcdata} {
+			if *p, err = sub(first + i); err != nil {
+				return nil, err
+			}
+		}
+	default:
+		return nil, fmt.Errorf("unsupported gopclntab version %#x", t.magic)
+	}
+
+	if len(t.ftab()) < (t.nfunc+1)*t.ftabEntrySize() {
+		return nil, errBadPclntab
+	}
+	return t, nil
+}
+
+func (t *pclntab) uintptr(b []byte) uint64 {
+	if t.ptrSize == 4 {
+		return uint64(binary.LittleEndian.Uint32(b))
+	}
+	return binary.LittleEndian.Uint64(b)
+}
+
+// ftab returns the table of function entry points and offsets of their
+// _func structs.
+func (t *pclntab) ftab() []byte {
+	if t.magic == pclntab12Magic {
+		return t.data[8+t.ptrSize:]
+	}
+	return t.funcdata
+}
+
+func (t *pclntab) ftabEntrySize() int {
+	if t.magic == pclntab118Magic || t.magic == pclntab120Magic {
+		return 8
+	}
+	return 2 * t.ptrSize
+}
+
+// ftabEntry returns the entry point of the i-th function and the offset
+// of its _func struct.
+func (t *pclntab) ftabEntry(i int) (entry uint64, funcoff uint64) {
+	b := t.ftab()[i*t.ftabEntrySize():]
+	if t.ftabEntrySize() == 8 {
+		return t.textStart + uint64(binary.LittleEndian.Uint32(b)), uint64(binary.LittleEndian.Uint32(b[4:]))
+	}
+	return t.uintptr(b), t.uintptr(b[t.ptrSize:])
+}
+
+// cstring returns the zero terminated string at the start of b.
+func cstring(b []byte) string {
+	if i := bytes.IndexByte(b, 0); i >= 0 {
+		return string(b[:i])
+	}
+	return string(b)
+}
+
+// funcs returns the functions described by t, sorted by entry point.
+func (t *pclntab) funcs() ([]pclntabFunc, error) {
+	// fields of _func after the entry point, see $GOROOT/src/runtime/runtime2.go
+	const (
+		fieldNameoff  = 0
+		fieldPcsp     = 3
+		fieldPcfile   = 4
+		fieldPcln     = 5
+		fieldCuOffset = 7
+	)
+	fieldsStart := uint64(t.ptrSize)
+	if t.ftabEntrySize() == 8 {
+		fieldsStart = 4
+	}
+
+	r := make([]pclntabFunc, 0, t.nfunc)
+	for i := 0; i < t.nfunc; i++ {
+		entry, funcoff := t.ftabEntry(i)
+		end, _ := t.ftabEntry(i + 1)
+		if funcoff+fieldsStart+4*(fieldCuOffset+1) > uint64(len(t.funcdata)) {
+			return nil, errBadPclntab
+		}
+		fields := t.funcdata[funcoff+fieldsStart:]
+		field := func(n int) uint32 {
+			return binary.LittleEndian.Uint32(fields[4*n:])
+		}
+		fn := pclntabFunc{
+			entry:  entry,
+			end:    end,
+			pcsp:   field(fieldPcsp),
+			pcfile: field(fieldPcfile),
+			pcln:   field(fieldPcln),
+		}
+		if t.magic != pclntab12Magic {
+			fn.cuOffset = field(fieldCuOffset)
+		}
+		if nameoff := field(fieldNameoff); int(nameoff) < len(t.funcnametab) {
+			fn.name = cstring(t.funcnametab[nameoff:])
+		}
+		r = append(r, fn)
+	}
+	return r, nil
+}
+
+// fileName returns the name of the file with index idx in the pcfile
+// table of fn.
+func (t *pclntab) fileName(fn *pclntabFunc, idx int32) string {
+	if idx < 0 {
+		return ""
+	}
+	if t.magic == pclntab12Magic {
+		off := 4 * int(idx)
+		if off+4 > len(t.filetab) {
+			return ""
+		}
+		nameoff := binary.LittleEndian.Uint32(t.filetab[off:])
+		if int(nameoff) >= len(t.data) {
+			return ""
+		}
+		return cstring(t.data[nameoff:])
+	}
+	off := 4 * (int(fn.cuOffset) + int(idx))
+	if off+4 > len(t.cutab) {
+		return ""
+	}
+	nameoff := binary.LittleEndian.Uint32(t.cutab[off:])
+	if nameoff == ^uint32(0) || int(nameoff) >= len(t.filetab) {
+		return ""
+	}
+	return cstring(t.filetab[nameoff:])
+}
+
+// pcvalue calls fn for each entry of the pc-value table starting at off
+// in pctab, see $GOROOT/src/runtime/symtab.go.
+func (t *pclntab) pcvalue(off uint32, entry uint64, fn func(start, end uint64, val int32)) {
+	if off == 0 || int(off) >= len(t.pctab) {
+		return
+	}
+	p := t.pctab[off:]
+	pc := entry
+	val := int32(-1)
+	for first := true; ; first = false {
+		uvdelta, n := binary.Uvarint(p)
+		if n <= 0 || (uvdelta == 0 && !first) {
+			return
+		}
+		p = p[n:]
+		if uvdelta&1 != 0 {
+			uvdelta = ^(uvdelta >> 1)
+		} else {
+			uvdelta >>= 1
+		}
+		val += int32(uvdelta)
+		pcdelta, n := binary.Uvarint(p)
+		if n <= 0 {
+			return
+		}
+		p = p[n:]
+		end := pc + pcdelta*t.quantum
+		fn(pc, end, val)
+		pc = end
+	}
+}
+
+// pclntabRow is a row of the line table of a function.
+type pclntabRow struct {
+	pc   uint64
+	file string
+	line int
+}
+
+// lineTable returns the line table of fn, merging its pcfile and pcln
+// tables.
+func (t *pclntab) lineTable(fn *pclntabFunc) []pclntabRow {
+	type pcval struct {
+		start, end uint64
+		val        int32
+	}
+	var files, lines []pcval
+	t.pcvalue(fn.pcfile, fn.entry, func(start, end uint64, val int32) {
+		files = append(files, pcval{start, end, val})
+	})
+	t.pcvalue(fn.pcln, fn.entry, func(start, end uint64, val int32) {
+		lines = append(lines, pcval{start, end, val})
+	})
+
+	var r []pclntabRow
+	for i, j := 0, 0; i < len(files) && j < len(lines); {
+		pc := files[i].start
+		if lines[j].start > pc {
+			pc = lines[j].start
+		}
+		if lines[j].val >= 0 {
+			row := pclntabRow{pc: pc, file: t.fileName(fn, files[i].val), line: int(lines[j].val)}
+			if len(r) == 0 || r[len(r)-1].file != row.file || r[len(r)-1].line != row.line {
+				r = append(r, row)
+			}
+		}
+		switch {
+		case files[i].end < lines[j].end:
+			i++
+		case files[i].end > lines[j].end:
+			j++
+		default:
+			i++
+			j++
+		}
+	}
+	return r
+}
+
+// pclntabSection is the gopclntab section of an executable.
+type pclntabSection struct {
+	data []byte
+	// addr is the address of the section, textStart the address of the
+	// text section of the executable.
+	addr, textStart uint64
+	// dataSections are the address ranges of the initialized data
+	// sections of the executable, one of them contains runtime.moduledata.
+	dataSections [][2]uint64
+}
+
+// findPclntabElf returns the gopclntab section of exe.
+func findPclntabElf(exe *elf.File) (*pclntabSection, error) {
+	r := &pclntabSection{}
+	if text := exe.Section(".text"); text != nil {
+		r.textStart = text.Addr
+	}
+	for _, sec := range exe.Sections {
+		if sec.Type == elf.SHT_PROGBITS && sec.Flags&(elf.SHF_ALLOC|elf.SHF_WRITE) == elf.SHF_ALLOC|elf.SHF_WRITE {
+			r.dataSections = append(r.dataSections, [2]uint64{sec.Addr, sec.Addr + sec.Size})
+		}
+	}
+	for _, name := range []string{".gopclntab", ".data.rel.ro.gopclntab"} {
+		if sec := exe.Section(name); sec != nil {
+			var err error
+			r.data, err = sec.Data()
+			r.addr = sec.Addr
+			return r, err
+		}
+	}
+	return nil, errors.New("could not find .gopclntab section")
+}
+
+// findPclntabMacho returns the gopclntab section of exe.
+func findPclntabMacho(exe *macho.File) (*pclntabSection, error) {
+	const sZerofill = 0x1 // section type of sections without contents, like __bss
+	r := &pclntabSection{}
+	if text := exe.Section("__text"); text != nil {
+		r.textStart = text.Addr
+	}
+	for _, sec := range exe.Sections {
+		if (sec.Seg == "__DATA" || sec.Seg == "__DATA_CONST") && sec.Flags&0xff != sZerofill {
+			r.dataSections = append(r.dataSections, [2]uint64{sec.Addr, sec.Addr + sec.Size})
+		}
+	}
+	if sec := exe.Section("__gopclntab"); sec != nil {
+		var err error
+		r.data, err = sec.Data()
+		r.addr = sec.Addr
+		return r, err
+	}
+	return nil, errors.New("could not find __gopclntab section")
+}
+
+// findPclntabPE returns gopclntab, which on windows is not a separate
+// section.
+func findPclntabPE(exe *pe.File, imageBase uint64) (*pclntabSection, error) {
+	r := &pclntabSection{}
+	if text := exe.Section(".text"); text != nil {
+		r.textStart = imageBase + uint64(text.VirtualAddress)
+	}
+	if data := exe.Section(".data"); data != nil {
+		r.dataSections = append(r.dataSections, [2]uint64{imageBase + uint64(data.VirtualAddress), imageBase + uint64(data.VirtualAddress) + uint64(data.Size)})
+	}
+	start, err := findPESymbol(exe, "runtime.pclntab")
+	if err != nil {
+		return nil, err
+	}
+	end, err := findPESymbol(exe, "runtime.epclntab")
+	if err != nil {
+		return nil, err
+	}
+	if start.SectionNumber != end.SectionNumber || start.Value > end.Value {
+		return nil, errBadPclntab
+	}
+	sec := exe.Sections[start.SectionNumber-1]
+	data, err := sec.Data()
+	if err != nil {
+		return nil, err
+	}
+	if uint64(end.Value) > uint64(len(data)) {
+		return nil, errBadPclntab
+	}
+	r.data = data[start.Value:end.Value]
+	r.addr = imageBase + uint64(sec.VirtualAddress) + uint64(start.Value)
+	return r, nil
+}
+
+// loadPclntab loads the functions, source files, line tables and frame
+// descriptions of image from its gopclntab section.
+// Functions are grouped into one compile unit per package.
+func (bi *BinaryInfo) loadPclntab(image *Image, sec *pclntabSection) error {
+	t, err := parsePclntab(sec.data, sec.textStart)
+	if err != nil {
+		return err
+	}
+	if t.ptrSize != bi.Arch.PtrSize() {
+		return errBadPclntab
+	}
+	image.pclntabAddr = sec.addr + image.StaticBase
+	image.pclntabMagic = t.magic
+	for _, r := range sec.dataSections {
+		image.dataSections = append(image.dataSections, [2]uint64{r[0] + image.StaticBase, r[1] + image.StaticBase})
+	}
+	fns, err := t.funcs()
+	if err != nil {
+		return err
+	}
+
+	if bi.types == nil {
+		bi.types = make(map[string]dwarfRef)
+	}
+	if bi.consts == nil {
+		bi.consts = make(map[dwarfRef]*constantType)
+	}
+	if bi.PackageMap == nil {
+		bi.PackageMap = make(map[string][]string)
+	}
+	if bi.inlinedCallLines == nil {
+		bi.inlinedCallLines = make(map[fileLine][]uint64)
+	}
+	image.runtimeTypeToDIE = make(map[uint64]runtimeTypeDIE)
+
+	var pkgs []string
+	pkgFuncs := make(map[string][]*pclntabFunc)
+	for i := range fns {
+		pkg := packageName(fns[i].name)
+		if pkgFuncs[pkg] == nil {
+			pkgs = append(pkgs, pkg)
+		}
+		pkgFuncs[pkg] = append(pkgFuncs[pkg], &fns[i])
+	}
+
+	for _, pkg := range pkgs {
+		cu := &compileUnit{
+			name:   pkg,
+			isgo:   true,
+			offset: dwarf.Offset(len(image.compileUnits)),
+			image:  image,
+		}
+		lw := newPclntabLineWriter(t.ptrSize)
+		for _, fn := range pkgFuncs[pkg] {
+			cu.ranges = append(cu.ranges, [2]uint64{fn.entry + image.StaticBase, fn.end + image.StaticBase})
+			lw.addSequence(fn.entry, fn.end, t.lineTable(fn))
+			bi.Functions = append(bi.Functions, Function{Name: fn.name, Entry: fn.entry + image.StaticBase, End: fn.end + image.StaticBase, cu: cu})
+		}
+		cu.lowPC = cu.ranges[0][0]
+		cu.lineInfo = line.Parse("", bytes.NewBuffer(lw.bytes()), nil, nil, image.StaticBase, bi.GOOS == "windows", t.ptrSize)
+		for _, fileEntry := range cu.lineInfo.FileNames {
+			bi.Sources = append(bi.Sources, fileEntry.Path)
+		}
+		image.compileUnits = append(image.compileUnits, cu)
+	}
+
+	bi.frameEntries = bi.frameEntries.Append(frame.Parse(bi.pclntabFrame(t, fns), binary.LittleEndian, image.StaticBase, t.ptrSize))
+
+	sort.Sort(functionsDebugInfoByEntry(bi.Functions))
+	bi.LookupFunc = make(map[string]*Function)
+	for i := range bi.Functions {
+		bi.LookupFunc[bi.Functions[i].Name] = &bi.Functions[i]
+	}
+	sort.Strings(bi.Sources)
+	bi.Sources = uniq(bi.Sources)
+	return nil
+}
+
+// pclntabLineWriter writes a debug_line program containing one sequence
+// for each function of a compile unit.
+type pclntabLineWriter struct {
+	ptrSize int
+	files   map[string]uint64
+	names   []string
+	prog    bytes.Buffer
+}
+
+func newPclntabLineWriter(ptrSize int) *pclntabLineWriter {
+	return &pclntabLineWriter{ptrSize: ptrSize, files: make(map[string]uint64)}
+}
+
+func (lw *pclntabLineWriter) fileIndex(name string) uint64 {
+	idx, ok := lw.files[name]
+	if !ok {
+		lw.names = append(lw.names, name)
+		idx = uint64(len(lw.names))
+		lw.files[name] = idx
+	}
+	return idx
+}
+
+// addSequence adds a sequence for the function [entry, end) with the
+// specified line table.
+func (lw *pclntabLineWriter) addSequence(entry, end uint64, rows []pclntabRow) {
+	if len(rows) == 0 {
+		return
+	}
+	p := &lw.prog
+	p.WriteByte(0)
+	util.EncodeULEB128(p, uint64(1+lw.ptrSize))
+	p.WriteByte(line.DW_LINE_set_address)
+	util.WriteUint(p, binary.LittleEndian, lw.ptrSize, entry)
+
+	// initial state of the line number state machine
+	pc, file, ln := entry, uint64(1), 1
+	for _, row := range rows {
+		if idx := lw.fileIndex(row.file); idx != file {
+			p.WriteByte(line.DW_LNS_set_file)
+			util.EncodeULEB128(p, idx)
+			file = idx
+		}
+		if row.line != ln {
+			p.WriteByte(line.DW_LNS_advance_line)
+			util.EncodeSLEB128(p, int64(row.line-ln))
+			ln = row.line
+		}
+		if row.pc != pc {
+			p.WriteByte(line.DW_LNS_advance_pc)
+			util.EncodeULEB128(p, row.pc-pc)
+			pc = row.pc
+		}
+		p.WriteByte(line.DW_LNS_copy)
+	}
+	if end > pc {
+		p.WriteByte(line.DW_LNS_advance_pc)
+		util.EncodeULEB128(p, end-pc)
+	}
+	p.WriteByte(0)
+	util.EncodeULEB128(p, 1)
+	p.WriteByte(line.DW_LINE_end_sequence)
+}
+
+// bytes returns the debug_line unit, in DWARF version 2 format.
+func (lw *pclntabLineWriter) bytes() []byte {
+	var hdr bytes.Buffer
+	hdr.WriteByte(1)                             // minimum_instruction_length
+	hdr.WriteByte(1)                             // default_is_stmt
+	hdr.WriteByte(0xfb)                          // line_base (-5)
+	hdr.WriteByte(14)                            // line_range
+	hdr.WriteByte(10)                            // opcode_base
+	hdr.Write([]byte{0, 1, 1, 1, 1, 0, 0, 0, 1}) // standard_opcode_lengths
+	hdr.WriteByte(0)                             // include_directories
+	for _, name := range lw.names {
+		hdr.WriteString(name)
+		hdr.Write([]byte{0, 0, 0, 0}) // directory, modification time, length
+	}
+	hdr.WriteByte(0)
+
+	var out bytes.Buffer
+	binary.Write(&out, binary.LittleEndian, uint32(2+4+hdr.Len()+lw.prog.Len())) // unit_length
+	binary.Write(&out, binary.LittleEndian, uint16(2))                           // version
+	binary.Write(&out, binary.LittleEndian, uint32(hdr.Len()))                   // header_length
+	out.Write(hdr.Bytes())
+	out.Write(lw.prog.Bytes())
+	return out.Bytes()
+}
+
+// pclntabDataAlignment is the data alignment factor used by the frame
+// descriptions generated by pclntabFrame.
+const pclntabDataAlignment = -4
+
+// pclntabFrame returns a debug_frame section describing how to unwind
+// the stack of fns, using the SP deltas from their pcsp tables, it is
+// equivalent to the one generated by the Go linker.
+func (bi *BinaryInfo) pclntabFrame(t *pclntab, fns []pclntabFunc) []byte {
+	var spReg, raReg uint64
+	switch bi.Arch.Name {
+	case "amd64":
+		spReg, raReg = amd64DwarfSPRegNum, amd64DwarfIPRegNum
+	case "386":
+		spReg, raReg = i386DwarfSPRegNum, i386DwarfIPRegNum
+	case "arm64":
+		spReg, raReg = arm64DwarfSPRegNum, arm64DwarfLRRegNum
+	}
+	ptrSize := int64(t.ptrSize)
+
+	var out bytes.Buffer
+	writeEntry := func(id uint32, body []byte) {
+		for (len(body)+4)%t.ptrSize != 0 {
+			body = append(body, frame.DW_CFA_nop)
+		}
+		binary.Write(&out, binary.LittleEndian, uint32(4+len(body)))
+		binary.Write(&out, binary.LittleEndian, id)
+		out.Write(body)
+	}
+
+	var cie bytes.Buffer
+	cie.WriteByte(3) // version
+	cie.WriteByte(0) // augmentation
+	util.EncodeULEB128(&cie, 1)
+	util.EncodeSLEB128(&cie, pclntabDataAlignment)
+	util.EncodeULEB128(&cie, raReg)
+	cie.WriteByte(frame.DW_CFA_def_cfa)
+	util.EncodeULEB128(&cie, spReg)
+	if bi.Arch.usesLR {
+		util.EncodeULEB128(&cie, 0)
+		cie.WriteByte(frame.DW_CFA_same_value)
+		util.EncodeULEB128(&cie, raReg)
+	} else {
+		// the return address is at the top of the stack on entry
+		util.EncodeULEB128(&cie, uint64(ptrSize))
+		cie.WriteByte(frame.DW_CFA_offset_extended)
+		util.EncodeULEB128(&cie, raReg)
+		util.EncodeULEB128(&cie, uint64(ptrSize/-pclntabDataAlignment))
+	}
+	cie.WriteByte(frame.DW_CFA_val_offset)
+	util.EncodeULEB128(&cie, spReg)
+	util.EncodeULEB128(&cie, 0)
+	writeEntry(0xffffffff, cie.Bytes())
+
+	for i := range fns {
+		fn := &fns[i]
+		var fde bytes.Buffer
+		util.WriteUint(&fde, binary.LittleEndian, t.ptrSize, fn.entry)
+		util.WriteUint(&fde, binary.LittleEndian, t.ptrSize, fn.end-fn.entry)
+		loc := fn.entry
+		t.pcvalue(fn.pcsp, fn.entry, func(start, end uint64, spdelta int32) {
+			if start != loc {
+				fde.WriteByte(frame.DW_CFA_advance_loc4)
+				binary.Write(&fde, binary.LittleEndian, uint32(start-loc))
+				loc = start
+			}
+			cfa := int64(spdelta)
+			if !bi.Arch.usesLR {
+				cfa += ptrSize
+			} else if spdelta > 0 {
+				// the link register is saved at the top of the frame
+				fde.WriteByte(frame.DW_CFA_offset_extended_sf)
+				util.EncodeULEB128(&fde, raReg)
+				util.EncodeSLEB128(&fde, -int64(spdelta)/pclntabDataAlignment)
+			} else {
+				fde.WriteByte(frame.DW_CFA_same_value)
+				util.EncodeULEB128(&fde, raReg)
+			}
+			fde.WriteByte(frame.DW_CFA_def_cfa_offset_sf)
+			util.EncodeSLEB128(&fde, cfa/pclntabDataAlignment)
+		})
+		writeEntry(0, fde.Bytes())
+	}
+	return out.Bytes()
+}
@@ -1,6 +1,12 @@
 package proc
 
 import (
+	"debug/elf"
+	"errors"
+	"go/constant"
+	"io/ioutil"
+	"os"
+	"os/exec"
 	"path/filepath"
 	"runtime"
 	"testing"
@@ -118,3 +124,277 @@ func TestDwarfVersion(t *testing.T) {
 		}
 	}
 }
+
+func TestParseProcNetAddr(t *testing.T) {
+	tests := []struct {
+		in, out string
+	}{
+		{"0100007F:1F90", "127.0.0.1:8080"},
+		{"00000000:0016", "0.0.0.0:22"},
+		{"00000000000000000000000001000000:0277", "[::1]:631"},
+		{"B80D0120000000000000000001000000:01BB", "[2001:db8::1]:443"},
+	}
+	for _, tc := range tests {
+		if out := parseProcNetAddr(tc.in); out != tc.out {
+			t.Errorf("parseProcNetAddr(%q): got %q expected %q", tc.in, out, tc.out)
+		}
+	}
+}
+
+// elfFileMem reads memory from the loadable segments of an ELF file.
+type elfFileMem struct {
+	f *elf.File
+}
+
+func (m elfFileMem) ReadMemory(buf []byte, addr uint64) (int, error) {
+	for _, prog := range m.f.Progs {
+		if prog.Type != elf.PT_LOAD || addr < prog.Vaddr || addr+uint64(len(buf)) > prog.Vaddr+prog.Memsz {
+			continue
+		}
+		// the part of the segment past Filesz is zero initialized (.bss)
+		for i := range buf {
+			buf[i] = 0
+		}
+		off := addr - prog.Vaddr
+		if off < prog.Filesz {
+			n := uint64(len(buf))
+			if off+n > prog.Filesz {
+				n = prog.Filesz - off
+			}
+			if _, err := prog.ReadAt(buf[:n], int64(off)); err != nil {
+				return 0, err
+			}
+		}
+		return len(buf), nil
+	}
+	return 0, errors.New("address not mapped")
+}
+
+func (m elfFileMem) WriteMemory(uint64, []byte) (int, error) {
+	panic("not supported")
+}
+
+func TestSplitDwarf(t *testing.T) {
+	// Tests that split compile units are loaded from .dwo files, from the
+	// debug info directories and from .dwp packages, for DWARF 5 and for the
+	// GNU extension to DWARF 4.
+	if runtime.GOOS != "linux" {
+		t.Skip("split DWARF test only supported on linux")
+	}
+	if _, err := exec.LookPath("gcc"); err != nil {
+		t.Skip("gcc not installed")
+	}
+
+	fixtureDir, err := filepath.Abs(filepath.Join(protest.FindFixturesDir(), "splitdwarf"))
+	assertNoError(err, t, "Abs")
+	tmpdir, err := ioutil.TempDir("", "splitdwarf")
+	assertNoError(err, t, "TempDir")
+	defer os.RemoveAll(tmpdir)
+
+	run := func(t *testing.T, dir, name string, args ...string) {
+		cmd := exec.Command(name, args...)
+		cmd.Dir = dir
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Skipf("%s %v: %v\n%s", name, args, err, out)
+		}
+	}
+
+	for _, version := range []string{"4", "5"} {
+		name := "dwarf" + version
+		t.Run(name, func(t *testing.T) {
+			builddir := filepath.Join(tmpdir, name, "build")
+			assertNoError(os.MkdirAll(builddir, 0755), t, "MkdirAll")
+			run(t, builddir, "gcc", "-g", "-O2", "-gdwarf-"+version, "-gsplit-dwarf", "-c", filepath.Join(fixtureDir, "main.c"), filepath.Join(fixtureDir, "sum.c"))
+			exe := filepath.Join(tmpdir, name, "splitdwarf")
+			run(t, builddir, "gcc", "-o", exe, "main.o", "sum.o")
+
+			check := func(t *testing.T, debugInfoDirs []string, splitPath string) {
+				ef, err := elf.Open(exe)
+				assertNoError(err, t, "elf.Open")
+				defer ef.Close()
+
+				bi := NewBinaryInfo(runtime.GOOS, runtime.GOARCH)
+				assertNoError(bi.LoadBinaryInfo(exe, ef.Entry, debugInfoDirs), t, "LoadBinaryInfo")
+				defer bi.Close()
+
+				syms, err := ef.Symbols()
+				assertNoError(err, t, "Symbols")
+				symaddr := map[string]uint64{}
+				for _, sym := range syms {
+					symaddr[sym.Name] = sym.Value
+				}
+
+				assertNoError(bi.Images[0].LoadError(), t, "LoadError")
+
+				for _, name := range []string{"main", "sum"} {
+					fn := bi.LookupFunc["C."+name]
+					if fn == nil {
+						t.Fatalf("function %s not found", name)
+					}
+					if fn.Entry != symaddr[name] {
+						t.Errorf("wrong entry point for %s: %#x (expected %#x)", name, fn.Entry, symaddr[name])
+					}
+					if !fn.cu.image.IsSplitDwarf() || fn.cu.image.Path != splitPath && filepath.Base(fn.cu.image.Path) != name+".dwo" {
+						t.Errorf("function %s loaded from unexpected image %q", name, fn.cu.image.Path)
+					}
+					if filepath.Base(fn.cu.name) != map[string]string{"main": "main.c", "sum": "sum.c"}[name] {
+						t.Errorf("wrong compile unit name for %s: %q", name, fn.cu.name)
+					}
+				}
+
+				pcs, err := bi.LineToPC(filepath.Join(fixtureDir, "sum.c"), 5)
+				assertNoError(err, t, "LineToPC")
+				if fn := bi.PCToFunc(pcs[0]); fn == nil || fn.Name != "C.sum" {
+					t.Errorf("wrong function for sum.c:5 %#x: %v", pcs[0], fn)
+				}
+
+				for _, image := range bi.Images[1:] {
+					if !image.IsSplitDwarf() {
+						t.Errorf("unexpected image %q", image.Path)
+					}
+				}
+
+				scope := globalScope(bi, bi.Images[0], elfFileMem{ef})
+				cfg := LoadConfig{FollowPointers: true, MaxVariableRecurse: 1, MaxStringLen: 64, MaxArrayValues: 64, MaxStructFields: -1}
+				for _, tc := range []struct {
+					expr, typ string
+					addr      uint64
+					value     int64
+				}{
+					{"C.origin.x", "int", symaddr["origin"], 1},
+					{"C.origin.y", "int", symaddr["origin"] + 4, 2},
+					{"C.counter", "int", symaddr["counter"], 0},
+					{"C.sum_calls", "int", symaddr["sum_calls"], 0},
+				} {
+					v, err := scope.EvalExpression(tc.expr, cfg)
+					assertNoError(err, t, "EvalExpression("+tc.expr+")")
+					if v.Unreadable != nil {
+						t.Errorf("%s unreadable: %v", tc.expr, v.Unreadable)
+						continue
+					}
+					if v.Addr != tc.addr {
+						t.Errorf("wrong address for %s: %#x (expected %#x)", tc.expr, v.Addr, tc.addr)
+					}
+					if typ := v.TypeString(); typ != tc.typ {
+						t.Errorf("wrong type for %s: %q (expected %q)", tc.expr, typ, tc.typ)
+					}
+					if n, _ := constant.Int64Val(v.Value); n != tc.value {
+						t.Errorf("wrong value for %s: %v (expected %d)", tc.expr, v.Value, tc.value)
+					}
+				}
+
+				v, err := scope.EvalExpression("C.origin", cfg)
+				assertNoError(err, t, "EvalExpression(C.origin)")
+				if typ := v.TypeString(); typ != "point" || len(v.Children) != 2 {
+					t.Errorf("wrong type for C.origin: %q %d", typ, len(v.Children))
+				}
+			}
+
+			t.Run("dwo", func(t *testing.T) {
+				check(t, nil, "")
+			})
+
+			t.Run("debug-info-directories", func(t *testing.T) {
+				debugdir := filepath.Join(tmpdir, name, "debug")
+				assertNoError(os.Mkdir(debugdir, 0755), t, "Mkdir")
+				for _, dwo := range []string{"main.dwo", "sum.dwo"} {
+					assertNoError(os.Rename(filepath.Join(builddir, dwo), filepath.Join(debugdir, dwo)), t, "Rename")
+				}
+				check(t, []string{debugdir}, "")
+			})
+
+			t.Run("dwp", func(t *testing.T) {
+				dwp := "llvm-dwp"
+				if _, err := exec.LookPath(dwp); err != nil {
+					t.Skip("llvm-dwp not installed")
+				}
+				run(t, filepath.Join(tmpdir, name, "debug"), dwp, "-o", exe+".dwp", "main.dwo", "sum.dwo")
+				assertNoError(os.RemoveAll(filepath.Join(tmpdir, name, "debug")), t, "RemoveAll")
+				check(t, nil, exe+".dwp")
+			})
+		})
+	}
+}
+
+func TestMemoryWrittenReadOnly(t *testing.T) {
+	// Instructions that only read their memory operand do not write memory.
+	bi := NewBinaryInfo("linux", "amd64")
+	for _, tc := range []struct {
+		text string
+		mem  []byte
+	}{
+		{"div qword ptr [rax]", []byte{0x48, 0xf7, 0x30}},
+		{"idiv dword ptr [rax]", []byte{0xf7, 0x38}},
+		{"mul byte ptr [rax]", []byte{0xf6, 0x20}},
+		{"imul qword ptr [rax]", []byte{0x48, 0xf7, 0x28}},
+		{"cmp qword ptr [rax], rbx", []byte{0x48, 0x39, 0x18}},
+	} {
+		var inst AsmInstruction
+		assertNoError(x86AsmDecode(&inst, tc.mem, nil, nil, bi, 64), t, "x86AsmDecode")
+		if text := inst.Text(IntelFlavour, bi); text != tc.text {
+			t.Fatalf("decoded %q, expected %q", text, tc.text)
+		}
+		if _, sz := memoryWritten(&inst, nil, bi); sz != 0 {
+			t.Errorf("%s reported as writing %d bytes", tc.text, sz)
+		}
+	}
+}
+
+func TestControlFlowGraph(t *testing.T) {
+	inst := func(pc uint64, kind AsmInstructionKind, dest uint64) AsmInstruction {
+		r := AsmInstruction{Loc: Location{PC: pc}, Size: 1, Kind: kind}
+		if dest != 0 {
+			r.DestLoc = &Location{PC: dest}
+		}
+		return r
+	}
+	// 0x10: cmp
+	// 0x11: jne 0x15
+	// 0x12: call
+	// 0x13: mov
+	// 0x14: jmp 0x16
+	// 0x15: mov
+	// 0x16: ret
+	// 0x17: jmp 0x100
+	text := []AsmInstruction{
+		inst(0x10, OtherInstruction, 0),
+		inst(0x11, CondJmpInstruction, 0x15),
+		inst(0x12, CallInstruction, 0x100),
+		inst(0x13, OtherInstruction, 0),
+		inst(0x14, JmpInstruction, 0x16),
+		inst(0x15, OtherInstruction, 0),
+		inst(0x16, RetInstruction, 0),
+		inst(0x17, JmpInstruction, 0x100),
+	}
+	tgt := []struct {
+		start, end uint64
+		succs      []BasicBlockEdge
+	}{
+		{0x10, 0x11, []BasicBlockEdge{{To: 3, Kind: BranchEdge}, {To: 1, Kind: FallthroughEdge}}},
+		{0x12, 0x12, []BasicBlockEdge{{To: 2, Kind: FallthroughEdge}}},
+		{0x13, 0x14, []BasicBlockEdge{{To: 4, Kind: JumpEdge}}},
+		{0x15, 0x15, []BasicBlockEdge{{To: 4, Kind: FallthroughEdge}}},
+		{0x16, 0x16, nil},
+		{0x17, 0x17, nil},
+	}
+	blocks := ControlFlowGraph(text)
+	if len(blocks) != len(tgt) {
+		t.Fatalf("wrong number of blocks %d, expected %d", len(blocks), len(tgt))
+	}
+	for i := range blocks {
+		insts := blocks[i].Insts
+		if start, end := insts[0].Loc.PC, insts[len(insts)-1].Loc.PC; start != tgt[i].start || end != tgt[i].end {
+			t.Errorf("block %d: wrong range %#x-%#x, expected %#x-%#x", i, start, end, tgt[i].start, tgt[i].end)
+		}
+		if len(blocks[i].Succs) != len(tgt[i].succs) {
+			t.Errorf("block %d: wrong successors %v, expected %v", i, blocks[i].Succs, tgt[i].succs)
+			continue
+		}
+		for j := range blocks[i].Succs {
+			if blocks[i].Succs[j] != tgt[i].succs[j] {
+				t.Errorf("block %d: wrong successors %v, expected %v", i, blocks[i].Succs, tgt[i].succs)
+				break
+			}
+		}
+	}
+}
@@ -1872,7 +1872,7 @@ func TestPackageVariables(t *testing.T) {
 			if skippedVariable[v.Name] {
 				continue
 			}
-			if v.Unreadable != nil && v.Unreadable.Error() != "no location attribute Location" {
+			if v.Unreadable != nil && v.Unreadable.Error() != "optimized out" {
 				failed = true
 				t.Logf("Unreadable variable %s: %v", v.Name, v.Unreadable)
 			}
@@ -3154,17 +3154,118 @@ func TestAttachStripped(t *testing.T) {
 		t.Fatalf("unknown backend %q", testBackend)
 	}
 
-	t.Logf("error is %v", err)
-
-	if err == nil {
-		p.Detach(true)
-		t.Fatalf("expected error after attach, got nothing")
-	} else {
+	if err != nil {
 		cmd.Process.Kill()
+		t.Fatalf("attach: %v", err)
+	}
+	if p.BinInfo().LookupFunc["main.main"] == nil {
+		t.Errorf("could not find main.main in stripped executable")
 	}
+	p.Detach(true)
 	os.Remove(fixture.Path)
 }
 
+func TestStrippedBinary(t *testing.T) {
+	// Functions, line tables and stack traces of executables without DWARF
+	// are loaded from gopclntab.
+	if runtime.GOOS == "darwin" {
+		t.Skip("-s does not produce stripped executables on macOS")
+	}
+	withTestProcessArgs("testnextprog", t, ".", []string{}, protest.LinkStrip, func(p *proc.Target, fixture protest.Fixture) {
+		if p.BinInfo().LookupFunc["main.helloworld"] == nil {
+			t.Fatal("could not find main.helloworld")
+		}
+		setFileBreakpoint(p, t, fixture.Source, 14)
+		assertNoError(p.Continue(), t, "Continue")
+		assertLineNumber(p, t, 14, "wrong line after continue")
+
+		frames, err := proc.ThreadStacktrace(p.CurrentThread(), 10)
+		assertNoError(err, t, "ThreadStacktrace")
+		tgt := []string{"main.helloworld", "main.testnext", "main.main"}
+		for i, name := range tgt {
+			if i >= len(frames) || frames[i].Call.Fn == nil || frames[i].Call.Fn.Name != name {
+				t.Fatalf("wrong stack trace, expected %v got %v", tgt, frames)
+			}
+		}
+
+		// Goroutines are read using the type descriptor of runtime.g and
+		// runtime.moduledata.
+		gs, _, err := proc.GoroutinesInfo(p, 0, 0)
+		assertNoError(err, t, "GoroutinesInfo")
+		var maing *proc.G
+		for _, g := range gs {
+			if g.ID == 1 {
+				maing = g
+			}
+		}
+		if maing == nil {
+			t.Fatalf("could not find main goroutine in %d goroutines", len(gs))
+		}
+		if loc := maing.UserCurrent(); loc.Fn == nil || loc.Fn.Name != "main.helloworld" {
+			t.Errorf("wrong location of main goroutine %#v", loc)
+		}
+		if loc := maing.StartLoc(); loc.Fn == nil || loc.Fn.Name != "runtime.main" {
+			t.Errorf("wrong start location of main goroutine %#v", loc)
+		}
+		if selg := p.SelectedGoroutine(); selg == nil || selg.ID != 1 {
+			t.Errorf("wrong selected goroutine %v", selg)
+		}
+
+		scope, err := proc.ThreadScope(p.CurrentThread())
+		assertNoError(err, t, "ThreadScope")
+		if _, err := scope.Locals(); err == nil {
+			t.Errorf("expected error reading local variables of stripped executable")
+		}
+	})
+}
+
+func TestFramePointerStacktrace(t *testing.T) {
+	// Stacktraces computed by following the frame pointer should match the
+	// ones computed using the frame descriptor entries.
+	if runtime.GOARCH != "amd64" {
+		t.Skip("frame pointers are only guaranteed on amd64")
+	}
+	withTestProcessArgs("testnextprog", t, ".", []string{}, 0, func(p *proc.Target, fixture protest.Fixture) {
+		setFileBreakpoint(p, t, fixture.Source, 14)
+		assertNoError(p.Continue(), t, "Continue")
+
+		frames, err := proc.ThreadStacktraceWithOptions(p.CurrentThread(), 10, 0)
+		assertNoError(err, t, "ThreadStacktrace")
+		fpframes, err := proc.ThreadStacktraceWithOptions(p.CurrentThread(), 10, proc.StacktraceFramePointer)
+		assertNoError(err, t, "ThreadStacktrace(StacktraceFramePointer)")
+
+		tgt := []string{"main.helloworld", "main.testnext", "main.main"}
+		for i, name := range tgt {
+			for _, trace := range [][]proc.Stackframe{frames, fpframes} {
+				if i >= len(trace) || trace[i].Call.Fn == nil || trace[i].Call.Fn.Name != name {
+					logStacktrace(t, p.BinInfo(), trace)
+					t.Fatalf("wrong stack trace, expected %v", tgt)
+				}
+			}
+			if i == 0 {
+				continue
+			}
+			if frames[i].Unwinder != proc.UnwinderDwarf || fpframes[i].Unwinder != proc.UnwinderFramePointer {
+				t.Errorf("frame %d: wrong unwinders %v %v", i, frames[i].Unwinder, fpframes[i].Unwinder)
+			}
+			if frames[i].Regs.CFA != fpframes[i].Regs.CFA {
+				t.Errorf("frame %d: CFA mismatch %#x %#x", i, frames[i].Regs.CFA, fpframes[i].Regs.CFA)
+			}
+		}
+		if frames[0].Unwinder != proc.UnwinderNone || fpframes[0].Unwinder != proc.UnwinderNone {
+			t.Errorf("topmost frame: wrong unwinders %v %v", frames[0].Unwinder, fpframes[0].Unwinder)
+		}
+		// None of the frames above are signal handler frames, all of them
+		// must have been unwound using the frame descriptor entries.
+		for i := 1; i < len(frames); i++ {
+			if frames[i].Unwinder != proc.UnwinderDwarf {
+				logStacktrace(t, p.BinInfo(), frames)
+				t.Errorf("frame %d: expected %v unwinder, got %v", i, proc.UnwinderDwarf, frames[i].Unwinder)
+			}
+		}
+	})
+}
+
 func TestIssue844(t *testing.T) {
 	// Conditional breakpoints should not prevent next from working if their
 	// condition isn't met.
@@ -4519,6 +4620,51 @@ func TestCgoStacktrace2(t *testing.T) {
 	})
 }
 
+func TestCgoEhFrameStacktrace(t *testing.T) {
+	skipUnlessOn(t, "linux/amd64 only", "linux", "amd64")
+	protest.MustHaveCgo(t)
+	// C functions have no .debug_frame entries, they should be unwound using
+	// the .eh_frame section.
+	withTestProcess("cgosignalstack", t, func(p *proc.Target, fixture protest.Fixture) {
+		setFunctionBreakpoint(p, t, "C.trap")
+		assertNoError(p.Continue(), t, "Continue")
+		frames, err := proc.ThreadStacktrace(p.CurrentThread(), 100)
+		assertNoError(err, t, "Stacktrace()")
+		logStacktrace(t, p.BinInfo(), frames)
+		m := stacktraceCheck(t, []string{"C.trap", "C.testfn", "main.main"}, frames)
+		if m == nil {
+			t.Fatal("see previous loglines")
+		}
+		if frames[m[1]].Unwinder != proc.UnwinderDwarf {
+			t.Errorf("C.testfn was not unwound using .eh_frame: %v", frames[m[1]].Unwinder)
+		}
+	})
+}
+
+func TestCgoSignalHandlerStacktrace(t *testing.T) {
+	skipUnlessOn(t, "linux/amd64 only", "linux", "amd64")
+	protest.MustHaveCgo(t)
+	// The stacktrace of a signal handler should continue with the frame
+	// interrupted by the signal, the frame of the signal trampoline is marked
+	// by the 'S' augmentation of .eh_frame.
+	withTestProcess("cgosignalstack", t, func(p *proc.Target, fixture protest.Fixture) {
+		setFunctionBreakpoint(p, t, "C.sighandler")
+		assertNoError(p.Continue(), t, "Continue")
+		frames, err := proc.ThreadStacktrace(p.CurrentThread(), 100)
+		assertNoError(err, t, "Stacktrace()")
+		logStacktrace(t, p.BinInfo(), frames)
+		m := stacktraceCheck(t, []string{"C.sighandler", "C.trap", "C.testfn", "main.main"}, frames)
+		if m == nil {
+			t.Fatal("see previous loglines")
+		}
+		// the program counter of the interrupted frame is the instruction that
+		// raised the signal, not a return address
+		if trap := frames[m[1]]; trap.Call.Line != 21 {
+			t.Errorf("wrong line for interrupted frame C.trap: %s:%d", trap.Call.File, trap.Call.Line)
+		}
+	})
+}
+
 func TestIssue1656(t *testing.T) {
 	skipUnlessOn(t, "amd64 only", "amd64")
 	withTestProcess("issue1656/", t, func(p *proc.Target, fixture protest.Fixture) {
@@ -4976,3 +5122,303 @@ func TestStepOutPreservesGoroutine(t *testing.T) {
 		}
 	})
 }
+
+func TestTimers(t *testing.T) {
+	withTestProcess("timers", t, func(p *proc.Target, fixture protest.Fixture) {
+		assertNoError(p.Continue(), t, "Continue()")
+		timers, err := proc.Timers(p)
+		assertNoError(err, t, "Timers")
+		now, err := proc.Nanotime(p)
+		hasNow := testBackend == "native" || runtime.GOOS == "windows"
+		if hasNow {
+			assertNoError(err, t, "Nanotime")
+		} else if err == nil {
+			t.Errorf("Nanotime returned the clock of the debugger for the %s backend", testBackend)
+		}
+		foundSleep, foundAfter, foundAfterFunc, foundTicker := false, false, false, false
+		for _, timer := range timers {
+			fnname := "?"
+			if timer.Func.Fn != nil {
+				fnname = timer.Func.Fn.Name
+			}
+			t.Logf("%#x when=%d period=%d func=%s goroutine=%d", timer.Addr, timer.When, timer.Period, fnname, timer.GoroutineID)
+			switch {
+			case fnname == "main.callback":
+				foundAfterFunc = true
+			case fnname == "time.sendTime" && timer.Period != 0:
+				foundTicker = true
+			case fnname == "time.sendTime" && timer.GoroutineID != 0:
+				foundAfter = true
+			case fnname == "runtime.goroutineReady" && timer.GoroutineID != 0:
+				foundSleep = true
+				// the sleeper sleeps for an hour since the start of the program
+				if !hasNow {
+					break
+				}
+				if d := time.Duration(timer.When - now); d <= 50*time.Minute || d > time.Hour {
+					t.Errorf("sleep timer fires in %v", d)
+				}
+			}
+		}
+		if !foundSleep || !foundAfter || !foundAfterFunc || !foundTicker {
+			t.Fatalf("missing timers sleep:%v after:%v afterfunc:%v ticker:%v", foundSleep, foundAfter, foundAfterFunc, foundTicker)
+		}
+	})
+}
+
+func TestPollDescs(t *testing.T) {
+	withTestProcess("netpoll", t, func(p *proc.Target, fixture protest.Fixture) {
+		assertNoError(p.Continue(), t, "Continue()")
+		pds, err := proc.PollDescs(p)
+		assertNoError(err, t, "PollDescs")
+		found := false
+		for _, pd := range pds {
+			t.Logf("%#x fd=%d read=%d write=%d %q", pd.Addr, pd.Fd, pd.ReadGoroutineID, pd.WriteGoroutineID, pd.Description)
+			if pd.ReadGoroutineID != 0 {
+				found = true
+				if runtime.GOOS == "linux" && testBackend == "native" && !strings.HasSuffix(pd.Description, "(listen)") {
+					t.Errorf("wrong description for listening socket %q", pd.Description)
+				}
+			}
+		}
+		if !found {
+			t.Fatal("could not find goroutine blocked in Accept")
+		}
+	})
+}
+
+func TestClosureCaptures(t *testing.T) {
+	if goversion.VersionAfterOrEqual(runtime.Version(), 1, 16) && !goversion.VersionAfterOrEqual(runtime.Version(), 1, 23) {
+		// the layout of closures is recovered from the DWARF description of
+		// runtime types up to Go 1.15 and from the offsets attached to the
+		// captured variables starting with Go 1.23.
+		t.Skip("captured variables are not described by this version of Go")
+	}
+	withTestProcess("closurecontents", t, func(p *proc.Target, fixture protest.Fixture) {
+		assertNoError(p.Continue(), t, "Continue()")
+
+		scope, err := proc.GoroutineScope(p.CurrentThread())
+		assertNoError(err, t, "GoroutineScope()")
+		vars, err := scope.LocalVariables(normalLoadConfig)
+		assertNoError(err, t, "LocalVariables()")
+		captured := map[string]int64{}
+		for _, v := range vars {
+			if v.Flags&proc.VariableClosure == 0 {
+				continue
+			}
+			n, _ := constant.Int64Val(v.Value)
+			captured[v.Name] = n
+		}
+		if captured["scale"] != 3 || captured["a"] != 3 || len(captured) != 2 {
+			t.Errorf("wrong captured variables: %v", captured)
+		}
+
+		scope, err = proc.ConvertEvalScope(p, -1, 1, 0)
+		assertNoError(err, t, "ConvertEvalScope()")
+		acc, err := scope.EvalVariable("acc", normalLoadConfig)
+		assertNoError(err, t, "EvalVariable(acc)")
+		children := map[string]int64{}
+		for _, child := range acc.Captured {
+			children[child.Name], _ = constant.Int64Val(child.Value)
+		}
+		if children["scale"] != 3 || children["a"] != 3 || len(children) != 2 {
+			t.Errorf("wrong captured variables of acc: %v", children)
+		}
+		if len(acc.Children) != 0 {
+			// funcCallPrepare would pass the first captured variable as the
+			// receiver of the call
+			t.Errorf("captured variables of acc loaded as children: %d", len(acc.Children))
+		}
+	})
+}
+
+func TestSearchMemory(t *testing.T) {
+	withTestProcess("memsearch", t, func(p *proc.Target, fixture protest.Fixture) {
+		assertNoError(p.Continue(), t, "Continue()")
+
+		needle := evalVariable(p, t, "&needle")
+		tv := evalVariable(p, t, "t")
+
+		heap, err := p.HeapRegions()
+		assertNoError(err, t, "HeapRegions()")
+		results, err := p.SearchMemory(heap, []byte("hidden treasure"), 0)
+		assertNoError(err, t, "SearchMemory(heap)")
+		found := false
+		for _, r := range results {
+			t.Logf("%#x heap=%v %s base=%#x size=%d", r.Addr, r.Heap, r.Symbol, r.Base, r.Size)
+			if r.Heap && r.Base <= r.Addr && r.Addr < r.Base+r.Size {
+				found = true
+			}
+		}
+		if !found {
+			t.Errorf("string not found in the heap")
+		}
+
+		pattern := []byte{0x88, 0x77, 0x66, 0x55, 0x44, 0x33, 0x22, 0x11}
+		results, err = p.SearchMemory(heap, pattern, 0)
+		assertNoError(err, t, "SearchMemory(heap)")
+		tptr := tv.Children[0].Addr
+		found = false
+		for _, r := range results {
+			if r.Addr == tptr && r.Base == tptr {
+				found = true
+			}
+		}
+		if !found {
+			t.Errorf("field A of %#x not found: %v", tptr, results)
+		}
+
+		addr := needle.Children[0].Addr
+		region := []proc.MemoryMapEntry{{Addr: addr - 16, Size: 64, Read: true}}
+		results, err = p.SearchMemory(region, []byte{0xde, 0xad, 0xbe, 0xef}, 0)
+		assertNoError(err, t, "SearchMemory(range)")
+		if len(results) != 1 || results[0].Addr != addr || results[0].Symbol != "main.needle" || results[0].Size != 8 {
+			t.Errorf("wrong results searching %#x: %v", addr, results)
+		}
+
+		if runtime.GOOS == "linux" && testBackend == "native" {
+			mappings, err := p.MemoryMap()
+			assertNoError(err, t, "MemoryMap()")
+			readable := []proc.MemoryMapEntry{}
+			for _, m := range mappings {
+				if m.Read {
+					readable = append(readable, m)
+				}
+			}
+			results, err = p.SearchMemory(readable, []byte{0xde, 0xad, 0xbe, 0xef, 0x12, 0x34, 0x56, 0x78}, 0)
+			assertNoError(err, t, "SearchMemory(all)")
+			found = false
+			for _, r := range results {
+				if r.Addr == addr {
+					found = true
+				}
+			}
+			if !found {
+				t.Errorf("main.needle (%#x) not found: %v", addr, results)
+			}
+		}
+	})
+}
+
+func TestMemoryRegions(t *testing.T) {
+	if runtime.GOOS != "linux" || testBackend != "native" {
+		t.Skip("memory map only supported by the native backend on linux")
+	}
+	withTestProcess("memsearch", t, func(p *proc.Target, fixture protest.Fixture) {
+		assertNoError(p.Continue(), t, "Continue()")
+		regions, err := p.MemoryRegions(true)
+		assertNoError(err, t, "MemoryRegions()")
+
+		find := func(addr uint64) *proc.MemoryRegion {
+			for i := range regions {
+				if regions[i].Contains(addr) {
+					return &regions[i]
+				}
+			}
+			t.Fatalf("no region contains %#x", addr)
+			return nil
+		}
+
+		for _, region := range regions {
+			t.Logf("%#x-%#x %s heap=%v text=%v data=%v goroutines=%v", region.Addr, region.End(), region.Filename, region.Heap, region.Text, region.ModuleData, region.Goroutines)
+		}
+
+		if r := find(p.BinInfo().LookupFunc["main.main"].Entry); !r.Text || !r.Exec {
+			t.Errorf("main.main not in a text region")
+		}
+		if r := find(evalVariable(p, t, "&needle").Children[0].Addr); !r.ModuleData {
+			t.Errorf("main.needle not in a module data region")
+		}
+		if r := find(evalVariable(p, t, "t").Children[0].Addr); !r.Heap {
+			t.Errorf("*t not in a heap region")
+		}
+		g, err := proc.GetG(p.CurrentThread())
+		assertNoError(err, t, "GetG()")
+		found := false
+		for _, region := range regions {
+			for _, gid := range region.Goroutines {
+				if gid == g.ID {
+					found = true
+				}
+			}
+		}
+		if !found {
+			t.Errorf("stack of goroutine %d not found", g.ID)
+		}
+	})
+}
+
+func TestDiffVariables(t *testing.T) {
+	withTestProcess("diffvars", t, func(p *proc.Target, fixture protest.Fixture) {
+		assertNoError(p.Continue(), t, "Continue()")
+		cfg := proc.LoadConfig{FollowPointers: true, MaxVariableRecurse: 3, MaxStringLen: 64, MaxArrayValues: 64, MaxStructFields: -1}
+		scope, err := proc.GoroutineScope(p.CurrentThread())
+		assertNoError(err, t, "GoroutineScope()")
+		eval := func(expr string) *proc.Variable {
+			v, err := scope.EvalVariable(expr, cfg)
+			assertNoError(err, t, fmt.Sprintf("EvalVariable(%s)", expr))
+			return v
+		}
+
+		diffs := proc.DiffVariables(eval("a"), eval("b"))
+		got := map[string]string{}
+		for _, diff := range diffs {
+			t.Logf("%q %s", diff.Path, diff.Reason)
+			got[diff.Path] = diff.Reason
+		}
+		for path, reason := range map[string]string{
+			".Inner.Name": "",
+			".Items":      "different lengths 3 and 4",
+			".Items[1]":   "",
+			`.Tags["y"]`:  "missing key",
+			`.Tags["z"]`:  "missing key",
+			".Any":        "different dynamic types",
+		} {
+			if r, ok := got[path]; !ok || r != reason {
+				t.Errorf("expected difference at %q (%q) got %q %v", path, reason, r, ok)
+			}
+		}
+		if len(diffs) != 6 {
+			t.Errorf("wrong number of differences %d", len(diffs))
+		}
+
+		if diffs := proc.DiffVariables(eval("a"), eval("c")); len(diffs) != 0 {
+			t.Errorf("unexpected differences between a and c: %v", diffs)
+		}
+	})
+}
+
+func TestCoverBreakpoints(t *testing.T) {
+	withTestProcess("locationsprog", t, func(p *proc.Target, fixture protest.Fixture) {
+		lines := p.BinInfo().StatementLines(func(pkg string) bool { return pkg == "main" })
+		for _, line := range lines {
+			if line.Package != "main" || line.File != fixture.Source {
+				t.Errorf("line %s:%d of package %s recorded", line.File, line.Line, line.Package)
+			}
+		}
+		assertNoError(p.SetCoverBreakpoints(lines), t, "SetCoverBreakpoints")
+
+		// user breakpoints overlapping coverage breakpoints still stop
+		setFileBreakpoint(p, t, fixture.Source, 27)
+		assertNoError(p.Continue(), t, "Continue()")
+		if _, ln := currentLineNumber(p, t); ln != 27 {
+			t.Fatalf("stopped at line %d, expected 27", ln)
+		}
+
+		err := p.Continue()
+		if _, exited := err.(proc.ErrProcessExited); !exited {
+			t.Fatalf("expected process to exit, got %v", err)
+		}
+
+		lines, hit := p.Coverage()
+		executed := make(map[int]bool)
+		for i := range lines {
+			executed[lines[i].Line] = hit[i]
+		}
+		for ln, exp := range map[int]bool{15: true, 23: true, 27: true, 34: true, 37: true, 44: true, 45: false} {
+			if got, ok := executed[ln]; !ok || got != exp {
+				t.Errorf("line %d: expected executed %v, got %v (recorded %v)", ln, exp, got, ok)
+			}
+		}
+	})
+}
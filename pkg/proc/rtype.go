@@ -0,0 +1,274 @@
+package proc
+
+import (
+	"debug/dwarf"
+	"encoding/binary"
+	"errors"
+	"reflect"
+
+	"github.com/go-delve/delve/pkg/dwarf/godwarf"
+)
+
+// This file implements reading types from the type descriptors that the
+// Go linker writes for the runtime (runtime._type, see
+// $GOROOT/src/runtime/type.go), they are used to read goroutines of
+// executables that have no DWARF.
+// The layout of runtime._type has been the same since Go 1.10, the
+// encoding of names changed in Go 1.17 and the encoding of struct field
+// offsets in Go 1.19, both are detected when runtime.g is found.
+
+// flag of struct field names marking embedded fields, starting with Go 1.19
+const nameflagEmbedded = 1 << 3
+
+var errNoRuntimeG = errors.New("could not find the type descriptor of runtime.g")
+
+// rtypeReader converts type descriptors into godwarf types.
+type rtypeReader struct {
+	mem     MemoryReadWriter
+	md      *moduleData
+	ptrSize int
+
+	// oldNames is true if names store their length in 2 bytes (Go 1.16 and
+	// earlier) instead of a varint.
+	oldNames bool
+	// shiftedOffsets is true if the offsets of struct fields are shifted
+	// left by one and the lowest bit marks embedded fields (Go 1.18 and
+	// earlier).
+	shiftedOffsets bool
+
+	cache map[uint64]godwarf.Type
+}
+
+// rtypeSize returns the size of runtime._type.
+func (r *rtypeReader) rtypeSize() int {
+	return 4*r.ptrSize + 16
+}
+
+func (r *rtypeReader) uintptr(buf []byte) uint64 {
+	return readUintPtr(buf, r.ptrSize)
+}
+
+// runtimeGTypeNoDwarf returns the type of runtime.g, read from its type
+// descriptor.
+func runtimeGTypeNoDwarf(bi *BinaryInfo, mem MemoryReadWriter) (godwarf.Type, error) {
+	if bi.runtimeGNoDwarf != nil {
+		return bi.runtimeGNoDwarf, nil
+	}
+	md, err := loadModuleDataNoDwarf(bi, mem)
+	if err != nil {
+		return nil, err
+	}
+	r := &rtypeReader{
+		mem:     cacheMemory(mem, md.types, int(md.etypes-md.types)),
+		md:      md,
+		ptrSize: bi.Arch.PtrSize(),
+		cache:   make(map[uint64]godwarf.Type),
+	}
+	addr, err := r.findRuntimeG()
+	if err != nil {
+		return nil, err
+	}
+	typ, err := r.typ(addr)
+	if err != nil {
+		return nil, err
+	}
+	bi.runtimeGNoDwarf = typ
+	return typ, nil
+}
+
+// findRuntimeG searches the type descriptors for the one describing
+// runtime.g and determines the encoding of names and field offsets.
+func (r *rtypeReader) findRuntimeG() (uint64, error) {
+	const name = "*runtime.g" // named types have tflagExtraStar set
+	buf := make([]byte, r.md.etypes-r.md.types)
+	if _, err := r.mem.ReadMemory(buf, r.md.types); err != nil {
+		return 0, err
+	}
+	rsz := r.rtypeSize()
+	for off := 0; off+rsz+4*r.ptrSize <= len(buf); off += r.ptrSize {
+		tflag, kind := buf[off+2*r.ptrSize+4], buf[off+2*r.ptrSize+7]
+		if reflect.Kind(kind&kindMask) != reflect.Struct || tflag&tflagExtraStar == 0 {
+			continue
+		}
+		str := int(int32(binary.LittleEndian.Uint32(buf[off+4*r.ptrSize+8:])))
+		if str <= 0 || str+3+len(name) > len(buf) {
+			continue
+		}
+		switch {
+		case buf[str+1] == byte(len(name)) && string(buf[str+2:str+2+len(name)]) == name:
+		case buf[str+1] == 0 && buf[str+2] == byte(len(name)) && string(buf[str+3:str+3+len(name)]) == name:
+			r.oldNames = true
+		default:
+			continue
+		}
+
+		// The first two fields of runtime.g are stack and stackguard0,
+		// stackguard0 is at an offset equal to the size of stack.
+		fields, nfields := r.uintptr(buf[off+rsz+r.ptrSize:]), r.uintptr(buf[off+rsz+2*r.ptrSize:])
+		if nfields < 2 {
+			return 0, errNoRuntimeG
+		}
+		fieldsbuf := make([]byte, 6*r.ptrSize)
+		if _, err := r.mem.ReadMemory(fieldsbuf, fields); err != nil {
+			return 0, err
+		}
+		stacksz := make([]byte, r.ptrSize)
+		if _, err := r.mem.ReadMemory(stacksz, r.uintptr(fieldsbuf[r.ptrSize:])); err != nil {
+			return 0, err
+		}
+		r.shiftedOffsets = r.uintptr(fieldsbuf[5*r.ptrSize:]) == 2*r.uintptr(stacksz)
+		return r.md.types + uint64(off), nil
+	}
+	return 0, errNoRuntimeG
+}
+
+// name reads the name at addr (see runtime.name in
+// $GOROOT/src/runtime/type.go), returning the name and its flags.
+func (r *rtypeReader) name(addr uint64) (string, byte, error) {
+	hdr := make([]byte, 1+binary.MaxVarintLen16)
+	if _, err := r.mem.ReadMemory(hdr, addr); err != nil {
+		return "", 0, err
+	}
+	var n uint64
+	var sz int
+	if r.oldNames {
+		n, sz = uint64(binary.BigEndian.Uint16(hdr[1:])), 2
+	} else {
+		n, sz = binary.Uvarint(hdr[1:])
+		if sz <= 0 {
+			return "", 0, errors.New("malformed name")
+		}
+	}
+	buf := make([]byte, n)
+	if _, err := r.mem.ReadMemory(buf, addr+1+uint64(sz)); err != nil {
+		return "", 0, err
+	}
+	return string(buf), hdr[0], nil
+}
+
+// typ returns the type described by the type descriptor at addr.
+func (r *rtypeReader) typ(addr uint64) (godwarf.Type, error) {
+	if typ := r.cache[addr]; typ != nil {
+		return typ, nil
+	}
+	rsz := r.rtypeSize()
+	buf := make([]byte, rsz+4*r.ptrSize)
+	if _, err := r.mem.ReadMemory(buf, addr); err != nil {
+		return nil, err
+	}
+	size := int64(r.uintptr(buf))
+	tflag, kind := buf[2*r.ptrSize+4], reflect.Kind(buf[2*r.ptrSize+7]&kindMask)
+	str := int32(binary.LittleEndian.Uint32(buf[4*r.ptrSize+8:]))
+	name, _, err := r.name(r.md.types + uint64(str))
+	if err != nil {
+		return nil, err
+	}
+	if tflag&tflagExtraStar != 0 && len(name) > 0 {
+		name = name[1:]
+	}
+	// the address of the descriptor is used as the offset of the type,
+	// godwarf uses it to detect recursive types
+	common := godwarf.CommonType{ByteSize: size, Name: name, ReflectKind: kind, Offset: dwarf.Offset(addr)}
+	basic := godwarf.BasicType{CommonType: common, BitSize: size * 8}
+	elem := func() (godwarf.Type, error) {
+		return r.typ(r.uintptr(buf[rsz:]))
+	}
+
+	var typ godwarf.Type
+	switch kind {
+	case reflect.Bool:
+		typ = &godwarf.BoolType{BasicType: basic}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		typ = &godwarf.IntType{BasicType: basic}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		typ = &godwarf.UintType{BasicType: basic}
+	case reflect.Float32, reflect.Float64:
+		typ = &godwarf.FloatType{BasicType: basic}
+	case reflect.Complex64, reflect.Complex128:
+		typ = &godwarf.ComplexType{BasicType: basic}
+	case reflect.String:
+		t := &godwarf.StringType{}
+		t.StructType = r.header(common, "str", &godwarf.UintType{BasicType: godwarf.BasicType{CommonType: godwarf.CommonType{ByteSize: 1, Name: "uint8", ReflectKind: reflect.Uint8}, BitSize: 8}}, "len")
+		typ = t
+	case reflect.Ptr:
+		t := &godwarf.PtrType{CommonType: common}
+		r.cache[addr] = t
+		if t.Type, err = elem(); err != nil {
+			return nil, err
+		}
+		typ = t
+	case reflect.UnsafePointer:
+		typ = &godwarf.PtrType{CommonType: common, Type: &godwarf.VoidType{}}
+	case reflect.Slice:
+		t := &godwarf.SliceType{}
+		r.cache[addr] = t
+		if t.ElemType, err = elem(); err != nil {
+			return nil, err
+		}
+		t.StructType = r.header(common, "array", t.ElemType, "len", "cap")
+		typ = t
+	case reflect.Array:
+		t := &godwarf.ArrayType{CommonType: common, Count: int64(r.uintptr(buf[rsz+2*r.ptrSize:]))}
+		r.cache[addr] = t
+		if t.Type, err = elem(); err != nil {
+			return nil, err
+		}
+		t.StrideBitSize = t.Type.Size() * 8
+		typ = t
+	case reflect.Struct:
+		t := &godwarf.StructType{CommonType: common, StructName: name, Kind: "struct"}
+		r.cache[addr] = t
+		if err := r.structFields(t, r.uintptr(buf[rsz+r.ptrSize:]), int(r.uintptr(buf[rsz+2*r.ptrSize:]))); err != nil {
+			return nil, err
+		}
+		typ = t
+	default:
+		// channels, maps, functions and interfaces are only needed to
+		// compute the layout of structs that contain them
+		typ = &godwarf.UnsupportedType{CommonType: common}
+	}
+	r.cache[addr] = typ
+	return typ, nil
+}
+
+// header returns the struct describing the header of a string or a slice,
+// a pointer to elem followed by the integer fields named in intFields.
+func (r *rtypeReader) header(common godwarf.CommonType, ptrName string, elem godwarf.Type, intFields ...string) godwarf.StructType {
+	ptrSize := int64(r.ptrSize)
+	t := godwarf.StructType{CommonType: common, StructName: common.Name, Kind: "struct"}
+	t.Field = append(t.Field, &godwarf.StructField{Name: ptrName, Type: &godwarf.PtrType{CommonType: godwarf.CommonType{ByteSize: ptrSize, ReflectKind: reflect.Ptr}, Type: elem}, ByteSize: ptrSize})
+	for i, name := range intFields {
+		inttyp := &godwarf.IntType{BasicType: godwarf.BasicType{CommonType: godwarf.CommonType{ByteSize: ptrSize, Name: "int", ReflectKind: reflect.Int}, BitSize: ptrSize * 8}}
+		t.Field = append(t.Field, &godwarf.StructField{Name: name, Type: inttyp, ByteOffset: int64(i+1) * ptrSize, ByteSize: ptrSize})
+	}
+	return t
+}
+
+// structFields reads the n fields of t from the array of
+// runtime.structfield at addr.
+func (r *rtypeReader) structFields(t *godwarf.StructType, addr uint64, n int) error {
+	fieldSize := 3 * r.ptrSize
+	buf := make([]byte, n*fieldSize)
+	if _, err := r.mem.ReadMemory(buf, addr); err != nil {
+		return err
+	}
+	for i := 0; i < n; i++ {
+		fieldbuf := buf[i*fieldSize:]
+		name, flags, err := r.name(r.uintptr(fieldbuf))
+		if err != nil {
+			return err
+		}
+		typ, err := r.typ(r.uintptr(fieldbuf[r.ptrSize:]))
+		if err != nil {
+			return err
+		}
+		off := r.uintptr(fieldbuf[2*r.ptrSize:])
+		embedded := flags&nameflagEmbedded != 0
+		if r.shiftedOffsets {
+			embedded = off&1 != 0
+			off >>= 1
+		}
+		t.Field = append(t.Field, &godwarf.StructField{Name: name, Type: typ, ByteOffset: int64(off), ByteSize: typ.Size(), Embedded: embedded})
+	}
+	return nil
+}
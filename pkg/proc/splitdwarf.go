@@ -0,0 +1,738 @@
+package proc
+
+import (
+	"bytes"
+	"debug/dwarf"
+	"debug/elf"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/go-delve/delve/pkg/dwarf/godwarf"
+	"github.com/go-delve/delve/pkg/dwarf/loclist"
+	"github.com/go-delve/delve/pkg/dwarf/util"
+	"github.com/hashicorp/golang-lru/simplelru"
+)
+
+// Split DWARF (-gsplit-dwarf) moves most of the debug info of a compile
+// unit to a separate .dwo file, leaving a skeleton compile unit in the
+// executable. The .dwo files of an executable can also be collected into a
+// single .dwp package.
+// The skeleton compile unit contains the address ranges and the line table
+// of the compile unit, as well as the base of its contribution to the
+// debug_addr section of the executable, which is referenced by the split
+// compile unit with the DW_FORM_addrx form and the DW_OP_addrx operation.
+// Each split compile unit is loaded into its own Image, since DWARF
+// offsets of the split compile unit are relative to its .debug_info.dwo
+// section.
+// Before DWARF 5 split DWARF was a GNU extension with the same layout but
+// different attributes and forms, its DW_FORM_GNU_str_index and
+// DW_FORM_GNU_addr_index forms are replaced with DW_FORM_strx and
+// DW_FORM_addrx before passing the split compile unit to debug/dwarf.
+
+const (
+	dwarfUnitTypeSplitCompile = 0x05 // DW_UT_split_compile
+
+	dwarfAttrGNUDwoName  = 0x2130 // DW_AT_GNU_dwo_name
+	dwarfAttrGNUDwoID    = 0x2131 // DW_AT_GNU_dwo_id
+	dwarfAttrGNUAddrBase = 0x2133 // DW_AT_GNU_addr_base
+
+	// forms used to find the DW_AT_GNU_dwo_id attribute of a split compile
+	// unit without debug/dwarf
+	dwarfFormAddr          = 0x01
+	dwarfFormBlock2        = 0x03
+	dwarfFormBlock4        = 0x04
+	dwarfFormData2         = 0x05
+	dwarfFormData4         = 0x06
+	dwarfFormData8         = 0x07
+	dwarfFormString        = 0x08
+	dwarfFormBlock         = 0x09
+	dwarfFormBlock1        = 0x0a
+	dwarfFormData1         = 0x0b
+	dwarfFormFlag          = 0x0c
+	dwarfFormSdata         = 0x0d
+	dwarfFormStrp          = 0x0e
+	dwarfFormUdata         = 0x0f
+	dwarfFormRefAddr       = 0x10
+	dwarfFormRef1          = 0x11
+	dwarfFormRef2          = 0x12
+	dwarfFormRef4          = 0x13
+	dwarfFormRef8          = 0x14
+	dwarfFormRefUdata      = 0x15
+	dwarfFormIndirect      = 0x16
+	dwarfFormSecOffset     = 0x17
+	dwarfFormExprloc       = 0x18
+	dwarfFormFlagPresent   = 0x19
+	dwarfFormStrx          = 0x1a
+	dwarfFormAddrx         = 0x1b
+	dwarfFormRefSig8       = 0x20
+	dwarfFormImplicitConst = 0x21
+	dwarfFormGNUAddrIndex  = 0x1f01
+	dwarfFormGNUStrIndex   = 0x1f02
+	dwarfFormGNURefAlt     = 0x1f20
+	dwarfFormGNUStrpAlt    = 0x1f21
+
+	// section identifiers used by version 5 of .debug_cu_index
+	dwarfSectInfo       = 1
+	dwarfSectAbbrev     = 3
+	dwarfSectLoclists   = 5
+	dwarfSectStrOffsets = 6
+	dwarfSectRnglists   = 8
+)
+
+// splitUnit contains the sections of a split compile unit.
+type splitUnit struct {
+	path       string // path of the .dwo or .dwp file
+	version    uint8  // DWARF version of the split compile unit
+	info       []byte
+	abbrev     []byte
+	str        []byte
+	strOffsets []byte
+	loclists   []byte
+	rnglists   []byte
+}
+
+// IsSplitDwarf returns true if this image contains a split compile unit,
+// loaded from a .dwo file or from a .dwp package, rather than a file
+// loaded by the target process.
+func (image *Image) IsSplitDwarf() bool {
+	return image.skeleton != nil
+}
+
+// loadSplitUnit loads the split compile unit corresponding to the skeleton
+// compile unit cu, contained in image, into a new image and reads its
+// debug_info.
+// Errors are logged as warnings: the rest of the executable can still be
+// debugged without the split compile unit.
+func (bi *BinaryInfo) loadSplitUnit(ctxt *loadDebugInfoMapsContext, image *Image, debugInfoBytes []byte, cu *compileUnit) {
+	skeleton := cu.entry
+	dwoName, _ := skeleton.Val(dwarfAttrDwoName).(string)
+	if dwoName == "" {
+		dwoName, _ = skeleton.Val(dwarfAttrGNUDwoName).(string)
+	}
+	if dwoName == "" {
+		bi.logger.Warnf("skeleton compile unit at %#x without DW_AT_dwo_name", skeleton.Offset)
+		return
+	}
+
+	dwoID, err := skeletonDwoID(skeleton, debugInfoBytes)
+	if err != nil {
+		bi.logger.Warnf("could not read DWO id of skeleton compile unit at %#x: %v", skeleton.Offset, err)
+		return
+	}
+
+	compdir, _ := skeleton.Val(dwarf.AttrCompDir).(string)
+	su, err := bi.findSplitUnit(ctxt, image, dwoName, compdir, dwoID)
+	if err != nil {
+		bi.logger.Warnf("could not load split compile unit %s: %v", dwoName, err)
+		return
+	}
+
+	addrBase, _ := addrBase(skeleton)
+	dwarfData, err := su.dwarfData(image.debugAddr.GetSubsection(uint64(addrBase)).Bytes())
+	if err != nil {
+		bi.logger.Warnf("could not load split compile unit %s: %v", su.path, err)
+		return
+	}
+	var loclist5 *loclist.Dwarf5Reader
+	if su.version >= 5 {
+		// location lists of the GNU extension use a format that is not
+		// supported
+		loclist5 = loclist.NewDwarf5Reader(su.loclists)
+	}
+
+	split := &Image{
+		Path:       su.path,
+		StaticBase: image.StaticBase,
+		addr:       image.addr,
+		index:      len(bi.Images),
+		skeleton:   image,
+		dwarf:      dwarfData,
+		loclist2:   loclist.NewDwarf2Reader(nil, bi.Arch.PtrSize()),
+		loclist5:   loclist5,
+		debugAddr:  image.debugAddr,
+		typeCache:  make(map[dwarf.Offset]godwarf.Type),
+
+		runtimeTypeToDIE: make(map[uint64]runtimeTypeDIE),
+	}
+	split.dwarfReader = split.dwarf.Reader()
+	split.dwarfTreeCache, _ = simplelru.NewLRU(dwarfTreeCacheSize, nil)
+	bi.Images = append(bi.Images, split)
+
+	splitCtxt := &loadDebugInfoMapsContext{
+		ardr:                split.DwarfReader(),
+		abstractOriginTable: make(map[dwarf.Offset]int),
+		knownPackageVars:    ctxt.knownPackageVars,
+		offsetToVersion:     util.ReadUnitVersions(su.info),
+	}
+
+	reader := split.DwarfReader()
+	entry, err := reader.Next()
+	if err != nil || entry == nil || entry.Tag != dwarf.TagCompileUnit {
+		bi.logger.Warnf("could not read split compile unit %s: %v", su.path, err)
+		return
+	}
+
+	// address ranges and line table are the ones of the skeleton unit,
+	// everything else comes from the split unit
+	cu.image = split
+	cu.skeleton = skeleton
+	cu.entry = entry
+	cu.offset = entry.Offset
+	cu.Version = splitCtxt.offsetToVersion[cu.offset]
+	cu.name, _ = entry.Val(dwarf.AttrName).(string)
+	if compdir, _ := entry.Val(dwarf.AttrCompDir).(string); compdir != "" && !filepath.IsAbs(cu.name) {
+		cu.name = filepath.Join(compdir, cu.name)
+	}
+	cu.producer, _ = entry.Val(dwarf.AttrProducer).(string)
+	split.compileUnits = append(split.compileUnits, cu)
+
+	if cu.lineInfo != nil {
+		for _, fileEntry := range cu.lineInfo.FileNames {
+			bi.Sources = append(bi.Sources, fileEntry.Path)
+		}
+	}
+
+	if entry.Children {
+		bi.loadDebugInfoMapsCompileUnit(splitCtxt, split, reader, cu)
+	}
+}
+
+// isSkeletonUnit returns true if entry is the skeleton compile unit of a
+// split compile unit.
+func isSkeletonUnit(entry *dwarf.Entry) bool {
+	return entry.Tag == dwarfTagSkeletonUnit || (entry.Tag == dwarf.TagCompileUnit && entry.Val(dwarfAttrGNUDwoName) != nil)
+}
+
+// addrBase returns the base of the contribution of the compile unit entry
+// to the debug_addr section.
+func addrBase(entry *dwarf.Entry) (int64, bool) {
+	if addrBase, ok := entry.Val(dwarfAttrAddrBase).(int64); ok {
+		return addrBase, true
+	}
+	addrBase, ok := entry.Val(dwarfAttrGNUAddrBase).(int64)
+	return addrBase, ok
+}
+
+// skeletonDwoID returns the DWO id of the skeleton compile unit, which is
+// the last field of its unit header in DWARF 5 and its DW_AT_GNU_dwo_id
+// attribute in the GNU extension.
+func skeletonDwoID(skeleton *dwarf.Entry, debugInfoBytes []byte) (uint64, error) {
+	if dwoID, ok := skeleton.Val(dwarfAttrGNUDwoID).(int64); ok {
+		return uint64(dwoID), nil
+	}
+	// unit_length, version, unit_type, address_size, debug_abbrev_offset
+	// and dwo_id, for 32bit and 64bit DWARF
+	for _, dwarf64 := range []bool{false, true} {
+		hdrsz := dwarf.Offset(4 + 2 + 1 + 1 + 4 + 8)
+		if dwarf64 {
+			hdrsz = 12 + 2 + 1 + 1 + 8 + 8
+		}
+		if skeleton.Offset < hdrsz || int(skeleton.Offset) > len(debugInfoBytes) {
+			continue
+		}
+		_, hdrDwarf64, version, byteOrder := util.ReadDwarfLengthVersion(debugInfoBytes[skeleton.Offset-hdrsz:])
+		if version != 5 || hdrDwarf64 != dwarf64 {
+			continue
+		}
+		return byteOrder.Uint64(debugInfoBytes[skeleton.Offset-8:]), nil
+	}
+	return 0, errors.New("malformed unit header")
+}
+
+// findSplitUnit returns the split compile unit with the specified DWO id.
+// The .dwp package of the executable is searched first, next to the
+// executable and in the debug info directories, then the .dwo file called
+// dwoName is searched relative to the compilation directory, next to the
+// executable and in the debug info directories.
+func (bi *BinaryInfo) findSplitUnit(ctxt *loadDebugInfoMapsContext, image *Image, dwoName, compdir string, dwoID uint64) (*splitUnit, error) {
+	if !ctxt.dwpSearched {
+		ctxt.dwpSearched = true
+		candidates := []string{image.Path + ".dwp"}
+		for _, dir := range bi.debugInfoDirectories {
+			candidates = append(candidates, filepath.Join(dir, filepath.Base(image.Path)+".dwp"))
+		}
+		for _, path := range candidates {
+			if _, err := os.Stat(path); err != nil {
+				continue
+			}
+			dwp, err := openDwp(path)
+			if err != nil {
+				bi.logger.Warnf("could not open %s: %v", path, err)
+				continue
+			}
+			ctxt.dwp = dwp
+			break
+		}
+	}
+
+	if ctxt.dwp != nil {
+		if su := ctxt.dwp.unit(dwoID); su != nil {
+			return su, nil
+		}
+	}
+
+	var candidates []string
+	if filepath.IsAbs(dwoName) {
+		candidates = append(candidates, dwoName)
+	} else {
+		if compdir != "" {
+			candidates = append(candidates, filepath.Join(compdir, dwoName))
+		}
+		candidates = append(candidates, filepath.Join(filepath.Dir(image.Path), dwoName))
+	}
+	candidates = append(candidates, filepath.Join(filepath.Dir(image.Path), filepath.Base(dwoName)))
+	for _, dir := range bi.debugInfoDirectories {
+		if !filepath.IsAbs(dwoName) {
+			candidates = append(candidates, filepath.Join(dir, dwoName))
+		}
+		candidates = append(candidates, filepath.Join(dir, filepath.Base(dwoName)))
+	}
+
+	var lastErr error
+	for _, path := range candidates {
+		if _, err := os.Stat(path); err != nil {
+			continue
+		}
+		su, err := openDwo(path, dwoID)
+		if err != nil {
+			lastErr = err
+			continue
+		}
+		return su, nil
+	}
+	if lastErr != nil {
+		return nil, lastErr
+	}
+	return nil, errors.New("file not found")
+}
+
+// openDwo reads the split compile unit with the specified DWO id from the
+// .dwo file at path.
+func openDwo(path string, dwoID uint64) (*splitUnit, error) {
+	f, err := elf.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	su := &splitUnit{path: path}
+	for _, sect := range []struct {
+		name string
+		dst  *[]byte
+	}{
+		{".debug_info.dwo", &su.info},
+		{".debug_abbrev.dwo", &su.abbrev},
+		{".debug_str.dwo", &su.str},
+		{".debug_str_offsets.dwo", &su.strOffsets},
+		{".debug_loclists.dwo", &su.loclists},
+		{".debug_rnglists.dwo", &su.rnglists},
+	} {
+		if s := f.Section(sect.name); s != nil {
+			*sect.dst, err = s.Data()
+			if err != nil {
+				return nil, fmt.Errorf("could not read %s of %s: %v", sect.name, path, err)
+			}
+		}
+	}
+
+	_, _, su.version, _ = util.ReadDwarfLengthVersion(su.info)
+	id, err := su.id()
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", path, err)
+	}
+	if id != dwoID {
+		return nil, fmt.Errorf("%s: DWO id mismatch %#x (expected %#x)", path, id, dwoID)
+	}
+	return su, nil
+}
+
+// id returns the DWO id of the split compile unit, read from its unit
+// header in DWARF 5 and from its DW_AT_GNU_dwo_id attribute in the GNU
+// extension.
+func (su *splitUnit) id() (uint64, error) {
+	info := su.info
+	_, dwarf64, version, byteOrder := util.ReadDwarfLengthVersion(info)
+	// unit_length and version
+	offsz, hdrsz := 4, 4+2
+	if dwarf64 {
+		offsz, hdrsz = 8, 12+2
+	}
+
+	switch version {
+	case 5:
+		// unit_type, address_size, debug_abbrev_offset
+		if len(info) < hdrsz+1+1+offsz+8 {
+			return 0, errors.New("truncated split compile unit header")
+		}
+		if info[hdrsz] != dwarfUnitTypeSplitCompile {
+			return 0, fmt.Errorf("unexpected unit type %#x", info[hdrsz])
+		}
+		return byteOrder.Uint64(info[hdrsz+1+1+offsz:]), nil
+
+	case 4:
+		abbrevs, err := su.abbrevTable(byteOrder, hdrsz, offsz)
+		if err != nil {
+			return 0, err
+		}
+		// debug_abbrev_offset, address_size
+		if len(info) < hdrsz+offsz+1 {
+			return 0, errors.New("truncated split compile unit header")
+		}
+		addrSize := int(info[hdrsz+offsz])
+		dwoID, err := findAttrValue(bytes.NewBuffer(info[hdrsz+offsz+1:]), abbrevs, dwarfAttrGNUDwoID, byteOrder, offsz, addrSize)
+		if err != nil {
+			return 0, fmt.Errorf("could not read DW_AT_GNU_dwo_id: %v", err)
+		}
+		return dwoID, nil
+
+	default:
+		return 0, fmt.Errorf("unsupported split compile unit version %d", version)
+	}
+}
+
+// abbrevTable returns the abbreviation table used by the split compile
+// unit, hdrsz is the size of the unit_length and version fields of its
+// header and offsz the size of offsets.
+func (su *splitUnit) abbrevTable(byteOrder binary.ByteOrder, hdrsz, offsz int) ([]abbrevDecl, error) {
+	if len(su.info) < hdrsz+offsz {
+		return nil, errors.New("truncated split compile unit header")
+	}
+	abbrevOff, _ := util.ReadUintRaw(bytes.NewReader(su.info[hdrsz:]), byteOrder, offsz)
+	if abbrevOff >= uint64(len(su.abbrev)) {
+		return nil, fmt.Errorf("invalid abbreviation table offset %#x", abbrevOff)
+	}
+	return parseAbbrevTable(su.abbrev[abbrevOff:])
+}
+
+// gnuSections returns the debug_abbrev and debug_info sections of a split
+// compile unit using the GNU extension, with the DW_FORM_GNU_str_index and
+// DW_FORM_GNU_addr_index forms replaced by DW_FORM_strx and DW_FORM_addrx.
+// The rewritten abbreviation table is at the start of the returned
+// debug_abbrev.
+func (su *splitUnit) gnuSections() (abbrev, info []byte, err error) {
+	_, dwarf64, _, byteOrder := util.ReadDwarfLengthVersion(su.info)
+	offsz, hdrsz := 4, 4+2
+	if dwarf64 {
+		offsz, hdrsz = 8, 12+2
+	}
+	abbrevs, err := su.abbrevTable(byteOrder, hdrsz, offsz)
+	if err != nil {
+		return nil, nil, err
+	}
+	for i := range abbrevs {
+		for j := range abbrevs[i].fields {
+			switch abbrevs[i].fields[j].form {
+			case dwarfFormGNUStrIndex:
+				abbrevs[i].fields[j].form = dwarfFormStrx
+			case dwarfFormGNUAddrIndex:
+				abbrevs[i].fields[j].form = dwarfFormAddrx
+			}
+		}
+	}
+
+	info = make([]byte, len(su.info))
+	copy(info, su.info)
+	for i := hdrsz; i < hdrsz+offsz; i++ {
+		info[i] = 0
+	}
+	return encodeAbbrevTable(abbrevs), info, nil
+}
+
+// abbrevField is an attribute specification of an abbreviation declaration.
+type abbrevField struct {
+	attr, form    uint64
+	implicitConst int64
+}
+
+// abbrevDecl is an abbreviation declaration.
+type abbrevDecl struct {
+	code, tag uint64
+	children  byte
+	fields    []abbrevField
+}
+
+// parseAbbrevTable parses the abbreviation table at the start of data.
+func parseAbbrevTable(data []byte) ([]abbrevDecl, error) {
+	buf := bytes.NewBuffer(data)
+	var decls []abbrevDecl
+	for {
+		if buf.Len() == 0 {
+			return nil, errors.New("truncated abbreviation table")
+		}
+		code, _ := util.DecodeULEB128(buf)
+		if code == 0 {
+			return decls, nil
+		}
+		decl := abbrevDecl{code: code}
+		decl.tag, _ = util.DecodeULEB128(buf)
+		decl.children, _ = buf.ReadByte()
+		for {
+			if buf.Len() == 0 {
+				return nil, errors.New("truncated abbreviation table")
+			}
+			attr, _ := util.DecodeULEB128(buf)
+			form, _ := util.DecodeULEB128(buf)
+			if attr == 0 && form == 0 {
+				break
+			}
+			field := abbrevField{attr: attr, form: form}
+			if form == dwarfFormImplicitConst {
+				field.implicitConst, _ = util.DecodeSLEB128(buf)
+			}
+			decl.fields = append(decl.fields, field)
+		}
+		decls = append(decls, decl)
+	}
+}
+
+// encodeAbbrevTable encodes decls as an abbreviation table.
+func encodeAbbrevTable(decls []abbrevDecl) []byte {
+	var buf bytes.Buffer
+	for _, decl := range decls {
+		util.EncodeULEB128(&buf, decl.code)
+		util.EncodeULEB128(&buf, decl.tag)
+		buf.WriteByte(decl.children)
+		for _, field := range decl.fields {
+			util.EncodeULEB128(&buf, field.attr)
+			util.EncodeULEB128(&buf, field.form)
+			if field.form == dwarfFormImplicitConst {
+				util.EncodeSLEB128(&buf, field.implicitConst)
+			}
+		}
+		buf.Write([]byte{0, 0})
+	}
+	buf.WriteByte(0)
+	return buf.Bytes()
+}
+
+// findAttrValue returns the value of the constant attribute attr of the
+// debugging information entry at the start of buf.
+func findAttrValue(buf *bytes.Buffer, abbrevs []abbrevDecl, attr uint64, byteOrder binary.ByteOrder, offsz, addrSize int) (uint64, error) {
+	code, _ := util.DecodeULEB128(buf)
+	for _, decl := range abbrevs {
+		if decl.code != code {
+			continue
+		}
+		for _, field := range decl.fields {
+			form := field.form
+			for form == dwarfFormIndirect {
+				form, _ = util.DecodeULEB128(buf)
+			}
+			if field.attr != attr {
+				if err := skipAttrValue(buf, form, byteOrder, offsz, addrSize); err != nil {
+					return 0, err
+				}
+				continue
+			}
+			switch form {
+			case dwarfFormData1:
+				b, err := buf.ReadByte()
+				return uint64(b), err
+			case dwarfFormData2:
+				return util.ReadUintRaw(buf, byteOrder, 2)
+			case dwarfFormData4:
+				return util.ReadUintRaw(buf, byteOrder, 4)
+			case dwarfFormData8:
+				return util.ReadUintRaw(buf, byteOrder, 8)
+			case dwarfFormUdata:
+				v, _ := util.DecodeULEB128(buf)
+				return v, nil
+			default:
+				return 0, fmt.Errorf("unexpected form %#x", form)
+			}
+		}
+		return 0, errors.New("attribute not found")
+	}
+	return 0, fmt.Errorf("unknown abbreviation code %d", code)
+}
+
+// skipAttrValue skips an attribute value encoded with form.
+func skipAttrValue(buf *bytes.Buffer, form uint64, byteOrder binary.ByteOrder, offsz, addrSize int) error {
+	var sz uint64
+	var err error
+	switch form {
+	case dwarfFormFlagPresent, dwarfFormImplicitConst:
+	case dwarfFormData1, dwarfFormRef1, dwarfFormFlag:
+		sz = 1
+	case dwarfFormData2, dwarfFormRef2:
+		sz = 2
+	case dwarfFormData4, dwarfFormRef4:
+		sz = 4
+	case dwarfFormData8, dwarfFormRef8, dwarfFormRefSig8:
+		sz = 8
+	case dwarfFormAddr:
+		sz = uint64(addrSize)
+	case dwarfFormStrp, dwarfFormRefAddr, dwarfFormSecOffset, dwarfFormGNURefAlt, dwarfFormGNUStrpAlt:
+		sz = uint64(offsz)
+	case dwarfFormSdata:
+		util.DecodeSLEB128(buf)
+	case dwarfFormUdata, dwarfFormRefUdata, dwarfFormStrx, dwarfFormAddrx, dwarfFormGNUStrIndex, dwarfFormGNUAddrIndex:
+		util.DecodeULEB128(buf)
+	case dwarfFormString:
+		_, err = buf.ReadBytes(0)
+	case dwarfFormBlock1:
+		var b byte
+		b, err = buf.ReadByte()
+		sz = uint64(b)
+	case dwarfFormBlock2:
+		sz, err = util.ReadUintRaw(buf, byteOrder, 2)
+	case dwarfFormBlock4:
+		sz, err = util.ReadUintRaw(buf, byteOrder, 4)
+	case dwarfFormBlock, dwarfFormExprloc:
+		sz, _ = util.DecodeULEB128(buf)
+	default:
+		return fmt.Errorf("unsupported form %#x", form)
+	}
+	if err != nil || uint64(buf.Len()) < sz {
+		return errors.New("unexpected end of entry")
+	}
+	buf.Next(int(sz))
+	return nil
+}
+
+// dwpFile is a DWARF package file, containing the split compile units of
+// an executable.
+type dwpFile struct {
+	path     string
+	sections map[int][]byte
+	str      []byte
+	units    map[uint64]map[int][2]uint32 // DWO id -> section id -> offset and size of the contribution
+}
+
+// openDwp reads the DWARF package file at path.
+func openDwp(path string) (*dwpFile, error) {
+	f, err := elf.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	dwp := &dwpFile{path: path, sections: make(map[int][]byte)}
+
+	read := func(name string) ([]byte, error) {
+		s := f.Section(name)
+		if s == nil {
+			return nil, nil
+		}
+		return s.Data()
+	}
+
+	for _, sect := range []struct {
+		name string
+		id   int
+	}{
+		{".debug_info.dwo", dwarfSectInfo},
+		{".debug_abbrev.dwo", dwarfSectAbbrev},
+		{".debug_loclists.dwo", dwarfSectLoclists},
+		{".debug_str_offsets.dwo", dwarfSectStrOffsets},
+		{".debug_rnglists.dwo", dwarfSectRnglists},
+	} {
+		data, err := read(sect.name)
+		if err != nil {
+			return nil, err
+		}
+		dwp.sections[sect.id] = data
+	}
+	dwp.str, err = read(".debug_str.dwo")
+	if err != nil {
+		return nil, err
+	}
+	index, err := read(".debug_cu_index")
+	if err != nil {
+		return nil, err
+	}
+	if index == nil {
+		return nil, errors.New("no .debug_cu_index section")
+	}
+	dwp.units, err = parseCUIndex(index, f.ByteOrder)
+	if err != nil {
+		return nil, err
+	}
+	return dwp, nil
+}
+
+// parseCUIndex parses the contents of a .debug_cu_index section, returning
+// the contributions of each compile unit to the sections of the package.
+// See DWARFv5 section 7.3.5, version 2 of the GNU extension uses the same
+// identifiers for the sections read here, except for range lists which it
+// does not have.
+func parseCUIndex(data []byte, order binary.ByteOrder) (map[uint64]map[int][2]uint32, error) {
+	buf := bytes.NewReader(data)
+	var hdr struct {
+		Version      uint32
+		SectionCount uint32
+		UnitCount    uint32
+		SlotCount    uint32
+	}
+	if err := binary.Read(buf, order, &hdr); err != nil {
+		return nil, fmt.Errorf("could not read .debug_cu_index header: %v", err)
+	}
+	if hdr.Version>>16 != 0 {
+		// DWARF 5 uses a 2 byte version followed by 2 bytes of padding, the
+		// GNU extension (version 2) a 4 byte version
+		hdr.Version >>= 16
+	}
+	if hdr.Version != 2 && hdr.Version != 5 {
+		return nil, fmt.Errorf("unsupported .debug_cu_index version %d", hdr.Version)
+	}
+
+	signatures := make([]uint64, hdr.SlotCount)
+	rows := make([]uint32, hdr.SlotCount)
+	sectionIDs := make([]uint32, hdr.SectionCount)
+	offsets := make([]uint32, hdr.SectionCount*hdr.UnitCount)
+	sizes := make([]uint32, hdr.SectionCount*hdr.UnitCount)
+	for _, v := range []interface{}{signatures, rows, sectionIDs, offsets, sizes} {
+		if err := binary.Read(buf, order, v); err != nil {
+			return nil, fmt.Errorf("could not read .debug_cu_index: %v", err)
+		}
+	}
+
+	units := make(map[uint64]map[int][2]uint32)
+	for i, row := range rows {
+		if row == 0 {
+			continue
+		}
+		if row > hdr.UnitCount {
+			return nil, fmt.Errorf("invalid row %d in .debug_cu_index", row)
+		}
+		contribs := make(map[int][2]uint32)
+		for j, id := range sectionIDs {
+			k := (row-1)*hdr.SectionCount + uint32(j)
+			contribs[int(id)] = [2]uint32{offsets[k], sizes[k]}
+		}
+		units[signatures[i]] = contribs
+	}
+	return units, nil
+}
+
+// unit returns the split compile unit with the specified DWO id, or nil if
+// the package does not contain it.
+func (dwp *dwpFile) unit(dwoID uint64) *splitUnit {
+	contribs, ok := dwp.units[dwoID]
+	if !ok {
+		return nil
+	}
+	section := func(id int) []byte {
+		c, ok := contribs[id]
+		data := dwp.sections[id]
+		if !ok || uint64(c[0])+uint64(c[1]) > uint64(len(data)) {
+			return nil
+		}
+		return data[c[0] : c[0]+c[1]]
+	}
+	info := section(dwarfSectInfo)
+	_, _, version, _ := util.ReadDwarfLengthVersion(info)
+	return &splitUnit{
+		path:       dwp.path,
+		version:    version,
+		info:       info,
+		abbrev:     section(dwarfSectAbbrev),
+		str:        dwp.str,
+		strOffsets: section(dwarfSectStrOffsets),
+		loclists:   section(dwarfSectLoclists),
+		rnglists:   section(dwarfSectRnglists),
+	}
+}
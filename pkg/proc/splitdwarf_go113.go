@@ -0,0 +1,14 @@
+// +build !go1.14
+
+package proc
+
+import (
+	"debug/dwarf"
+	"errors"
+)
+
+// dwarfData returns an error: reading the string offsets and addresses of a
+// split compile unit requires debug/dwarf.Data.AddSection, added in Go 1.14.
+func (su *splitUnit) dwarfData(debugAddr []byte) (*dwarf.Data, error) {
+	return nil, errors.New("split DWARF requires Delve to be built with Go 1.14 or later")
+}
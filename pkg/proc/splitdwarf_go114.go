@@ -0,0 +1,61 @@
+// +build go1.14
+
+package proc
+
+import (
+	"debug/dwarf"
+	"errors"
+
+	"github.com/go-delve/delve/pkg/dwarf/util"
+)
+
+// dwarfData returns the DWARF data of the split compile unit, debugAddr is the
+// contribution of the skeleton compile unit to the debug_addr section.
+func (su *splitUnit) dwarfData(debugAddr []byte) (*dwarf.Data, error) {
+	if su.info == nil || su.abbrev == nil {
+		return nil, errors.New("missing .debug_info.dwo or .debug_abbrev.dwo section")
+	}
+
+	abbrev, info := su.abbrev, su.info
+	strOffsets, rnglists := skipSectionHeader(su.strOffsets, 2+2), skipSectionHeader(su.rnglists, 2+1+1+4)
+	if su.version < 5 {
+		var err error
+		abbrev, info, err = su.gnuSections()
+		if err != nil {
+			return nil, err
+		}
+		// the GNU extension has no header for debug_str_offsets and uses
+		// debug_ranges of the executable instead of debug_rnglists
+		strOffsets, rnglists = su.strOffsets, nil
+	}
+
+	dwarfData, err := dwarf.New(abbrev, nil, nil, info, nil, nil, nil, su.str)
+	if err != nil {
+		return nil, err
+	}
+
+	// A split compile unit does not have the DW_AT_str_offsets_base,
+	// DW_AT_rnglists_base and DW_AT_addr_base attributes, its string
+	// offsets and range lists tables immediately follow the header of their
+	// sections and its addresses start at the DW_AT_addr_base of the skeleton
+	// unit. Since debug/dwarf assumes missing bases to be 0 the sections are
+	// passed to it starting from their bases.
+	dwarfData.AddSection(".debug_str_offsets", strOffsets)
+	dwarfData.AddSection(".debug_rnglists", rnglists)
+	dwarfData.AddSection(".debug_addr", debugAddr)
+	return dwarfData, nil
+}
+
+// skipSectionHeader returns the contents of a DWARF 5 section following
+// its header, sz is the size of the header after the unit_length field.
+func skipSectionHeader(data []byte, sz int) []byte {
+	_, dwarf64, _, _ := util.ReadDwarfLengthVersion(data)
+	sz += 4
+	if dwarf64 {
+		sz += 8
+	}
+	if len(data) < sz {
+		return nil
+	}
+	return data[sz:]
+}
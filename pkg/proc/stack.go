@@ -1,12 +1,14 @@
 package proc
 
 import (
+	"bytes"
 	"debug/dwarf"
 	"errors"
 	"fmt"
 	"go/constant"
 
 	"github.com/go-delve/delve/pkg/dwarf/frame"
+	"github.com/go-delve/delve/pkg/dwarf/godwarf"
 	"github.com/go-delve/delve/pkg/dwarf/op"
 	"github.com/go-delve/delve/pkg/dwarf/reader"
 )
@@ -48,6 +50,9 @@ type Stackframe struct {
 	Inlined bool
 	// Bottom is true if this is the bottom of the stack
 	Bottom bool
+	// Unwinder is the method used to compute the registers of this frame
+	// from the registers of the frame it called.
+	Unwinder FrameUnwinder
 
 	// lastpc is a memory address guaranteed to belong to the last instruction
 	// executed in this stack frame.
@@ -60,6 +65,12 @@ type Stackframe struct {
 	// Use this value to determine active lexical scopes for the stackframe.
 	lastpc uint64
 
+	// caller is the frame of the function that called the function of this
+	// frame (or of the function this frame is inlined into), it is used to
+	// recover the values that parameters had on entry to the function from
+	// the call site parameters of the caller.
+	caller *Stackframe
+
 	// TopmostDefer is the defer that would be at the top of the stack when a
 	// panic unwind would get to this call frame, in other words it's the first
 	// deferred function that will  be called if the runtime unwinds past this
@@ -93,6 +104,12 @@ func (frame *Stackframe) FramePointerOffset() int64 {
 // ThreadStacktrace returns the stack trace for thread.
 // Note the locations in the array are return addresses not call addresses.
 func ThreadStacktrace(thread Thread, depth int) ([]Stackframe, error) {
+	return ThreadStacktraceWithOptions(thread, depth, 0)
+}
+
+// ThreadStacktraceWithOptions is like ThreadStacktrace but computes the
+// stacktrace using opts.
+func ThreadStacktraceWithOptions(thread Thread, depth int, opts StacktraceOptions) ([]Stackframe, error) {
 	g, _ := GetG(thread)
 	if g == nil {
 		regs, err := thread.Registers()
@@ -100,10 +117,10 @@ func ThreadStacktrace(thread Thread, depth int) ([]Stackframe, error) {
 			return nil, err
 		}
 		so := thread.BinInfo().PCToImage(regs.PC())
-		it := newStackIterator(thread.BinInfo(), thread.ProcessMemory(), thread.BinInfo().Arch.RegistersToDwarfRegisters(so.StaticBase, regs), 0, nil, -1, nil, 0)
+		it := newStackIterator(thread.BinInfo(), thread.ProcessMemory(), thread.BinInfo().Arch.RegistersToDwarfRegisters(so.StaticBase, regs), 0, nil, -1, nil, opts)
 		return it.stacktrace(depth)
 	}
-	return g.Stacktrace(depth, 0)
+	return g.Stacktrace(depth, opts)
 }
 
 func (g *G) stackIterator(opts StacktraceOptions) (*stackIterator, error) {
@@ -145,8 +162,41 @@ const (
 	// StacktraceG requests a stacktrace starting with the register
 	// values saved in the runtime.g structure.
 	StacktraceG
+
+	// StacktraceFramePointer requests a stacktrace that follows the chain of
+	// frame pointers, ignoring the call frame information of the executable.
+	StacktraceFramePointer
+)
+
+// FrameUnwinder is the method used to compute the registers of a stack
+// frame.
+type FrameUnwinder uint8
+
+const (
+	// UnwinderNone is used for frames whose registers were not unwound
+	// from a previous frame: the topmost frame and frames whose registers
+	// were read from the runtime during a stack switch.
+	UnwinderNone FrameUnwinder = iota
+	// UnwinderDwarf is used for frames unwound using the frame descriptor
+	// entries of the executable.
+	UnwinderDwarf
+	// UnwinderFramePointer is used for frames unwound by following the frame
+	// pointer, either because it was requested with StacktraceFramePointer or
+	// because no frame descriptor entry covers the PC of the previous frame.
+	UnwinderFramePointer
 )
 
+func (u FrameUnwinder) String() string {
+	switch u {
+	case UnwinderDwarf:
+		return "dwarf"
+	case UnwinderFramePointer:
+		return "fp"
+	default:
+		return ""
+	}
+}
+
 // Stacktrace returns the stack trace for a goroutine.
 // Note the locations in the array are return addresses not call addresses.
 func (g *G) Stacktrace(depth int, opts StacktraceOptions) ([]Stackframe, error) {
@@ -190,6 +240,17 @@ type stackIterator struct {
 
 	// regs is the register set for the current frame
 	regs op.DwarfRegisters
+	// unwinder is the method used to compute regs
+	unwinder FrameUnwinder
+	// callFrameUnwinder is the method used by the last call to advanceRegs
+	callFrameUnwinder FrameUnwinder
+	// sigframe is true if the current frame was interrupted by a signal, pc
+	// is then the address of the next instruction to execute instead of a
+	// return address
+	sigframe bool
+	// callFrameSigframe is true if the last call to advanceRegs unwound a
+	// signal handler frame (the 'S' augmentation of .eh_frame)
+	callFrameSigframe bool
 
 	g                  *G     // the goroutine being stacktraced, nil if we are stacktracing a goroutine-less thread
 	g0_sched_sp        uint64 // value of g0.sched.sp (see comments around its use)
@@ -259,12 +320,16 @@ func (it *stackIterator) Next() bool {
 	it.top = false
 	it.pc = it.frame.Ret
 	it.regs = callFrameRegs
+	it.unwinder = it.callFrameUnwinder
+	it.sigframe = it.callFrameSigframe
 	return true
 }
 
 func (it *stackIterator) switchToGoroutineStack() {
 	it.systemstack = false
 	it.top = false
+	it.unwinder = UnwinderNone
+	it.sigframe = false
 	it.pc = it.g.PC
 	it.regs.Reg(it.regs.SPRegNum).Uint64Val = it.g.SP
 	it.regs.AddReg(it.regs.BPRegNum, op.DwarfRegisterFromUint64(it.g.BP))
@@ -307,9 +372,9 @@ func (it *stackIterator) newStackframe(ret, retaddr uint64) Stackframe {
 	} else {
 		it.regs.FrameBase = it.frameBase(fn)
 	}
-	r := Stackframe{Current: Location{PC: it.pc, File: f, Line: l, Fn: fn}, Regs: it.regs, Ret: ret, addrret: retaddr, stackHi: it.stackhi, SystemStack: it.systemstack, lastpc: it.pc}
+	r := Stackframe{Current: Location{PC: it.pc, File: f, Line: l, Fn: fn}, Regs: it.regs, Ret: ret, addrret: retaddr, stackHi: it.stackhi, SystemStack: it.systemstack, Unwinder: it.unwinder, lastpc: it.pc}
 	r.Call = r.Current
-	if !it.top && r.Current.Fn != nil && it.pc != r.Current.Fn.Entry {
+	if !it.top && !it.sigframe && r.Current.Fn != nil && it.pc != r.Current.Fn.Entry {
 		// if the return address is the entry point of the function that
 		// contains it then this is some kind of fake return frame (for example
 		// runtime.sigreturn) that didn't actually call the current frame,
@@ -348,9 +413,23 @@ func (it *stackIterator) stacktrace(depth int) ([]Stackframe, error) {
 		}
 		frames = append(frames, Stackframe{Err: err})
 	}
+	linkCallerFrames(frames)
 	return frames, nil
 }
 
+// linkCallerFrames sets the caller field of every frame in frames.
+func linkCallerFrames(frames []Stackframe) {
+	for i := range frames {
+		j := i
+		for j < len(frames) && frames[j].Inlined {
+			j++
+		}
+		if j+1 < len(frames) && frames[j+1].Err == nil {
+			frames[i].caller = &frames[j+1]
+		}
+	}
+}
+
 func (it *stackIterator) appendInlineCalls(frames []Stackframe, frame Stackframe) []Stackframe {
 	if frame.Call.Fn == nil {
 		return append(frames, frame)
@@ -360,7 +439,7 @@ func (it *stackIterator) appendInlineCalls(frames []Stackframe, frame Stackframe
 	}
 
 	callpc := frame.Call.PC
-	if len(frames) > 0 {
+	if len(frames) > 0 && !it.sigframe {
 		callpc--
 	}
 
@@ -377,7 +456,8 @@ func (it *stackIterator) appendInlineCalls(frames []Stackframe, frame Stackframe
 		if !okname || !okfileidx || !okline {
 			break
 		}
-		if fileidx-1 < 0 || fileidx-1 >= int64(len(frame.Current.Fn.cu.lineInfo.FileNames)) {
+		callfile, okcallfile := frame.Current.Fn.cu.lineInfo.FileName(fileidx)
+		if !okcallfile {
 			break
 		}
 
@@ -397,10 +477,11 @@ func (it *stackIterator) appendInlineCalls(frames []Stackframe, frame Stackframe
 			Err:         frame.Err,
 			SystemStack: frame.SystemStack,
 			Inlined:     true,
+			Unwinder:    frame.Unwinder,
 			lastpc:      frame.lastpc,
 		})
 
-		frame.Call.File = frame.Current.Fn.cu.lineInfo.FileNames[fileidx-1].Path
+		frame.Call.File = callfile
 		frame.Call.Line = int(line)
 	}
 
@@ -408,18 +489,41 @@ func (it *stackIterator) appendInlineCalls(frames []Stackframe, frame Stackframe
 }
 
 // advanceRegs calculates it.callFrameRegs using it.regs and the frame
-// descriptor entry for the current stack frame.
-// it.regs.CallFrameCFA is updated.
+// descriptor entry for the current stack frame, or the frame pointer if
+// there is no frame descriptor entry for it.pc or the StacktraceFramePointer
+// option is set.
+// it.regs.CallFrameCFA and it.callFrameUnwinder are updated.
 func (it *stackIterator) advanceRegs() (callFrameRegs op.DwarfRegisters, ret uint64, retaddr uint64) {
-	fde, err := it.bi.frameEntries.FDEForPC(it.pc)
 	var framectx *frame.FrameContext
-	if _, nofde := err.(*frame.ErrNoFDEForPC); nofde {
+	it.callFrameSigframe = false
+	if it.opts&StacktraceFramePointer != 0 {
 		framectx = it.bi.Arch.fixFrameUnwindContext(nil, it.pc, it.bi)
+		it.callFrameUnwinder = UnwinderFramePointer
 	} else {
-		framectx = it.bi.Arch.fixFrameUnwindContext(fde.EstablishFrame(it.pc), it.pc, it.bi)
+		fde, err := it.bi.frameEntries.FDEForPC(it.pc)
+		if _, nofde := err.(*frame.ErrNoFDEForPC); nofde {
+			framectx = it.bi.Arch.fixFrameUnwindContext(nil, it.pc, it.bi)
+			it.callFrameUnwinder = UnwinderFramePointer
+		} else {
+			fdectx := fde.EstablishFrame(it.pc)
+			framectx = it.bi.Arch.fixFrameUnwindContext(fdectx, it.pc, it.bi)
+			it.callFrameUnwinder = UnwinderDwarf
+			it.callFrameSigframe = fde.CIE.SignalFrame
+			if framectx != fdectx {
+				// the frame descriptor entry was replaced with the frame
+				// pointer rule (for example inside runtime.sigreturn)
+				it.callFrameUnwinder = UnwinderFramePointer
+			}
+		}
 	}
 
-	cfareg, err := it.executeFrameRegRule(0, framectx.CFA, 0)
+	cfarule := framectx.CFA
+	if cfarule.Rule == frame.RuleExpression {
+		// DW_CFA_def_cfa_expression, the value of the expression is the CFA
+		// itself (not its address)
+		cfarule.Rule = frame.RuleValExpression
+	}
+	cfareg, _ := it.executeFrameRegRule(0, cfarule, 0)
 	if cfareg == nil {
 		it.err = fmt.Errorf("CFA becomes undefined at PC %#x", it.pc)
 		return op.DwarfRegisters{}, 0, 0
@@ -464,6 +568,15 @@ func (it *stackIterator) advanceRegs() (callFrameRegs op.DwarfRegisters, ret uin
 	return callFrameRegs, ret, retaddr
 }
 
+// exprRegs returns the registers used to evaluate the expressions of frame
+// descriptor entries, the expressions of signal handler frames dereference
+// the context saved on the stack by the kernel.
+func (it *stackIterator) exprRegs() op.DwarfRegisters {
+	regs := it.regs
+	regs.ReadMemory = it.mem.ReadMemory
+	return regs
+}
+
 func (it *stackIterator) executeFrameRegRule(regnum uint64, rule frame.DWRule, cfa int64) (*op.DwarfRegister, error) {
 	switch rule.Rule {
 	default:
@@ -483,13 +596,13 @@ func (it *stackIterator) executeFrameRegRule(regnum uint64, rule frame.DWRule, c
 	case frame.RuleRegister:
 		return it.regs.Reg(rule.Reg), nil
 	case frame.RuleExpression:
-		v, _, err := op.ExecuteStackProgram(it.regs, rule.Expression, it.bi.Arch.PtrSize())
+		v, _, err := op.ExecuteStackProgram(it.exprRegs(), rule.Expression, it.bi.Arch.PtrSize())
 		if err != nil {
 			return nil, err
 		}
 		return it.readRegisterAt(regnum, uint64(v))
 	case frame.RuleValExpression:
-		v, _, err := op.ExecuteStackProgram(it.regs, rule.Expression, it.bi.Arch.PtrSize())
+		v, _, err := op.ExecuteStackProgram(it.exprRegs(), rule.Expression, it.bi.Arch.PtrSize())
 		if err != nil {
 			return nil, err
 		}
@@ -678,6 +791,9 @@ func (d *Defer) EvalScope(thread Thread) (*EvalScope, error) {
 		scope.Regs.Reg(scope.Regs.SPRegNum).Uint64Val = uint64(scope.Regs.CFA)
 	}
 
+	if scope.Fn.cu.image.dwarf == nil {
+		return nil, errNoDwarf
+	}
 	rdr := scope.Fn.cu.image.dwarfReader
 	rdr.Seek(scope.Fn.offset)
 	e, err := rdr.Next()
@@ -689,3 +805,119 @@ func (d *Defer) EvalScope(thread Thread) (*EvalScope, error) {
 
 	return scope, nil
 }
+
+// entryValue returns the value that the DWARF expression expr, usually a
+// single register, had on entry to the function of frame.
+// If frame is stopped at the entry point of its function the current
+// registers are used, otherwise the value is read from the call site
+// parameters (DW_TAG_call_site_parameter) of the call instruction in the
+// caller frame.
+func (frame *Stackframe) entryValue(bi *BinaryInfo, expr []byte) (int64, error) {
+	if fn := frame.Current.Fn; fn != nil && frame.Current.PC == fn.Entry && frame.lastpc == fn.Entry {
+		v, _, err := op.ExecuteStackProgram(frame.Regs, expr, bi.Arch.PtrSize())
+		return v, err
+	}
+	return frame.callSiteValue(bi, func(param *godwarf.Tree) bool {
+		loc, _ := param.Val(dwarf.AttrLocation).([]byte)
+		return bytes.Equal(loc, expr)
+	})
+}
+
+// parameterRef returns the value that the formal parameter at offset off
+// had on entry to the function of frame, as specified by the call site
+// parameters of the call instruction in the caller frame.
+func (frame *Stackframe) parameterRef(bi *BinaryInfo, off dwarf.Offset) (int64, error) {
+	return frame.callSiteValue(bi, func(param *godwarf.Tree) bool {
+		ref, ok := param.Val(dwarfAttrCallParameter).(dwarf.Offset)
+		if !ok {
+			ref, ok = param.Val(dwarf.AttrAbstractOrigin).(dwarf.Offset)
+		}
+		return ok && ref == off
+	})
+}
+
+// callSiteValue evaluates, in the caller frame, the value of the first
+// parameter of the call site for frame that matches match.
+func (frame *Stackframe) callSiteValue(bi *BinaryInfo, match func(*godwarf.Tree) bool) (int64, error) {
+	caller := frame.caller
+	if caller == nil || caller.Current.Fn == nil {
+		return 0, &OptimizedOutError{}
+	}
+	image := caller.Current.Fn.cu.image
+	tree, err := image.getDwarfTree(caller.Current.Fn.offset)
+	if err != nil {
+		return 0, err
+	}
+	site := findCallSite(tree, caller.Current.PC-image.StaticBase)
+	if site == nil {
+		return 0, &OptimizedOutError{}
+	}
+	for _, param := range site.Children {
+		if param.Tag != dwarfTagCallSiteParameter && param.Tag != dwarfTagGNUCallSiteParameter {
+			continue
+		}
+		if !match(param) {
+			continue
+		}
+		value, ok := param.Val(dwarfAttrCallValue).([]byte)
+		if !ok {
+			value, ok = param.Val(dwarfAttrGNUCallSiteValue).([]byte)
+		}
+		if !ok {
+			break
+		}
+		regs := caller.Regs
+		regs.DebugAddr = image.debugAddrFunc(param.Offset)
+		caller.setEntryValueFuncs(bi, &regs)
+		v, pieces, err := op.ExecuteStackProgram(regs, value, bi.Arch.PtrSize())
+		if err != nil {
+			return 0, err
+		}
+		if len(pieces) > 1 {
+			return 0, errors.New("unsupported call site value")
+		}
+		return v, nil
+	}
+	return 0, &OptimizedOutError{}
+}
+
+// setEntryValueFuncs sets the callbacks used by DW_OP_entry_value and
+// DW_OP_GNU_parameter_ref to evaluate location expressions in frame.
+func (frame *Stackframe) setEntryValueFuncs(bi *BinaryInfo, regs *op.DwarfRegisters) {
+	regs.EntryValue = func(expr []byte) (int64, error) {
+		return frame.entryValue(bi, expr)
+	}
+	regs.ParameterRef = func(off uint64) (int64, error) {
+		fn := frame.Current.Fn
+		if fn == nil {
+			return 0, &OptimizedOutError{}
+		}
+		// the operand of DW_OP_GNU_parameter_ref is relative to the start of
+		// the compile unit
+		return frame.parameterRef(bi, fn.cu.unitOffset()+dwarf.Offset(off))
+	}
+}
+
+// findCallSite returns the call site entry (DW_TAG_call_site or
+// DW_TAG_GNU_call_site) of tree with return address retpc, the address
+// must not be relocated.
+func findCallSite(tree *godwarf.Tree, retpc uint64) *godwarf.Tree {
+	for _, child := range tree.Children {
+		switch child.Tag {
+		case dwarfTagCallSite, dwarfTagGNUCallSite:
+			pc, ok := child.Val(dwarfAttrCallReturnPC).(uint64)
+			if !ok {
+				// DW_TAG_GNU_call_site uses DW_AT_low_pc for the return address
+				pc, ok = child.Val(dwarf.AttrLowpc).(uint64)
+			}
+			if ok && pc == retpc {
+				return child
+			}
+		case dwarf.TagLexDwarfBlock, dwarf.TagInlinedSubroutine:
+			if site := findCallSite(child, retpc); site != nil {
+				return site
+			}
+		}
+	}
+	return nil
+}
@@ -58,6 +58,14 @@ type Target struct {
 	// have read and parsed from the targets memory.
 	// This must be cleared whenever the target is resumed.
 	gcache goroutineCache
+
+	// cover records the lines executed by the target, see SetCoverBreakpoints.
+	cover *coverState
+
+	// hostClock is true if the target runs on the same machine as the
+	// debugger and runtime.nanotime can be read from the clock of the
+	// debugger, see Nanotime.
+	hostClock bool
 }
 
 // ErrProcessExited indicates that the process has exited and contains both
@@ -120,6 +128,7 @@ type NewTargetConfig struct {
 	DebugInfoDirs       []string   // Directories to search for split debug info
 	DisableAsyncPreempt bool       // Go 1.14 asynchronous preemption should be disabled
 	StopReason          StopReason // Initial stop reason
+	HostClock           bool       // The target shares the monotonic clock of the debugger
 }
 
 // DisableAsyncPreemptEnv returns a process environment (like os.Environ)
@@ -159,6 +168,7 @@ func NewTarget(p Process, currentThread Thread, cfg NewTargetConfig) (*Target, e
 		fncallForG:    make(map[int]*callInjection),
 		StopReason:    cfg.StopReason,
 		currentThread: currentThread,
+		hostClock:     cfg.HostClock,
 	}
 
 	g, _ := GetG(currentThread)
@@ -270,6 +280,15 @@ func (t *Target) Detach(kill bool) error {
 	return t.proc.Detach(kill)
 }
 
+// MemoryMap returns the memory mappings of the target process, sorted by
+// address.
+func (t *Target) MemoryMap() ([]MemoryMapEntry, error) {
+	if _, err := t.Valid(); err != nil {
+		return nil, err
+	}
+	return t.proc.MemoryMap()
+}
+
 // setAsyncPreemptOff enables or disables async goroutine preemption by
 // writing the value 'v' to runtime.debug.asyncpreemptoff.
 // A value of '1' means off, a value of '0' means on.
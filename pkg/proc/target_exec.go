@@ -8,6 +8,7 @@ import (
 	"go/token"
 	"path/filepath"
 	"strings"
+	"time"
 
 	"github.com/go-delve/delve/pkg/astutil"
 	"github.com/go-delve/delve/pkg/dwarf/reader"
@@ -69,6 +70,7 @@ func (dbp *Target) Continue() error {
 		}
 		dbp.ClearAllGCache()
 		trapthread, stopReason, err := dbp.proc.ContinueOnce()
+		stopTime := time.Now()
 		dbp.StopReason = stopReason
 		if err != nil {
 			// Attempt to refresh status of current thread/current goroutine, see
@@ -89,11 +91,17 @@ func (dbp *Target) Continue() error {
 		}
 
 		threads := dbp.ThreadList()
+		for _, th := range threads {
+			if bpstate := th.Breakpoint(); bpstate.Breakpoint != nil {
+				bpstate.HitTime = stopTime
+			}
+		}
 
 		callInjectionDone, callErr := callInjectionProtocol(dbp, threads)
-		// callErr check delayed until after pickCurrentThread, which must always
-		// happen, otherwise the debugger could be left in an inconsistent
-		// state.
+		coverHit, coverErr := dbp.clearCoverBreakpoints(threads)
+		// callErr and coverErr checks delayed until after pickCurrentThread,
+		// which must always happen, otherwise the debugger could be left in an
+		// inconsistent state.
 
 		if err := pickCurrentThread(dbp, trapthread, threads); err != nil {
 			return err
@@ -102,11 +110,16 @@ func (dbp *Target) Continue() error {
 		if callErr != nil {
 			return callErr
 		}
+		if coverErr != nil {
+			return coverErr
+		}
 
 		curthread := dbp.CurrentThread()
 		curbp := curthread.Breakpoint()
 
 		switch {
+		case curbp.Breakpoint == nil && coverHit:
+			// only coverage breakpoints were hit, repeat
 		case curbp.Breakpoint == nil:
 			// runtime.Breakpoint, manual stop or debugCallV1-related stop
 			recorded, _ := dbp.Recorded()
@@ -700,6 +713,10 @@ func FindDeferReturnCalls(text []AsmInstruction) []uint64 {
 // belonging to the current function.
 func removeInlinedCalls(pcs []uint64, topframe Stackframe) ([]uint64, error) {
 	dwarfTree, err := topframe.Call.Fn.cu.image.getDwarfTree(topframe.Call.Fn.offset)
+	if err == errNoDwarf {
+		// without debug_info there is no information about inlined calls
+		return pcs, nil
+	}
 	if err != nil {
 		return pcs, err
 	}
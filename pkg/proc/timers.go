@@ -0,0 +1,294 @@
+package proc
+
+import (
+	"encoding/binary"
+	"errors"
+	"go/constant"
+	"reflect"
+	"sort"
+)
+
+// Timer status values, from: src/runtime/time.go (Go 1.14 and later).
+const (
+	timerNoStatus = iota
+	timerWaiting
+	timerRunning
+	timerDeleted
+	timerRemoving
+	timerRemoved
+	timerModifying
+	timerModifiedEarlier
+	timerModifiedLater
+	timerMoving
+)
+
+// Timer represents a pending timer of the Go runtime (a runtime.timer
+// struct).
+type Timer struct {
+	Addr   uint64 // Address of the runtime.timer struct
+	When   int64  // Value of runtime.nanotime() when the timer fires
+	Period int64  // Period of periodic timers (for example time.Ticker), 0 for one-shot timers
+
+	// Func is the function called when the timer fires. For timers
+	// created by time.AfterFunc this is the function passed to AfterFunc
+	// rather than the runtime wrapper that calls it.
+	Func Location
+
+	// GoroutineID is the ID of the goroutine waiting for the timer to fire,
+	// either sleeping (time.Sleep) or blocked on the channel of a time.Timer
+	// or time.Ticker. It is 0 if no goroutine is waiting on the timer.
+	GoroutineID int
+
+	Unreadable error // could not read the timer
+}
+
+var errNoTimers = errors.New("could not find timers (unsupported version of Go?)")
+
+// windowsInterruptTime is the address of the InterruptTime field of the
+// KUSER_SHARED_DATA structure, mapped at the same address in every process
+// on Windows.
+const windowsInterruptTime = 0x7ffe0008
+
+// timerLoadConfig loads all fields of a runtime.timer without following
+// pointers, the value of the arg field is only partially loaded.
+var timerLoadConfig = LoadConfig{false, 1, 64, 0, -1, 0}
+
+// Timers returns the list of pending timers of the target process, sorted
+// by the time at which they will fire.
+// On Go 1.14 and later timers are read from the per-P timer heaps, on
+// earlier versions of Go from the global timer buckets.
+func Timers(p *Target) ([]*Timer, error) {
+	if _, err := p.Valid(); err != nil {
+		return nil, err
+	}
+	bi := p.BinInfo()
+	scope := globalScope(bi, bi.Images[0], p.Memory())
+
+	heaps, err := timerHeaps(scope)
+	if err != nil {
+		return nil, err
+	}
+
+	r := []*Timer{}
+	for _, heap := range heaps {
+		heap.loadValue(LoadConfig{MaxArrayValues: int(heap.Len)})
+		if heap.Unreadable != nil {
+			r = append(r, &Timer{Addr: heap.Addr, Unreadable: heap.Unreadable})
+			continue
+		}
+		for i := range heap.Children {
+			tptr := &heap.Children[i]
+			if tptr.Unreadable != nil {
+				r = append(r, &Timer{Unreadable: tptr.Unreadable})
+				continue
+			}
+			if len(tptr.Children) != 1 || tptr.Children[0].Addr == 0 {
+				continue
+			}
+			if t := parseTimer(tptr.Children[0].clone()); t != nil {
+				r = append(r, t)
+			}
+		}
+	}
+
+	sort.SliceStable(r, func(i, j int) bool {
+		return r[i].When < r[j].When
+	})
+	return r, nil
+}
+
+// Nanotime returns the current value of the clock used by the runtime of
+// the target process to schedule timers (runtime.nanotime). The clock can
+// only be read for running processes, not for core files and recordings,
+// and, outside of Windows, only for processes debugged by the native
+// backend, which run on the same machine as the debugger.
+func Nanotime(p *Target) (int64, error) {
+	if _, err := p.Valid(); err != nil {
+		return 0, err
+	}
+	if recorded, _ := p.Recorded(); recorded {
+		return 0, errors.New("the current time is not known for recorded processes and core files")
+	}
+	if p.BinInfo().GOOS != "windows" {
+		if !p.hostClock {
+			return 0, errors.New("the current time is only known for processes debugged by the native backend")
+		}
+		return hostNanotime()
+	}
+	// On Windows runtime.nanotime reads the interrupt time, in units of
+	// 100ns, from KUSER_SHARED_DATA. The low part is followed by two copies
+	// of the high part, they are equal when the value is consistent.
+	buf := make([]byte, 12)
+	for {
+		if _, err := p.Memory().ReadMemory(buf, windowsInterruptTime); err != nil {
+			return 0, err
+		}
+		high1, high2 := binary.LittleEndian.Uint32(buf[4:]), binary.LittleEndian.Uint32(buf[8:])
+		if high1 == high2 {
+			return int64(uint64(high1)<<32|uint64(binary.LittleEndian.Uint32(buf))) * 100, nil
+		}
+	}
+}
+
+// timerHeaps returns the slices of *runtime.timer used by the runtime to
+// store pending timers.
+func timerHeaps(scope *EvalScope) ([]*Variable, error) {
+	allp, err := scope.findGlobal("runtime", "allp")
+	if err == nil && allp.Kind == reflect.Slice {
+		allp.loadValue(LoadConfig{MaxArrayValues: int(allp.Len)})
+		if allp.Unreadable != nil {
+			return nil, allp.Unreadable
+		}
+		heaps := []*Variable{}
+		for i := range allp.Children {
+			pptr := &allp.Children[i]
+			if pptr.Unreadable != nil || len(pptr.Children) != 1 || pptr.Children[0].Addr == 0 {
+				continue
+			}
+			timers, err := pptr.Children[0].structMember("timers")
+			if err != nil {
+				// Go 1.13 and earlier, timers are stored in runtime.timers
+				heaps = nil
+				break
+			}
+			heaps = append(heaps, timers)
+		}
+		if heaps != nil {
+			return heaps, nil
+		}
+	}
+
+	buckets, err := scope.findGlobal("runtime", "timers")
+	if err != nil {
+		return nil, errNoTimers
+	}
+	switch buckets.Kind {
+	case reflect.Array:
+		// Go 1.10 to Go 1.13, runtime.timers is an array of timersBucket
+		buckets.loadValue(LoadConfig{MaxArrayValues: int(buckets.Len)})
+		if buckets.Unreadable != nil {
+			return nil, buckets.Unreadable
+		}
+		heaps := make([]*Variable, 0, len(buckets.Children))
+		for i := range buckets.Children {
+			t, err := buckets.Children[i].structMember("t")
+			if err != nil {
+				return nil, err
+			}
+			heaps = append(heaps, t)
+		}
+		return heaps, nil
+	case reflect.Struct:
+		// Go 1.9 and earlier, a single timer heap.
+		t, err := buckets.structMember("t")
+		if err != nil {
+			return nil, err
+		}
+		return []*Variable{t}, nil
+	}
+	return nil, errNoTimers
+}
+
+// parseTimer reads the runtime.timer struct tv. Returns nil if the timer
+// has been deleted.
+func parseTimer(tv *Variable) *Timer {
+	t := &Timer{Addr: tv.Addr}
+	tv.loadValue(timerLoadConfig)
+	if tv.Unreadable != nil {
+		t.Unreadable = tv.Unreadable
+		return t
+	}
+
+	loadInt64 := func(name string) int64 {
+		fv := tv.fieldVariable(name)
+		if fv == nil || fv.Value == nil {
+			return 0
+		}
+		n, _ := constant.Int64Val(fv.Value)
+		return n
+	}
+
+	t.When = loadInt64("when")
+	t.Period = loadInt64("period")
+
+	if tv.fieldVariable("status") != nil {
+		switch loadInt64("status") {
+		case timerDeleted, timerRemoving, timerRemoved:
+			return nil
+		case timerModifiedEarlier, timerModifiedLater:
+			t.When = loadInt64("nextwhen")
+		}
+	}
+
+	fv := tv.fieldVariable("f")
+	if fv == nil || fv.Unreadable != nil {
+		t.Unreadable = errors.New("could not read timer function")
+		return t
+	}
+	t.Func = timerFuncLocation(tv.bi, fv.Base)
+
+	argv := tv.fieldVariable("arg")
+	if argv == nil || argv.Unreadable != nil || len(argv.Children) != 1 {
+		return t
+	}
+	data := &argv.Children[0]
+	if data.Unreadable != nil {
+		return t
+	}
+
+	switch {
+	case data.Kind == reflect.Ptr && data.RealType.String() == "*runtime.g" && len(data.Children) == 1:
+		// time.Sleep, the argument is the sleeping goroutine.
+		t.GoroutineID = goidOf(&data.Children[0])
+	case data.Kind == reflect.Chan:
+		// time.NewTimer, time.After and time.Ticker, the argument is the
+		// channel the current time will be sent to.
+		t.GoroutineID = chanFirstReceiver(data)
+	case data.Kind == reflect.Func && data.Base != 0:
+		// time.AfterFunc, the argument is the function that will be called.
+		t.Func = timerFuncLocation(tv.bi, data.Base)
+	}
+	return t
+}
+
+func timerFuncLocation(bi *BinaryInfo, pc uint64) Location {
+	f, l, fn := bi.PCToLine(pc)
+	return Location{PC: pc, File: f, Line: l, Fn: fn}
+}
+
+// goidOf returns the ID of the goroutine described by the runtime.g
+// struct gv.
+func goidOf(gv *Variable) int {
+	goidv := gv.loadFieldNamed("goid")
+	if goidv == nil {
+		return 0
+	}
+	n, _ := constant.Int64Val(goidv.Value)
+	return int(n)
+}
+
+// chanFirstReceiver returns the ID of the first goroutine waiting to
+// receive from channel ch or 0 if no goroutine is waiting on it.
+func chanFirstReceiver(ch *Variable) int {
+	recvq, err := ch.structMember("recvq")
+	if err != nil {
+		return 0
+	}
+	first, err := recvq.structMember("first")
+	if err != nil {
+		return 0
+	}
+	sudog := first.maybeDereference()
+	if sudog.Unreadable != nil || sudog.Addr == 0 {
+		return 0
+	}
+	gptr, err := sudog.structMember("g")
+	if err != nil {
+		return 0
+	}
+	gv := gptr.maybeDereference()
+	if gv.Unreadable != nil || gv.Addr == 0 {
+		return 0
+	}
+	return goidOf(gv)
+}
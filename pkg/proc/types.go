@@ -81,6 +81,11 @@ type loadDebugInfoMapsContext struct {
 	abstractOriginTable map[dwarf.Offset]int
 	knownPackageVars    map[string]struct{}
 	offsetToVersion     map[dwarf.Offset]uint8
+
+	// dwp is the DWARF package file of the image, if dwpSearched is true
+	// and one was found.
+	dwp         *dwpFile
+	dwpSearched bool
 }
 
 func newLoadDebugInfoMapsContext(bi *BinaryInfo, image *Image, offsetToVersion map[dwarf.Offset]uint8) *loadDebugInfoMapsContext {
@@ -151,6 +156,47 @@ func runtimeTypeToDIE(_type *Variable, dataAddr uint64) (typ godwarf.Type, kind
 	return typ, kind, nil
 }
 
+// runtimeTypeAddr returns the address of the runtime._type struct
+// describing typ. This is the inverse of runtimeTypeToDIE and it only works
+// on go1.11 and later.
+func runtimeTypeAddr(bi *BinaryInfo, mem MemoryReadWriter, typ godwarf.Type) (uint64, error) {
+	mds, err := loadModuleData(bi, mem)
+	if err != nil {
+		return 0, fmt.Errorf("error loading module data: %v", err)
+	}
+	for i := range mds {
+		so := bi.moduleDataToImage(&mds[i])
+		if so == nil || so.index != typ.Common().Index {
+			continue
+		}
+		for off, rtdie := range so.runtimeTypeToDIE {
+			if rtdie.offset == typ.Common().Offset {
+				return mds[i].types + off, nil
+			}
+		}
+	}
+	return 0, fmt.Errorf("could not find runtime type for %s", typ.String())
+}
+
+// typeHasPointers returns true if values of type typ can contain pointers.
+func typeHasPointers(typ godwarf.Type) bool {
+	switch typ := resolveTypedef(typ).(type) {
+	case *godwarf.IntType, *godwarf.UintType, *godwarf.FloatType, *godwarf.ComplexType, *godwarf.BoolType, *godwarf.CharType, *godwarf.UcharType:
+		return false
+	case *godwarf.ArrayType:
+		return typeHasPointers(typ.Type)
+	case *godwarf.StructType:
+		for _, field := range typ.Field {
+			if typeHasPointers(field.Type) {
+				return true
+			}
+		}
+		return false
+	default:
+		return true
+	}
+}
+
 type nameOfRuntimeTypeEntry struct {
 	typename string
 	kind     int64
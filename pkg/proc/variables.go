@@ -79,6 +79,9 @@ const (
 	VariableFakeAddress
 	// VariableCPrt means the variable is a C pointer
 	VariableCPtr
+	// VariableClosure means the variable was captured by the closure the
+	// current function belongs to.
+	VariableClosure
 )
 
 // Variable represents a variable. It contains the address, name,
@@ -119,6 +122,11 @@ type Variable struct {
 
 	Children []Variable
 
+	// Captured contains the variables captured by the closure described by
+	// a function variable. They are kept separate from Children, which
+	// holds the receiver of method values.
+	Captured []Variable
+
 	loaded     bool
 	Unreadable error
 
@@ -442,6 +450,9 @@ func getGVariable(thread Thread) (*Variable, error) {
 
 func newGVariable(thread Thread, gaddr uint64, deref bool) (*Variable, error) {
 	typ, err := thread.BinInfo().findType("runtime.g")
+	if err != nil && thread.BinInfo().Images[0].dwarf == nil {
+		typ, err = runtimeGTypeNoDwarf(thread.BinInfo(), thread.ProcessMemory())
+	}
 	if err != nil {
 		return nil, err
 	}
@@ -839,6 +850,15 @@ func (v *Variable) parseG() (*G, error) {
 			unreadable = true
 			return 0
 		}
+		if vv.Kind == reflect.Struct {
+			// starting with Go 1.20 atomicstatus is an atomic.Uint32, a
+			// struct wrapping the value
+			vv = vv.fieldVariable("value")
+			if vv == nil {
+				unreadable = true
+				return 0
+			}
+		}
 		n, _ := constant.Int64Val(vv.Value)
 		return n
 	}
@@ -1134,7 +1154,18 @@ func extractVarInfoFromEntry(bi *BinaryInfo, image *Image, regs op.DwarfRegister
 		return nil, err
 	}
 
+	regs.DebugAddr = image.debugAddrFunc(entry.Offset)
 	addr, pieces, descr, err := bi.Location(entry, dwarf.AttrLocation, regs.PC(), regs)
+	if len(pieces) == 1 && pieces[0].Kind == op.ImplicitPtrPiece {
+		v, err := implicitPointerVariable(bi, image, regs, mem, n, t, pieces[0])
+		if err != nil {
+			v = newVariable(n, 0, t, bi, mem)
+			v.Unreadable = err
+		}
+		v.LocationExpr = descr
+		v.DeclLine, _ = entry.Val(dwarf.AttrDeclLine).(int64)
+		return v, nil
+	}
 	if pieces != nil {
 		addr = fakeAddress
 		var cmem *compositeMemory
@@ -1156,6 +1187,48 @@ func extractVarInfoFromEntry(bi *BinaryInfo, image *Image, regs op.DwarfRegister
 	return v, nil
 }
 
+// implicitPointerVariable returns a variable of pointer type typ, named
+// name, described by piece, a DW_OP_implicit_pointer: the pointer itself was
+// optimized out but the value it points to is described by another DIE.
+func implicitPointerVariable(bi *BinaryInfo, image *Image, regs op.DwarfRegisters, mem MemoryReadWriter, name string, typ godwarf.Type, piece op.Piece) (*Variable, error) {
+	if _, isptr := resolveTypedef(typ).(*godwarf.PtrType); !isptr {
+		return nil, fmt.Errorf("implicit pointer for variable of type %s", typ.String())
+	}
+	tree, err := image.getDwarfTree(dwarf.Offset(piece.Ref))
+	if err != nil {
+		return nil, err
+	}
+	target, err := extractVarInfoFromEntry(bi, image, regs, mem, tree)
+	if err != nil {
+		return nil, err
+	}
+	if target.Unreadable != nil {
+		return nil, fmt.Errorf("implicit pointer to %s: %v", target.Name, target.Unreadable)
+	}
+	if target.Flags&VariableFakeAddress == 0 {
+		// the value pointed to is in memory, the pointer can be read from
+		// composite memory containing its address.
+		buf := make([]byte, 8)
+		binary.LittleEndian.PutUint64(buf, uint64(int64(target.Addr)+piece.Offset))
+		cmem, err := newCompositeMemory(mem, regs, []op.Piece{{Kind: op.ImmPiece, Bytes: buf}})
+		if err != nil {
+			return nil, err
+		}
+		v := newVariable(name, fakeAddress, typ, bi, cmem)
+		v.Flags |= VariableFakeAddress
+		return v, nil
+	}
+	if piece.Offset != 0 {
+		return nil, fmt.Errorf("implicit pointer to %s+%d, which is not in memory", target.Name, piece.Offset)
+	}
+	// the value pointed to is not in memory, it becomes the (already
+	// dereferenced) child of the pointer.
+	v := newVariable(name, 0, typ, bi, mem)
+	v.Children = []Variable{*target}
+	v.loaded = true
+	return v, nil
+}
+
 // If v is a pointer a new variable is returned containing the value pointed by v.
 func (v *Variable) maybeDereference() *Variable {
 	if v.Unreadable != nil {
@@ -1303,6 +1376,9 @@ func (v *Variable) loadValueInternal(recurseLevel int, cfg LoadConfig) {
 		}
 	case reflect.Func:
 		v.readFunctionPtr()
+		if v.Unreadable == nil && v.closureAddr != 0 && recurseLevel <= cfg.MaxVariableRecurse {
+			v.loadClosureVariables(recurseLevel, cfg)
+		}
 	default:
 		v.Unreadable = fmt.Errorf("unknown or unsupported kind: \"%s\"", v.Kind.String())
 	}
@@ -1793,6 +1869,25 @@ func (v *Variable) readFunctionPtr() {
 	v.Value = constant.MakeString(fn.Name)
 }
 
+// loadClosureVariables loads the variables captured by the closure
+// described by v into v.Captured.
+func (v *Variable) loadClosureVariables(recurseLevel int, cfg LoadConfig) {
+	fn := v.bi.PCToFunc(v.Base)
+	if fn == nil {
+		return
+	}
+	image := v.bi.funcToImage(fn)
+	dwarfTree, err := image.getDwarfTree(fn.offset)
+	if err != nil {
+		return
+	}
+	layout := closureLayout(v.bi, image, dwarfTree)
+	for _, cv := range closureVariables(v.bi, DereferenceMemory(v.mem), layout, v.closureAddr) {
+		cv.loadValueInternal(recurseLevel+1, cfg)
+		v.Captured = append(v.Captured, *cv)
+	}
+}
+
 // funcvalAddr reads the address of the funcval contained in a function variable.
 func (v *Variable) funcvalAddr() uint64 {
 	val, err := readUintRaw(v.mem, v.Addr, int64(v.bi.Arch.PtrSize()))
@@ -1913,7 +2008,7 @@ func (v *Variable) mapIterator() *mapIterator {
 		}
 	}
 
-	if it.buckets.Kind != reflect.Struct || it.oldbuckets.Kind != reflect.Struct {
+	if it.buckets == nil || it.oldbuckets == nil || it.buckets.Kind != reflect.Struct || it.oldbuckets.Kind != reflect.Struct {
 		v.Unreadable = errMapBucketsNotStruct
 		return nil
 	}
@@ -5,11 +5,15 @@ package terminal
 import (
 	"bufio"
 	"bytes"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"go/parser"
 	"go/scanner"
 	"io"
+	"io/ioutil"
 	"math"
 	"os"
 	"os/exec"
@@ -20,6 +24,7 @@ import (
 	"strconv"
 	"strings"
 	"text/tabwriter"
+	"time"
 
 	"github.com/cosiner/argv"
 	"github.com/go-delve/delve/pkg/locspec"
@@ -124,9 +129,16 @@ See also: "help on", "help cond" and "help clear"`},
 		{aliases: []string{"trace", "t"}, group: breakCmds, cmdFn: tracepoint, helpMsg: `Set tracepoint.
 
 	trace [name] <linespec>
+	trace -instructions [-regs] [-mem] [-max <n>] [-o <file>] <from> <to>
 
 A tracepoint is a breakpoint that does not stop the execution of the program, instead when the tracepoint is hit a notification is displayed. See $GOPATH/src/github.com/go-delve/delve/Documentation/cli/locspec.md for the syntax of linespec.
 
+The second form single-steps the selected thread from the location <from> until it reaches the location <to>, printing the address, source line and disassembly of every instruction executed. If the thread is not stopped at <from> the program is continued until <from> is reached. Tracing stops after 10000 instructions, or the number specified with -max.
+
+	-regs	also print the registers changed by each instruction
+	-mem	also print the memory written by each instruction (only on x86, for the destination operand and for PUSH and CALL)
+	-o	write the trace to <file> instead of standard output
+
 See also: "help on", "help cond" and "help clear"`},
 		{aliases: []string{"restart", "r"}, group: runCmds, cmdFn: restart, helpMsg: `Restart process.
 
@@ -164,6 +176,12 @@ Optional [count] argument allows you to skip multiple lines.
 	
 	call [-unsafe] <function call expression>
 	
+Functions, methods, methods called through an interface value and function values (including closures) can be called, for example:
+
+	call f(1, 2)
+	call w.Write(buf)
+	call fnvar(x)
+
 Current limitations:
 - only pointers to stack-allocated objects can be passed as argument.
 - only some automatic type conversions are supported.
@@ -211,12 +229,41 @@ If no flag is specified the default is -u.`},
 Called without arguments it will show information about the current goroutine.
 Called with a single argument it will switch to the specified goroutine.
 Called with more arguments it will execute a command on the specified goroutine.`},
+		{aliases: []string{"timers"}, group: goroutineCmds, cmdFn: timers, helpMsg: `Print out pending timers.
+
+	timers
+
+Prints all pending timers of the runtime, sorted by the time at which they will fire. For each timer the function called when it fires and the goroutine waiting on it (for example a goroutine sleeping in time.Sleep or blocked on the channel of a time.Timer) are shown.
+
+The expiration time of each timer is shown relative to the current time of the target, negative values are timers that should have already fired. For core files and recordings, where the current time is not known, it is shown relative to the earliest pending timer.`},
+		{aliases: []string{"netpoll"}, group: goroutineCmds, cmdFn: netpoll, helpMsg: `Print out file descriptors registered with the network poller.
+
+	netpoll
+
+For each file descriptor known to the network poller of the runtime shows the goroutines blocked waiting for it to become readable or writable and the deadlines of pending reads and writes. Deadlines are expressed as values of the monotonic clock of the target process.
+
+When debugging a live process on Linux sockets are resolved to their local and remote endpoints, other file descriptors to the file they refer to.`},
 		{aliases: []string{"breakpoints", "bp"}, group: breakCmds, cmdFn: breakpoints, helpMsg: "Print out info for active breakpoints."},
 		{aliases: []string{"print", "p"}, group: dataCmds, allowedPrefixes: onPrefix | deferredPrefix, cmdFn: printVar, helpMsg: `Evaluate an expression.
 
-	[goroutine <n>] [frame <m>] print <expression>
+	[goroutine <n>] [frame <m>] print [%<verb>|-fmt <format>] <expression>
+	[goroutine <n>] [frame <m>] print -json <expression>
+
+See $GOPATH/src/github.com/go-delve/delve/Documentation/cli/expr.md for a description of supported expressions.
+
+The value can be formatted using a verb of the fmt package, for example:
+
+	print %x v
+	print %08b flags
+	print %c r
 
-See $GOPATH/src/github.com/go-delve/delve/Documentation/cli/expr.md for a description of supported expressions.`},
+or using one of the named formats hex, bin, oct, dec, char and quote:
+
+	print -fmt bin flags
+
+Formats are applied to all numbers contained in the value that the verb applies to, for example %f formats floating point numbers but leaves integers unchanged. Strings are only formatted by the %s, %q, %x and %X verbs, these verbs also format byte slices and arrays as a whole (for example %x prints a hex dump of a byte slice).
+
+The -json option prints the value encoded as JSON, see the dump-var command for a description of the encoding.`},
 		{aliases: []string{"whatis"}, group: dataCmds, cmdFn: whatisCommand, helpMsg: `Prints type of an expression.
 
 	whatis <expression>`},
@@ -224,7 +271,17 @@ See $GOPATH/src/github.com/go-delve/delve/Documentation/cli/expr.md for a descri
 
 	[goroutine <n>] [frame <m>] set <variable> = <value>
 
-See $GOPATH/src/github.com/go-delve/delve/Documentation/cli/expr.md for a description of supported expressions. Only numerical variables and pointers can be changed.`},
+See $GOPATH/src/github.com/go-delve/delve/Documentation/cli/expr.md for a description of supported expressions.
+
+Numerical variables, booleans, pointers and strings can be changed, variables of any type can also be assigned the value of another variable of the same type or a composite literal. Assigning to an element of a map that does not exist adds it to the map.
+
+For example:
+
+	set s = "hello"
+	set cfg = Config{Debug: true, Names: []string{"a", "b"}}
+	set m["k"] = v
+
+Literal strings, slice literals and new map elements need memory to be allocated in the target process, this is done by injecting a function call in the current goroutine (see the 'call' command).`},
 		{aliases: []string{"sources"}, cmdFn: sources, helpMsg: `Print list of source files.
 
 	sources [<regex>]
@@ -242,21 +299,23 @@ If regex is specified only the functions matching it will be returned.`},
 If regex is specified only the types matching it will be returned.`},
 		{aliases: []string{"args"}, allowedPrefixes: onPrefix | deferredPrefix, group: dataCmds, cmdFn: args, helpMsg: `Print function arguments.
 
-	[goroutine <n>] [frame <m>] args [-v] [<regex>]
+	[goroutine <n>] [frame <m>] args [-v] [%<verb>|-fmt <format>] [<regex>]
 
-If regex is specified only function arguments with a name matching it will be returned. If -v is specified more information about each function argument will be shown.`},
+If regex is specified only function arguments with a name matching it will be returned. If -v is specified more information about each function argument will be shown. Values are formatted as described in the help of the print command.`},
 		{aliases: []string{"locals"}, allowedPrefixes: onPrefix | deferredPrefix, group: dataCmds, cmdFn: locals, helpMsg: `Print local variables.
 
-	[goroutine <n>] [frame <m>] locals [-v] [<regex>]
+	[goroutine <n>] [frame <m>] locals [-v] [%<verb>|-fmt <format>] [<regex>]
 
 The name of variables that are shadowed in the current scope will be shown in parenthesis.
 
-If regex is specified only local variables with a name matching it will be returned. If -v is specified more information about each local variable will be shown.`},
+When the current function is a closure the variables it captured are listed separately, under 'closure'. Captured variables are recognized for executables built by Go 1.23 or later and, for closures allocated on the heap, by Go 1.15 or earlier.
+
+If regex is specified only local variables with a name matching it will be returned. If -v is specified more information about each local variable will be shown. Values are formatted as described in the help of the print command.`},
 		{aliases: []string{"vars"}, cmdFn: vars, group: dataCmds, helpMsg: `Print package variables.
 
-	vars [-v] [<regex>]
+	vars [-v] [%<verb>|-fmt <format>] [<regex>]
 
-If regex is specified only package variables with a name matching it will be returned. If -v is specified more information about each package variable will be shown.`},
+If regex is specified only package variables with a name matching it will be returned. If -v is specified more information about each package variable will be shown. Values are formatted as described in the help of the print command.`},
 		{aliases: []string{"regs"}, cmdFn: regs, group: dataCmds, helpMsg: `Print contents of CPU registers.
 
 	regs [-a]
@@ -292,6 +351,12 @@ For example:
 			normal	- attempts to automatically switch between cgo frames and go frames
 			simple	- disables automatic switch between cgo and go
 			fromg	- starts from the registers stored in the runtime.g struct
+			fp	- unwinds the stack by following the frame pointer, ignoring the
+			  call frame information of the executable
+
+Frames that were unwound using the frame pointer, because the executable
+has no call frame information for the function they called or because -mode fp
+was used, are marked with "(fp)".
 `},
 		{aliases: []string{"frame"},
 			group: stackCmds,
@@ -341,12 +406,17 @@ If path ends with the .star extension it will be interpreted as a starlark scrip
 If path is a single '-' character an interactive starlark interpreter will start instead. Type 'exit' to exit.`},
 		{aliases: []string{"disassemble", "disass"}, cmdFn: disassCommand, helpMsg: `Disassembler.
 
-	[goroutine <n>] [frame <m>] disassemble [-a <start> <end>] [-l <locspec>]
+	[goroutine <n>] [frame <m>] disassemble [-s] [-a <start> <end>] [-l <locspec>]
+	[goroutine <n>] [frame <m>] disassemble -cfg [<locspec>] [-o <file>]
 
 If no argument is specified the function being executed in the selected stack frame will be executed.
 
+	-s			interleaves the instructions with the source lines they belong to and marks the boundaries of inlined calls
 	-a <start> <end>	disassembles the specified address range
-	-l <locspec>		disassembles the specified function`},
+	-l <locspec>		disassembles the specified function
+	-cfg [<locspec>]	prints the control flow graph of the specified function, or of the function being executed, in Graphviz DOT format
+
+The control flow graph splits the function into basic blocks, each listing its source lines and instructions, instructions with a breakpoint are marked with '*' and the block containing the current instruction is highlighted. With -o the graph is written to <file> instead, as JSON if the name of the file ends in .json.`},
 		{aliases: []string{"on"}, group: breakCmds, cmdFn: c.onCmd, helpMsg: `Executes a command when a breakpoint is hit.
 
 	on <breakpoint name or id> <command>.
@@ -394,18 +464,79 @@ If locspec is omitted edit will open the current source file in the editor, othe
 
 Format represents the data format and the value is one of this list (default hex): bin(binary), oct(octal), dec(decimal), hex(hexadecimal), addr(address).
 Length is the number of bytes (default 1) and must be less than or equal to 1000.
-Address is the memory location of the target to examine. Please note '-len' is deprecated by '-count and -size'.
+Address is the memory location of the target to examine, if it can not be read the error reports whether it is outside all memory mappings of the target (see memregions). Please note '-len' is deprecated by '-count and -size'.
 
 For example:
 
     x -fmt hex -count 20 -size 1 0xc00008af38`},
 
+		{aliases: []string{"dump-var"}, group: dataCmds, cmdFn: dumpVarCommand, helpMsg: `Writes the value of an expression to a file as JSON.
+
+	[goroutine <n>] [frame <m>] dump-var <expression> <file>
+
+The value is loaded recursively, up to the limits configured with the config command (max-variable-recurse, max-array-values, max-string-len), increase them to export large data structures.
+
+Structs are written as JSON objects, numbers, strings and booleans as their JSON counterparts, arrays and slices as JSON arrays. Maps are written as JSON objects if their keys are numbers, strings or booleans, otherwise as arrays of {"key": ..., "value": ...} objects. Pointers and interfaces are dereferenced, nil values are written as null. The parts of the value that could not be exported are replaced by objects with a single key:
+
+	{"$unreadable": "<error>"}	the value could not be read
+	{"$cycle": "<address>"}	a pointer to a value already being written
+	{"$notLoaded": "<address>"}	a value not loaded because of the load limits
+	{"$more": <n>}	last element of a truncated array, slice or map
+
+Truncated strings are written as {"$string": "<loaded part>", "$more": <n>}.`},
+		{aliases: []string{"diff"}, group: dataCmds, allowedPrefixes: deferredPrefix, cmdFn: diffCommand, helpMsg: `Compare two values.
+
+	[goroutine <n>] [frame <m>] diff <expression1> <expression2>
+
+Prints the differences between the values of the two expressions, field by field. Structs, arrays, slices, maps, pointers and interfaces are compared recursively, up to the limits configured with the config command (max-variable-recurse, max-array-values, etc). The two values can have different but compatible types, for example two structs with the same fields.
+
+Each difference is printed as the path of the differing element followed by its value in the first and second expression, for example:
+
+	.Items[2].Name: "foo" != "bar"
+	["key"]: missing key: 1 != <missing>`},
+
+		{aliases: []string{"memregions"}, group: dataCmds, cmdFn: memregions, helpMsg: `Print the memory mappings of the target process.
+
+	memregions
+
+For each memory mapping prints its start and end address, permissions, mapped file and what the Go runtime uses it for: text (executable code), module data (data and bss sections of a Go module), heap (memory used by the Go heap) and the stacks of goroutines (with their IDs).
+
+The memory mappings are available for native processes on Linux, for core files and for gdbserver stubs implementing the qMemoryRegionInfo packet.`},
+
+		{aliases: []string{"find"}, group: dataCmds, cmdFn: findCommand, helpMsg: `Search memory for a sequence of bytes.
+
+	find [-n <max>] [-range <start> <end> | -mapping <address> | -heap] <pattern>
+
+The pattern is one of:
+
+	-x <bytes>		a sequence of bytes in hexadecimal, for example: -x deadbeef or -x "de ad be ef"
+	-s <string>		a string, it can be quoted using Go syntax, for example: -s "hello\n"
+	-i8|-i16|-i32|-i64 <n>	an integer of 8, 16, 32 or 64 bits
+
+A pattern without an option is searched as a string.
+
+By default all readable memory mappings are searched, this requires the memory mappings of the target process, which are available for native processes on Linux and for core files. Alternatively:
+
+	-range <start> <end>	searches the addresses between start (included) and end (excluded)
+	-mapping <address>	searches the memory mapping containing address
+	-heap			searches the memory used by the Go heap
+
+For each match its address is printed, followed by the heap object or the function or package variable containing it, if any. At most 100 matches are printed, use -n to change this limit (0 means no limit).
+
+For example:
+
+	find -heap -s "secret"
+	find -n 10 -i64 0xdeadbeef`},
+
 		{aliases: []string{"display"}, group: dataCmds, cmdFn: display, helpMsg: `Print value of an expression every time the program stops.
 
-	display -a <expression>
+	display -a [%<verb>|-fmt <format>] <expression>
 	display -d <number>
+	display -changes
+
+The '-a' option adds an expression to the list of expression printed every time the program stops. The '-d' option removes the specified expression from the list. The value of the expression is formatted as described in the help of the print command.
 
-The '-a' option adds an expression to the list of expression printed every time the program stops. The '-d' option removes the specified expression from the list.
+The '-changes' option toggles the highlighting of the expressions whose value changed since the previous time they were printed, changed expressions are marked with a '*'. Use the diff command to see in detail how two values differ.
 
 If display is called without arguments it will print the value of all expression in the list.`},
 	}
@@ -616,6 +747,90 @@ func threads(t *Term, ctx callContext, args string) error {
 	return nil
 }
 
+func timers(t *Term, ctx callContext, args string) error {
+	timers, now, err := t.client.ListTimers()
+	if err != nil {
+		return err
+	}
+	if len(timers) == 0 {
+		fmt.Println("No pending timers")
+		return nil
+	}
+	if now == 0 {
+		// core files and recordings, times are relative to the first timer
+		fmt.Println("Current time of the target not available, times are relative to the first timer")
+		now = timers[0].When
+	}
+	w := new(tabwriter.Writer)
+	w.Init(os.Stdout, 4, 4, 2, ' ', 0)
+	defer w.Flush()
+	for _, timer := range timers {
+		if timer.Unreadable != "" {
+			fmt.Fprintf(w, "Timer %#x\t(unreadable %s)\n", timer.Addr, timer.Unreadable)
+			continue
+		}
+		when := time.Duration(timer.When - now).String()
+		if timer.When >= now {
+			when = "+" + when
+		}
+		if timer.Period != 0 {
+			when += fmt.Sprintf(" every %v", time.Duration(timer.Period))
+		}
+		waiting := ""
+		if timer.GoroutineID != 0 {
+			waiting = fmt.Sprintf("goroutine %d", timer.GoroutineID)
+		}
+		fmt.Fprintf(w, "Timer %#x\t%s\t%s %s:%d\t%s\n", timer.Addr, when, timer.Func.Function.Name(), t.formatPath(timer.Func.File), timer.Func.Line, waiting)
+	}
+	return nil
+}
+
+func netpoll(t *Term, ctx callContext, args string) error {
+	pds, err := t.client.ListPollDescs()
+	if err != nil {
+		return err
+	}
+	if len(pds) == 0 {
+		fmt.Println("No file descriptors registered with the network poller")
+		return nil
+	}
+	w := new(tabwriter.Writer)
+	w.Init(os.Stdout, 4, 4, 2, ' ', 0)
+	defer w.Flush()
+	for _, pd := range pds {
+		if pd.Unreadable != "" {
+			fmt.Fprintf(w, "pollDesc %#x\t(unreadable %s)\n", pd.Addr, pd.Unreadable)
+			continue
+		}
+		desc := pd.Description
+		if pd.Closing {
+			desc += " (closing)"
+		}
+		fmt.Fprintf(w, "fd %d\t%s\t%s\t%s\n", pd.Fd, desc, formatPollWaiter("read", pd.ReadGoroutineID, pd.ReadDeadline), formatPollWaiter("write", pd.WriteGoroutineID, pd.WriteDeadline))
+	}
+	return nil
+}
+
+func formatPollWaiter(mode string, gid int, deadline int64) string {
+	var buf bytes.Buffer
+	if gid != 0 {
+		fmt.Fprintf(&buf, "%s: goroutine %d", mode, gid)
+	}
+	switch {
+	case deadline < 0:
+		if buf.Len() == 0 {
+			fmt.Fprintf(&buf, "%s:", mode)
+		}
+		buf.WriteString(" deadline expired")
+	case deadline > 0:
+		if buf.Len() == 0 {
+			fmt.Fprintf(&buf, "%s:", mode)
+		}
+		fmt.Fprintf(&buf, " deadline at %v", time.Duration(deadline))
+	}
+	return buf.String()
+}
+
 func thread(t *Term, ctx callContext, args string) error {
 	if len(args) == 0 {
 		return fmt.Errorf("you must specify a thread")
@@ -1526,9 +1741,132 @@ func breakpoint(t *Term, ctx callContext, args string) error {
 }
 
 func tracepoint(t *Term, ctx callContext, args string) error {
+	if args == "-instructions" || strings.HasPrefix(args, "-instructions ") {
+		return traceInstructions(t, ctx, strings.TrimSpace(args[len("-instructions"):]))
+	}
 	return setBreakpoint(t, ctx, true, args)
 }
 
+const defaultMaxTracedInstructions = 10000
+
+var traceInstructionsUsageError = errors.New("wrong number of arguments: trace -instructions [-regs] [-mem] [-max <n>] [-o <file>] <from> <to>")
+
+// traceInstructions implements 'trace -instructions'.
+func traceInstructions(t *Term, ctx callContext, args string) error {
+	var regs, mem bool
+	max := defaultMaxTracedInstructions
+	outpath := ""
+	v := strings.Fields(args)
+	for len(v) > 0 && strings.HasPrefix(v[0], "-") {
+		switch v[0] {
+		case "-regs":
+			regs = true
+		case "-mem":
+			mem = true
+		case "-max", "-o":
+			if len(v) < 2 {
+				return traceInstructionsUsageError
+			}
+			if v[0] == "-o" {
+				outpath = v[1]
+			} else {
+				n, err := strconv.Atoi(v[1])
+				if err != nil || n <= 0 {
+					return fmt.Errorf("wrong argument: %q is not a positive number", v[1])
+				}
+				max = n
+			}
+			v = v[1:]
+		default:
+			return fmt.Errorf("unknown option %s", v[0])
+		}
+		v = v[1:]
+	}
+	if len(v) != 2 {
+		return traceInstructionsUsageError
+	}
+
+	fromLocs, err := t.client.FindLocation(ctx.Scope, v[0], true, t.substitutePathRules())
+	if err != nil {
+		return err
+	}
+	if len(fromLocs) != 1 {
+		return fmt.Errorf("%s specifies multiple locations", v[0])
+	}
+	from := fromLocs[0].PC
+	toLocs, err := t.client.FindLocation(ctx.Scope, v[1], true, t.substitutePathRules())
+	if err != nil {
+		return err
+	}
+	to := make([]uint64, len(toLocs))
+	for i := range toLocs {
+		to[i] = toLocs[i].PC
+	}
+
+	defer t.onStop()
+
+	state, err := t.client.GetState()
+	if err != nil {
+		return err
+	}
+	if state.CurrentThread == nil || state.CurrentThread.PC != from {
+		bp, err := t.client.CreateBreakpoint(&api.Breakpoint{Addr: from})
+		if err != nil && !strings.Contains(err.Error(), "Breakpoint exists") {
+			return err
+		}
+		for state = range t.client.Continue() {
+			if state.Err != nil {
+				break
+			}
+		}
+		if bp != nil {
+			if _, err := t.client.ClearBreakpoint(bp.ID); err != nil && state.Err == nil {
+				return err
+			}
+		}
+		if state.Err != nil {
+			printcontextNoState(t)
+			return state.Err
+		}
+		if state.CurrentThread == nil || state.CurrentThread.PC != from {
+			printcontext(t, state)
+			return fmt.Errorf("stopped before reaching %s", v[0])
+		}
+	}
+
+	insts, reached, err := t.client.TraceInstructions(to, max, regs, mem, t.disassembleFlavour())
+
+	out := io.Writer(os.Stdout)
+	if outpath != "" {
+		f, err := os.Create(outpath)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		out = f
+	}
+	traceInstructionsPrint(insts, out)
+
+	if err != nil {
+		printcontextNoState(t)
+		return err
+	}
+	switch {
+	case reached:
+		fmt.Printf("%d instructions traced, reached %s\n", len(insts), v[1])
+	case len(insts) >= max:
+		fmt.Printf("%d instructions traced, stopped before reaching %s\n", len(insts), v[1])
+	default:
+		fmt.Printf("%d instructions traced, interrupted before reaching %s\n", len(insts), v[1])
+	}
+	state, err = t.client.GetState()
+	if err != nil {
+		return err
+	}
+	printcontext(t, state)
+	return nil
+}
+
 func edit(t *Term, ctx callContext, args string) error {
 	file, lineno, _, err := getLocation(t, ctx, args, false)
 	if err != nil {
@@ -1629,17 +1967,288 @@ func examineMemoryCmd(t *Term, ctx callContext, args string) error {
 
 	memArea, isLittleEndian, err := t.client.ExamineMemory(address, count*size)
 	if err != nil {
+		// listing the memory regions can be slow (with gdbserial it walks
+		// the whole address space), only do it to explain a failed read
+		if regions, rerr := t.client.ListMemoryRegions(false); rerr == nil && !insideMemoryRegion(regions, address) {
+			return fmt.Errorf("%v (address %#x is not inside any memory region of the target)", err, address)
+		}
 		return err
 	}
 	fmt.Print(api.PrettyExamineMemory(uintptr(address), memArea, isLittleEndian, priFmt, size))
 	return nil
 }
 
+// insideMemoryRegion returns true if addr is inside one of regions.
+func insideMemoryRegion(regions []api.MemoryRegion, addr uint64) bool {
+	for i := range regions {
+		if regions[i].Contains(addr) {
+			return true
+		}
+	}
+	return false
+}
+
+// maxMemregionsGoroutines is the maximum number of goroutine IDs printed
+// for each memory region by the memregions command.
+const maxMemregionsGoroutines = 10
+
+func memregions(t *Term, ctx callContext, args string) error {
+	if args != "" {
+		return errors.New("too many arguments")
+	}
+	regions, err := t.client.ListMemoryRegions(true)
+	if err != nil {
+		return err
+	}
+	w := new(tabwriter.Writer)
+	w.Init(os.Stdout, 0, 8, 2, ' ', 0)
+	for _, region := range regions {
+		perms := []byte("---")
+		if region.Read {
+			perms[0] = 'r'
+		}
+		if region.Write {
+			perms[1] = 'w'
+		}
+		if region.Exec {
+			perms[2] = 'x'
+		}
+		file := region.Filename
+		if file != "" && region.Offset != 0 {
+			file = fmt.Sprintf("%s+%#x", file, region.Offset)
+		}
+		fmt.Fprintf(w, "%#x-%#x\t%s\t%s\t%s\n", region.Addr, region.Addr+region.Size, perms, file, describeMemoryRegion(&region))
+	}
+	return w.Flush()
+}
+
+// describeMemoryRegion describes what the Go runtime uses a memory region
+// for.
+func describeMemoryRegion(region *api.MemoryRegion) string {
+	var v []string
+	if region.Text {
+		v = append(v, "text")
+	}
+	if region.ModuleData {
+		v = append(v, "module data")
+	}
+	if region.Heap {
+		v = append(v, "heap")
+	}
+	if len(region.Goroutines) > 0 {
+		var buf bytes.Buffer
+		fmt.Fprintf(&buf, "stacks of goroutines")
+		for i, gid := range region.Goroutines {
+			if i >= maxMemregionsGoroutines {
+				fmt.Fprintf(&buf, " ...+%d more", len(region.Goroutines)-i)
+				break
+			}
+			fmt.Fprintf(&buf, " %d", gid)
+		}
+		v = append(v, buf.String())
+	}
+	return strings.Join(v, ", ")
+}
+
+// defaultFindMaxResults is the default maximum number of results printed
+// by the find command.
+const defaultFindMaxResults = 100
+
+func findCommand(t *Term, ctx callContext, args string) error {
+	var (
+		scope      = api.MemorySearchAll
+		start, end uint64
+		max        = defaultFindMaxResults
+		pattern    []byte
+		err        error
+	)
+
+	parseAddr := func(s string) (uint64, error) {
+		addr, err := strconv.ParseUint(s, 0, 64)
+		if err != nil {
+			return 0, fmt.Errorf("could not parse address %q: %v", s, err)
+		}
+		return addr, nil
+	}
+
+	rest := strings.TrimSpace(args)
+	for pattern == nil {
+		v := split2PartsBySpace(rest)
+		arg := v[0]
+		rest = ""
+		if len(v) == 2 {
+			rest = v[1]
+		}
+		switch arg {
+		case "":
+			return errors.New("no pattern specified")
+		case "-n":
+			v = split2PartsBySpace(rest)
+			if max, err = strconv.Atoi(v[0]); err != nil || max < 0 {
+				return errors.New("-n must be followed by a non-negative integer")
+			}
+			rest = ""
+			if len(v) == 2 {
+				rest = v[1]
+			}
+		case "-range":
+			v = strings.SplitN(rest, " ", 3)
+			if len(v) < 2 {
+				return errors.New("-range must be followed by two addresses")
+			}
+			if start, err = parseAddr(v[0]); err != nil {
+				return err
+			}
+			if end, err = parseAddr(v[1]); err != nil {
+				return err
+			}
+			scope = api.MemorySearchRange
+			rest = ""
+			if len(v) == 3 {
+				rest = strings.TrimSpace(v[2])
+			}
+		case "-mapping":
+			v = split2PartsBySpace(rest)
+			if start, err = parseAddr(v[0]); err != nil {
+				return err
+			}
+			scope = api.MemorySearchMapping
+			rest = ""
+			if len(v) == 2 {
+				rest = v[1]
+			}
+		case "-heap":
+			scope = api.MemorySearchHeap
+		case "-x":
+			pattern, err = parseHexPattern(rest)
+			if err != nil {
+				return err
+			}
+		case "-s":
+			pattern, err = parseStringPattern(rest)
+			if err != nil {
+				return err
+			}
+		case "-i8", "-i16", "-i32", "-i64":
+			pattern, err = parseIntPattern(arg[2:], rest)
+			if err != nil {
+				return err
+			}
+		default:
+			if strings.HasPrefix(arg, "-") {
+				return fmt.Errorf("unknown option %q", arg)
+			}
+			pattern, err = parseStringPattern(strings.TrimSpace(arg + " " + rest))
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	results, err := t.client.SearchMemory(scope, start, end, pattern, max)
+	if err != nil {
+		return err
+	}
+	if len(results) == 0 {
+		fmt.Println("pattern not found")
+		return nil
+	}
+	w := new(tabwriter.Writer)
+	w.Init(os.Stdout, 0, 8, 1, ' ', 0)
+	for _, r := range results {
+		switch {
+		case r.Heap:
+			fmt.Fprintf(w, "%#x\theap object %#x+%d (size %d)\n", r.Addr, r.Base, r.Addr-r.Base, r.Size)
+		case r.Symbol != "":
+			fmt.Fprintf(w, "%#x\t%s+%d\n", r.Addr, r.Symbol, r.Addr-r.Base)
+		default:
+			fmt.Fprintf(w, "%#x\t\n", r.Addr)
+		}
+	}
+	w.Flush()
+	if max > 0 && len(results) >= max {
+		fmt.Printf("(results truncated, use -n to show more)\n")
+	}
+	return nil
+}
+
+// parseHexPattern parses a sequence of bytes written in hexadecimal, with
+// an optional 0x prefix and optionally separated by spaces.
+func parseHexPattern(s string) ([]byte, error) {
+	s = strings.Trim(strings.TrimSpace(s), "\"")
+	s = strings.Replace(strings.TrimPrefix(s, "0x"), " ", "", -1)
+	if s == "" {
+		return nil, errors.New("-x must be followed by a sequence of bytes")
+	}
+	b, err := hex.DecodeString(s)
+	if err != nil {
+		return nil, fmt.Errorf("could not parse byte sequence: %v", err)
+	}
+	return b, nil
+}
+
+// parseStringPattern parses a string, either literally or, if it is
+// quoted, as a Go string literal.
+func parseStringPattern(s string) ([]byte, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return nil, errors.New("empty string")
+	}
+	if s[0] == '"' || s[0] == '`' {
+		var err error
+		s, err = strconv.Unquote(s)
+		if err != nil {
+			return nil, fmt.Errorf("could not parse string: %v", err)
+		}
+	}
+	return []byte(s), nil
+}
+
+// parseIntPattern parses an integer of the given size (in bits), signed or
+// unsigned, and returns its representation in the memory of the target.
+func parseIntPattern(bits, s string) ([]byte, error) {
+	size, _ := strconv.Atoi(bits)
+	s = strings.TrimSpace(s)
+	var n uint64
+	if i, err := strconv.ParseInt(s, 0, size); err == nil {
+		n = uint64(i)
+	} else if u, err := strconv.ParseUint(s, 0, size); err == nil {
+		n = u
+	} else {
+		return nil, fmt.Errorf("could not parse %q as a %d bit integer", s, size)
+	}
+	buf := make([]byte, 8)
+	binary.LittleEndian.PutUint64(buf, n)
+	return buf[:size/8], nil
+}
+
 func printVar(t *Term, ctx callContext, args string) error {
 	if len(args) == 0 {
 		return fmt.Errorf("not enough arguments")
 	}
+	if rest, ok := cutPrefixFlag(args, "-json"); ok {
+		if ctx.Prefix == onPrefix {
+			return fmt.Errorf("-json not supported on breakpoint")
+		}
+		buf, err := t.client.EvalJSON(ctx.Scope, rest, t.loadConfig())
+		if err != nil {
+			return err
+		}
+		var out bytes.Buffer
+		if err := json.Indent(&out, buf, "", "\t"); err != nil {
+			return err
+		}
+		fmt.Println(out.String())
+		return nil
+	}
+	fmtstr, args, err := parseFormatArg(args)
+	if err != nil {
+		return err
+	}
 	if ctx.Prefix == onPrefix {
+		if fmtstr != "" {
+			return fmt.Errorf("format not supported on breakpoint")
+		}
 		ctx.Breakpoint.Variables = append(ctx.Breakpoint.Variables, args)
 		return nil
 	}
@@ -1648,10 +2257,99 @@ func printVar(t *Term, ctx callContext, args string) error {
 		return err
 	}
 
-	fmt.Println(val.MultilineString(""))
+	t.prettyPrint(val)
+	fmt.Println(val.MultilineStringFormatted("", fmtstr))
 	return nil
 }
 
+// cutPrefixFlag returns the arguments following flag, if args starts
+// with it.
+func cutPrefixFlag(args, flag string) (string, bool) {
+	v := strings.SplitN(strings.TrimSpace(args), " ", 2)
+	if v[0] != flag {
+		return args, false
+	}
+	if len(v) < 2 {
+		return "", true
+	}
+	return strings.TrimSpace(v[1]), true
+}
+
+func dumpVarCommand(t *Term, ctx callContext, args string) error {
+	args = strings.TrimSpace(args)
+	i := strings.LastIndex(args, " ")
+	if i < 0 {
+		return fmt.Errorf("not enough arguments")
+	}
+	expr, path := strings.TrimSpace(args[:i]), args[i+1:]
+	buf, err := t.client.EvalJSON(ctx.Scope, expr, t.loadConfig())
+	if err != nil {
+		return err
+	}
+	var out bytes.Buffer
+	if err := json.Indent(&out, buf, "", "\t"); err != nil {
+		return err
+	}
+	out.WriteByte('\n')
+	return ioutil.WriteFile(path, out.Bytes(), 0644)
+}
+
+func diffCommand(t *Term, ctx callContext, args string) error {
+	expr1, expr2, err := splitTwoExprs(args)
+	if err != nil {
+		return err
+	}
+	diffs, err := t.client.Diff(ctx.Scope, expr1, expr2, t.loadConfig())
+	if err != nil {
+		return err
+	}
+	if len(diffs) == 0 {
+		fmt.Println("no differences")
+		return nil
+	}
+	valueString := func(v *api.Variable) string {
+		if v == nil {
+			return "<missing>"
+		}
+		t.prettyPrint(v)
+		return v.SinglelineString()
+	}
+	for _, diff := range diffs {
+		path := diff.Path
+		if path == "" {
+			path = "(value)"
+		}
+		if diff.Reason != "" {
+			fmt.Printf("%s: %s: %s != %s\n", path, diff.Reason, valueString(diff.X), valueString(diff.Y))
+		} else {
+			fmt.Printf("%s: %s != %s\n", path, valueString(diff.X), valueString(diff.Y))
+		}
+	}
+	return nil
+}
+
+// splitTwoExprs splits args into two Go expressions separated by a space.
+func splitTwoExprs(args string) (string, string, error) {
+	args = strings.TrimSpace(args)
+	for i := range args {
+		if args[i] != ' ' {
+			continue
+		}
+		expr1, expr2 := strings.TrimSpace(args[:i]), strings.TrimSpace(args[i+1:])
+		if expr1 == "" || expr2 == "" {
+			continue
+		}
+		if _, err := parser.ParseExpr(expr1); err != nil {
+			continue
+		}
+		if _, err := parser.ParseExpr(expr2); err != nil {
+			continue
+		}
+		return expr1, expr2, nil
+	}
+	return "", "", errors.New("two expressions expected")
+}
+
 func whatisCommand(t *Term, ctx callContext, args string) error {
 	if len(args) == 0 {
 		return fmt.Errorf("not enough arguments")
@@ -1692,7 +2390,7 @@ func setVar(t *Term, ctx callContext, args string) error {
 	return t.client.SetVariable(ctx.Scope, lexpr, rexpr)
 }
 
-func printFilteredVariables(varType string, vars []api.Variable, filter string, cfg api.LoadConfig) error {
+func printFilteredVariables(t *Term, varType string, vars []api.Variable, filter, fmtstr string, cfg api.LoadConfig) error {
 	reg, err := regexp.Compile(filter)
 	if err != nil {
 		return err
@@ -1701,14 +2399,15 @@ func printFilteredVariables(varType string, vars []api.Variable, filter string,
 	for _, v := range vars {
 		if reg == nil || reg.Match([]byte(v.Name)) {
 			match = true
+			t.prettyPrint(&v)
 			name := v.Name
 			if v.Flags&api.VariableShadowed != 0 {
 				name = "(" + name + ")"
 			}
 			if cfg == ShortLoadConfig {
-				fmt.Printf("%s = %s\n", name, v.SinglelineString())
+				fmt.Printf("%s = %s\n", name, v.SinglelineStringFormatted(fmtstr))
 			} else {
-				fmt.Printf("%s = %s\n", name, v.MultilineString(""))
+				fmt.Printf("%s = %s\n", name, v.MultilineStringFormatted("", fmtstr))
 			}
 		}
 	}
@@ -1741,23 +2440,53 @@ func types(t *Term, ctx callContext, args string) error {
 	return printSortedStrings(t.client.ListTypes(args))
 }
 
-func parseVarArguments(args string, t *Term) (filter string, cfg api.LoadConfig) {
+func parseVarArguments(args string, t *Term) (filter, fmtstr string, cfg api.LoadConfig, err error) {
+	cfg = ShortLoadConfig
 	if v := split2PartsBySpace(args); len(v) >= 1 && v[0] == "-v" {
+		cfg = t.loadConfig()
+		args = ""
 		if len(v) == 2 {
-			return v[1], t.loadConfig()
-		} else {
-			return "", t.loadConfig()
+			args = v[1]
 		}
 	}
-	return args, ShortLoadConfig
+	fmtstr, filter, err = parseFormatArg(args)
+	return filter, fmtstr, cfg, err
+}
+
+// parseFormatArg removes a format, either a verb of the fmt package
+// (%<verb>) or a named format (-fmt <format>), from the start of args.
+func parseFormatArg(args string) (fmtstr, rest string, err error) {
+	const fmtOption = "-fmt "
+	args = strings.TrimSpace(args)
+	var format string
+	switch {
+	case strings.HasPrefix(args, "%"):
+		format = args
+	case strings.HasPrefix(args, fmtOption):
+		format = strings.TrimSpace(args[len(fmtOption):])
+	default:
+		return "", args, nil
+	}
+	v := split2PartsBySpace(format)
+	if len(v) == 2 {
+		rest = v[1]
+	}
+	fmtstr, err = api.ParseFormat(v[0])
+	return fmtstr, rest, err
 }
 
 func args(t *Term, ctx callContext, args string) error {
-	filter, cfg := parseVarArguments(args, t)
+	filter, fmtstr, cfg, err := parseVarArguments(args, t)
+	if err != nil {
+		return err
+	}
 	if ctx.Prefix == onPrefix {
 		if filter != "" {
 			return fmt.Errorf("filter not supported on breakpoint")
 		}
+		if fmtstr != "" {
+			return fmt.Errorf("format not supported on breakpoint")
+		}
 		ctx.Breakpoint.LoadArgs = &cfg
 		return nil
 	}
@@ -1765,15 +2494,21 @@ func args(t *Term, ctx callContext, args string) error {
 	if err != nil {
 		return err
 	}
-	return printFilteredVariables("args", vars, filter, cfg)
+	return printFilteredVariables(t, "args", vars, filter, fmtstr, cfg)
 }
 
 func locals(t *Term, ctx callContext, args string) error {
-	filter, cfg := parseVarArguments(args, t)
+	filter, fmtstr, cfg, err := parseVarArguments(args, t)
+	if err != nil {
+		return err
+	}
 	if ctx.Prefix == onPrefix {
 		if filter != "" {
 			return fmt.Errorf("filter not supported on breakpoint")
 		}
+		if fmtstr != "" {
+			return fmt.Errorf("format not supported on breakpoint")
+		}
 		ctx.Breakpoint.LoadLocals = &cfg
 		return nil
 	}
@@ -1781,16 +2516,34 @@ func locals(t *Term, ctx callContext, args string) error {
 	if err != nil {
 		return err
 	}
-	return printFilteredVariables("locals", locals, filter, cfg)
+	var closure []api.Variable
+	for i := 0; i < len(locals); i++ {
+		if locals[i].Flags&api.VariableClosure != 0 {
+			closure = append(closure, locals[i])
+			locals = append(locals[:i], locals[i+1:]...)
+			i--
+		}
+	}
+	if err := printFilteredVariables(t, "locals", locals, filter, fmtstr, cfg); err != nil {
+		return err
+	}
+	if len(closure) > 0 {
+		fmt.Println("closure:")
+		return printFilteredVariables(t, "closure variables", closure, filter, fmtstr, cfg)
+	}
+	return nil
 }
 
 func vars(t *Term, ctx callContext, args string) error {
-	filter, cfg := parseVarArguments(args, t)
+	filter, fmtstr, cfg, err := parseVarArguments(args, t)
+	if err != nil {
+		return err
+	}
 	vars, err := t.client.ListPackageVariables(filter, cfg)
 	if err != nil {
 		return err
 	}
-	return printFilteredVariables("vars", vars, filter, cfg)
+	return printFilteredVariables(t, "vars", vars, filter, fmtstr, cfg)
 }
 
 func regs(t *Term, ctx callContext, args string) error {
@@ -1886,18 +2639,21 @@ func parseStackArgs(argstr string) (stackArgs, error) {
 			case "-mode":
 				i++
 				if i >= len(args) {
-					return stackArgs{}, fmt.Errorf("expected normal, simple or fromg after -mode")
+					return stackArgs{}, fmt.Errorf("expected normal, simple, fromg or fp after -mode")
 				}
 				switch args[i] {
 				case "normal":
 					r.opts &^= api.StacktraceSimple
 					r.opts &^= api.StacktraceG
+					r.opts &^= api.StacktraceFramePointer
 				case "simple":
 					r.opts |= api.StacktraceSimple
 				case "fromg":
 					r.opts |= api.StacktraceG | api.StacktraceSimple
+				case "fp":
+					r.opts |= api.StacktraceFramePointer
 				default:
-					return stackArgs{}, fmt.Errorf("expected normal, simple or fromg after -mode")
+					return stackArgs{}, fmt.Errorf("expected normal, simple, fromg or fp after -mode")
 				}
 			case "-a":
 				i++
@@ -2010,11 +2766,29 @@ func (c *Commands) sourceCommand(t *Term, ctx callContext, args string) error {
 	return c.executeFile(t, args)
 }
 
-var disasmUsageError = errors.New("wrong number of arguments: disassemble [-a <start> <end>] [-l <locspec>]")
+var disasmUsageError = errors.New("wrong number of arguments: disassemble [-s] [-a <start> <end>] [-l <locspec>] or disassemble -cfg [<locspec>] [-o <file>]")
 
 func disassCommand(t *Term, ctx callContext, args string) error {
 	var cmd, rest string
 
+	// -s can appear anywhere in the arguments
+	showSource := false
+	fields := strings.Fields(args)
+	for i := 0; i < len(fields); i++ {
+		if fields[i] == "-s" {
+			showSource = true
+			fields = append(fields[:i], fields[i+1:]...)
+			i--
+		}
+	}
+	args = strings.Join(fields, " ")
+
+	flavor := t.disassembleFlavour()
+
+	if !showSource && (args == "-cfg" || strings.HasPrefix(args, "-cfg ")) {
+		return disasmCFG(t, ctx, strings.TrimSpace(args[len("-cfg"):]), flavor)
+	}
+
 	if args != "" {
 		argv := split2PartsBySpace(args)
 		if len(argv) != 2 {
@@ -2024,15 +2798,14 @@ func disassCommand(t *Term, ctx callContext, args string) error {
 		rest = argv[1]
 	}
 
-	flavor := api.IntelFlavour
-	if t.conf != nil && t.conf.DisassembleFlavor != nil {
-		switch *t.conf.DisassembleFlavor {
-		case "go":
-			flavor = api.GoFlavour
-		case "gnu":
-			flavor = api.GNUFlavour
+	disassemble := func(startpc, endpc uint64) (api.AsmInstructions, error) {
+		switch {
+		case showSource:
+			return t.client.DisassembleSource(ctx.Scope, startpc, endpc, flavor, t.substitutePathRules())
+		case endpc == 0:
+			return t.client.DisassemblePC(ctx.Scope, startpc, flavor)
 		default:
-			flavor = api.IntelFlavour
+			return t.client.DisassembleRange(ctx.Scope, startpc, endpc, flavor)
 		}
 	}
 
@@ -2045,7 +2818,7 @@ func disassCommand(t *Term, ctx callContext, args string) error {
 		if err != nil {
 			return err
 		}
-		disasm, disasmErr = t.client.DisassemblePC(ctx.Scope, locs[0].PC, flavor)
+		disasm, disasmErr = disassemble(locs[0].PC, 0)
 	case "-a":
 		v := split2PartsBySpace(rest)
 		if len(v) != 2 {
@@ -2059,7 +2832,7 @@ func disassCommand(t *Term, ctx callContext, args string) error {
 		if err != nil {
 			return fmt.Errorf("wrong argument: %q is not a number", v[1])
 		}
-		disasm, disasmErr = t.client.DisassembleRange(ctx.Scope, uint64(startpc), uint64(endpc), flavor)
+		disasm, disasmErr = disassemble(uint64(startpc), uint64(endpc))
 	case "-l":
 		locs, err := t.client.FindLocation(ctx.Scope, rest, true, t.substitutePathRules())
 		if err != nil {
@@ -2068,7 +2841,7 @@ func disassCommand(t *Term, ctx callContext, args string) error {
 		if len(locs) != 1 {
 			return errors.New("expression specifies multiple locations")
 		}
-		disasm, disasmErr = t.client.DisassemblePC(ctx.Scope, locs[0].PC, flavor)
+		disasm, disasmErr = disassemble(locs[0].PC, 0)
 	default:
 		return disasmUsageError
 	}
@@ -2077,8 +2850,71 @@ func disassCommand(t *Term, ctx callContext, args string) error {
 		return disasmErr
 	}
 
-	disasmPrint(disasm, os.Stdout)
+	disasmPrint(disasm, os.Stdout, showSource)
+
+	return nil
+}
+
+// disassembleFlavour returns the assembly syntax selected by the
+// disassemble-flavor configuration option.
+func (t *Term) disassembleFlavour() api.AssemblyFlavour {
+	if t.conf != nil && t.conf.DisassembleFlavor != nil {
+		switch *t.conf.DisassembleFlavor {
+		case "go":
+			return api.GoFlavour
+		case "gnu":
+			return api.GNUFlavour
+		}
+	}
+	return api.IntelFlavour
+}
+
+// disasmCFG implements 'disassemble -cfg [<locspec>] [-o <file>]'.
+func disasmCFG(t *Term, ctx callContext, args string, flavor api.AssemblyFlavour) error {
+	v := strings.Fields(args)
+	outpath := ""
+	if n := len(v); n >= 2 && v[n-2] == "-o" {
+		outpath = v[n-1]
+		v = v[:n-2]
+	}
+	spec := strings.Join(v, " ")
+	if spec == "" {
+		spec = "+0"
+	}
+	locs, err := t.client.FindLocation(ctx.Scope, spec, true, t.substitutePathRules())
+	if err != nil {
+		return err
+	}
+	if len(locs) != 1 {
+		return errors.New("expression specifies multiple locations")
+	}
+	blocks, err := t.client.ControlFlowGraph(ctx.Scope, locs[0].PC, flavor, t.substitutePathRules())
+	if err != nil {
+		return err
+	}
 
+	if outpath == "" {
+		disasmPrintDot(blocks, os.Stdout)
+		return nil
+	}
+	f, err := os.Create(outpath)
+	if err != nil {
+		return err
+	}
+	if filepath.Ext(outpath) == ".json" {
+		enc := json.NewEncoder(f)
+		enc.SetIndent("", "\t")
+		err = enc.Encode(blocks)
+	} else {
+		disasmPrintDot(blocks, f)
+	}
+	if err1 := f.Close(); err == nil {
+		err = err1
+	}
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Control flow graph of %s (%d blocks) written to %s\n", locs[0].Function.Name(), len(blocks), outpath)
 	return nil
 }
 
@@ -2118,6 +2954,7 @@ func printStack(t *Term, out io.Writer, stack []api.Stackframe, ind string, offs
 
 	d := digits(len(stack) - 1)
 	fmtstr := "%s%" + strconv.Itoa(d) + "d  0x%016x in %s\n"
+	fpfmtstr := "%s%" + strconv.Itoa(d) + "d  0x%016x in %s (fp)\n"
 	s := ind + strings.Repeat(" ", d+2+len(ind))
 
 	for i := range stack {
@@ -2125,7 +2962,11 @@ func printStack(t *Term, out io.Writer, stack []api.Stackframe, ind string, offs
 			fmt.Fprintf(out, "%serror: %s\n", s, stack[i].Err)
 			continue
 		}
-		fmt.Fprintf(out, fmtstr, ind, i, stack[i].PC, stack[i].Function.Name())
+		if stack[i].Unwinder == "fp" {
+			fmt.Fprintf(out, fpfmtstr, ind, i, stack[i].PC, stack[i].Function.Name())
+		} else {
+			fmt.Fprintf(out, fmtstr, ind, i, stack[i].PC, stack[i].Function.Name())
+		}
 		fmt.Fprintf(out, "%sat %s:%d\n", s, t.formatPath(stack[i].File), stack[i].Line)
 
 		if offsets {
@@ -2226,6 +3067,13 @@ func printReturnValues(th *api.Thread) {
 func printcontextThread(t *Term, th *api.Thread) {
 	fn := th.Function
 
+	t.prettyPrintAll(th.ReturnValues)
+	if th.BreakpointInfo != nil {
+		t.prettyPrintAll(th.BreakpointInfo.Arguments)
+		t.prettyPrintAll(th.BreakpointInfo.Locals)
+		t.prettyPrintAll(th.BreakpointInfo.Variables)
+	}
+
 	if th.Breakpoint == nil {
 		printcontextLocation(t, api.Location{PC: th.PC, File: th.File, Line: th.Line, Function: th.Function})
 		printReturnValues(th)
@@ -2288,10 +3136,10 @@ func printcontextThread(t *Term, th *api.Thread) {
 	}
 
 	printReturnValues(th)
-	printBreakpointInfo(t, th, false)
+	printBreakpointInfo(t, os.Stdout, th, false)
 }
 
-func printBreakpointInfo(t *Term, th *api.Thread, tracepointOnNewline bool) {
+func printBreakpointInfo(t *Term, out io.Writer, th *api.Thread, tracepointOnNewline bool) {
 	if th.BreakpointInfo == nil {
 		return
 	}
@@ -2308,61 +3156,78 @@ func printBreakpointInfo(t *Term, th *api.Thread, tracepointOnNewline bool) {
 			return
 		}
 		didprintnl = true
-		fmt.Println()
+		fmt.Fprintln(out)
 	}
 
 	if bpi.Goroutine != nil {
 		tracepointnl()
-		writeGoroutineLong(t, os.Stdout, bpi.Goroutine, "\t")
+		writeGoroutineLong(t, out, bpi.Goroutine, "\t")
 	}
 
 	for _, v := range bpi.Variables {
 		tracepointnl()
-		fmt.Printf("\t%s: %s\n", v.Name, v.MultilineString("\t"))
+		fmt.Fprintf(out, "\t%s: %s\n", v.Name, v.MultilineString("\t"))
 	}
 
 	for _, v := range bpi.Locals {
 		tracepointnl()
 		if *bp.LoadLocals == longLoadConfig {
-			fmt.Printf("\t%s: %s\n", v.Name, v.MultilineString("\t"))
+			fmt.Fprintf(out, "\t%s: %s\n", v.Name, v.MultilineString("\t"))
 		} else {
-			fmt.Printf("\t%s: %s\n", v.Name, v.SinglelineString())
+			fmt.Fprintf(out, "\t%s: %s\n", v.Name, v.SinglelineString())
 		}
 	}
 
 	if bp.LoadArgs != nil && *bp.LoadArgs == longLoadConfig {
 		for _, v := range bpi.Arguments {
 			tracepointnl()
-			fmt.Printf("\t%s: %s\n", v.Name, v.MultilineString("\t"))
+			fmt.Fprintf(out, "\t%s: %s\n", v.Name, v.MultilineString("\t"))
 		}
 	}
 
 	if bpi.Stacktrace != nil {
 		tracepointnl()
-		fmt.Printf("\tStack:\n")
-		printStack(t, os.Stdout, bpi.Stacktrace, "\t\t", false)
+		fmt.Fprintf(out, "\tStack:\n")
+		printStack(t, out, bpi.Stacktrace, "\t\t", false)
 	}
 }
 
 func printTracepoint(t *Term, th *api.Thread, bpname string, fn *api.Function, args string, hasReturnValue bool) {
+	hit := traceHit{report: true}
+	if t.traceTracking() {
+		hit = t.trackTracepoint(th, th.BreakpointHitTime)
+	}
+	if !hit.report {
+		return
+	}
+	if t.traceJSON {
+		printTracepointJSON(t, th, hit)
+		return
+	}
+	out := t.traceOutput()
 	if th.Breakpoint.Tracepoint {
-		fmt.Fprintf(os.Stderr, "> goroutine(%d): %s%s(%s)", th.GoroutineID, bpname, fn.Name(), args)
+		fmt.Fprintf(out, "> goroutine(%d): %s%s(%s)", th.GoroutineID, bpname, fn.Name(), args)
 		if !hasReturnValue {
-			fmt.Println()
+			fmt.Fprintln(out)
 		}
-		printBreakpointInfo(t, th, !hasReturnValue)
+		printBreakpointInfo(t, out, th, !hasReturnValue)
 	}
 	if th.Breakpoint.TraceReturn {
 		retVals := make([]string, 0, len(th.ReturnValues))
 		for _, v := range th.ReturnValues {
 			retVals = append(retVals, v.SinglelineString())
 		}
-		fmt.Fprintf(os.Stderr, " => (%s)\n", strings.Join(retVals, ","))
+		fmt.Fprintf(out, " => (%s)", strings.Join(retVals, ","))
+		if hit.paired && t.traceLatency {
+			fmt.Fprintf(out, " [%v]", hit.dur)
+		}
+		fmt.Fprintln(out)
 	}
-	if th.Breakpoint.TraceReturn || !hasReturnValue {
+	// the stack of the call is printed by printBreakpointInfo
+	if th.Breakpoint.TraceReturn {
 		if th.BreakpointInfo != nil && th.BreakpointInfo.Stacktrace != nil {
-			fmt.Fprintf(os.Stderr, "\tStack:\n")
-			printStack(t, os.Stderr, th.BreakpointInfo.Stacktrace, "\t\t", false)
+			fmt.Fprintf(out, "\tStack:\n")
+			printStack(t, out, th.BreakpointInfo.Stacktrace, "\t\t", false)
 		}
 	}
 }
@@ -2574,19 +3439,35 @@ func clearCheckpoint(t *Term, ctx callContext, args string) error {
 
 func display(t *Term, ctx callContext, args string) error {
 	const (
-		addOption = "-a "
-		delOption = "-d "
+		addOption     = "-a "
+		delOption     = "-d "
+		changesOption = "-changes"
 	)
 	switch {
 	case args == "":
 		t.printDisplays()
 
+	case args == changesOption:
+		t.displayChanges = !t.displayChanges
+		if t.displayChanges {
+			fmt.Println("Highlighting changed expressions")
+		} else {
+			fmt.Println("Not highlighting changed expressions")
+		}
+
 	case strings.HasPrefix(args, addOption):
 		args = strings.TrimSpace(args[len(addOption):])
 		if args == "" {
 			return fmt.Errorf("not enough arguments")
 		}
-		t.addDisplay(args)
+		fmtstr, args, err := parseFormatArg(args)
+		if err != nil {
+			return err
+		}
+		if args == "" {
+			return fmt.Errorf("not enough arguments")
+		}
+		t.addDisplay(args, fmtstr)
 		t.printDisplay(len(t.displays) - 1)
 
 	case strings.HasPrefix(args, delOption):
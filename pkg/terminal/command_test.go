@@ -1,6 +1,8 @@
 package terminal
 
 import (
+	"bytes"
+	"encoding/json"
 	"flag"
 	"fmt"
 	"io/ioutil"
@@ -8,6 +10,7 @@ import (
 	"net/http"
 	"os"
 	"path/filepath"
+	"reflect"
 	"regexp"
 	"runtime"
 	"strconv"
@@ -324,6 +327,34 @@ func TestTraceOnNonFunctionEntry(t *testing.T) {
 	})
 }
 
+func TestTraceInstructions(t *testing.T) {
+	if runtime.GOOS != "linux" {
+		t.Skip("the process is not stopped at its entry point")
+	}
+	test.AllowRecording(t)
+	withTestTerminal("issue573", t, func(term *FakeTerminal) {
+		state, err := term.client.GetState()
+		if err != nil {
+			t.Fatal(err)
+		}
+		pc := state.CurrentThread.PC
+		term.AssertExecError("trace -instructions -max x a b", `wrong argument: "x" is not a positive number`)
+		term.AssertExecError("trace -instructions a", "wrong number of arguments: trace -instructions [-regs] [-mem] [-max <n>] [-o <file>] <from> <to>")
+
+		out := term.MustExec(fmt.Sprintf("trace -instructions -regs -max 1000 *%#x runtime.rt0_go", pc))
+		lines := strings.Split(out, "\n")
+		if !strings.Contains(lines[0], fmt.Sprintf("%#x", pc)) {
+			t.Errorf("trace does not start at %#x: %q", pc, lines[0])
+		}
+		if !strings.Contains(out, "instructions traced, reached runtime.rt0_go") {
+			t.Errorf("runtime.rt0_go not reached: %s", out)
+		}
+		if !strings.Contains(out, "\t; ") {
+			t.Errorf("no register changes printed: %s", out)
+		}
+	})
+}
+
 func TestExitStatus(t *testing.T) {
 	withTestTerminal("continuetestprog", t, func(term *FakeTerminal) {
 		term.Exec("continue")
@@ -894,6 +925,33 @@ func TestDisassembleAutogenerated(t *testing.T) {
 	})
 }
 
+func TestDisassembleSourceFlag(t *testing.T) {
+	// The -s flag of disassemble can be specified before or after the other
+	// arguments.
+	withTestTerminal("math", t, func(term *FakeTerminal) {
+		term.MustExec("break main.main")
+		term.MustExec("continue")
+		locs, err := term.client.FindLocation(api.EvalScope{GoroutineID: -1}, "main.main", true, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		rng := fmt.Sprintf("%#x %#x", locs[0].PC, locs[0].PC+16)
+		for _, args := range [][2]string{
+			{"-s -l main.main", "-l main.main -s"},
+			{"-s -a " + rng, "-a " + rng + " -s"},
+		} {
+			out1 := term.MustExec("disassemble " + args[0])
+			out2 := term.MustExec("disassemble " + args[1])
+			if !strings.Contains(out1, "math.go:") {
+				t.Errorf("source lines missing from the output of disassemble %s:\n%s", args[0], out1)
+			}
+			if out1 != out2 {
+				t.Errorf("different output for disassemble %s and disassemble %s:\n%s\n%s", args[0], args[1], out1, out2)
+			}
+		}
+	})
+}
+
 func TestIssue1090(t *testing.T) {
 	// Exit while executing 'next' should report the "Process exited" error
 	// message instead of crashing.
@@ -1042,6 +1100,14 @@ func TestExamineMemoryCmd(t *testing.T) {
 		term.MustExec("break examinememory.go:24")
 		term.MustExec("continue")
 
+		if runtime.GOOS == "linux" && testBackend == "native" {
+			// failed reads of unmapped memory explain why they failed
+			_, err := term.Exec("examinemem 0x8")
+			if err == nil || !strings.Contains(err.Error(), "is not inside any memory region") {
+				t.Errorf("unexpected error reading unmapped memory: %v", err)
+			}
+		}
+
 		addressStr := strings.TrimSpace(term.MustExec("p bspUintptr"))
 		address, err := strconv.ParseInt(addressStr, 0, 64)
 		if err != nil {
@@ -1075,6 +1141,82 @@ func TestExamineMemoryCmd(t *testing.T) {
 	})
 }
 
+func TestFindCmd(t *testing.T) {
+	withTestTerminal("memsearch", t, func(term *FakeTerminal) {
+		term.MustExec("continue")
+		addr := strings.TrimSpace(term.MustExec("print %#x uintptr(&needle)"))
+		n, err := strconv.ParseUint(addr, 0, 64)
+		if err != nil {
+			t.Fatalf("could not parse address %q: %v", addr, err)
+		}
+		res := term.MustExec(fmt.Sprintf("find -range %s %#x -x deadbeef", addr, n+16))
+		t.Logf("find -range: %s", res)
+		if !strings.Contains(res, addr) || !strings.Contains(res, "main.needle+0") {
+			t.Errorf("needle not found at %s", addr)
+		}
+		res = term.MustExec("find -heap -s \"hidden treasure\"")
+		t.Logf("find -heap: %s", res)
+		if !strings.Contains(res, "heap object") {
+			t.Errorf("string not found in the heap")
+		}
+	})
+}
+
+func TestPrintJSON(t *testing.T) {
+	withTestTerminal("testvariables2", t, func(term *FakeTerminal) {
+		term.MustExec("continue")
+		var m map[string]struct{ A, B int }
+		out := term.MustExec("print -json m1")
+		if err := json.Unmarshal([]byte(out), &m); err != nil {
+			t.Fatalf("could not decode %q: %v", out, err)
+		}
+		if m["Malone"].A != 2 || m["Malone"].B != 3 {
+			t.Errorf("wrong value for m1[\"Malone\"]: %v", m["Malone"])
+		}
+
+		path := filepath.Join(os.TempDir(), "dlv-dump-var-test.json")
+		defer os.Remove(path)
+		term.MustExec("dump-var iface6 " + path)
+		buf, err := ioutil.ReadFile(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		t.Logf("dump-var: %s", buf)
+		if !strings.Contains(string(buf), "$cycle") {
+			t.Errorf("cycle not annotated")
+		}
+	})
+}
+
+func TestParsePattern(t *testing.T) {
+	for _, tc := range []struct {
+		f   func(string) ([]byte, error)
+		in  string
+		out []byte
+	}{
+		{parseHexPattern, "deadbeef", []byte{0xde, 0xad, 0xbe, 0xef}},
+		{parseHexPattern, "0xdead", []byte{0xde, 0xad}},
+		{parseHexPattern, `"de ad be ef"`, []byte{0xde, 0xad, 0xbe, 0xef}},
+		{parseStringPattern, "hello world", []byte("hello world")},
+		{parseStringPattern, `"a\tb"`, []byte("a\tb")},
+		{func(s string) ([]byte, error) { return parseIntPattern("16", s) }, "0x1234", []byte{0x34, 0x12}},
+		{func(s string) ([]byte, error) { return parseIntPattern("32", s) }, "-1", []byte{0xff, 0xff, 0xff, 0xff}},
+		{func(s string) ([]byte, error) { return parseIntPattern("8", s) }, "255", []byte{0xff}},
+	} {
+		out, err := tc.f(tc.in)
+		if err != nil {
+			t.Errorf("%q: %v", tc.in, err)
+			continue
+		}
+		if !bytes.Equal(out, tc.out) {
+			t.Errorf("%q: expected %x got %x", tc.in, tc.out, out)
+		}
+	}
+	if _, err := parseIntPattern("8", "256"); err == nil {
+		t.Errorf("256 parsed as an 8 bit integer")
+	}
+}
+
 func TestPrintOnTracepoint(t *testing.T) {
 	withTestTerminal("increment", t, func(term *FakeTerminal) {
 		term.MustExec("trace main.Increment")
@@ -1140,3 +1282,217 @@ func TestParseNewArgv(t *testing.T) {
 		}
 	}
 }
+
+func TestPrintTracepointJSON(t *testing.T) {
+	var buf bytes.Buffer
+	term := &Term{}
+	term.SetTraceOutput(&buf, true)
+	fn := &api.Function{Name_: "main.foo"}
+	call := &api.Thread{
+		ID: 10, GoroutineID: 1, File: "main.go", Line: 17, Function: fn,
+		Breakpoint: &api.Breakpoint{Tracepoint: true},
+		BreakpointInfo: &api.BreakpointInfo{
+			Arguments: []api.Variable{
+				{Name: "x", Type: "int", Kind: reflect.Int, Value: "99", Flags: api.VariableArgument},
+				{Name: "z", Type: "int", Kind: reflect.Int, Value: "0", Flags: api.VariableReturnArgument},
+			},
+			Stacktrace: []api.Stackframe{{Location: api.Location{PC: 0x1000, File: "main.go", Line: 17, Function: fn}}},
+		},
+	}
+	ret := &api.Thread{
+		ID: 10, GoroutineID: 1, File: "main.go", Line: 20, Function: fn,
+		Breakpoint:   &api.Breakpoint{TraceReturn: true},
+		ReturnValues: []api.Variable{{Name: "z", Type: "int", Kind: reflect.Int, Value: "9900", Flags: api.VariableReturnArgument}},
+	}
+	printTracepoint(term, call, "", fn, "", true)
+	printTracepoint(term, call, "", fn, "", true)
+	printTracepoint(term, ret, "", fn, "", true)
+
+	tgt := []struct {
+		kind    string
+		depth   int
+		args    int
+		returns int
+	}{{"call", 0, 1, 0}, {"call", 1, 1, 0}, {"return", 1, 0, 1}}
+	dec := json.NewDecoder(&buf)
+	for i := range tgt {
+		var ev traceEvent
+		if err := dec.Decode(&ev); err != nil {
+			t.Fatalf("could not decode event %d: %v", i, err)
+		}
+		t.Logf("%#v", ev)
+		if ev.Kind != tgt[i].kind || ev.Depth != tgt[i].depth || len(ev.Args) != tgt[i].args || len(ev.Returns) != tgt[i].returns {
+			t.Errorf("event %d: wrong kind, depth or number of arguments and return values %#v", i, ev)
+		}
+		if ev.Function != "main.foo" || ev.GoroutineID != 1 || ev.ThreadID != 10 {
+			t.Errorf("event %d: wrong function, goroutine or thread %#v", i, ev)
+		}
+	}
+	if buf.Len() != 0 {
+		t.Errorf("trailing output %q", buf.String())
+	}
+}
+
+func TestTraceLatency(t *testing.T) {
+	term := &Term{}
+	term.SetTraceLatency(true)
+	thread := func(gid int, fn string, ret bool) *api.Thread {
+		return &api.Thread{GoroutineID: gid, Function: &api.Function{Name_: fn}, Breakpoint: &api.Breakpoint{Tracepoint: !ret, TraceReturn: ret}}
+	}
+	start := time.Unix(0, 0)
+	at := func(ms int) time.Time { return start.Add(time.Duration(ms) * time.Millisecond) }
+
+	hits := []struct {
+		th     *api.Thread
+		ms     int
+		depth  int
+		dur    time.Duration
+		paired bool
+	}{
+		{thread(1, "main.a", false), 0, 0, 0, false},
+		{thread(2, "main.a", false), 1, 0, 0, false},
+		{thread(1, "main.b", false), 2, 1, 0, false},
+		{thread(1, "main.b", true), 5, 1, 3 * time.Millisecond, true},
+		{thread(2, "main.a", true), 11, 0, 10 * time.Millisecond, true},
+		{thread(1, "main.c", false), 12, 1, 0, false},
+		// main.c does not return through a traced return instruction
+		{thread(1, "main.a", true), 20, 0, 20 * time.Millisecond, true},
+		{thread(1, "main.b", true), 21, 0, 0, false},
+	}
+	for i, hit := range hits {
+		r := term.trackTracepoint(hit.th, at(hit.ms))
+		if r.depth != hit.depth || r.dur != hit.dur || r.paired != hit.paired || !r.report {
+			t.Errorf("hit %d: got %#v; expected depth %d, duration %v, paired %v", i, r, hit.depth, hit.dur, hit.paired)
+		}
+	}
+
+	var buf bytes.Buffer
+	term.PrintTraceLatencySummary(&buf)
+	t.Logf("summary:\n%s", buf.String())
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 3 {
+		t.Fatalf("wrong number of lines in summary: %d", len(lines))
+	}
+	if fields := strings.Fields(lines[1]); strings.Join(fields, " ") != "main.a 2 10ms 10ms 20ms 20ms 30ms" {
+		t.Errorf("wrong summary for main.a: %q", lines[1])
+	}
+	if fields := strings.Fields(lines[2]); strings.Join(fields, " ") != "main.b 1 3ms 3ms 3ms 3ms 3ms" {
+		t.Errorf("wrong summary for main.b: %q", lines[2])
+	}
+
+	buf.Reset()
+	if err := term.WriteChromeTrace(&buf); err != nil {
+		t.Fatal(err)
+	}
+	var trace struct {
+		TraceEvents []chromeTraceEvent `json:"traceEvents"`
+	}
+	if err := json.Unmarshal(buf.Bytes(), &trace); err != nil {
+		t.Fatalf("could not decode %q: %v", buf.String(), err)
+	}
+	if len(trace.TraceEvents) != 3 {
+		t.Fatalf("wrong number of events: %s", buf.String())
+	}
+	if ev := trace.TraceEvents[1]; ev.Name != "main.a" || ev.Phase != "X" || ev.Ts != 1000 || ev.Dur != 10000 || ev.Tid != 2 {
+		t.Errorf("wrong event %#v", ev)
+	}
+}
+
+func TestTraceFilter(t *testing.T) {
+	thread := func(fn string, ret bool, labels map[string]string) *api.Thread {
+		return &api.Thread{
+			GoroutineID:    1,
+			Function:       &api.Function{Name_: fn},
+			Breakpoint:     &api.Breakpoint{Tracepoint: !ret, TraceReturn: ret},
+			BreakpointInfo: &api.BreakpointInfo{Goroutine: &api.Goroutine{ID: 1, Labels: labels}},
+		}
+	}
+	type hit struct {
+		fn     string
+		ret    bool
+		report bool
+	}
+	check := func(name string, filter TraceFilter, labels map[string]string, hits []hit) {
+		term := &Term{}
+		term.SetTraceFilter(filter)
+		for i, h := range hits {
+			if r := term.trackTracepoint(thread(h.fn, h.ret, labels), time.Now()); r.report != h.report {
+				t.Errorf("%s: hit %d (%s return=%v): got report=%v", name, i, h.fn, h.ret, r.report)
+			}
+		}
+	}
+
+	// main.a calls main.b which calls main.c, main.c is then called again
+	// outside of main.a
+	calls := func(ra, rb, rc, rc2 bool) []hit {
+		return []hit{
+			{"main.a", false, ra}, {"main.b", false, rb}, {"main.c", false, rc},
+			{"main.c", true, rc}, {"main.b", true, rb}, {"main.a", true, ra},
+			{"main.c", false, rc2}, {"main.c", true, rc2},
+		}
+	}
+
+	check("no filter", TraceFilter{}, nil, calls(true, true, true, true))
+	check("max depth", TraceFilter{MaxDepth: 2}, nil, calls(true, true, false, true))
+	check("follow calls", TraceFilter{Roots: []string{"main.a"}, FollowCalls: 1}, nil, calls(true, true, false, false))
+	check("follow calls deeper", TraceFilter{Roots: []string{"main.a"}, FollowCalls: 2}, nil, calls(true, true, true, false))
+	check("labels match", TraceFilter{Labels: map[string]string{"k": "v"}}, map[string]string{"k": "v", "x": "y"}, calls(true, true, true, true))
+	check("labels mismatch", TraceFilter{Labels: map[string]string{"k": "v"}}, map[string]string{"k": "w"}, calls(false, false, false, false))
+}
+
+func TestCompletion(t *testing.T) {
+	withTestTerminal("testvariables2", t, func(term *FakeTerminal) {
+		check := func(line string, tgt ...string) {
+			t.Helper()
+			c := term.complete(line)
+			for _, s := range tgt {
+				found := false
+				for _, x := range c {
+					if x == s {
+						found = true
+						break
+					}
+				}
+				if !found {
+					t.Errorf("completions of %q do not contain %q: %q", line, s, c)
+				}
+			}
+		}
+
+		check("brea", "break", "breakpoints")
+		check("help conti", "help continue")
+		check("config max-str", "config max-string-len")
+		check("b main.mai", "b main.main")
+		check("break testvariables2.", "break testvariables2.go:")
+		check("goroutine 1 list testvariables2.", "goroutine 1 list testvariables2.go:")
+
+		term.MustExec("break mybp main.main")
+		check("clear my", "clear mybp")
+		check("on mybp pri", "on mybp print")
+
+		term.MustExec("source " + findStarFile("echo_expr"))
+		check("echo_", "echo_expr")
+	})
+}
+
+func TestCompletionExpr(t *testing.T) {
+	withTestTerminal("testvariables2", t, func(term *FakeTerminal) {
+		term.MustExec("continue")
+		check := func(line string, tgt []string) {
+			t.Helper()
+			c := term.complete(line)
+			if !reflect.DeepEqual(c, tgt) {
+				t.Errorf("completions of %q: expected %q got %q", line, tgt, c)
+			}
+		}
+		check("print as1.", []string{"print as1.A", "print as1.B", "print as1.Error", "print as1.NonPointerRecieverMethod"})
+		check("p c1.pb.a.", []string{"p c1.pb.a.A", "p c1.pb.a.B", "p c1.pb.a.Error", "p c1.pb.a.NonPointerRecieverMethod"})
+		check("display -a len(as1.A", []string{"display -a len(as1.A"})
+		check("goroutine 1 frame 0 set as", []string{"goroutine 1 frame 0 set as1", "goroutine 1 frame 0 set as2"})
+
+		gs := term.complete("goroutine ")
+		if len(gs) == 0 || !strings.HasPrefix(gs[0], "goroutine ") {
+			t.Errorf("no goroutine IDs completed: %q", gs)
+		}
+	})
+}
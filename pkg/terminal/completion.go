@@ -0,0 +1,280 @@
+package terminal
+
+import (
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/go-delve/delve/service/api"
+)
+
+// completionLoadConfig is the configuration used to load the variables
+// whose names, fields and methods are completed.
+var completionLoadConfig = api.LoadConfig{FollowPointers: true, MaxVariableRecurse: 1, MaxStructFields: -1}
+
+// exprSuffixRegex matches the selector expression, a chain of identifiers
+// separated by dots, at the end of an expression.
+var exprSuffixRegex = regexp.MustCompile(`[\p{L}\p{N}_.]*$`)
+
+// complete returns the completions of line, a command typed at the prompt.
+// Completions are whole lines, as expected by liner.
+func (t *Term) complete(line string) []string {
+	return t.completeCommand("", line, api.EvalScope{GoroutineID: -1})
+}
+
+// completeCommand returns the completions of line, evaluating expressions
+// in scope. Head is the part of the command line preceding line and is
+// prepended to all completions.
+func (t *Term) completeCommand(head, line string, scope api.EvalScope) []string {
+	sp := strings.Index(line, " ")
+	if sp < 0 {
+		return completeWords(head, t.commandNames(), strings.ToLower(line))
+	}
+	cmdname, args := line[:sp], line[sp+1:]
+	var cmd *command
+	for i := range t.cmds.cmds {
+		if t.cmds.cmds[i].match(cmdname) {
+			cmd = &t.cmds.cmds[i]
+			break
+		}
+	}
+	if cmd == nil {
+		return nil
+	}
+	head += cmdname + " "
+
+	// scope prefixes and 'on' are followed by another command
+	switch cmd.aliases[0] {
+	case "goroutine", "frame", "deferred", "on":
+		if sp := strings.Index(args, " "); sp >= 0 {
+			arg := args[:sp]
+			n, _ := strconv.Atoi(arg)
+			switch cmd.aliases[0] {
+			case "goroutine":
+				scope.GoroutineID = n
+			case "frame":
+				scope.Frame = n
+			case "deferred":
+				scope.DeferredCall = n
+			}
+			return t.completeCommand(head+arg+" ", args[sp+1:], scope)
+		}
+	}
+
+	argHead, word := "", args
+	if sp := strings.LastIndex(args, " "); sp >= 0 {
+		argHead, word = args[:sp+1], args[sp+1:]
+	}
+	nargs := len(strings.Fields(argHead))
+
+	switch cmd.aliases[0] {
+	case "break", "trace", "list", "edit":
+		if strings.HasPrefix(word, "-") {
+			return nil
+		}
+		return t.completeLocspec(head+argHead, word)
+	case "print", "set", "display", "whatis":
+		return t.completeExpr(head, args, scope)
+	case "goroutine":
+		if nargs == 0 {
+			return t.completeGoroutineIDs(head, word)
+		}
+	case "clear", "condition", "on":
+		if nargs == 0 {
+			return t.completeBreakpoints(head, word)
+		}
+	case "config":
+		if nargs == 0 {
+			return completeWords(head, t.configKeys(), word)
+		}
+	case "help":
+		if nargs == 0 {
+			return completeWords(head, t.commandNames(), word)
+		}
+	}
+	return nil
+}
+
+// completeWords returns head followed by each of words that starts with
+// prefix.
+func completeWords(head string, words []string, prefix string) (c []string) {
+	for _, w := range words {
+		if strings.HasPrefix(w, prefix) {
+			c = append(c, head+w)
+		}
+	}
+	return c
+}
+
+// commandNames returns the names and aliases of all commands, including
+// the ones defined by starlark scripts.
+func (t *Term) commandNames() (r []string) {
+	for _, cmd := range t.cmds.cmds {
+		r = append(r, cmd.aliases...)
+	}
+	return r
+}
+
+// configKeys returns the names of the configuration parameters accepted
+// by the config command and its options.
+func (t *Term) configKeys() []string {
+	r := []string{"-list", "-save"}
+	if t.conf == nil {
+		return r
+	}
+	it := iterateConfiguration(t.conf)
+	for it.Next() {
+		fieldName, _ := it.Field()
+		if fieldName != "" {
+			r = append(r, fieldName)
+		}
+	}
+	return r
+}
+
+// completeLocspec completes word as a location specifier: the name of a
+// function or the name of a source file followed by ':'. Files are
+// completed with the shortest suffix of their path that starts with word.
+func (t *Term) completeLocspec(head, word string) (c []string) {
+	if strings.Contains(word, ":") || strings.HasPrefix(word, "*") || strings.HasPrefix(word, "+") {
+		return nil
+	}
+	funcs, _ := t.client.ListFunctions(regexp.QuoteMeta(word))
+	for _, f := range funcs {
+		c = append(c, head+f)
+	}
+	sources, _ := t.client.ListSources(regexp.QuoteMeta(word))
+	seen := make(map[string]bool)
+	for _, file := range sources {
+		suffix := pathSuffixWithPrefix(file, word)
+		if suffix == "" || seen[suffix] {
+			continue
+		}
+		seen[suffix] = true
+		c = append(c, head+suffix+":")
+	}
+	return c
+}
+
+// pathSuffixWithPrefix returns the shortest suffix of path that starts at
+// the beginning of a path element and has prefix as a prefix.
+func pathSuffixWithPrefix(path, prefix string) string {
+	for i := len(path) - 1; i >= 0; i-- {
+		if i > 0 && path[i-1] != '/' && path[i-1] != '\\' {
+			continue
+		}
+		if strings.HasPrefix(path[i:], prefix) {
+			return path[i:]
+		}
+	}
+	return ""
+}
+
+// completeExpr completes the selector expression at the end of expr. An
+// identifier is completed with the names of the local variables and
+// arguments, a selector with the fields and methods of the value it is
+// applied to.
+func (t *Term) completeExpr(head, expr string, scope api.EvalScope) []string {
+	sel := exprSuffixRegex.FindString(expr)
+	head += expr[:len(expr)-len(sel)]
+
+	dot := strings.LastIndex(sel, ".")
+	if dot < 0 {
+		var names []string
+		args, _ := t.client.ListFunctionArgs(scope, ShortLoadConfig)
+		locals, _ := t.client.ListLocalVariables(scope, ShortLoadConfig)
+		for _, v := range append(args, locals...) {
+			names = append(names, v.Name)
+		}
+		sort.Strings(names)
+		return completeWords(head, names, sel)
+	}
+
+	base, word := sel[:dot], sel[dot+1:]
+	if base == "" {
+		return nil
+	}
+	v, err := t.client.EvalVariable(scope, base, completionLoadConfig)
+	if err != nil {
+		return nil
+	}
+	head += base + "."
+	for (v.Kind == reflect.Ptr || v.Kind == reflect.Interface) && len(v.Children) > 0 {
+		v = &v.Children[0]
+	}
+	names := t.methodNames(v.Type)
+	if v.Kind == reflect.Struct {
+		for _, field := range v.Children {
+			names = append(names, field.Name)
+		}
+	}
+	sort.Strings(names)
+	return completeWords(head, names, word)
+}
+
+// methodNames returns the names of the methods of the named type typ, or
+// of the type typ points to, that are present in the executable.
+func (t *Term) methodNames(typ string) []string {
+	typ = strings.TrimPrefix(typ, "*")
+	dot := strings.LastIndex(typ, ".")
+	if dot < 0 || strings.ContainsAny(typ, "[]() ") {
+		return nil
+	}
+	pkg, name := regexp.QuoteMeta(typ[:dot]), regexp.QuoteMeta(typ[dot+1:])
+	funcs, _ := t.client.ListFunctions("^" + pkg + `\.(` + name + `|\(\*` + name + `\))\.[^.]+$`)
+	var r []string
+	for _, f := range funcs {
+		// skip compiler generated functions, like the wrappers of method
+		// values (whose names end in -fm)
+		if name := f[strings.LastIndex(f, ".")+1:]; isIdentifier(name) {
+			r = append(r, name)
+		}
+	}
+	return r
+}
+
+// isIdentifier returns true if name is a Go identifier.
+func isIdentifier(name string) bool {
+	for i, c := range name {
+		if !unicode.IsLetter(c) && c != '_' && (i == 0 || !unicode.IsDigit(c)) {
+			return false
+		}
+	}
+	return name != ""
+}
+
+// completeGoroutineIDs completes word with the IDs of the goroutines.
+func (t *Term) completeGoroutineIDs(head, word string) []string {
+	gs, _, err := t.client.ListGoroutines(0, goroutineBatchSize)
+	if err != nil {
+		return nil
+	}
+	var ids []string
+	for _, g := range gs {
+		ids = append(ids, strconv.Itoa(g.ID))
+	}
+	return completeWords(head, ids, word)
+}
+
+// completeBreakpoints completes word with the IDs and names of the user
+// breakpoints.
+func (t *Term) completeBreakpoints(head, word string) []string {
+	bps, err := t.client.ListBreakpoints()
+	if err != nil {
+		return nil
+	}
+	var r []string
+	for _, bp := range bps {
+		if bp.ID < 0 {
+			continue
+		}
+		r = append(r, strconv.Itoa(bp.ID))
+		if bp.Name != "" {
+			r = append(r, bp.Name)
+		}
+	}
+	return completeWords(head, r, word)
+}
@@ -5,12 +5,13 @@ import (
 	"fmt"
 	"io"
 	"path/filepath"
+	"strings"
 	"text/tabwriter"
 
 	"github.com/go-delve/delve/service/api"
 )
 
-func disasmPrint(dv api.AsmInstructions, out io.Writer) {
+func disasmPrint(dv api.AsmInstructions, out io.Writer, showSource bool) {
 	bw := bufio.NewWriter(out)
 	defer bw.Flush()
 	if len(dv) > 0 && dv[0].Loc.Function != nil {
@@ -18,7 +19,12 @@ func disasmPrint(dv api.AsmInstructions, out io.Writer) {
 	}
 	tw := tabwriter.NewWriter(bw, 1, 8, 1, '\t', 0)
 	defer tw.Flush()
-	for _, inst := range dv {
+	var prev *api.AsmInstruction
+	for i := range dv {
+		inst := &dv[i]
+		if showSource {
+			disasmPrintSource(tw, bw, prev, inst)
+		}
 		atbp := ""
 		if inst.Breakpoint {
 			atbp = "*"
@@ -28,5 +34,125 @@ func disasmPrint(dv api.AsmInstructions, out io.Writer) {
 			atpc = "=>"
 		}
 		fmt.Fprintf(tw, "%s\t%s:%d\t%#x%s\t%x\t%s\n", atpc, filepath.Base(inst.Loc.File), inst.Loc.Line, inst.Loc.PC, atbp, inst.Bytes, inst.Text)
+		prev = inst
+	}
+}
+
+// disasmPrintSource prints the boundaries of the inlined calls entered or
+// exited between instructions prev and inst and the source line of inst,
+// if it is different from the source line of prev.
+func disasmPrintSource(tw *tabwriter.Writer, out io.Writer, prev, inst *api.AsmInstruction) {
+	var prevInlined []api.InlinedCall
+	if prev != nil {
+		prevInlined = prev.InlinedCalls
+	}
+	common := 0
+	for common < len(prevInlined) && common < len(inst.InlinedCalls) && sameInlinedCall(prevInlined[common], inst.InlinedCalls[common]) {
+		common++
+	}
+	if prev != nil && common == len(prevInlined) && common == len(inst.InlinedCalls) && prev.Loc.File == inst.Loc.File && prev.Loc.Line == inst.Loc.Line {
+		return
+	}
+
+	// source lines are not aligned with the instructions
+	tw.Flush()
+	for i := len(prevInlined) - 1; i >= common; i-- {
+		fmt.Fprintf(out, "; end of inlined call to %s\n", prevInlined[i].Function.Name())
+	}
+	for _, call := range inst.InlinedCalls[common:] {
+		fmt.Fprintf(out, "; inlined call to %s at %s:%d\n", call.Function.Name(), filepath.Base(call.File), call.Line)
+	}
+	fmt.Fprintf(out, "%s:%d:\t%s\n", filepath.Base(inst.Loc.File), inst.Loc.Line, strings.TrimSpace(inst.Source))
+}
+
+func sameInlinedCall(a, b api.InlinedCall) bool {
+	return a.Function.Name() == b.Function.Name() && a.File == b.File && a.Line == b.Line
+}
+
+// disasmPrintDot prints the control flow graph blocks in Graphviz DOT
+// format. Each block lists the source lines and the instructions it
+// contains, instructions with a breakpoint are marked with '*' and the
+// block containing the current instruction is highlighted.
+func disasmPrintDot(blocks []api.BasicBlock, out io.Writer) {
+	bw := bufio.NewWriter(out)
+	defer bw.Flush()
+	name := "cfg"
+	if len(blocks) > 0 && blocks[0].Insts[0].Loc.Function != nil {
+		name = blocks[0].Insts[0].Loc.Function.Name()
+	}
+	fmt.Fprintf(bw, "digraph %s {\n", dotQuote(name))
+	fmt.Fprintf(bw, "\tnode [shape=box fontname=\"monospace\"];\n")
+	for i, block := range blocks {
+		var label strings.Builder
+		atpc := false
+		for j := range block.Insts {
+			inst := &block.Insts[j]
+			if j == 0 || inst.Loc.File != block.Insts[j-1].Loc.File || inst.Loc.Line != block.Insts[j-1].Loc.Line {
+				fmt.Fprintf(&label, "%s:%d: %s\n", filepath.Base(inst.Loc.File), inst.Loc.Line, strings.TrimSpace(inst.Source))
+			}
+			prefix := "  "
+			if inst.AtPC {
+				prefix = "=>"
+				atpc = true
+			}
+			atbp := " "
+			if inst.Breakpoint {
+				atbp = "*"
+			}
+			fmt.Fprintf(&label, "%s %#x%s %s\n", prefix, inst.Loc.PC, atbp, inst.Text)
+		}
+		attrs := ""
+		if atpc {
+			attrs = " style=filled fillcolor=yellow"
+		}
+		fmt.Fprintf(bw, "\tb%d [label=%s%s];\n", i, dotLabel(label.String()), attrs)
+	}
+	for i, block := range blocks {
+		for _, edge := range block.Succs {
+			attrs := ""
+			switch edge.Kind {
+			case "fallthrough":
+				attrs = " [style=dashed]"
+			case "branch":
+				attrs = " [color=green label=\"branch\"]"
+			}
+			fmt.Fprintf(bw, "\tb%d -> b%d%s;\n", i, edge.To, attrs)
+		}
+	}
+	fmt.Fprintf(bw, "}\n")
+}
+
+// dotQuote returns s as a DOT quoted string.
+func dotQuote(s string) string {
+	return "\"" + strings.NewReplacer("\\", "\\\\", "\"", "\\\"").Replace(s) + "\""
+}
+
+// dotLabel returns s as a DOT quoted string with each line left justified.
+func dotLabel(s string) string {
+	return strings.Replace(dotQuote(s), "\n", "\\l", -1)
+}
+
+// traceInstructionsPrint prints the instructions traced by 'trace
+// -instructions', one per line, followed by the registers and memory
+// they changed.
+func traceInstructionsPrint(insts []api.TracedInstruction, out io.Writer) {
+	bw := bufio.NewWriter(out)
+	defer bw.Flush()
+	tw := tabwriter.NewWriter(bw, 1, 8, 1, '\t', 0)
+	defer tw.Flush()
+	for i := range insts {
+		inst := &insts[i].Inst
+		fmt.Fprintf(tw, "%s:%d\t%#x\t%s", filepath.Base(inst.Loc.File), inst.Loc.Line, inst.Loc.PC, inst.Text)
+		var changes []string
+		for _, reg := range insts[i].Registers {
+			changes = append(changes, fmt.Sprintf("%s=%s", reg.Name, strings.Join(strings.Fields(reg.Value), " ")))
+		}
+		for _, w := range insts[i].MemoryWrites {
+			changes = append(changes, fmt.Sprintf("[%#x]=%x", w.Addr, w.Data))
+		}
+		if len(changes) > 0 {
+			fmt.Fprintf(tw, "\t; %s", strings.Join(changes, " "))
+		}
+		fmt.Fprintf(tw, "\n")
 	}
 }
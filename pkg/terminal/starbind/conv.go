@@ -1,6 +1,7 @@
 package starbind
 
 import (
+	"encoding/json"
 	"errors"
 	"fmt"
 	"math"
@@ -43,6 +44,9 @@ func (env *Env) interfaceToStarlarkValue(v interface{}) starlark.Value {
 		return starlark.MakeInt64(int64(v))
 	case string:
 		return starlark.String(v)
+	case json.RawMessage:
+		// JSON encoded values are passed to scripts as strings
+		return starlark.String(v)
 	case map[string]uint64:
 		// this is the only map type that we use in the api, if we ever want to
 		// add more maps to the api a more general approach will be necessary.
@@ -245,14 +249,24 @@ func (env *Env) variableValueToStarlarkValue(v *api.Variable, top bool) (starlar
 		}
 	case reflect.Ptr, reflect.Interface:
 		if len(v.Children) > 0 {
-			v.Children[0] = *env.autoLoad(varAddrExpr(&v.Children[0]))
+			v.Children[0] = *env.autoLoad(varAddrExpr(&v.Children[0]), &v.Children[0])
 		}
 		return ptrVariableAsStarlarkValue{v, env}, nil
 	}
 	return nil, nil
 }
 
-func (env *Env) autoLoad(expr string) *api.Variable {
+// autoLoad evaluates expr using autoLoadConfig. Loaded is the value of
+// expr that has already been read, if any, it is returned as is when the
+// environment doesn't have a client (for example when running pretty
+// printers in the DAP server).
+func (env *Env) autoLoad(expr string, loaded *api.Variable) *api.Variable {
+	if env.ctx.Client() == nil {
+		if loaded == nil {
+			return &api.Variable{Unreadable: "value not loaded"}
+		}
+		return loaded
+	}
 	v, err := env.ctx.Client().EvalVariable(api.EvalScope{GoroutineID: -1}, expr, autoLoadConfig)
 	if err != nil {
 		return &api.Variable{Unreadable: err.Error()}
@@ -303,7 +317,7 @@ func (v structVariableAsStarlarkValue) Type() string {
 func (v structVariableAsStarlarkValue) Attr(name string) (starlark.Value, error) {
 	for i := range v.v.Children {
 		if v.v.Children[i].Name == name {
-			v2 := v.env.autoLoad(varAddrExpr(&v.v.Children[i]))
+			v2 := v.env.autoLoad(varAddrExpr(&v.v.Children[i]), &v.v.Children[i])
 			return v.env.variableValueToStarlarkValue(v2, false)
 		}
 	}
@@ -364,7 +378,11 @@ func (v sliceVariableAsStarlarkValue) Index(i int) starlark.Value {
 	if i >= v.Len() {
 		return nil
 	}
-	v2 := v.env.autoLoad(fmt.Sprintf("%s[%d]", varAddrExpr(v.v), i))
+	var loaded *api.Variable
+	if i < len(v.v.Children) {
+		loaded = &v.v.Children[i]
+	}
+	v2 := v.env.autoLoad(fmt.Sprintf("%s[%d]", varAddrExpr(v.v), i), loaded)
 	r, err := v.env.variableValueToStarlarkValue(v2, false)
 	if err != nil {
 		return starlark.String(err.Error())
@@ -438,7 +456,7 @@ func (v ptrVariableAsStarlarkValue) Attr(name string) (starlark.Value, error) {
 		// allow double-autodereference for iface to ptr to struct
 		vchild := &v.v.Children[0]
 		if len(vchild.Children) > 0 {
-			vchild.Children[0] = *v.env.autoLoad(varAddrExpr(&vchild.Children[0]))
+			vchild.Children[0] = *v.env.autoLoad(varAddrExpr(&vchild.Children[0]), &vchild.Children[0])
 		}
 		v2 := ptrVariableAsStarlarkValue{vchild, v.env}
 		return v2.Attr(name)
@@ -522,7 +540,7 @@ func (v mapVariableAsStarlarkValue) Get(key starlark.Value) (starlark.Value, boo
 		return starlark.None, false, fmt.Errorf("key type not supported %T", key)
 	}
 
-	v2 := v.env.autoLoad(fmt.Sprintf("%s[%s]", varAddrExpr(v.v), keyExpr))
+	v2 := v.env.autoLoad(fmt.Sprintf("%s[%s]", varAddrExpr(v.v), keyExpr), nil)
 	r, err := v.env.variableValueToStarlarkValue(v2, false)
 	if err != nil {
 		if err.Error() == "key not found" {
@@ -542,12 +560,12 @@ func (v mapVariableAsStarlarkValue) Items() []starlark.Tuple {
 }
 
 func mapStarlarkTupleAt(v *api.Variable, env *Env, i int) starlark.Tuple {
-	keyv := env.autoLoad(varAddrExpr(&v.Children[i]))
+	keyv := env.autoLoad(varAddrExpr(&v.Children[i]), &v.Children[i])
 	key, err := env.variableValueToStarlarkValue(keyv, false)
 	if err != nil {
 		key = starlark.None
 	}
-	valv := env.autoLoad(varAddrExpr(&v.Children[i+1]))
+	valv := env.autoLoad(varAddrExpr(&v.Children[i+1]), &v.Children[i+1])
 	val, err := env.variableValueToStarlarkValue(valv, false)
 	if err != nil {
 		val = starlark.None
@@ -573,14 +591,14 @@ func (it *mapVariableAsStarlarkValueIterator) Next(p *starlark.Value) bool {
 		return false
 	}
 	if it.cur >= len(it.v.Children) {
-		v2 := it.env.autoLoad(fmt.Sprintf("%s[%d:]", varAddrExpr(it.v), len(it.v.Children)/2))
+		v2 := it.env.autoLoad(fmt.Sprintf("%s[%d:]", varAddrExpr(it.v), len(it.v.Children)/2), nil)
 		it.v.Children = append(it.v.Children, v2.Children...)
 	}
 	if it.cur >= len(it.v.Children) {
 		return false
 	}
 
-	keyv := it.env.autoLoad(varAddrExpr(&it.v.Children[it.cur]))
+	keyv := it.env.autoLoad(varAddrExpr(&it.v.Children[it.cur]), &it.v.Children[it.cur])
 	key, err := it.env.variableValueToStarlarkValue(keyv, false)
 	if err != nil {
 		key = starlark.None
@@ -0,0 +1,212 @@
+package starbind
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"regexp"
+	"strconv"
+
+	"go.starlark.net/starlark"
+
+	"github.com/go-delve/delve/service/api"
+)
+
+const (
+	registerPrettyPrinterBuiltinName = "register_pretty_printer"
+
+	// maxPrettyPrintDepth is the maximum depth at which pretty printers are
+	// applied to the children of a variable.
+	maxPrettyPrintDepth = 10
+)
+
+// prettyPrinter is a starlark function, registered with
+// register_pretty_printer, that describes the variables of a type.
+type prettyPrinter struct {
+	typ string
+	re  *regexp.Regexp // nil if typ isn't a valid regular expression
+	fn  starlark.Callable
+}
+
+func (pp *prettyPrinter) matches(typ string) bool {
+	return typ == pp.typ || (pp.re != nil && pp.re.MatchString(typ))
+}
+
+func (env *Env) registerPrettyPrinterBuiltin(thread *starlark.Thread, _ *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	var typ string
+	var fn starlark.Callable
+	if err := starlark.UnpackArgs(registerPrettyPrinterBuiltinName, args, kwargs, "type", &typ, "fn", &fn); err != nil {
+		return starlark.None, decorateError(thread, err)
+	}
+	pp := &prettyPrinter{typ: typ, fn: fn}
+	pp.re, _ = regexp.Compile("^(?:" + typ + ")$")
+	for i := range env.prettyPrinters {
+		if env.prettyPrinters[i].typ == typ {
+			env.prettyPrinters[i] = pp
+			return starlark.None, nil
+		}
+	}
+	env.prettyPrinters = append(env.prettyPrinters, pp)
+	return starlark.None, nil
+}
+
+// HasPrettyPrinter returns true if a pretty printer was registered for
+// variables of type typ.
+func (env *Env) HasPrettyPrinter(typ string) bool {
+	return env.findPrettyPrinter(typ) != nil
+}
+
+func (env *Env) findPrettyPrinter(typ string) *prettyPrinter {
+	if env == nil {
+		return nil
+	}
+	for _, pp := range env.prettyPrinters {
+		if pp.matches(typ) {
+			return pp
+		}
+	}
+	return nil
+}
+
+// PrettyPrint applies the pretty printers registered by starlark scripts
+// to v and its children. The value of a variable handled by a pretty
+// printer is replaced by the summary returned by the pretty printer, its
+// children by the children returned by the pretty printer and the
+// api.VariablePrettyPrinted flag is set.
+// If a pretty printer fails the variable is left unchanged and the error
+// is returned after all other variables have been processed.
+func (env *Env) PrettyPrint(v *api.Variable) error {
+	if env == nil || len(env.prettyPrinters) == 0 {
+		return nil
+	}
+	return env.prettyPrint(v, 0)
+}
+
+func (env *Env) prettyPrint(v *api.Variable, depth int) error {
+	if depth > maxPrettyPrintDepth || v.Flags&api.VariablePrettyPrinted != 0 || v.Unreadable != "" {
+		return nil
+	}
+	var err error
+	if pp := env.findPrettyPrinter(v.Type); pp != nil {
+		err = env.applyPrettyPrinter(pp, v)
+	}
+	for i := range v.Children {
+		if err2 := env.prettyPrint(&v.Children[i], depth+1); err == nil {
+			err = err2
+		}
+	}
+	return err
+}
+
+func (env *Env) applyPrettyPrinter(pp *prettyPrinter, v *api.Variable) (err error) {
+	defer func() {
+		if ierr := recover(); ierr != nil {
+			err = fmt.Errorf("pretty printer for %s: %v", v.Type, ierr)
+		}
+	}()
+	r, err := starlark.Call(env.newThread(), pp.fn, starlark.Tuple{env.interfaceToStarlarkValue(v)}, nil)
+	if err != nil {
+		return fmt.Errorf("pretty printer for %s: %v", v.Type, err)
+	}
+
+	var summary starlark.Value
+	var children []api.Variable
+	switch r := r.(type) {
+	case starlark.String:
+		summary = r
+	case starlark.Tuple:
+		if len(r) != 2 {
+			return fmt.Errorf("pretty printer for %s: wrong number of return values", v.Type)
+		}
+		summary = r[0]
+		children, err = env.prettyPrinterChildren(r[1])
+		if err != nil {
+			return fmt.Errorf("pretty printer for %s: %v", v.Type, err)
+		}
+	default:
+		return fmt.Errorf("pretty printer for %s: returned %s, expected a string or a (summary, children) tuple", v.Type, r.Type())
+	}
+	s, ok := summary.(starlark.String)
+	if !ok {
+		return fmt.Errorf("pretty printer for %s: summary is a %s, expected a string", v.Type, summary.Type())
+	}
+
+	v.Value = string(s)
+	v.Children = children
+	v.Len = int64(len(children))
+	v.Flags |= api.VariablePrettyPrinted
+	return nil
+}
+
+// prettyPrinterChildren converts the children returned by a pretty
+// printer, either a dictionary mapping names to values, a list of (name,
+// value) tuples or a list of values, into a list of variables.
+func (env *Env) prettyPrinterChildren(val starlark.Value) ([]api.Variable, error) {
+	switch val := val.(type) {
+	case starlark.NoneType:
+		return nil, nil
+	case *starlark.Dict:
+		r := make([]api.Variable, 0, val.Len())
+		for _, item := range val.Items() {
+			name, ok := item[0].(starlark.String)
+			if !ok {
+				return nil, errors.New("children dictionary keys must be strings")
+			}
+			r = append(r, starlarkValueToVariable(string(name), item[1]))
+		}
+		return r, nil
+	case starlark.Iterable:
+		r := []api.Variable{}
+		it := val.Iterate()
+		defer it.Done()
+		var x starlark.Value
+		for i := 0; it.Next(&x); i++ {
+			if t, ok := x.(starlark.Tuple); ok && len(t) == 2 {
+				if name, ok := t[0].(starlark.String); ok {
+					r = append(r, starlarkValueToVariable(string(name), t[1]))
+					continue
+				}
+			}
+			r = append(r, starlarkValueToVariable(fmt.Sprintf("[%d]", i), x))
+		}
+		return r, nil
+	}
+	return nil, fmt.Errorf("children is a %s, expected a dictionary or a list", val.Type())
+}
+
+// starlarkValueToVariable converts a child returned by a pretty printer to
+// a variable. Variables of the target process are returned as they are,
+// other values are converted to synthetic variables.
+func starlarkValueToVariable(name string, val starlark.Value) api.Variable {
+	var r api.Variable
+	switch val := val.(type) {
+	case structAsStarlarkValue:
+		if v, ok := val.v.Interface().(api.Variable); ok {
+			r = v
+		} else {
+			r = api.Variable{Value: val.String()}
+		}
+	case structVariableAsStarlarkValue:
+		r = *val.v
+	case sliceVariableAsStarlarkValue:
+		r = *val.v
+	case ptrVariableAsStarlarkValue:
+		r = *val.v
+	case mapVariableAsStarlarkValue:
+		r = *val.v
+	case starlark.String:
+		r = api.Variable{Kind: reflect.String, Type: "string", Value: string(val), Len: int64(len(val))}
+	case starlark.Int:
+		r = api.Variable{Kind: reflect.Int, Type: "int", Value: val.String()}
+	case starlark.Float:
+		r = api.Variable{Kind: reflect.Float64, Type: "float64", Value: strconv.FormatFloat(float64(val), 'g', -1, 64)}
+	case starlark.Bool:
+		r = api.Variable{Kind: reflect.Bool, Type: "bool", Value: strconv.FormatBool(bool(val))}
+	case starlark.NoneType:
+		r = api.Variable{Value: "nil"}
+	default:
+		r = api.Variable{Value: val.String()}
+	}
+	r.Name = name
+	return r
+}
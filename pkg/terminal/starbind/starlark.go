@@ -40,6 +40,8 @@ func init() {
 
 // Context is the context in which starlark scripts are evaluated.
 // It contains methods to call API functions, command line commands, etc.
+// Client can return nil, in which case the builtins calling API functions
+// are not defined.
 type Context interface {
 	Client() service.Client
 	RegisterCommand(name, helpMsg string, cmdfn func(args string) error)
@@ -55,6 +57,8 @@ type Env struct {
 	thread    *starlark.Thread
 	cancelfn  context.CancelFunc
 
+	prettyPrinters []*prettyPrinter
+
 	ctx Context
 }
 
@@ -64,7 +68,11 @@ func New(ctx Context) *Env {
 
 	env.ctx = ctx
 
-	env.env = env.starlarkPredeclare()
+	if ctx.Client() != nil {
+		env.env = env.starlarkPredeclare()
+	} else {
+		env.env = starlark.StringDict{}
+	}
 	env.env[dlvCommandBuiltinName] = starlark.NewBuiltin(dlvCommandBuiltinName, func(thread *starlark.Thread, _ *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
 		if err := isCancelled(thread); err != nil {
 			return starlark.None, err
@@ -114,6 +122,7 @@ func New(ctx Context) *Env {
 	env.env[defaultLoadConfigBuiltinName] = starlark.NewBuiltin(defaultLoadConfigBuiltinName, func(_ *starlark.Thread, _ *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
 		return env.interfaceToStarlarkValue(env.ctx.LoadConfig()), nil
 	})
+	env.env[registerPrettyPrinterBuiltinName] = starlark.NewBuiltin(registerPrettyPrinterBuiltinName, env.registerPrettyPrinterBuiltin)
 	return env
 }
 
@@ -5,6 +5,10 @@ import (
 	"runtime"
 	"strings"
 	"testing"
+
+	"github.com/go-delve/delve/pkg/terminal/starbind"
+	"github.com/go-delve/delve/service"
+	"github.com/go-delve/delve/service/api"
 )
 
 func TestStarlarkExamples(t *testing.T) {
@@ -20,6 +24,7 @@ func TestStarlarkExamples(t *testing.T) {
 		t.Run("echo_expr", func(t *testing.T) { testStarlarkEchoExpr(t, term) })
 		t.Run("find_array", func(t *testing.T) { testStarlarkFindArray(t, term) })
 		t.Run("map_iteration", func(t *testing.T) { testStarlarkMapIteration(t, term) })
+		t.Run("pretty_printers", func(t *testing.T) { testStarlarkPrettyPrinters(t, term) })
 	})
 }
 
@@ -122,6 +127,24 @@ func testStarlarkMapIteration(t *testing.T, term *FakeTerminal) {
 	t.Logf("%s", out)
 }
 
+func testStarlarkPrettyPrinters(t *testing.T, term *FakeTerminal) {
+	term.MustExec("source " + findStarFile("pretty_printers"))
+	out := term.MustExec("print as1")
+	t.Logf("print as1: %q", out)
+	if out != "astruct(1, 1) {sum: 2, A: 1}\n" {
+		t.Error("output mismatch")
+	}
+	out = term.MustExec("print s2")
+	t.Logf("print s2: %q", out)
+	if !strings.Contains(out, "astruct(3, 4) {sum: 7, A: 3}") {
+		t.Error("pretty printer not applied to slice elements")
+	}
+	out = term.MustExec("locals as1")
+	if !strings.Contains(out, "as1 = astruct(1, 1)") {
+		t.Errorf("pretty printer not applied to locals: %q", out)
+	}
+}
+
 func TestStarlarkVariable(t *testing.T) {
 	withTestTerminal("testvariables2", t, func(term *FakeTerminal) {
 		term.MustExec("continue")
@@ -162,3 +185,27 @@ func TestStarlarkVariable(t *testing.T) {
 		}
 	})
 }
+
+// noClientContext is a starbind.Context without a client, like the one
+// used by the DAP server to run pretty printers.
+type noClientContext struct{}
+
+func (noClientContext) Client() service.Client { return nil }
+
+func (noClientContext) RegisterCommand(name, helpMsg string, cmdfn func(args string) error) {}
+
+func (noClientContext) CallCommand(cmdstr string) error { return nil }
+
+func (noClientContext) Scope() api.EvalScope { return api.EvalScope{GoroutineID: -1} }
+
+func (noClientContext) LoadConfig() api.LoadConfig { return api.LoadConfig{} }
+
+func TestStarlarkNoClient(t *testing.T) {
+	// Builtins calling API functions must not be defined without a client.
+	env := starbind.New(noClientContext{})
+	_, err := env.Execute("noclient.star", "def main():\n\teval(None, \"1\")\n", "main", nil)
+	t.Logf("%v", err)
+	if err == nil || !strings.Contains(err.Error(), "undefined: eval") {
+		t.Errorf("unexpected error %v", err)
+	}
+}
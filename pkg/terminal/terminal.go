@@ -54,7 +54,9 @@ type Term struct {
 	dumb     bool
 	stdout   io.Writer
 	InitFile string
-	displays []string
+
+	displays       []displayEntry
+	displayChanges bool // highlight display expressions that changed
 
 	historyFile *os.File
 
@@ -62,6 +64,20 @@ type Term struct {
 
 	substitutePathRulesCache [][2]string
 
+	// traceOut is where the output of tracepoints is written, os.Stderr if
+	// nil. If traceJSON is set each tracepoint hit is written as a line of
+	// JSON.
+	traceOut  io.Writer
+	traceJSON bool
+	// traceCalls are the traced calls each goroutine is executing. If
+	// traceLatency is set the duration of each call is printed when it
+	// returns and recorded in traceRecords.
+	traceCalls   map[int][]traceCall
+	traceLatency bool
+	traceRecords []traceRecord
+	traceFilter  TraceFilter
+	traceRoots   map[string]bool
+
 	// quitContinue is set to true by exitCommand to signal that the process
 	// should be resumed before quitting.
 	quitContinue bool
@@ -113,9 +129,46 @@ func New(client service.Client, conf *config.Config) *Term {
 	}
 
 	t.starlarkEnv = starbind.New(starlarkContext{t})
+	if conf.PrettyPrinterScript != "" {
+		if _, err := t.starlarkEnv.Execute(conf.PrettyPrinterScript, nil, "main", nil); err != nil {
+			fmt.Fprintf(os.Stderr, "Could not load pretty printers: %v\n", err)
+		}
+	}
 	return t
 }
 
+// SetTraceOutput makes tracepoints write their output to w, as
+// newline-delimited JSON events if json is set.
+func (t *Term) SetTraceOutput(w io.Writer, json bool) {
+	t.traceOut = w
+	t.traceJSON = json
+}
+
+func (t *Term) traceOutput() io.Writer {
+	if t.traceOut == nil {
+		return os.Stderr
+	}
+	return t.traceOut
+}
+
+// prettyPrint applies the pretty printers registered by starlark scripts
+// to vars.
+func (t *Term) prettyPrint(vars ...*api.Variable) {
+	for _, v := range vars {
+		if err := t.starlarkEnv.PrettyPrint(v); err != nil {
+			fmt.Fprintf(os.Stderr, "%v\n", err)
+		}
+	}
+}
+
+// prettyPrintAll applies the pretty printers registered by starlark
+// scripts to all variables in vars.
+func (t *Term) prettyPrintAll(vars []api.Variable) {
+	for i := range vars {
+		t.prettyPrint(&vars[i])
+	}
+}
+
 // Close returns the terminal to its previous mode.
 func (t *Term) Close() {
 	t.line.Close()
@@ -181,24 +234,7 @@ func (t *Term) Run() (int, error) {
 	signal.Notify(ch, syscall.SIGINT)
 	go t.sigintGuard(ch, multiClient)
 
-	t.line.SetCompleter(func(line string) (c []string) {
-		if strings.HasPrefix(line, "break ") || strings.HasPrefix(line, "b ") {
-			filter := line[strings.Index(line, " ")+1:]
-			funcs, _ := t.client.ListFunctions(filter)
-			for _, f := range funcs {
-				c = append(c, "break "+f)
-			}
-			return
-		}
-		for _, cmd := range t.cmds.cmds {
-			for _, alias := range cmd.aliases {
-				if strings.HasPrefix(alias, strings.ToLower(line)) {
-					c = append(c, alias)
-				}
-			}
-		}
-		return
-	})
+	t.line.SetCompleter(t.complete)
 
 	fullHistoryFile, err := config.GetConfigFilePath(historyFile)
 	if err != nil {
@@ -437,13 +473,20 @@ func (t *Term) loadConfig() api.LoadConfig {
 	return r
 }
 
+// displayEntry is an expression printed every time the program stops.
+type displayEntry struct {
+	expr   string
+	fmtstr string // format used to print the value of expr
+	last   string // value of expr the last time it was printed
+}
+
 func (t *Term) removeDisplay(n int) error {
 	if n < 0 || n >= len(t.displays) {
 		return fmt.Errorf("%d is out of range", n)
 	}
-	t.displays[n] = ""
+	t.displays[n] = displayEntry{}
 	for i := len(t.displays) - 1; i >= 0; i-- {
-		if t.displays[i] != "" {
+		if t.displays[i].expr != "" {
 			t.displays = t.displays[:i+1]
 			return nil
 		}
@@ -452,26 +495,39 @@ func (t *Term) removeDisplay(n int) error {
 	return nil
 }
 
-func (t *Term) addDisplay(expr string) {
-	t.displays = append(t.displays, expr)
+func (t *Term) addDisplay(expr, fmtstr string) {
+	t.displays = append(t.displays, displayEntry{expr: expr, fmtstr: fmtstr})
 }
 
 func (t *Term) printDisplay(i int) {
-	expr := t.displays[i]
+	expr := t.displays[i].expr
 	val, err := t.client.EvalVariable(api.EvalScope{GoroutineID: -1}, expr, ShortLoadConfig)
 	if err != nil {
 		if isErrProcessExited(err) {
 			return
 		}
-		fmt.Printf("%d: %s = error %v\n", i, expr, err)
+		fmt.Fprintf(t.stdout, "%d: %s = error %v\n", i, expr, err)
+		t.displays[i].last = ""
+		return
+	}
+	t.prettyPrint(val)
+	value := val.SinglelineStringFormatted(t.displays[i].fmtstr)
+	last := t.displays[i].last
+	t.displays[i].last = value
+	if t.displayChanges && last != "" && last != value {
+		line := fmt.Sprintf("*%d: %s = %s", i, val.Name, value)
+		if !t.dumb {
+			line = fmt.Sprintf(terminalHighlightEscapeCode, ansiYellow) + line + terminalResetEscapeCode
+		}
+		fmt.Fprintln(t.stdout, line)
 		return
 	}
-	fmt.Printf("%d: %s = %s\n", i, val.Name, val.SinglelineString())
+	fmt.Fprintf(t.stdout, "%d: %s = %s\n", i, val.Name, value)
 }
 
 func (t *Term) printDisplays() {
 	for i := range t.displays {
-		if t.displays[i] != "" {
+		if t.displays[i].expr != "" {
 			t.printDisplay(i)
 		}
 	}
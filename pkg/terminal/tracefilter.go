@@ -0,0 +1,124 @@
+package terminal
+
+import (
+	"time"
+
+	"github.com/go-delve/delve/service/api"
+)
+
+// TraceFilter selects the tracepoint hits that are reported.
+type TraceFilter struct {
+	// Labels, if not empty, are the pprof labels the goroutine hitting
+	// the tracepoint must have. The tracepoints must load the goroutine
+	// (see api.Breakpoint.Goroutine) for this to work.
+	Labels map[string]string
+	// MaxDepth, if greater than zero, is the maximum number of nested
+	// traced calls reported, calls nested deeper are not reported.
+	MaxDepth int
+	// If FollowCalls is greater than zero calls to functions other than
+	// Roots are only reported if they happen at most FollowCalls traced
+	// calls below a call to one of Roots.
+	Roots       []string
+	FollowCalls int
+}
+
+// traceCall is a traced call that hasn't returned yet.
+type traceCall struct {
+	fn    string
+	start time.Time
+}
+
+// traceHit is a tracepoint hit, as tracked by trackTracepoint.
+type traceHit struct {
+	// depth is the number of traced calls the goroutine was executing
+	// when the function was called.
+	depth int
+	// paired is set for returns matched with the entry of the function,
+	// dur is the duration of the call.
+	paired bool
+	dur    time.Duration
+	report bool
+}
+
+// SetTraceFilter sets the filter for tracepoint hits.
+func (t *Term) SetTraceFilter(filter TraceFilter) {
+	t.traceFilter = filter
+	t.traceRoots = make(map[string]bool)
+	for _, fn := range filter.Roots {
+		t.traceRoots[fn] = true
+	}
+}
+
+// traceTracking returns true if tracepoint hits must be paired with the
+// calls they belong to.
+func (t *Term) traceTracking() bool {
+	return t.traceJSON || t.traceLatency || len(t.traceFilter.Labels) > 0 || t.traceFilter.MaxDepth > 0 || t.traceFilter.FollowCalls > 0
+}
+
+// trackTracepoint updates the traced calls being executed by the
+// goroutine of th for a hit of its tracepoint at time now and decides
+// whether the hit should be reported.
+// Returns are paired with the innermost call of the same function, calls
+// above it did not return through a traced return instruction (for
+// example because they panicked) and are discarded.
+func (t *Term) trackTracepoint(th *api.Thread, now time.Time) traceHit {
+	if !t.traceLabelsMatch(th) {
+		return traceHit{}
+	}
+	if t.traceCalls == nil {
+		t.traceCalls = make(map[int][]traceCall)
+	}
+	calls := t.traceCalls[th.GoroutineID]
+	fn := th.Function.Name()
+	follow := t.traceFilter.FollowCalls > 0
+
+	if !th.Breakpoint.TraceReturn {
+		if follow && !t.traceRoots[fn] {
+			root := -1
+			for i := range calls {
+				if t.traceRoots[calls[i].fn] {
+					root = i
+					break
+				}
+			}
+			if root < 0 || len(calls)-root > t.traceFilter.FollowCalls {
+				return traceHit{}
+			}
+		}
+		t.traceCalls[th.GoroutineID] = append(calls, traceCall{fn: fn, start: now})
+		return traceHit{depth: len(calls), report: t.traceDepthReported(len(calls))}
+	}
+
+	for i := len(calls) - 1; i >= 0; i-- {
+		if calls[i].fn != fn {
+			continue
+		}
+		hit := traceHit{depth: i, paired: true, dur: now.Sub(calls[i].start), report: t.traceDepthReported(i)}
+		if hit.report && t.traceLatency {
+			t.traceRecords = append(t.traceRecords, traceRecord{fn: fn, goroutineID: th.GoroutineID, start: calls[i].start, dur: hit.dur})
+		}
+		t.traceCalls[th.GoroutineID] = calls[:i]
+		return hit
+	}
+	// the entry of the function was not seen
+	return traceHit{depth: len(calls), report: (!follow || t.traceRoots[fn]) && t.traceDepthReported(len(calls))}
+}
+
+func (t *Term) traceDepthReported(depth int) bool {
+	return t.traceFilter.MaxDepth <= 0 || depth < t.traceFilter.MaxDepth
+}
+
+func (t *Term) traceLabelsMatch(th *api.Thread) bool {
+	if len(t.traceFilter.Labels) == 0 {
+		return true
+	}
+	if th.BreakpointInfo == nil || th.BreakpointInfo.Goroutine == nil {
+		return false
+	}
+	for k, v := range t.traceFilter.Labels {
+		if th.BreakpointInfo.Goroutine.Labels[k] != v {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,91 @@
+package terminal
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/go-delve/delve/service/api"
+)
+
+// traceEvent is a tracepoint hit, as written by 'dlv trace --output-format json'.
+type traceEvent struct {
+	Time time.Time `json:"time"`
+	// Kind is "call" for tracepoints at the entry of a function and
+	// "return" for tracepoints at its return instructions.
+	Kind        string `json:"kind"`
+	GoroutineID int    `json:"goroutineID"`
+	ThreadID    int    `json:"threadID"`
+	Function    string `json:"function"`
+	File        string `json:"file"`
+	Line        int    `json:"line"`
+	// Depth is the number of traced calls the goroutine was executing
+	// when the function was called.
+	Depth   int             `json:"depth"`
+	Args    []traceVariable `json:"args,omitempty"`
+	Returns []traceVariable `json:"returns,omitempty"`
+	Stack   []traceFrame    `json:"stack,omitempty"`
+	// Duration is the duration of the call in nanoseconds, only set for
+	// return events when latency is measured.
+	Duration int64 `json:"duration,omitempty"`
+}
+
+type traceVariable struct {
+	Name  string `json:"name"`
+	Type  string `json:"type"`
+	Value string `json:"value"`
+}
+
+type traceFrame struct {
+	PC       uint64 `json:"pc"`
+	Function string `json:"function"`
+	File     string `json:"file"`
+	Line     int    `json:"line"`
+}
+
+// printTracepointJSON writes the tracepoint hit by th to the trace
+// output as a single line of JSON.
+func printTracepointJSON(t *Term, th *api.Thread, hit traceHit) {
+	ev := traceEvent{
+		Time:        th.BreakpointHitTime,
+		Depth:       hit.depth,
+		GoroutineID: th.GoroutineID,
+		ThreadID:    th.ID,
+		Function:    th.Function.Name(),
+		File:        th.File,
+		Line:        th.Line,
+	}
+
+	if th.Breakpoint.TraceReturn {
+		ev.Kind = "return"
+		ev.Returns = traceVariables(th.ReturnValues, 0)
+		if hit.paired && t.traceLatency {
+			ev.Duration = hit.dur.Nanoseconds()
+		}
+	} else {
+		ev.Kind = "call"
+		if th.BreakpointInfo != nil {
+			ev.Args = traceVariables(th.BreakpointInfo.Arguments, api.VariableArgument)
+		}
+	}
+
+	if th.BreakpointInfo != nil {
+		for _, frame := range th.BreakpointInfo.Stacktrace {
+			ev.Stack = append(ev.Stack, traceFrame{PC: frame.PC, Function: frame.Function.Name(), File: frame.File, Line: frame.Line})
+		}
+	}
+
+	json.NewEncoder(t.traceOutput()).Encode(&ev)
+}
+
+// traceVariables converts the variables in vars with all the flags in
+// flags set.
+func traceVariables(vars []api.Variable, flags api.VariableFlags) []traceVariable {
+	var r []traceVariable
+	for i := range vars {
+		if vars[i].Flags&flags != flags {
+			continue
+		}
+		r = append(r, traceVariable{Name: vars[i].Name, Type: vars[i].Type, Value: vars[i].SinglelineString()})
+	}
+	return r
+}
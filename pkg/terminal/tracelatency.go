@@ -0,0 +1,108 @@
+package terminal
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"text/tabwriter"
+	"time"
+)
+
+// traceRecord is a traced call that returned.
+type traceRecord struct {
+	fn          string
+	goroutineID int
+	start       time.Time
+	dur         time.Duration
+}
+
+// SetTraceLatency enables measuring the duration of traced calls. Times
+// are taken by the debugger when the target process stops at the
+// tracepoints, so they include the overhead of stopping the target process
+// but not the communication with the client.
+func (t *Term) SetTraceLatency(latency bool) {
+	t.traceLatency = latency
+}
+
+// PrintTraceLatencySummary prints the number of calls and the minimum,
+// median, 99th percentile, maximum and total duration of the calls to
+// each traced function.
+func (t *Term) PrintTraceLatencySummary(out io.Writer) {
+	durs := make(map[string][]time.Duration)
+	var fns []string
+	for _, rec := range t.traceRecords {
+		if _, ok := durs[rec.fn]; !ok {
+			fns = append(fns, rec.fn)
+		}
+		durs[rec.fn] = append(durs[rec.fn], rec.dur)
+	}
+	sort.Strings(fns)
+
+	w := tabwriter.NewWriter(out, 0, 8, 2, ' ', 0)
+	fmt.Fprintf(w, "Function\tCount\tMin\tP50\tP99\tMax\tTotal\n")
+	for _, fn := range fns {
+		d := durs[fn]
+		sort.Slice(d, func(i, j int) bool { return d[i] < d[j] })
+		var total time.Duration
+		for _, x := range d {
+			total += x
+		}
+		fmt.Fprintf(w, "%s\t%d\t%v\t%v\t%v\t%v\t%v\n", fn, len(d), d[0], percentile(d, 50), percentile(d, 99), d[len(d)-1], total)
+	}
+	w.Flush()
+}
+
+// percentile returns the p-th percentile of the sorted durations d, using
+// the nearest rank method.
+func percentile(d []time.Duration, p int) time.Duration {
+	rank := (p*len(d) + 99) / 100
+	if rank < 1 {
+		rank = 1
+	}
+	return d[rank-1]
+}
+
+// chromeTraceEvent is a complete event of the Chrome trace event format.
+type chromeTraceEvent struct {
+	Name  string  `json:"name"`
+	Cat   string  `json:"cat"`
+	Phase string  `json:"ph"`
+	Ts    float64 `json:"ts"`
+	Dur   float64 `json:"dur"`
+	Pid   int     `json:"pid"`
+	Tid   int     `json:"tid"`
+}
+
+// WriteChromeTrace writes the traced calls that returned in the Chrome
+// trace event format, with one track for each goroutine, so that they can
+// be opened with a timeline viewer such as chrome://tracing or Perfetto.
+func (t *Term) WriteChromeTrace(out io.Writer) error {
+	var trace struct {
+		TraceEvents     []chromeTraceEvent `json:"traceEvents"`
+		DisplayTimeUnit string             `json:"displayTimeUnit"`
+	}
+	trace.TraceEvents = []chromeTraceEvent{}
+	trace.DisplayTimeUnit = "ns"
+	var origin time.Time
+	for i, rec := range t.traceRecords {
+		if i == 0 || rec.start.Before(origin) {
+			origin = rec.start
+		}
+	}
+	micros := func(d time.Duration) float64 {
+		return float64(d.Nanoseconds()) / 1000
+	}
+	for _, rec := range t.traceRecords {
+		trace.TraceEvents = append(trace.TraceEvents, chromeTraceEvent{
+			Name:  rec.fn,
+			Cat:   "function",
+			Phase: "X",
+			Ts:    micros(rec.start.Sub(origin)),
+			Dur:   micros(rec.dur),
+			Pid:   1,
+			Tid:   rec.goroutineID,
+		})
+	}
+	return json.NewEncoder(out).Encode(&trace)
+}
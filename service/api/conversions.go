@@ -10,6 +10,7 @@ import (
 	"sort"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/go-delve/delve/pkg/dwarf/godwarf"
 	"github.com/go-delve/delve/pkg/dwarf/op"
@@ -91,9 +92,11 @@ func ConvertThread(th proc.Thread) *Thread {
 	}
 
 	var bp *Breakpoint
+	var hitTime time.Time
 
 	if b := th.Breakpoint(); b.Active {
 		bp = ConvertBreakpoint(b.Breakpoint)
+		hitTime = b.HitTime
 	}
 
 	if g, _ := proc.GetG(th); g != nil {
@@ -108,6 +111,8 @@ func ConvertThread(th proc.Thread) *Thread {
 		Function:    function,
 		GoroutineID: gid,
 		Breakpoint:  bp,
+
+		BreakpointHitTime: hitTime,
 	}
 }
 
@@ -216,11 +221,16 @@ func ConvertVar(v *proc.Variable) *Variable {
 		}
 
 	default:
-		r.Children = make([]Variable, len(v.Children))
+		r.Children = make([]Variable, len(v.Children), len(v.Children)+len(v.Captured))
 
 		for i := range v.Children {
 			r.Children[i] = *ConvertVar(&v.Children[i])
 		}
+
+		// variables captured by closures
+		for i := range v.Captured {
+			r.Children = append(r.Children, *ConvertVar(&v.Captured[i]))
+		}
 	}
 
 	return &r
@@ -308,6 +318,99 @@ func ConvertGoroutines(gs []*proc.G) []*Goroutine {
 	return goroutines
 }
 
+// ConvertTimers converts from []*proc.Timer to []api.Timer.
+func ConvertTimers(timers []*proc.Timer) []Timer {
+	r := make([]Timer, len(timers))
+	for i, t := range timers {
+		r[i] = Timer{
+			Addr:        t.Addr,
+			When:        t.When,
+			Period:      t.Period,
+			Func:        ConvertLocation(t.Func),
+			GoroutineID: t.GoroutineID,
+		}
+		if t.Unreadable != nil {
+			r[i].Unreadable = t.Unreadable.Error()
+		}
+	}
+	return r
+}
+
+// ConvertPollDescs converts from []*proc.PollDesc to []api.PollDesc.
+func ConvertPollDescs(pds []*proc.PollDesc) []PollDesc {
+	r := make([]PollDesc, len(pds))
+	for i, pd := range pds {
+		r[i] = PollDesc{
+			Addr:             pd.Addr,
+			Fd:               pd.Fd,
+			Closing:          pd.Closing,
+			ReadGoroutineID:  pd.ReadGoroutineID,
+			WriteGoroutineID: pd.WriteGoroutineID,
+			ReadDeadline:     pd.ReadDeadline,
+			WriteDeadline:    pd.WriteDeadline,
+			Description:      pd.Description,
+		}
+		if pd.Unreadable != nil {
+			r[i].Unreadable = pd.Unreadable.Error()
+		}
+	}
+	return r
+}
+
+// ConvertVariableDiffs converts a slice of proc.VariableDiff to a slice of
+// api.VariableDiff.
+func ConvertVariableDiffs(diffs []proc.VariableDiff) []VariableDiff {
+	r := make([]VariableDiff, len(diffs))
+	for i, diff := range diffs {
+		r[i] = VariableDiff{Path: diff.Path, Reason: diff.Reason}
+		if diff.X != nil {
+			r[i].X = ConvertVar(diff.X)
+		}
+		if diff.Y != nil {
+			r[i].Y = ConvertVar(diff.Y)
+		}
+	}
+	return r
+}
+
+// ConvertMemoryRegions converts a slice of proc.MemoryRegion to a slice of
+// api.MemoryRegion.
+func ConvertMemoryRegions(regions []proc.MemoryRegion) []MemoryRegion {
+	r := make([]MemoryRegion, len(regions))
+	for i, region := range regions {
+		r[i] = MemoryRegion{
+			Addr:       region.Addr,
+			Size:       region.Size,
+			Read:       region.Read,
+			Write:      region.Write,
+			Exec:       region.Exec,
+			Filename:   region.Filename,
+			Offset:     region.Offset,
+			Heap:       region.Heap,
+			Text:       region.Text,
+			ModuleData: region.ModuleData,
+			Goroutines: region.Goroutines,
+		}
+	}
+	return r
+}
+
+// ConvertMemorySearchResults converts a slice of proc.MemorySearchResult
+// to a slice of api.MemorySearchResult.
+func ConvertMemorySearchResults(results []proc.MemorySearchResult) []MemorySearchResult {
+	r := make([]MemorySearchResult, len(results))
+	for i, res := range results {
+		r[i] = MemorySearchResult{
+			Addr:   res.Addr,
+			Heap:   res.Heap,
+			Symbol: res.Symbol,
+			Base:   res.Base,
+			Size:   res.Size,
+		}
+	}
+	return r
+}
+
 // ConvertLocation converts from proc.Location to api.Location.
 func ConvertLocation(loc proc.Location) Location {
 	return Location{
@@ -335,6 +438,29 @@ func ConvertAsmInstruction(inst proc.AsmInstruction, text string) AsmInstruction
 	}
 }
 
+// ConvertBasicBlockEdges converts a slice of proc.BasicBlockEdge to a slice
+// of api.BasicBlockEdge.
+func ConvertBasicBlockEdges(edges []proc.BasicBlockEdge) []BasicBlockEdge {
+	r := make([]BasicBlockEdge, len(edges))
+	for i := range edges {
+		r[i] = BasicBlockEdge{To: edges[i].To, Kind: edges[i].Kind.String()}
+	}
+	return r
+}
+
+// ConvertInlinedCalls converts a slice of proc.InlinedCallSite to a slice
+// of api.InlinedCall.
+func ConvertInlinedCalls(calls []proc.InlinedCallSite) []InlinedCall {
+	if len(calls) == 0 {
+		return nil
+	}
+	r := make([]InlinedCall, len(calls))
+	for i := range calls {
+		r[i] = InlinedCall{Function: ConvertFunction(calls[i].Fn), File: calls[i].File, Line: calls[i].Line}
+	}
+	return r
+}
+
 // LoadConfigToProc converts an api.LoadConfig to proc.LoadConfig.
 func LoadConfigToProc(cfg *LoadConfig) *proc.LoadConfig {
 	if cfg == nil {
@@ -0,0 +1,252 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math"
+	"reflect"
+	"strconv"
+)
+
+// Keys of the objects used by JSONValue to annotate the parts of a value
+// that could not be exported.
+const (
+	// JSONUnreadableKey is set to the error message of unreadable values.
+	JSONUnreadableKey = "$unreadable"
+	// JSONCycleKey is set to the address of pointers to a value that is
+	// already being exported (a cycle in the data structure).
+	JSONCycleKey = "$cycle"
+	// JSONNotLoadedKey is set to the address of values that were not loaded
+	// because of the LoadConfig used.
+	JSONNotLoadedKey = "$notLoaded"
+	// JSONMoreKey is set to the number of elements of arrays, slices, maps
+	// and strings that were not loaded because of the LoadConfig used.
+	JSONMoreKey = "$more"
+	// JSONStringKey contains the loaded part of a truncated string.
+	JSONStringKey = "$string"
+)
+
+// JSONValue returns the value of v encoded as JSON. Structs and maps with
+// keys of a basic type are converted to objects, maps with other keys are
+// converted to arrays of {"key": ..., "value": ...} objects, arrays and
+// slices are converted to arrays, pointers and interfaces are
+// dereferenced. Parts of the value that could not be exported are
+// replaced by objects with one of the JSON...Key keys.
+// If indent is not empty the output is indented using it.
+func (v *Variable) JSONValue(indent string) ([]byte, error) {
+	e := &jsonEncoder{visiting: make(map[uint64]bool)}
+	buf, err := json.Marshal(e.value(v))
+	if err != nil || indent == "" {
+		return buf, err
+	}
+	var out bytes.Buffer
+	if err := json.Indent(&out, buf, "", indent); err != nil {
+		return nil, err
+	}
+	return out.Bytes(), nil
+}
+
+// jsonObject is a JSON object that preserves the order of its keys.
+type jsonObject []jsonField
+
+type jsonField struct {
+	key   string
+	value interface{}
+}
+
+func (o jsonObject) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+	for i, f := range o {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		key, err := json.Marshal(f.key)
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(key)
+		buf.WriteByte(':')
+		val, err := json.Marshal(f.value)
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(val)
+	}
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
+
+type jsonEncoder struct {
+	// visiting contains the addresses of the values pointed to by the
+	// pointers currently being exported.
+	visiting map[uint64]bool
+}
+
+func jsonAnnotation(key string, value interface{}) jsonObject {
+	return jsonObject{{key, value}}
+}
+
+func (e *jsonEncoder) value(v *Variable) interface{} {
+	if v.Unreadable != "" {
+		return jsonAnnotation(JSONUnreadableKey, v.Unreadable)
+	}
+
+	switch v.Kind {
+	case reflect.Bool:
+		return v.Value == "true"
+
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		if v.Value == "" {
+			return nil
+		}
+		return json.Number(v.Value)
+
+	case reflect.Float32, reflect.Float64:
+		f, err := strconv.ParseFloat(v.Value, 64)
+		if err != nil || math.IsNaN(f) || math.IsInf(f, 0) {
+			// not representable as a JSON number
+			return v.Value
+		}
+		return json.Number(strconv.FormatFloat(f, 'g', -1, 64))
+
+	case reflect.Complex64, reflect.Complex128:
+		if len(v.Children) != 2 {
+			return v.Value
+		}
+		return fmt.Sprintf("(%s + %si)", v.Children[0].Value, v.Children[1].Value)
+
+	case reflect.String:
+		if int64(len(v.Value)) < v.Len {
+			return jsonObject{{JSONStringKey, v.Value}, {JSONMoreKey, v.Len - int64(len(v.Value))}}
+		}
+		return v.Value
+
+	case reflect.Ptr:
+		if len(v.Children) == 0 || v.Children[0].Addr == 0 {
+			return nil
+		}
+		pointee := &v.Children[0]
+		addr := fmt.Sprintf("%#x", pointee.Addr)
+		if pointee.OnlyAddr {
+			return jsonAnnotation(JSONNotLoadedKey, addr)
+		}
+		if e.visiting[pointee.Addr] {
+			return jsonAnnotation(JSONCycleKey, addr)
+		}
+		e.visiting[pointee.Addr] = true
+		r := e.value(pointee)
+		delete(e.visiting, pointee.Addr)
+		return r
+
+	case reflect.Interface:
+		if v.Addr == 0 || len(v.Children) == 0 {
+			return nil
+		}
+		data := &v.Children[0]
+		if data.Kind == reflect.Invalid {
+			return nil
+		}
+		if data.OnlyAddr {
+			return jsonAnnotation(JSONNotLoadedKey, fmt.Sprintf("%#x", v.Addr))
+		}
+		return e.value(data)
+
+	case reflect.Struct:
+		if v.Len > 0 && len(v.Children) == 0 {
+			return jsonAnnotation(JSONNotLoadedKey, fmt.Sprintf("%#x", v.Addr))
+		}
+		return e.fields(v.Children)
+
+	case reflect.Array, reflect.Slice:
+		if v.Kind == reflect.Slice && v.Base == 0 {
+			return nil
+		}
+		r := make([]interface{}, 0, len(v.Children)+1)
+		for i := range v.Children {
+			r = append(r, e.value(&v.Children[i]))
+		}
+		if more := v.Len - int64(len(v.Children)); more > 0 {
+			r = append(r, jsonAnnotation(JSONMoreKey, more))
+		}
+		return r
+
+	case reflect.Map:
+		if v.Base == 0 {
+			return nil
+		}
+		return e.mapValue(v)
+
+	case reflect.Chan, reflect.Func, reflect.UnsafePointer:
+		if v.Kind == reflect.Func && len(v.Children) > 0 {
+			// variables captured by the closure
+			return jsonObject{{"$func", v.Value}, {"$captured", e.fields(v.Children)}}
+		}
+		return v.SinglelineString()
+	}
+
+	if v.Value != "" {
+		return v.Value
+	}
+	return nil
+}
+
+func (e *jsonEncoder) fields(children []Variable) jsonObject {
+	r := make(jsonObject, 0, len(children))
+	for i := range children {
+		r = append(r, jsonField{children[i].Name, e.value(&children[i])})
+	}
+	return r
+}
+
+func (e *jsonEncoder) mapValue(v *Variable) interface{} {
+	more := v.Len - int64(len(v.Children)/2)
+
+	scalarKeys := true
+	for i := 0; i+1 < len(v.Children); i += 2 {
+		if _, ok := jsonMapKey(&v.Children[i]); !ok {
+			scalarKeys = false
+			break
+		}
+	}
+
+	if scalarKeys {
+		r := make(jsonObject, 0, len(v.Children)/2+1)
+		for i := 0; i+1 < len(v.Children); i += 2 {
+			key, _ := jsonMapKey(&v.Children[i])
+			r = append(r, jsonField{key, e.value(&v.Children[i+1])})
+		}
+		if more > 0 {
+			r = append(r, jsonField{JSONMoreKey, more})
+		}
+		return r
+	}
+
+	r := make([]interface{}, 0, len(v.Children)/2+1)
+	for i := 0; i+1 < len(v.Children); i += 2 {
+		r = append(r, jsonObject{{"key", e.value(&v.Children[i])}, {"value", e.value(&v.Children[i+1])}})
+	}
+	if more > 0 {
+		r = append(r, jsonAnnotation(JSONMoreKey, more))
+	}
+	return r
+}
+
+// jsonMapKey returns the string used as the key of a JSON object for the
+// map key k, if k has a basic type.
+func jsonMapKey(k *Variable) (string, bool) {
+	if k.Unreadable != "" {
+		return "", false
+	}
+	switch k.Kind {
+	case reflect.Bool, reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr,
+		reflect.Float32, reflect.Float64:
+		return k.Value, true
+	case reflect.String:
+		return k.Value, int64(len(k.Value)) == k.Len
+	}
+	return "", false
+}
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"io"
 	"reflect"
+	"regexp"
 	"strconv"
 	"strings"
 	"text/tabwriter"
@@ -19,19 +20,121 @@ const (
 
 // SinglelineString returns a representation of v on a single line.
 func (v *Variable) SinglelineString() string {
-	var buf bytes.Buffer
-	v.writeTo(&buf, true, false, true, "")
-	return buf.String()
+	return v.SinglelineStringFormatted("")
 }
 
 // MultilineString returns a representation of v on multiple lines.
 func (v *Variable) MultilineString(indent string) string {
+	return v.MultilineStringFormatted(indent, "")
+}
+
+// SinglelineStringFormatted returns a representation of v on a single
+// line, numbers, strings and byte slices are formatted using fmtstr (see
+// FormatValue).
+func (v *Variable) SinglelineStringFormatted(fmtstr string) string {
+	var buf bytes.Buffer
+	v.writeTo(&buf, true, false, true, "", fmtstr)
+	return buf.String()
+}
+
+// MultilineStringFormatted returns a representation of v on multiple
+// lines, numbers, strings and byte slices are formatted using fmtstr (see
+// FormatValue).
+func (v *Variable) MultilineStringFormatted(indent, fmtstr string) string {
 	var buf bytes.Buffer
-	v.writeTo(&buf, true, true, true, indent)
+	v.writeTo(&buf, true, true, true, indent, fmtstr)
 	return buf.String()
 }
 
-func (v *Variable) writeTo(buf io.Writer, top, newlines, includeType bool, indent string) {
+// formatNames maps the names accepted by ParseFormat to format strings.
+var formatNames = map[string]string{
+	"hex":   "%#x",
+	"bin":   "%#b",
+	"oct":   "%#o",
+	"dec":   "%d",
+	"char":  "%c",
+	"quote": "%q",
+}
+
+var formatRegexp = regexp.MustCompile(`^%[-+# 0]*[0-9]*(\.[0-9]+)?[bcdoOqxXUeEfFgGs]$`)
+
+// ParseFormat parses the format s, either a fmt verb (for example %x or
+// %08b) or one of hex, bin, oct, dec, char and quote, and returns the
+// corresponding format string.
+func ParseFormat(s string) (string, error) {
+	if fmtstr, ok := formatNames[s]; ok {
+		return fmtstr, nil
+	}
+	if !formatRegexp.MatchString(s) {
+		return "", fmt.Errorf("invalid format %q", s)
+	}
+	return s, nil
+}
+
+// FormatValue formats value, the value of a variable of the specified
+// kind as stored in the Value field of Variable, using fmtstr. Integers,
+// floating point numbers and strings are formatted as if passed to
+// fmt.Sprintf, but only by the verbs that fmt defines for their kind. If
+// the value can not be formatted using fmtstr it is returned unchanged.
+func FormatValue(kind reflect.Kind, value, fmtstr string) string {
+	if fmtstr == "" {
+		return value
+	}
+	switch kind {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if !isIntegerVerb(fmtstr) {
+			break
+		}
+		if n, err := strconv.ParseInt(value, 10, 64); err == nil {
+			return fmt.Sprintf(fmtstr, n)
+		}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		if !isIntegerVerb(fmtstr) {
+			break
+		}
+		if n, err := strconv.ParseUint(value, 10, 64); err == nil {
+			return fmt.Sprintf(fmtstr, n)
+		}
+	case reflect.Float32, reflect.Float64:
+		if !isFloatVerb(fmtstr) {
+			break
+		}
+		if f, err := strconv.ParseFloat(value, 64); err == nil {
+			return fmt.Sprintf(fmtstr, f)
+		}
+	case reflect.String:
+		if isStringVerb(fmtstr) {
+			return fmt.Sprintf(fmtstr, value)
+		}
+	}
+	return value
+}
+
+func isIntegerVerb(fmtstr string) bool {
+	switch fmtstr[len(fmtstr)-1] {
+	case 'b', 'c', 'd', 'o', 'O', 'q', 'x', 'X', 'U':
+		return true
+	}
+	return false
+}
+
+func isFloatVerb(fmtstr string) bool {
+	switch fmtstr[len(fmtstr)-1] {
+	case 'b', 'e', 'E', 'f', 'F', 'g', 'G', 'x', 'X':
+		return true
+	}
+	return false
+}
+
+func isStringVerb(fmtstr string) bool {
+	switch fmtstr[len(fmtstr)-1] {
+	case 's', 'q', 'x', 'X':
+		return true
+	}
+	return false
+}
+
+func (v *Variable) writeTo(buf io.Writer, top, newlines, includeType bool, indent, fmtstr string) {
 	if v.Unreadable != "" {
 		fmt.Fprintf(buf, "(unreadable %s)", v.Unreadable)
 		return
@@ -46,11 +149,16 @@ func (v *Variable) writeTo(buf io.Writer, top, newlines, includeType bool, inden
 		return
 	}
 
+	if v.Flags&VariablePrettyPrinted != 0 {
+		v.writePrettyPrintedTo(buf, newlines, indent, fmtstr)
+		return
+	}
+
 	switch v.Kind {
 	case reflect.Slice:
-		v.writeSliceTo(buf, newlines, includeType, indent)
+		v.writeSliceTo(buf, newlines, includeType, indent, fmtstr)
 	case reflect.Array:
-		v.writeArrayTo(buf, newlines, includeType, indent)
+		v.writeArrayTo(buf, newlines, includeType, indent, fmtstr)
 	case reflect.Ptr:
 		if v.Type == "" || len(v.Children) == 0 {
 			fmt.Fprint(buf, "nil")
@@ -62,7 +170,7 @@ func (v *Variable) writeTo(buf io.Writer, top, newlines, includeType bool, inden
 			}
 		} else {
 			fmt.Fprint(buf, "*")
-			v.Children[0].writeTo(buf, false, newlines, includeType, indent)
+			v.Children[0].writeTo(buf, false, newlines, includeType, indent, fmtstr)
 		}
 	case reflect.UnsafePointer:
 		if len(v.Children) == 0 {
@@ -71,10 +179,10 @@ func (v *Variable) writeTo(buf io.Writer, top, newlines, includeType bool, inden
 			fmt.Fprintf(buf, "unsafe.Pointer(%#x)", v.Children[0].Addr)
 		}
 	case reflect.String:
-		v.writeStringTo(buf)
+		v.writeStringTo(buf, fmtstr)
 	case reflect.Chan:
 		if newlines {
-			v.writeStructTo(buf, newlines, includeType, indent)
+			v.writeStructTo(buf, newlines, includeType, indent, fmtstr)
 		} else {
 			if len(v.Children) == 0 {
 				fmt.Fprintf(buf, "%s nil", v.Type)
@@ -83,7 +191,7 @@ func (v *Variable) writeTo(buf io.Writer, top, newlines, includeType bool, inden
 			}
 		}
 	case reflect.Struct:
-		v.writeStructTo(buf, newlines, includeType, indent)
+		v.writeStructTo(buf, newlines, includeType, indent, fmtstr)
 	case reflect.Interface:
 		if v.Addr == 0 {
 			// an escaped interface variable that points to nil, this shouldn't
@@ -111,7 +219,7 @@ func (v *Variable) writeTo(buf io.Writer, top, newlines, includeType bool, inden
 			} else if data.Children[0].OnlyAddr {
 				fmt.Fprintf(buf, "0x%x", v.Children[0].Addr)
 			} else {
-				v.Children[0].writeTo(buf, false, newlines, !includeType, indent)
+				v.Children[0].writeTo(buf, false, newlines, !includeType, indent, fmtstr)
 			}
 		} else if data.OnlyAddr {
 			if strings.Contains(v.Type, "/") {
@@ -120,36 +228,48 @@ func (v *Variable) writeTo(buf io.Writer, top, newlines, includeType bool, inden
 				fmt.Fprintf(buf, "*(*%s)(%#x)", v.Type, v.Addr)
 			}
 		} else {
-			v.Children[0].writeTo(buf, false, newlines, !includeType, indent)
+			v.Children[0].writeTo(buf, false, newlines, !includeType, indent, fmtstr)
 		}
 	case reflect.Map:
-		v.writeMapTo(buf, newlines, includeType, indent)
+		v.writeMapTo(buf, newlines, includeType, indent, fmtstr)
 	case reflect.Func:
 		if v.Value == "" {
 			fmt.Fprint(buf, "nil")
 		} else {
 			fmt.Fprintf(buf, "%s", v.Value)
+			if len(v.Children) > 0 {
+				// variables captured by the closure
+				fmt.Fprint(buf, " ")
+				v.writeFieldsTo(buf, newlines, indent, fmtstr)
+			}
 		}
 	case reflect.Complex64, reflect.Complex128:
 		fmt.Fprintf(buf, "(%s + %si)", v.Children[0].Value, v.Children[1].Value)
 	default:
 		if v.Value != "" {
-			buf.Write([]byte(v.Value))
+			buf.Write([]byte(FormatValue(v.Kind, v.Value, fmtstr)))
 		} else {
 			fmt.Fprintf(buf, "(unknown %s)", v.Kind)
 		}
 	}
 }
 
-func (v *Variable) writeStringTo(buf io.Writer) {
+func (v *Variable) writeStringTo(buf io.Writer, fmtstr string) {
 	s := v.Value
+	if fmtstr != "" && isStringVerb(fmtstr) {
+		fmt.Fprintf(buf, fmtstr, s)
+		if len(s) != int(v.Len) {
+			fmt.Fprintf(buf, "...+%d more", int(v.Len)-len(s))
+		}
+		return
+	}
 	if len(s) != int(v.Len) {
 		s = fmt.Sprintf("%s...+%d more", s, int(v.Len)-len(s))
 	}
 	fmt.Fprintf(buf, "%q", s)
 }
 
-func (v *Variable) writeSliceTo(buf io.Writer, newlines, includeType bool, indent string) {
+func (v *Variable) writeSliceTo(buf io.Writer, newlines, includeType bool, indent, fmtstr string) {
 	if includeType {
 		fmt.Fprintf(buf, "%s len: %d, cap: %d, ", v.Type, v.Len, v.Cap)
 	}
@@ -157,17 +277,48 @@ func (v *Variable) writeSliceTo(buf io.Writer, newlines, includeType bool, inden
 		fmt.Fprintf(buf, "nil")
 		return
 	}
-	v.writeSliceOrArrayTo(buf, newlines, indent)
+	v.writeSliceOrArrayTo(buf, newlines, indent, fmtstr)
 }
 
-func (v *Variable) writeArrayTo(buf io.Writer, newlines, includeType bool, indent string) {
+func (v *Variable) writeArrayTo(buf io.Writer, newlines, includeType bool, indent, fmtstr string) {
 	if includeType {
 		fmt.Fprintf(buf, "%s ", v.Type)
 	}
-	v.writeSliceOrArrayTo(buf, newlines, indent)
+	v.writeSliceOrArrayTo(buf, newlines, indent, fmtstr)
+}
+
+func (v *Variable) writePrettyPrintedTo(buf io.Writer, newlines bool, indent, fmtstr string) {
+	fmt.Fprint(buf, v.Value)
+	if len(v.Children) > 0 {
+		fmt.Fprint(buf, " ")
+		v.writeFieldsTo(buf, newlines, indent, fmtstr)
+	}
 }
 
-func (v *Variable) writeStructTo(buf io.Writer, newlines, includeType bool, indent string) {
+func (v *Variable) writeFieldsTo(buf io.Writer, newlines bool, indent, fmtstr string) {
+	nl := v.shouldNewlineStruct(newlines)
+
+	fmt.Fprint(buf, "{")
+	for i := range v.Children {
+		if nl {
+			fmt.Fprintf(buf, "\n%s%s", indent, indentString)
+		}
+		fmt.Fprintf(buf, "%s: ", v.Children[i].Name)
+		v.Children[i].writeTo(buf, false, nl, true, indent+indentString, fmtstr)
+		if i != len(v.Children)-1 || nl {
+			fmt.Fprint(buf, ",")
+			if !nl {
+				fmt.Fprint(buf, " ")
+			}
+		}
+	}
+	if nl {
+		fmt.Fprintf(buf, "\n%s", indent)
+	}
+	fmt.Fprint(buf, "}")
+}
+
+func (v *Variable) writeStructTo(buf io.Writer, newlines, includeType bool, indent, fmtstr string) {
 	if int(v.Len) != len(v.Children) && len(v.Children) == 0 {
 		if strings.Contains(v.Type, "/") {
 			fmt.Fprintf(buf, "(*%q)(%#x)", v.Type, v.Addr)
@@ -190,7 +341,7 @@ func (v *Variable) writeStructTo(buf io.Writer, newlines, includeType bool, inde
 			fmt.Fprintf(buf, "\n%s%s", indent, indentString)
 		}
 		fmt.Fprintf(buf, "%s: ", v.Children[i].Name)
-		v.Children[i].writeTo(buf, false, nl, true, indent+indentString)
+		v.Children[i].writeTo(buf, false, nl, true, indent+indentString, fmtstr)
 		if i != len(v.Children)-1 || nl {
 			fmt.Fprint(buf, ",")
 			if !nl {
@@ -211,7 +362,7 @@ func (v *Variable) writeStructTo(buf io.Writer, newlines, includeType bool, inde
 	fmt.Fprint(buf, "}")
 }
 
-func (v *Variable) writeMapTo(buf io.Writer, newlines, includeType bool, indent string) {
+func (v *Variable) writeMapTo(buf io.Writer, newlines, includeType bool, indent, fmtstr string) {
 	if includeType {
 		fmt.Fprintf(buf, "%s ", v.Type)
 	}
@@ -232,9 +383,9 @@ func (v *Variable) writeMapTo(buf io.Writer, newlines, includeType bool, indent
 			fmt.Fprintf(buf, "\n%s%s", indent, indentString)
 		}
 
-		key.writeTo(buf, false, false, false, indent+indentString)
+		key.writeTo(buf, false, false, false, indent+indentString, fmtstr)
 		fmt.Fprint(buf, ": ")
-		value.writeTo(buf, false, nl, false, indent+indentString)
+		value.writeTo(buf, false, nl, false, indent+indentString, fmtstr)
 		if i != len(v.Children)-1 || nl {
 			fmt.Fprint(buf, ", ")
 		}
@@ -326,7 +477,16 @@ func (v *Variable) shouldNewlineStruct(newlines bool) bool {
 	return false
 }
 
-func (v *Variable) writeSliceOrArrayTo(buf io.Writer, newlines bool, indent string) {
+func (v *Variable) writeSliceOrArrayTo(buf io.Writer, newlines bool, indent, fmtstr string) {
+	if b, ok := v.byteSliceValue(fmtstr); ok {
+		// hex dump (or string) of a byte slice
+		fmt.Fprintf(buf, fmtstr, b)
+		if len(v.Children) != int(v.Len) {
+			fmt.Fprintf(buf, "...+%d more", int(v.Len)-len(v.Children))
+		}
+		return
+	}
+
 	nl := v.shouldNewlineArray(newlines)
 	fmt.Fprint(buf, "[")
 
@@ -334,7 +494,7 @@ func (v *Variable) writeSliceOrArrayTo(buf io.Writer, newlines bool, indent stri
 		if nl {
 			fmt.Fprintf(buf, "\n%s%s", indent, indentString)
 		}
-		v.Children[i].writeTo(buf, false, nl, false, indent+indentString)
+		v.Children[i].writeTo(buf, false, nl, false, indent+indentString, fmtstr)
 		if i != len(v.Children)-1 || nl {
 			fmt.Fprint(buf, ",")
 		}
@@ -360,6 +520,26 @@ func (v *Variable) writeSliceOrArrayTo(buf io.Writer, newlines bool, indent stri
 	fmt.Fprint(buf, "]")
 }
 
+// byteSliceValue returns the contents of v, a slice or array of bytes,
+// if fmtstr should be applied to the whole slice instead of its elements.
+func (v *Variable) byteSliceValue(fmtstr string) ([]byte, bool) {
+	if fmtstr == "" || !isStringVerb(fmtstr) || len(v.Children) == 0 {
+		return nil, false
+	}
+	b := make([]byte, len(v.Children))
+	for i := range v.Children {
+		if v.Children[i].Kind != reflect.Uint8 {
+			return nil, false
+		}
+		n, err := strconv.ParseUint(v.Children[i].Value, 10, 8)
+		if err != nil {
+			return nil, false
+		}
+		b[i] = byte(n)
+	}
+	return b, true
+}
+
 // PrettyExamineMemory examine the memory and format data
 //
 // `format` specifies the data format (or data type), `size` specifies size of each data,
@@ -3,6 +3,7 @@ package api
 import (
 	"fmt"
 	"math"
+	"reflect"
 	"strings"
 	"testing"
 )
@@ -57,3 +58,130 @@ func Test_byteArrayToUInt64(t *testing.T) {
 		})
 	}
 }
+
+func TestPrettyPrintedVariable(t *testing.T) {
+	v := Variable{
+		Name:  "r",
+		Type:  "main.Ring",
+		Kind:  reflect.Struct,
+		Flags: VariablePrettyPrinted,
+		Value: "Ring(len=2)",
+		Len:   2,
+		Children: []Variable{
+			{Name: "[0]", Kind: reflect.Int, Type: "int", Value: "1"},
+			{Name: "[1]", Kind: reflect.String, Type: "string", Value: "two", Len: 3},
+		},
+	}
+	if s, tgt := v.SinglelineString(), `Ring(len=2) {[0]: 1, [1]: "two"}`; s != tgt {
+		t.Errorf("expected %q got %q", tgt, s)
+	}
+	v.Children = nil
+	if s, tgt := v.SinglelineString(), "Ring(len=2)"; s != tgt {
+		t.Errorf("expected %q got %q", tgt, s)
+	}
+}
+
+func TestFormattedVariable(t *testing.T) {
+	for _, tc := range []struct {
+		in, out string
+		err     bool
+	}{
+		{"%x", "%x", false},
+		{"%08b", "%08b", false},
+		{"hex", "%#x", false},
+		{"bin", "%#b", false},
+		{"quote", "%q", false},
+		{"%v", "", true},
+		{"nonsense", "", true},
+	} {
+		fmtstr, err := ParseFormat(tc.in)
+		if (err != nil) != tc.err || fmtstr != tc.out {
+			t.Errorf("ParseFormat(%q) = %q, %v", tc.in, fmtstr, err)
+		}
+	}
+
+	v := Variable{
+		Kind: reflect.Struct,
+		Type: "main.T",
+		Len:  5,
+		Children: []Variable{
+			{Name: "A", Kind: reflect.Int, Type: "int", Value: "255"},
+			{Name: "F", Kind: reflect.Float64, Type: "float64", Value: "1.5"},
+			{Name: "R", Kind: reflect.Int32, Type: "int32", Value: "65"},
+			{Name: "S", Kind: reflect.String, Type: "string", Value: "hi", Len: 2},
+			{Name: "B", Kind: reflect.Slice, Type: "[]uint8", Addr: 0xc000020000, Base: 0xc000010000, Len: 3, Cap: 3, Children: []Variable{
+				{Kind: reflect.Uint8, Type: "uint8", Value: "104"},
+				{Kind: reflect.Uint8, Type: "uint8", Value: "105"},
+				{Kind: reflect.Uint8, Type: "uint8", Value: "255"},
+			}},
+		},
+	}
+
+	for _, tc := range []struct {
+		fmtstr, out string
+	}{
+		{"", `main.T {A: 255, F: 1.5, R: 65, S: "hi", B: []uint8 len: 3, cap: 3, [104,105,255]}`},
+		{"%x", `main.T {A: ff, F: 0x1.8p+00, R: 41, S: 6869, B: []uint8 len: 3, cap: 3, 6869ff}`},
+		{"%c", `main.T {A: ÿ, F: 1.5, R: A, S: "hi", B: []uint8 len: 3, cap: 3, [h,i,ÿ]}`},
+		{"%s", "main.T {A: 255, F: 1.5, R: 65, S: hi, B: []uint8 len: 3, cap: 3, hi\xff}"},
+		{"%.2f", `main.T {A: 255, F: 1.50, R: 65, S: "hi", B: []uint8 len: 3, cap: 3, [104,105,255]}`},
+		{"%e", `main.T {A: 255, F: 1.500000e+00, R: 65, S: "hi", B: []uint8 len: 3, cap: 3, [104,105,255]}`},
+		{"%g", `main.T {A: 255, F: 1.5, R: 65, S: "hi", B: []uint8 len: 3, cap: 3, [104,105,255]}`},
+		{"%d", `main.T {A: 255, F: 1.5, R: 65, S: "hi", B: []uint8 len: 3, cap: 3, [104,105,255]}`},
+	} {
+		out := v.SinglelineStringFormatted(tc.fmtstr)
+		if out != tc.out {
+			t.Errorf("format %q:\nexpected: %s\ngot:      %s", tc.fmtstr, tc.out, out)
+		}
+	}
+}
+
+func TestJSONValue(t *testing.T) {
+	// node.Next points back to node
+	node := Variable{Kind: reflect.Struct, Type: "main.Node", Addr: 0xc000010000, Len: 2, Children: []Variable{
+		{Name: "Name", Kind: reflect.String, Type: "string", Value: "a\"b", Len: 3},
+		{Name: "Next", Kind: reflect.Ptr, Type: "*main.Node", Children: []Variable{{Kind: reflect.Struct, Addr: 0xc000010000, Len: 2}}},
+	}}
+
+	v := Variable{
+		Kind: reflect.Struct,
+		Type: "main.T",
+		Len:  7,
+		Children: []Variable{
+			{Name: "N", Kind: reflect.Int, Type: "int", Value: "-1"},
+			{Name: "F", Kind: reflect.Float64, Type: "float64", Value: "+Inf"},
+			{Name: "S", Kind: reflect.String, Type: "string", Value: "ab", Len: 10},
+			{Name: "L", Kind: reflect.Slice, Type: "[]int", Base: 0xc000020000, Len: 3, Cap: 3, Children: []Variable{
+				{Kind: reflect.Int, Type: "int", Value: "1"},
+			}},
+			{Name: "M", Kind: reflect.Map, Type: "map[string]bool", Base: 0xc000030000, Len: 1, Children: []Variable{
+				{Kind: reflect.String, Type: "string", Value: "k", Len: 1},
+				{Kind: reflect.Bool, Type: "bool", Value: "true"},
+			}},
+			{Name: "P", Kind: reflect.Ptr, Type: "*main.Node", Children: []Variable{{Kind: reflect.Struct, Addr: 0xc000010000, Len: 2, Unreadable: "bad address"}}},
+			{Name: "Q", Kind: reflect.Ptr, Type: "*main.Node", Children: []Variable{node}},
+		},
+	}
+
+	const tgt = `{"N":-1,"F":"+Inf","S":{"$string":"ab","$more":8},"L":[1,{"$more":2}],"M":{"k":true},"P":{"$unreadable":"bad address"},"Q":{"Name":"a\"b","Next":{"$cycle":"0xc000010000"}}}`
+	out, err := v.JSONValue("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(out) != tgt {
+		t.Errorf("expected: %s\ngot:      %s", tgt, out)
+	}
+
+	m := Variable{Kind: reflect.Map, Type: "map[main.K]int", Base: 0xc000030000, Len: 1, Children: []Variable{
+		{Kind: reflect.Struct, Type: "main.K", Len: 1, Children: []Variable{{Name: "X", Kind: reflect.Int, Type: "int", Value: "1"}}},
+		{Kind: reflect.Int, Type: "int", Value: "2"},
+	}}
+	const tgtm = `[{"key":{"X":1},"value":2}]`
+	out, err = m.JSONValue("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(out) != tgtm {
+		t.Errorf("expected: %s\ngot:      %s", tgtm, out)
+	}
+}
@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"reflect"
 	"strconv"
+	"time"
 	"unicode"
 
 	"github.com/go-delve/delve/pkg/proc"
@@ -125,6 +126,9 @@ type Thread struct {
 	Breakpoint *Breakpoint `json:"breakPoint,omitempty"`
 	// Informations requested by the current breakpoint
 	BreakpointInfo *BreakpointInfo `json:"breakPointInfo,omitempty"`
+	// Time at which the thread stopped at the current breakpoint, as
+	// measured by the debugger
+	BreakpointHitTime time.Time `json:"breakPointHitTime"`
 
 	// ReturnValues contains the return values of the function we just stepped out of
 	ReturnValues []Variable
@@ -156,6 +160,13 @@ type Stackframe struct {
 
 	Bottom bool `json:"Bottom,omitempty"` // Bottom is true if this is the bottom frame of the stack
 
+	// Unwinder is the method used to compute the registers of this frame:
+	// "dwarf" for frames computed using the call frame information of the
+	// executable, "fp" for frames computed by following the frame pointer and
+	// the empty string for the topmost frame (or frames read from the runtime
+	// after a stack switch).
+	Unwinder string `json:"unwinder,omitempty"`
+
 	Err string
 }
 
@@ -233,8 +244,21 @@ const (
 	// the variable is the return value of a function call and allocated on a
 	// frame that no longer exists)
 	VariableFakeAddress
+
+	// VariableCPtr means the variable is a C pointer
+	VariableCPtr
+
+	// VariableClosure means the variable was captured by the closure the
+	// current function belongs to
+	VariableClosure
 )
 
+// VariablePrettyPrinted means the value and children of the variable were
+// replaced by the output of a user defined pretty printer: Value contains a
+// summary of the variable and Children the children returned by the pretty
+// printer. This flag is only set by clients.
+const VariablePrettyPrinted VariableFlags = 1 << 15
+
 // Variable describes a variable.
 type Variable struct {
 	// Name of the variable or struct member
@@ -426,6 +450,66 @@ type AsmInstruction struct {
 	Breakpoint bool
 	// In AtPC is true this is the instruction the current thread is stopped at
 	AtPC bool
+	// InlinedCalls is the list of inlined calls containing this instruction,
+	// from the outermost to the innermost. Only set if the source of the
+	// instructions was requested.
+	InlinedCalls []InlinedCall
+	// Source is the text of the source line of this instruction. Only set
+	// if the source of the instructions was requested.
+	Source string
+}
+
+// BasicBlock is a basic block of the control flow graph of a function.
+type BasicBlock struct {
+	// Insts are the instructions of the block.
+	Insts AsmInstructions
+	// Succs are the edges leaving the block.
+	Succs []BasicBlockEdge
+}
+
+// BasicBlockEdge is an edge of the control flow graph of a function.
+type BasicBlockEdge struct {
+	// To is the index of the destination block.
+	To int
+	// Kind is "fallthrough", "jump" or "branch" (a taken conditional jump).
+	Kind string
+}
+
+// TracedInstruction is an instruction executed while tracing the
+// instructions of a thread.
+type TracedInstruction struct {
+	Inst AsmInstruction
+	// Registers are the general purpose registers changed by the
+	// instruction, other than the program counter, with their new value.
+	Registers []Register
+	// MemoryWrites are the memory locations written by the instruction.
+	MemoryWrites []MemoryWrite
+}
+
+// MemoryWrite is a memory location written by an instruction and its new
+// contents.
+type MemoryWrite struct {
+	Addr uint64
+	Data []byte
+}
+
+// CoverLine is a statement line of a coverage recording.
+type CoverLine struct {
+	// Package is the import path of the package containing the line.
+	Package string
+	File    string
+	Line    int
+	// Covered is true if the line was executed.
+	Covered bool
+}
+
+// InlinedCall is an inlined call containing an instruction.
+type InlinedCall struct {
+	// Function is the inlined function.
+	Function *Function
+	// File and Line are the position of the call site.
+	File string
+	Line int
 }
 
 // AsmInstructions is a slice of single instructions.
@@ -509,6 +593,118 @@ type Ancestor struct {
 	Unreadable string
 }
 
+// Timer represents a pending timer of the target's runtime.
+type Timer struct {
+	// Addr is the address of the runtime.timer struct
+	Addr uint64 `json:"addr"`
+	// When is the value of the target's monotonic clock (runtime.nanotime) at which the timer fires
+	When int64 `json:"when"`
+	// Period is the period of periodic timers, 0 for one-shot timers
+	Period int64 `json:"period"`
+	// Func is the location of the function called when the timer fires
+	Func Location `json:"func"`
+	// GoroutineID is the ID of the goroutine waiting on the timer, 0 if there isn't one
+	GoroutineID int `json:"goroutineID"`
+
+	Unreadable string `json:"unreadable"`
+}
+
+// PollDesc represents a file descriptor registered with the network poller
+// of the target's runtime.
+type PollDesc struct {
+	// Addr is the address of the runtime.pollDesc struct
+	Addr uint64 `json:"addr"`
+	// Fd is the file descriptor
+	Fd int `json:"fd"`
+	// Closing is true if the file descriptor is being closed
+	Closing bool `json:"closing"`
+	// ReadGoroutineID is the ID of the goroutine waiting for the file descriptor to become readable
+	ReadGoroutineID int `json:"readGoroutineID"`
+	// WriteGoroutineID is the ID of the goroutine waiting for the file descriptor to become writable
+	WriteGoroutineID int `json:"writeGoroutineID"`
+	// ReadDeadline is the value of the target's monotonic clock at which pending reads time out
+	ReadDeadline int64 `json:"readDeadline"`
+	// WriteDeadline is the value of the target's monotonic clock at which pending writes time out
+	WriteDeadline int64 `json:"writeDeadline"`
+	// Description describes the file descriptor, for sockets it contains the local and remote endpoints
+	Description string `json:"description,omitempty"`
+
+	Unreadable string `json:"unreadable"`
+}
+
+// VariableDiff is a difference between two values found by Diff.
+type VariableDiff struct {
+	// Path is the path of the differing values, relative to the values
+	// being compared, for example ".Field[2]", or the empty string if the
+	// values themselves differ.
+	Path string `json:"path"`
+	// X and Y are the differing values, one of them is nil if the value
+	// only exists on one side (for example a map key missing from one of the
+	// maps).
+	X *Variable `json:"x,omitempty"`
+	Y *Variable `json:"y,omitempty"`
+	// Reason describes the difference when it isn't just a different value,
+	// for example "different types" or "different lengths".
+	Reason string `json:"reason,omitempty"`
+}
+
+// MemoryRegion is a memory mapping of the target process.
+type MemoryRegion struct {
+	Addr  uint64 `json:"addr"`
+	Size  uint64 `json:"size"`
+	Read  bool   `json:"read"`
+	Write bool   `json:"write"`
+	Exec  bool   `json:"exec"`
+	// Filename is the name of the mapped file, empty for anonymous mappings
+	Filename string `json:"filename,omitempty"`
+	// Offset is the offset of the mapping in Filename
+	Offset uint64 `json:"offset"`
+
+	// Heap is true if the region contains spans of the Go heap
+	Heap bool `json:"heap"`
+	// Text is true if the region contains executable code of the target
+	Text bool `json:"text"`
+	// ModuleData is true if the region contains the data or bss sections of a Go module
+	ModuleData bool `json:"moduleData"`
+	// Goroutines lists the IDs of the goroutines whose stack is inside the region
+	Goroutines []int `json:"goroutines,omitempty"`
+}
+
+// Contains returns true if addr is inside the region.
+func (r *MemoryRegion) Contains(addr uint64) bool {
+	return addr >= r.Addr && addr < r.Addr+r.Size
+}
+
+// MemorySearchScope selects the memory searched by SearchMemory.
+type MemorySearchScope uint8
+
+const (
+	// MemorySearchAll searches all readable memory mappings of the target.
+	MemorySearchAll MemorySearchScope = iota
+	// MemorySearchRange searches the addresses between Start (included) and
+	// End (excluded).
+	MemorySearchRange
+	// MemorySearchMapping searches the memory mapping containing Start.
+	MemorySearchMapping
+	// MemorySearchHeap searches the memory used by the Go heap.
+	MemorySearchHeap
+)
+
+// MemorySearchResult is an occurrence of the pattern searched by
+// SearchMemory.
+type MemorySearchResult struct {
+	// Addr is the address where the pattern was found
+	Addr uint64 `json:"addr"`
+	// Heap is true if Addr is inside an object allocated on the Go heap,
+	// Base and Size are the address and size of the object
+	Heap bool `json:"heap,omitempty"`
+	// Symbol is the name of the function or package variable containing
+	// Addr, Base and Size are its address and size
+	Symbol string `json:"symbol,omitempty"`
+	Base   uint64 `json:"base,omitempty"`
+	Size   uint64 `json:"size,omitempty"`
+}
+
 // StacktraceOptions is the type of the Opts field of StacktraceIn that
 // configures the stacktrace.
 // Tracks proc.StacktraceOptions
@@ -526,6 +722,10 @@ const (
 	// StacktraceG requests a stacktrace starting with the register
 	// values saved in the runtime.g structure.
 	StacktraceG
+
+	// StacktraceFramePointer requests a stacktrace that follows the chain of
+	// frame pointers, ignoring the call frame information of the executable.
+	StacktraceFramePointer
 )
 
 // ImportPathToDirectoryPath maps an import path to a directory path.
@@ -87,6 +87,11 @@ type Client interface {
 	ListPackageVariables(filter string, cfg api.LoadConfig) ([]api.Variable, error)
 	// EvalVariable returns a variable in the context of the current thread.
 	EvalVariable(scope api.EvalScope, symbol string, cfg api.LoadConfig) (*api.Variable, error)
+	// EvalJSON evaluates expr and returns its value encoded as JSON.
+	EvalJSON(scope api.EvalScope, expr string, cfg api.LoadConfig) ([]byte, error)
+	// Diff evaluates expr1 and expr2 and returns the differences between
+	// their values.
+	Diff(scope api.EvalScope, expr1, expr2 string, cfg api.LoadConfig) ([]api.VariableDiff, error)
 
 	// SetVariable sets the value of a variable
 	SetVariable(scope api.EvalScope, symbol, value string) error
@@ -109,6 +114,12 @@ type Client interface {
 	// ListGoroutines lists all goroutines.
 	ListGoroutines(start, count int) ([]*api.Goroutine, int, error)
 
+	// ListTimers lists the pending timers of the target process and the
+	// current value of its runtime.nanotime clock, 0 if not known.
+	ListTimers() ([]api.Timer, int64, error)
+	// ListPollDescs lists the file descriptors registered with the network poller.
+	ListPollDescs() ([]api.PollDesc, error)
+
 	// Returns stacktrace
 	Stacktrace(goroutineID int, depth int, opts api.StacktraceOptions, cfg *api.LoadConfig) ([]api.Stackframe, error)
 
@@ -136,6 +147,22 @@ type Client interface {
 	DisassembleRange(scope api.EvalScope, startPC, endPC uint64, flavour api.AssemblyFlavour) (api.AsmInstructions, error)
 	// Disassemble code of the function containing PC
 	DisassemblePC(scope api.EvalScope, pc uint64, flavour api.AssemblyFlavour) (api.AsmInstructions, error)
+	// DisassembleSource disassembles code between startPC and endPC, or the
+	// function containing startPC if endPC is 0, reporting the source line
+	// and inlined calls of each instruction
+	DisassembleSource(scope api.EvalScope, startPC, endPC uint64, flavour api.AssemblyFlavour, substitutePathRules [][2]string) (api.AsmInstructions, error)
+	// ControlFlowGraph splits the function containing pc into basic blocks.
+	ControlFlowGraph(scope api.EvalScope, pc uint64, flavour api.AssemblyFlavour, substitutePathRules [][2]string) ([]api.BasicBlock, error)
+
+	// TraceInstructions single-steps the selected thread until it reaches
+	// one of stopPCs, returning the executed instructions.
+	TraceInstructions(stopPCs []uint64, maxInsts int, regs, mem bool, flavour api.AssemblyFlavour) ([]api.TracedInstruction, bool, error)
+
+	// StartCoverage starts recording which statement lines of packages are
+	// executed.
+	StartCoverage(packages []string) (int, error)
+	// Coverage returns the statement lines recorded since StartCoverage.
+	Coverage() ([]api.CoverLine, error)
 
 	// Recorded returns true if the target is a recording.
 	Recorded() bool
@@ -162,6 +189,17 @@ type Client interface {
 	// This function will return an error if it reads less than `length` bytes.
 	ExamineMemory(address uint64, length int) ([]byte, bool, error)
 
+	// ListMemoryRegions lists the memory mappings of the target process. If
+	// classify is true each mapping is also classified using the state of
+	// the Go runtime.
+	ListMemoryRegions(classify bool) ([]api.MemoryRegion, error)
+
+	// SearchMemory searches the memory of the target process, selected by
+	// scope, for pattern and returns at most max results (all results if max
+	// is 0). Start and end are used by the MemorySearchRange and
+	// MemorySearchMapping scopes.
+	SearchMemory(scope api.MemorySearchScope, start, end uint64, pattern []byte, max int) ([]api.MemorySearchResult, error)
+
 	// StopRecording stops a recording if one is in progress.
 	StopRecording() error
 
@@ -42,4 +42,8 @@ type Config struct {
 
 	// DisconnectChan will be closed by the server when the client disconnects
 	DisconnectChan chan<- struct{}
+
+	// PrettyPrinterScript is the path of a starlark script registering
+	// pretty printers, used by the DAP server.
+	PrettyPrinterScript string
 }
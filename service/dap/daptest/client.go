@@ -552,8 +552,13 @@ func (c *Client) ReadMemoryRequest() {
 }
 
 // DisassembleRequest sends a 'disassemble' request.
-func (c *Client) DisassembleRequest() {
-	c.send(&dap.DisassembleRequest{Request: *c.newRequest("disassemble")})
+func (c *Client) DisassembleRequest(memoryReference string, instructionOffset, instructionCount int, resolveSymbols bool) {
+	request := &dap.DisassembleRequest{Request: *c.newRequest("disassemble")}
+	request.Arguments.MemoryReference = memoryReference
+	request.Arguments.InstructionOffset = instructionOffset
+	request.Arguments.InstructionCount = instructionCount
+	request.Arguments.ResolveSymbols = resolveSymbols
+	c.send(request)
 }
 
 // CancelRequest sends a 'cancel' request.
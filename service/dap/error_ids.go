@@ -20,5 +20,6 @@ const (
 	UnableToListGlobals        = 2007
 	UnableToLookupVariable     = 2008
 	UnableToEvaluateExpression = 2009
+	UnableToDisassemble        = 2010
 	// Add more codes as we support more requests
 )
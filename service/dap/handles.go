@@ -1,6 +1,9 @@
 package dap
 
-import "github.com/go-delve/delve/pkg/proc"
+import (
+	"github.com/go-delve/delve/pkg/proc"
+	"github.com/go-delve/delve/service/api"
+)
 
 const startHandle = 1000
 
@@ -52,7 +55,24 @@ func (hs *variablesHandlesMap) get(handle int) (*proc.Variable, bool) {
 	if !ok {
 		return nil, false
 	}
-	return v.(*proc.Variable), true
+	pv, ok := v.(*proc.Variable)
+	return pv, ok
+}
+
+// createPretty creates a handle for a variable produced by a pretty printer.
+func (hs *variablesHandlesMap) createPretty(value *api.Variable) int {
+	return hs.m.create(value)
+}
+
+// getPretty returns the variable produced by a pretty printer associated
+// with handle.
+func (hs *variablesHandlesMap) getPretty(handle int) (*api.Variable, bool) {
+	v, ok := hs.m.get(handle)
+	if !ok {
+		return nil, false
+	}
+	av, ok := v.(*api.Variable)
+	return av, ok
 }
 
 func (hs *variablesHandlesMap) reset() {
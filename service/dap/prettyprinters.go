@@ -0,0 +1,98 @@
+package dap
+
+import (
+	"errors"
+
+	"github.com/go-delve/delve/pkg/proc"
+	"github.com/go-delve/delve/pkg/terminal/starbind"
+	"github.com/go-delve/delve/service"
+	"github.com/go-delve/delve/service/api"
+	"github.com/google/go-dap"
+)
+
+// prettyPrinterContext is the context used to run the starlark script
+// registering pretty printers. The DAP server doesn't have a client
+// connection, pretty printers only have access to the values already
+// loaded for the variables they receive and the builtins calling API
+// functions are not defined.
+type prettyPrinterContext struct{}
+
+func (prettyPrinterContext) Client() service.Client { return nil }
+
+func (prettyPrinterContext) RegisterCommand(name, helpMsg string, cmdfn func(args string) error) {}
+
+func (prettyPrinterContext) CallCommand(cmdstr string) error {
+	return errors.New("commands can not be called by pretty printers in DAP mode")
+}
+
+func (prettyPrinterContext) Scope() api.EvalScope {
+	return api.EvalScope{GoroutineID: -1}
+}
+
+func (prettyPrinterContext) LoadConfig() api.LoadConfig {
+	return api.LoadConfig{FollowPointers: true, MaxVariableRecurse: 1, MaxStringLen: 64, MaxArrayValues: 64, MaxStructFields: -1}
+}
+
+// loadPrettyPrinters executes the pretty printers script specified in the
+// configuration of the server.
+func (s *Server) loadPrettyPrinters() {
+	if s.config.PrettyPrinterScript == "" {
+		return
+	}
+	env := starbind.New(prettyPrinterContext{})
+	if _, err := env.Execute(s.config.PrettyPrinterScript, nil, "main", nil); err != nil {
+		s.log.Errorf("could not load pretty printers: %v", err)
+		return
+	}
+	s.prettyPrinters = env
+}
+
+// prettyPrint returns the result of the pretty printer registered for the
+// type of v, or nil if there is no such pretty printer.
+func (s *Server) prettyPrint(v *proc.Variable) *api.Variable {
+	if !s.prettyPrinters.HasPrettyPrinter(api.PrettyTypeName(v.DwarfType)) {
+		return nil
+	}
+	r := api.ConvertVar(v)
+	if err := s.prettyPrinters.PrettyPrint(r); err != nil {
+		s.log.Error(err)
+	}
+	if r.Flags&api.VariablePrettyPrinted == 0 {
+		return nil
+	}
+	return r
+}
+
+// convertPrettyPrinted converts a variable produced by a pretty printer
+// (or one of its children) to a dap.Variable value and reference.
+func (s *Server) convertPrettyPrinted(v *api.Variable, skipRef bool) (value string, variablesReference int) {
+	if v.Flags&api.VariablePrettyPrinted != 0 {
+		value = v.Value
+	} else {
+		value = v.SinglelineString()
+	}
+	if len(v.Children) > 0 && !skipRef {
+		variablesReference = s.variableHandles.createPretty(v)
+	}
+	return value, variablesReference
+}
+
+// onPrettyPrintedVariablesRequest handles 'variables' requests for the
+// children of a variable produced by a pretty printer.
+func (s *Server) onPrettyPrintedVariablesRequest(request *dap.VariablesRequest, v *api.Variable) {
+	children := make([]dap.Variable, len(v.Children))
+	for i := range v.Children {
+		c := &v.Children[i]
+		value, variablesReference := s.convertPrettyPrinted(c, false)
+		children[i] = dap.Variable{
+			Name:               c.Name,
+			Value:              value,
+			VariablesReference: variablesReference,
+		}
+	}
+	response := &dap.VariablesResponse{
+		Response: *newResponse(request.Request),
+		Body:     dap.VariablesResponseBody{Variables: children},
+	}
+	s.send(response)
+}
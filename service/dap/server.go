@@ -19,11 +19,14 @@ import (
 	"path/filepath"
 	"reflect"
 	"regexp"
+	"sort"
+	"strconv"
 	"strings"
 
 	"github.com/go-delve/delve/pkg/gobuild"
 	"github.com/go-delve/delve/pkg/logflags"
 	"github.com/go-delve/delve/pkg/proc"
+	"github.com/go-delve/delve/pkg/terminal/starbind"
 	"github.com/go-delve/delve/service"
 	"github.com/go-delve/delve/service/api"
 	"github.com/go-delve/delve/service/debugger"
@@ -67,6 +70,9 @@ type Server struct {
 	variableHandles *variablesHandlesMap
 	// args tracks special settings for handling debug session requests.
 	args launchAttachArgs
+	// prettyPrinters contains the pretty printers registered by the script
+	// specified in config, nil if there is no such script.
+	prettyPrinters *starbind.Env
 }
 
 // launchAttachArgs captures arguments from launch/attach request that
@@ -95,7 +101,7 @@ func NewServer(config *service.Config) *Server {
 	logger := logflags.DAPLogger()
 	logflags.WriteDAPListeningMessage(config.Listener.Addr().String())
 	logger.Debug("DAP server pid = ", os.Getpid())
-	return &Server{
+	s := &Server{
 		config:            config,
 		listener:          config.Listener,
 		stopChan:          make(chan struct{}),
@@ -104,6 +110,8 @@ func NewServer(config *service.Config) *Server {
 		variableHandles:   newVariablesHandlesMap(),
 		args:              defaultArgs,
 	}
+	s.loadPrettyPrinters()
+	return s
 }
 
 // Stop stops the DAP debugger service, closes the listener and the client
@@ -351,7 +359,6 @@ func (s *Server) handleRequest(request dap.Message) {
 		s.onReadMemoryRequest(request)
 	case *dap.DisassembleRequest:
 		// Optional (capability ‘supportsDisassembleRequest’)
-		// TODO: implement this request in V1
 		s.onDisassembleRequest(request)
 	case *dap.CancelRequest:
 		// Optional (capability ‘supportsCancelRequest’)
@@ -383,6 +390,7 @@ func (s *Server) onInitializeRequest(request *dap.InitializeRequest) {
 	response := &dap.InitializeResponse{Response: *newResponse(request.Request)}
 	response.Body.SupportsConfigurationDoneRequest = true
 	response.Body.SupportsConditionalBreakpoints = true
+	response.Body.SupportsValueFormattingOptions = true
 	// TODO(polina): support this to match vscode-go functionality
 	response.Body.SupportsSetVariable = false
 	// TODO(polina): support these requests in addition to vscode-go feature parity
@@ -393,7 +401,7 @@ func (s *Server) onInitializeRequest(request *dap.InitializeRequest) {
 	response.Body.SupportsSetExpression = false
 	response.Body.SupportsLoadedSourcesRequest = false
 	response.Body.SupportsReadMemoryRequest = false
-	response.Body.SupportsDisassembleRequest = false
+	response.Body.SupportsDisassembleRequest = true
 	response.Body.SupportsCancelRequest = false
 	s.send(response)
 }
@@ -726,7 +734,7 @@ func (s *Server) onStackTraceRequest(request *dap.StackTraceRequest) {
 	for i, frame := range frames {
 		loc := &frame.Call
 		uniqueStackFrameID := s.stackFrameHandles.create(stackFrame{goroutineID, i})
-		stackFrames[i] = dap.StackFrame{Id: uniqueStackFrameID, Line: loc.Line}
+		stackFrames[i] = dap.StackFrame{Id: uniqueStackFrameID, Line: loc.Line, InstructionPointerReference: fmt.Sprintf("%#x", loc.PC)}
 		if loc.Fn == nil {
 			stackFrames[i].Name = "???"
 		} else {
@@ -778,6 +786,15 @@ func (s *Server) onScopesRequest(request *dap.ScopesRequest) {
 		s.sendErrorResponse(request.Request, UnableToListLocals, "Unable to list locals", err.Error())
 		return
 	}
+	// Variables captured by a closure are shown in their own scope
+	var captured []*proc.Variable
+	for i := 0; i < len(locals); i++ {
+		if locals[i].Flags&proc.VariableClosure != 0 {
+			captured = append(captured, locals[i])
+			locals = append(locals[:i], locals[i+1:]...)
+			i--
+		}
+	}
 	locScope := &proc.Variable{Name: "Locals", Children: slicePtrVarToSliceVar(locals)}
 
 	// TODO(polina): Annotate shadowed variables
@@ -786,6 +803,12 @@ func (s *Server) onScopesRequest(request *dap.ScopesRequest) {
 	scopeLocals := dap.Scope{Name: locScope.Name, VariablesReference: s.variableHandles.create(locScope)}
 	scopes := []dap.Scope{scopeArgs, scopeLocals}
 
+	if len(captured) > 0 {
+		closureScope := &proc.Variable{Name: "Closure", Children: slicePtrVarToSliceVar(captured)}
+		scopeClosure := dap.Scope{Name: closureScope.Name, VariablesReference: s.variableHandles.create(closureScope)}
+		scopes = append(scopes, scopeClosure)
+	}
+
 	if s.args.showGlobalVariables {
 		// Limit what global variables we will return to the current package only.
 		// TODO(polina): This is how vscode-go currently does it to make
@@ -838,11 +861,16 @@ func slicePtrVarToSliceVar(vars []*proc.Variable) []proc.Variable {
 // onVariablesRequest handles 'variables' requests.
 // This is a mandatory request to support.
 func (s *Server) onVariablesRequest(request *dap.VariablesRequest) {
+	if pv, ok := s.variableHandles.getPretty(request.Arguments.VariablesReference); ok {
+		s.onPrettyPrintedVariablesRequest(request, pv)
+		return
+	}
 	v, ok := s.variableHandles.get(request.Arguments.VariablesReference)
 	if !ok {
 		s.sendErrorResponse(request.Request, UnableToLookupVariable, "Unable to lookup variable", fmt.Sprintf("unknown reference %d", request.Arguments.VariablesReference))
 		return
 	}
+	fmtstr := valueFormat(request.Arguments.Format)
 	children := make([]dap.Variable, 0)
 	// TODO(polina): check and handle if variable loaded incompletely
 	// https://github.com/go-delve/delve/blob/master/Documentation/api/ClientHowto.md#looking-into-variables
@@ -853,8 +881,8 @@ func (s *Server) onVariablesRequest(request *dap.VariablesRequest) {
 			// A map will have twice as many children as there are key-value elements.
 			kvIndex := i / 2
 			// Process children in pairs: even indices are map keys, odd indices are values.
-			key, keyref := s.convertVariable(&v.Children[i])
-			val, valref := s.convertVariable(&v.Children[i+1])
+			key, keyref := s.convertVariableFormatted(&v.Children[i], fmtstr)
+			val, valref := s.convertVariableFormatted(&v.Children[i+1], fmtstr)
 			// If key or value or both are scalars, we can use
 			// a single variable to represet key:value format.
 			// Otherwise, we must return separate variables for both.
@@ -888,7 +916,7 @@ func (s *Server) onVariablesRequest(request *dap.VariablesRequest) {
 		children = make([]dap.Variable, len(v.Children))
 		for i := range v.Children {
 			c := &v.Children[i]
-			value, varref := s.convertVariable(c)
+			value, varref := s.convertVariableFormatted(c, fmtstr)
 			children[i] = dap.Variable{
 				Name:               fmt.Sprintf("[%d]", i),
 				Value:              value,
@@ -899,7 +927,7 @@ func (s *Server) onVariablesRequest(request *dap.VariablesRequest) {
 		children = make([]dap.Variable, len(v.Children))
 		for i := range v.Children {
 			c := &v.Children[i]
-			value, variablesReference := s.convertVariable(c)
+			value, variablesReference := s.convertVariableFormatted(c, fmtstr)
 			children[i] = dap.Variable{
 				Name:               c.Name,
 				Value:              value,
@@ -924,17 +952,33 @@ func (s *Server) onVariablesRequest(request *dap.VariablesRequest) {
 // custom, a zero reference, reminiscent of a zero pointer, is used to indicate that
 // a scalar variable cannot be "dereferenced" to get its elements (as there are none).
 func (s *Server) convertVariable(v *proc.Variable) (value string, variablesReference int) {
-	return s.convertVariableWithOpts(v, false)
+	return s.convertVariableWithOpts(v, false, "")
+}
+
+// convertVariableFormatted is like convertVariable but formats numeric
+// values using fmtstr, see api.FormatValue.
+func (s *Server) convertVariableFormatted(v *proc.Variable, fmtstr string) (value string, variablesReference int) {
+	return s.convertVariableWithOpts(v, false, fmtstr)
 }
 
 func (s *Server) convertVariableToString(v *proc.Variable) string {
-	val, _ := s.convertVariableWithOpts(v, true)
+	val, _ := s.convertVariableWithOpts(v, true, "")
 	return val
 }
 
+// valueFormat returns the format string corresponding to the value
+// formatting options requested by the client.
+func valueFormat(format dap.ValueFormat) string {
+	if format.Hex {
+		return "%#x"
+	}
+	return ""
+}
+
 // convertVarialbeWithOpts allows to skip reference generation in case all we need is
 // a string representation of the variable.
-func (s *Server) convertVariableWithOpts(v *proc.Variable, skipRef bool) (value string, variablesReference int) {
+// If fmtstr isn't empty numeric values are formatted using it.
+func (s *Server) convertVariableWithOpts(v *proc.Variable, skipRef bool, fmtstr string) (value string, variablesReference int) {
 	maybeCreateVariableHandle := func(v *proc.Variable) int {
 		if skipRef {
 			return 0
@@ -945,6 +989,11 @@ func (s *Server) convertVariableWithOpts(v *proc.Variable, skipRef bool) (value
 		value = fmt.Sprintf("unreadable <%v>", v.Unreadable)
 		return
 	}
+	if s.prettyPrinters != nil {
+		if pv := s.prettyPrint(v); pv != nil {
+			return s.convertPrettyPrinted(pv, skipRef)
+		}
+	}
 	typeName := api.PrettyTypeName(v.DwarfType)
 	switch v.Kind {
 	case reflect.UnsafePointer:
@@ -1047,6 +1096,9 @@ func (s *Server) convertVariableWithOpts(v *proc.Variable, skipRef bool) (value
 		vvalue := api.VariableValueAsString(v)
 		if vvalue != "" {
 			value = vvalue
+			if fmtstr != "" && v.Kind != reflect.Bool {
+				value = api.FormatValue(v.Kind, vvalue, fmtstr)
+			}
 		} else {
 			value = "<" + typeName + ">"
 		}
@@ -1170,7 +1222,7 @@ func (s *Server) onEvaluateRequest(request *dap.EvaluateRequest) {
 			s.sendErrorResponseWithOpts(request.Request, UnableToEvaluateExpression, "Unable to evaluate expression", err.Error(), showErrorToUser)
 			return
 		}
-		exprVal, exprRef := s.convertVariable(exprVar)
+		exprVal, exprRef := s.convertVariableFormatted(exprVar, valueFormat(request.Arguments.Format))
 		response.Body = dap.EvaluateResponseBody{Result: exprVal, VariablesReference: exprRef}
 	}
 	s.send(response)
@@ -1230,10 +1282,76 @@ func (s *Server) onReadMemoryRequest(request *dap.ReadMemoryRequest) {
 	s.sendNotYetImplementedErrorResponse(request.Request)
 }
 
-// onDisassembleRequest sends a not-yet-implemented error response.
-// Capability 'supportsDisassembleRequest' is not set 'initialize' response.
+// onDisassembleRequest handles 'disassemble' requests.
+// Capability 'supportsDisassembleRequest' is set in 'initialize' response.
+// Instructions are read from the function containing the instruction at
+// memoryReference+offset, instructions requested before the start or after
+// the end of the function are returned as invalid instructions.
 func (s *Server) onDisassembleRequest(request *dap.DisassembleRequest) {
-	s.sendNotYetImplementedErrorResponse(request.Request)
+	addr, err := strconv.ParseUint(request.Arguments.MemoryReference, 0, 64)
+	if err != nil {
+		s.sendErrorResponse(request.Request, UnableToDisassemble, "Unable to disassemble", fmt.Sprintf("invalid memory reference %q", request.Arguments.MemoryReference))
+		return
+	}
+	addr += uint64(int64(request.Arguments.Offset))
+	insts, err := s.debugger.Disassemble(-1, addr, 0)
+	if err != nil {
+		s.sendErrorResponse(request.Request, UnableToDisassemble, "Unable to disassemble", err.Error())
+		return
+	}
+	if len(insts) == 0 {
+		s.sendErrorResponse(request.Request, UnableToDisassemble, "Unable to disassemble", fmt.Sprintf("no instructions at %#x", addr))
+		return
+	}
+
+	start := sort.Search(len(insts), func(i int) bool { return insts[i].Loc.PC+uint64(insts[i].Size) > addr }) + request.Arguments.InstructionOffset
+	end := start + request.Arguments.InstructionCount
+	apiInsts := make(api.AsmInstructions, 0, request.Arguments.InstructionCount)
+	for i := max(start, 0); i < min(end, len(insts)); i++ {
+		apiInsts = append(apiInsts, api.ConvertAsmInstruction(insts[i], s.debugger.AsmInstructionText(&insts[i], proc.IntelFlavour)))
+	}
+	if request.Arguments.ResolveSymbols {
+		s.debugger.AsmInstructionsSource(apiInsts, nil)
+	}
+
+	instructions := make([]dap.DisassembledInstruction, 0, request.Arguments.InstructionCount)
+	invalid := func(pc uint64) dap.DisassembledInstruction {
+		return dap.DisassembledInstruction{Address: fmt.Sprintf("%#x", pc), Instruction: "(bad)"}
+	}
+	for i := start; i < 0 && i < end; i++ {
+		instructions = append(instructions, invalid(insts[0].Loc.PC))
+	}
+	lastFile := ""
+	for _, inst := range apiInsts {
+		instruction := dap.DisassembledInstruction{
+			Address:          fmt.Sprintf("%#x", inst.Loc.PC),
+			InstructionBytes: fmt.Sprintf("%x", inst.Bytes),
+			Instruction:      inst.Text,
+			Line:             inst.Loc.Line,
+		}
+		if inst.Loc.File != lastFile && inst.Loc.File != "<autogenerated>" {
+			instruction.Location = dap.Source{Name: filepath.Base(inst.Loc.File), Path: inst.Loc.File}
+			lastFile = inst.Loc.File
+		}
+		if request.Arguments.ResolveSymbols {
+			if n := len(inst.InlinedCalls); n > 0 {
+				instruction.Symbol = inst.InlinedCalls[n-1].Function.Name()
+			} else {
+				instruction.Symbol = inst.Loc.Function.Name()
+			}
+		}
+		instructions = append(instructions, instruction)
+	}
+	last := insts[len(insts)-1]
+	for len(instructions) < request.Arguments.InstructionCount {
+		instructions = append(instructions, invalid(last.Loc.PC+uint64(last.Size)))
+	}
+
+	response := &dap.DisassembleResponse{
+		Response: *newResponse(request.Request),
+		Body:     dap.DisassembleResponseBody{Instructions: instructions},
+	}
+	s.send(response)
 }
 
 // onCancelRequest sends a not-yet-implemented error response.
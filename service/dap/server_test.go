@@ -1772,6 +1772,56 @@ type onBreakpoint struct {
 	disconnect bool
 }
 
+func TestDisassembleRequest(t *testing.T) {
+	runTest(t, "increment", func(client *daptest.Client, fixture protest.Fixture) {
+		runDebugSessionWithBPs(t, client,
+			// Launch
+			func() {
+				client.LaunchRequest("exec", fixture.Path, !stopOnEntry)
+			},
+			// Set breakpoints
+			fixture.Source, []int{8},
+			[]onBreakpoint{{
+				// Stop at line 8
+				execute: func() {
+					client.StackTraceRequest(1, 0, 1)
+					stResp := client.ExpectStackTraceResponse(t)
+					if len(stResp.Body.StackFrames) != 1 {
+						t.Fatalf("got %#v, want 1 stack frame", stResp)
+					}
+					pc := stResp.Body.StackFrames[0].InstructionPointerReference
+					if pc == "" {
+						t.Fatalf("got %#v, want InstructionPointerReference", stResp.Body.StackFrames[0])
+					}
+
+					client.DisassembleRequest(pc, -2, 5, true)
+					dResp := client.ExpectDisassembleResponse(t)
+					insts := dResp.Body.Instructions
+					if len(insts) != 5 {
+						t.Fatalf("got %d instructions, want 5: %#v", len(insts), insts)
+					}
+					if insts[2].Address != pc {
+						t.Errorf("got address %s, want %s", insts[2].Address, pc)
+					}
+					if insts[2].Line != 8 || insts[2].Symbol != "main.Increment" {
+						t.Errorf("got %#v, want instruction of main.Increment at line 8", insts[2])
+					}
+					if insts[0].Location.Path != fixture.Source {
+						t.Errorf("got location %#v, want %s", insts[0].Location, fixture.Source)
+					}
+
+					// Memory references that are not addresses are rejected.
+					client.DisassembleRequest("main.Increment", 0, 1, false)
+					er := client.ExpectErrorResponse(t)
+					if er.Body.Error.Id != UnableToDisassemble {
+						t.Errorf("got %#v, want Id=%d", er, UnableToDisassemble)
+					}
+				},
+				disconnect: false,
+			}})
+	})
+}
+
 // runDebugSessionWithBPs is a helper for executing the common init and shutdown
 // sequences for a program that does not stop on entry
 // while specifying breakpoints and unique launch criteria via parameters.
@@ -1994,9 +2044,6 @@ func TestOptionalNotYetImplementedResponses(t *testing.T) {
 		client.ReadMemoryRequest()
 		expectNotYetImplemented("readMemory")
 
-		client.DisassembleRequest()
-		expectNotYetImplemented("disassemble")
-
 		client.CancelRequest()
 		expectNotYetImplemented("cancel")
 	})
@@ -6,6 +6,7 @@ import (
 	"errors"
 	"fmt"
 	"go/parser"
+	"io/ioutil"
 	"os"
 	"path/filepath"
 	"regexp"
@@ -1261,8 +1262,32 @@ func (d *Debugger) EvalVariableInScope(goid, frame, deferredCall int, symbol str
 	return s.EvalVariable(symbol, cfg)
 }
 
+// DiffVariablesInScope evaluates expr1 and expr2 in the given scope and
+// returns the differences between their values.
+func (d *Debugger) DiffVariablesInScope(goid, frame, deferredCall int, expr1, expr2 string, cfg proc.LoadConfig) ([]proc.VariableDiff, error) {
+	d.targetMutex.Lock()
+	defer d.targetMutex.Unlock()
+
+	s, err := proc.ConvertEvalScope(d.target, goid, frame, deferredCall)
+	if err != nil {
+		return nil, err
+	}
+	x, err := s.EvalVariable(expr1, cfg)
+	if err != nil {
+		return nil, err
+	}
+	y, err := s.EvalVariable(expr2, cfg)
+	if err != nil {
+		return nil, err
+	}
+	return proc.DiffVariables(x, y), nil
+}
+
 // SetVariableInScope will set the value of the variable represented by
 // 'symbol' to the value given, in the given scope.
+// If the new value needs memory to be allocated in the target process
+// (literal strings, slice literals, new map elements) a function call will
+// be injected to allocate it.
 func (d *Debugger) SetVariableInScope(goid, frame, deferredCall int, symbol, value string) error {
 	d.targetMutex.Lock()
 	defer d.targetMutex.Unlock()
@@ -1271,7 +1296,18 @@ func (d *Debugger) SetVariableInScope(goid, frame, deferredCall int, symbol, val
 	if err != nil {
 		return err
 	}
-	return s.SetVariable(symbol, value)
+	err = s.SetVariable(symbol, value)
+	if !proc.IsErrFuncCallNotAllowed(err) {
+		return err
+	}
+
+	d.log.Debugf("set %s = %s with function calls", symbol, value)
+	if err := d.target.ChangeDirection(proc.Forward); err != nil {
+		return err
+	}
+	d.setRunning(true)
+	defer d.setRunning(false)
+	return proc.SetVariableWithCalls(d.target, goid, frame, deferredCall, symbol, value)
 }
 
 // Goroutines will return a list of goroutines in the target process.
@@ -1298,7 +1334,7 @@ func (d *Debugger) Stacktrace(goroutineID, depth int, opts api.StacktraceOptions
 	}
 
 	if g == nil {
-		return proc.ThreadStacktrace(d.target.CurrentThread(), depth)
+		return proc.ThreadStacktraceWithOptions(d.target.CurrentThread(), depth, proc.StacktraceOptions(opts))
 	} else {
 		return g.Stacktrace(depth, proc.StacktraceOptions(opts))
 	}
@@ -1346,6 +1382,37 @@ func (d *Debugger) Ancestors(goroutineID, numAncestors, depth int) ([]api.Ancest
 	return r, nil
 }
 
+// Timers returns the list of pending timers of the target process and the
+// current value of its runtime.nanotime clock, or 0 if the current time
+// is not known.
+func (d *Debugger) Timers() ([]api.Timer, int64, error) {
+	d.targetMutex.Lock()
+	defer d.targetMutex.Unlock()
+
+	timers, err := proc.Timers(d.target)
+	if err != nil {
+		return nil, 0, err
+	}
+	now, err := proc.Nanotime(d.target)
+	if err != nil {
+		now = 0
+	}
+	return api.ConvertTimers(timers), now, nil
+}
+
+// PollDescs returns the file descriptors registered with the network
+// poller of the target process.
+func (d *Debugger) PollDescs() ([]api.PollDesc, error) {
+	d.targetMutex.Lock()
+	defer d.targetMutex.Unlock()
+
+	pds, err := proc.PollDescs(d.target)
+	if err != nil {
+		return nil, err
+	}
+	return api.ConvertPollDescs(pds), nil
+}
+
 // ConvertStacktrace converts a slice of proc.Stackframe into a slice of
 // api.Stackframe, loading local variables and arguments of each frame if
 // cfg is not nil.
@@ -1366,7 +1433,8 @@ func (d *Debugger) convertStacktrace(rawlocs []proc.Stackframe, cfg *proc.LoadCo
 
 			Defers: d.convertDefers(rawlocs[i].Defers),
 
-			Bottom: rawlocs[i].Bottom,
+			Bottom:   rawlocs[i].Bottom,
+			Unwinder: rawlocs[i].Unwinder.String(),
 		}
 		if rawlocs[i].Err != nil {
 			frame.Err = rawlocs[i].Err.Error()
@@ -1504,12 +1572,69 @@ func (d *Debugger) Disassemble(goroutineID int, addr1, addr2 uint64) ([]proc.Asm
 	return proc.Disassemble(d.target.Memory(), regs, d.target.Breakpoints(), d.target.BinInfo(), addr1, addr2)
 }
 
+// TraceInstructions single-steps the thread of the selected goroutine
+// until it reaches one of stopPCs, see proc.TraceInstructions.
+func (d *Debugger) TraceInstructions(stopPCs []uint64, maxInsts int, flags proc.TraceInstructionsFlags) ([]proc.TracedInstruction, bool, error) {
+	d.targetMutex.Lock()
+	defer d.targetMutex.Unlock()
+
+	d.setRunning(true)
+	defer d.setRunning(false)
+
+	if err := d.target.ChangeDirection(proc.Forward); err != nil {
+		return nil, false, err
+	}
+	return proc.TraceInstructions(d.target, stopPCs, maxInsts, flags)
+}
+
+// ControlFlowGraph disassembles the function containing pc and splits it
+// into basic blocks.
+func (d *Debugger) ControlFlowGraph(goroutineID int, pc uint64) ([]proc.BasicBlock, error) {
+	insts, err := d.Disassemble(goroutineID, pc, 0)
+	if err != nil {
+		return nil, err
+	}
+	return proc.ControlFlowGraph(insts), nil
+}
+
 func (d *Debugger) AsmInstructionText(inst *proc.AsmInstruction, flavour proc.AssemblyFlavour) string {
 	d.targetMutex.Lock()
 	defer d.targetMutex.Unlock()
 	return inst.Text(flavour, d.target.BinInfo())
 }
 
+// AsmInstructionsSource sets the InlinedCalls and Source fields of each
+// instruction in insts. Source files are read after applying
+// substitutePathRules to their path, the Source field of instructions
+// belonging to a file that can not be read is left empty.
+func (d *Debugger) AsmInstructionsSource(insts api.AsmInstructions, substitutePathRules [][2]string) {
+	d.targetMutex.Lock()
+	defer d.targetMutex.Unlock()
+	bi := d.target.BinInfo()
+	files := make(map[string][]string)
+	for i := range insts {
+		inst := &insts[i]
+		inst.InlinedCalls = api.ConvertInlinedCalls(bi.InlinedCallSites(inst.Loc.PC))
+		if inst.Loc.File == "" || inst.Loc.Line <= 0 {
+			continue
+		}
+		lines, ok := files[inst.Loc.File]
+		if !ok {
+			path := inst.Loc.File
+			if len(substitutePathRules) > 0 {
+				path = locspec.SubstitutePath(path, substitutePathRules)
+			}
+			if buf, err := ioutil.ReadFile(path); err == nil {
+				lines = strings.Split(string(buf), "\n")
+			}
+			files[inst.Loc.File] = lines
+		}
+		if inst.Loc.Line <= len(lines) {
+			inst.Source = strings.TrimRight(lines[inst.Loc.Line-1], "\r")
+		}
+	}
+}
+
 // Recorded returns true if the target is a recording.
 func (d *Debugger) Recorded() (recorded bool, tracedir string) {
 	d.targetMutex.Lock()
@@ -1560,8 +1685,13 @@ func (d *Debugger) ClearCheckpoint(id int) error {
 func (d *Debugger) ListDynamicLibraries() []*proc.Image {
 	d.targetMutex.Lock()
 	defer d.targetMutex.Unlock()
-	return d.target.BinInfo().Images[1:] // skips the first image because it's the executable file
-
+	r := []*proc.Image{}
+	for _, image := range d.target.BinInfo().Images[1:] { // skips the first image because it's the executable file
+		if !image.IsSplitDwarf() {
+			r = append(r, image)
+		}
+	}
+	return r
 }
 
 // ExamineMemory returns the raw memory stored at the given address.
@@ -1583,6 +1713,68 @@ func (d *Debugger) ExamineMemory(address uint64, length int) ([]byte, error) {
 	return data, nil
 }
 
+// MemoryRegions returns the memory mappings of the target process. If
+// classify is true the mappings are also classified using the state of
+// the Go runtime.
+func (d *Debugger) MemoryRegions(classify bool) ([]api.MemoryRegion, error) {
+	d.targetMutex.Lock()
+	defer d.targetMutex.Unlock()
+
+	regions, err := d.target.MemoryRegions(classify)
+	if err != nil {
+		return nil, err
+	}
+	return api.ConvertMemoryRegions(regions), nil
+}
+
+// SearchMemory searches the memory of the target process, selected by
+// scope, for pattern, returning at most max results (all results if max
+// is 0).
+func (d *Debugger) SearchMemory(scope api.MemorySearchScope, start, end uint64, pattern []byte, max int) ([]api.MemorySearchResult, error) {
+	d.targetMutex.Lock()
+	defer d.targetMutex.Unlock()
+
+	var regions []proc.MemoryMapEntry
+	switch scope {
+	case api.MemorySearchRange:
+		if end <= start {
+			return nil, errors.New("empty range")
+		}
+		regions = []proc.MemoryMapEntry{{Addr: start, Size: end - start, Read: true}}
+	case api.MemorySearchMapping, api.MemorySearchAll:
+		mappings, err := d.target.MemoryMap()
+		if err != nil {
+			if err == proc.ErrMemoryMapNotSupported {
+				return nil, fmt.Errorf("%v, a range of addresses must be specified", err)
+			}
+			return nil, err
+		}
+		for _, m := range mappings {
+			if !m.Read || (scope == api.MemorySearchMapping && !m.Contains(start)) {
+				continue
+			}
+			regions = append(regions, m)
+		}
+		if scope == api.MemorySearchMapping && len(regions) == 0 {
+			return nil, fmt.Errorf("address %#x is not inside a readable memory mapping", start)
+		}
+	case api.MemorySearchHeap:
+		var err error
+		regions, err = d.target.HeapRegions()
+		if err != nil {
+			return nil, err
+		}
+	default:
+		return nil, fmt.Errorf("unknown memory search scope %d", scope)
+	}
+
+	results, err := d.target.SearchMemory(regions, pattern, max)
+	if err != nil {
+		return nil, err
+	}
+	return api.ConvertMemorySearchResults(results), nil
+}
+
 func (d *Debugger) GetVersion(out *api.GetVersionOut) error {
 	if d.config.CoreFile != "" {
 		if d.config.Backend == "rr" {
@@ -1621,6 +1813,51 @@ func (d *Debugger) ListPackagesBuildInfo(includeFiles bool) []*proc.PackageBuild
 	return d.target.BinInfo().ListPackagesBuildInfo(includeFiles)
 }
 
+// StartCoverage sets a one-shot breakpoint on every statement line of the
+// packages matching one of pkgs and returns the number of lines, see
+// proc.(*Target).SetCoverBreakpoints.
+// A pattern ending in "/..." matches a package and all its subpackages.
+func (d *Debugger) StartCoverage(pkgs []string) (int, error) {
+	d.targetMutex.Lock()
+	defer d.targetMutex.Unlock()
+	lines := d.target.BinInfo().StatementLines(func(pkg string) bool {
+		for _, pattern := range pkgs {
+			if matchPackagePattern(pattern, pkg) {
+				return true
+			}
+		}
+		return false
+	})
+	if len(lines) == 0 {
+		return 0, fmt.Errorf("no statement lines found in %s", strings.Join(pkgs, ", "))
+	}
+	if err := d.target.SetCoverBreakpoints(lines); err != nil {
+		return 0, err
+	}
+	return len(lines), nil
+}
+
+func matchPackagePattern(pattern, pkg string) bool {
+	if !strings.HasSuffix(pattern, "...") {
+		return pattern == pkg
+	}
+	prefix := strings.TrimSuffix(pattern, "...")
+	return strings.HasPrefix(pkg, prefix) || (strings.HasSuffix(prefix, "/") && pkg == prefix[:len(prefix)-1])
+}
+
+// Coverage returns the lines recorded since StartCoverage was called and
+// whether each one was executed.
+func (d *Debugger) Coverage() []api.CoverLine {
+	d.targetMutex.Lock()
+	defer d.targetMutex.Unlock()
+	lines, hit := d.target.Coverage()
+	r := make([]api.CoverLine, len(lines))
+	for i := range lines {
+		r[i] = api.CoverLine{Package: lines[i].Package, File: lines[i].File, Line: lines[i].Line, Covered: hit[i]}
+	}
+	return r
+}
+
 // StopRecording stops a recording (if one is in progress)
 func (d *Debugger) StopRecording() error {
 	d.recordMutex.Lock()
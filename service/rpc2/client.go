@@ -279,6 +279,21 @@ func (c *RPCClient) EvalVariable(scope api.EvalScope, expr string, cfg api.LoadC
 	return out.Variable, err
 }
 
+// EvalJSON evaluates expr and returns its value encoded as JSON.
+func (c *RPCClient) EvalJSON(scope api.EvalScope, expr string, cfg api.LoadConfig) ([]byte, error) {
+	var out EvalJSONOut
+	err := c.call("EvalJSON", EvalJSONIn{scope, expr, &cfg}, &out)
+	return out.Value, err
+}
+
+// Diff evaluates expr1 and expr2 and returns the differences between their
+// values.
+func (c *RPCClient) Diff(scope api.EvalScope, expr1, expr2 string, cfg api.LoadConfig) ([]api.VariableDiff, error) {
+	var out DiffOut
+	err := c.call("Diff", DiffIn{scope, expr1, expr2, &cfg}, &out)
+	return out.Diffs, err
+}
+
 func (c *RPCClient) SetVariable(scope api.EvalScope, symbol, value string) error {
 	out := new(SetOut)
 	return c.call("Set", SetIn{scope, symbol, value}, out)
@@ -344,6 +359,20 @@ func (c *RPCClient) Stacktrace(goroutineId, depth int, opts api.StacktraceOption
 	return out.Locations, err
 }
 
+// ListTimers lists the pending timers of the target process.
+func (c *RPCClient) ListTimers() ([]api.Timer, int64, error) {
+	var out ListTimersOut
+	err := c.call("ListTimers", ListTimersIn{}, &out)
+	return out.Timers, out.Now, err
+}
+
+// ListPollDescs lists the file descriptors registered with the network poller.
+func (c *RPCClient) ListPollDescs() ([]api.PollDesc, error) {
+	var out ListPollDescsOut
+	err := c.call("ListPollDescs", ListPollDescsIn{}, &out)
+	return out.PollDescs, err
+}
+
 func (c *RPCClient) Ancestors(goroutineID int, numAncestors int, depth int) ([]api.Ancestor, error) {
 	var out AncestorsOut
 	err := c.call("Ancestors", AncestorsIn{goroutineID, numAncestors, depth}, &out)
@@ -365,17 +394,58 @@ func (c *RPCClient) FindLocation(scope api.EvalScope, loc string, findInstructio
 // Disassemble code between startPC and endPC
 func (c *RPCClient) DisassembleRange(scope api.EvalScope, startPC, endPC uint64, flavour api.AssemblyFlavour) (api.AsmInstructions, error) {
 	var out DisassembleOut
-	err := c.call("Disassemble", DisassembleIn{scope, startPC, endPC, flavour}, &out)
+	err := c.call("Disassemble", DisassembleIn{scope, startPC, endPC, flavour, false, nil}, &out)
 	return out.Disassemble, err
 }
 
 // Disassemble function containing pc
 func (c *RPCClient) DisassemblePC(scope api.EvalScope, pc uint64, flavour api.AssemblyFlavour) (api.AsmInstructions, error) {
 	var out DisassembleOut
-	err := c.call("Disassemble", DisassembleIn{scope, pc, 0, flavour}, &out)
+	err := c.call("Disassemble", DisassembleIn{scope, pc, 0, flavour, false, nil}, &out)
+	return out.Disassemble, err
+}
+
+// Disassemble code between startPC and endPC, or the function containing
+// startPC if endPC is 0, interleaved with source lines
+func (c *RPCClient) DisassembleSource(scope api.EvalScope, startPC, endPC uint64, flavour api.AssemblyFlavour, substitutePathRules [][2]string) (api.AsmInstructions, error) {
+	var out DisassembleOut
+	err := c.call("Disassemble", DisassembleIn{scope, startPC, endPC, flavour, true, substitutePathRules}, &out)
 	return out.Disassemble, err
 }
 
+// ControlFlowGraph disassembles the function containing pc and splits it
+// into basic blocks.
+func (c *RPCClient) ControlFlowGraph(scope api.EvalScope, pc uint64, flavour api.AssemblyFlavour, substitutePathRules [][2]string) ([]api.BasicBlock, error) {
+	var out ControlFlowGraphOut
+	err := c.call("ControlFlowGraph", ControlFlowGraphIn{scope, pc, flavour, substitutePathRules}, &out)
+	return out.Blocks, err
+}
+
+// TraceInstructions single-steps the selected thread until it reaches one
+// of stopPCs or maxInsts instructions are executed, returns the executed
+// instructions and true if one of stopPCs was reached.
+func (c *RPCClient) TraceInstructions(stopPCs []uint64, maxInsts int, regs, mem bool, flavour api.AssemblyFlavour) ([]api.TracedInstruction, bool, error) {
+	var out TraceInstructionsOut
+	err := c.call("TraceInstructions", TraceInstructionsIn{stopPCs, maxInsts, regs, mem, flavour}, &out)
+	return out.Instructions, out.Reached, err
+}
+
+// StartCoverage starts recording which statement lines of packages are
+// executed, returns the number of lines recorded.
+func (c *RPCClient) StartCoverage(packages []string) (int, error) {
+	var out StartCoverageOut
+	err := c.call("StartCoverage", StartCoverageIn{packages}, &out)
+	return out.Lines, err
+}
+
+// Coverage returns the statement lines recorded since StartCoverage was
+// called.
+func (c *RPCClient) Coverage() ([]api.CoverLine, error) {
+	var out CoverageOut
+	err := c.call("Coverage", CoverageIn{}, &out)
+	return out.Lines, err
+}
+
 // Recorded returns true if the debugger target is a recording.
 func (c *RPCClient) Recorded() bool {
 	out := new(RecordedOut)
@@ -451,6 +521,20 @@ func (c *RPCClient) ExamineMemory(address uint64, count int) ([]byte, bool, erro
 	return out.Mem, out.IsLittleEndian, nil
 }
 
+// ListMemoryRegions lists the memory mappings of the target process.
+func (c *RPCClient) ListMemoryRegions(classify bool) ([]api.MemoryRegion, error) {
+	var out ListMemoryRegionsOut
+	err := c.call("ListMemoryRegions", ListMemoryRegionsIn{Classify: classify}, &out)
+	return out.Regions, err
+}
+
+// SearchMemory searches the memory of the target process for pattern.
+func (c *RPCClient) SearchMemory(scope api.MemorySearchScope, start, end uint64, pattern []byte, max int) ([]api.MemorySearchResult, error) {
+	var out SearchMemoryOut
+	err := c.call("SearchMemory", SearchMemoryIn{Pattern: pattern, Scope: scope, Start: start, End: end, MaxResults: max}, &out)
+	return out.Results, err
+}
+
 func (c *RPCClient) StopRecording() error {
 	return c.call("StopRecording", StopRecordingIn{}, &StopRecordingOut{})
 }
@@ -1,6 +1,7 @@
 package rpc2
 
 import (
+	"encoding/json"
 	"errors"
 	"fmt"
 	"sort"
@@ -221,6 +222,56 @@ func (s *RPCServer) Ancestors(arg AncestorsIn, out *AncestorsOut) error {
 	return err
 }
 
+// ListTimersIn holds the arguments of ListTimers.
+type ListTimersIn struct {
+}
+
+// ListTimersOut holds the return values of ListTimers.
+type ListTimersOut struct {
+	Timers []api.Timer
+	// Now is the current value of runtime.nanotime in the target process,
+	// 0 if it is not known (for example for core files).
+	Now int64
+}
+
+// ListTimers lists the pending timers of the target process, sorted by the
+// time at which they fire.
+// For each timer the function called when it fires and the goroutine
+// waiting on it (for example a goroutine sleeping in time.Sleep or
+// blocked on the channel returned by time.After) is returned.
+func (s *RPCServer) ListTimers(arg ListTimersIn, out *ListTimersOut) error {
+	timers, now, err := s.debugger.Timers()
+	if err != nil {
+		return err
+	}
+	out.Timers = timers
+	out.Now = now
+	return nil
+}
+
+// ListPollDescsIn holds the arguments of ListPollDescs.
+type ListPollDescsIn struct {
+}
+
+// ListPollDescsOut holds the return values of ListPollDescs.
+type ListPollDescsOut struct {
+	PollDescs []api.PollDesc
+}
+
+// ListPollDescs lists the file descriptors registered with the network
+// poller of the target process, with the goroutines blocked reading or
+// writing them.
+// For live processes on Linux sockets are resolved to their local and
+// remote endpoints.
+func (s *RPCServer) ListPollDescs(arg ListPollDescsIn, out *ListPollDescsOut) error {
+	pds, err := s.debugger.PollDescs()
+	if err != nil {
+		return err
+	}
+	out.PollDescs = pds
+	return nil
+}
+
 type ListBreakpointsIn struct {
 }
 
@@ -488,6 +539,70 @@ func (s *RPCServer) Eval(arg EvalIn, out *EvalOut) error {
 	return nil
 }
 
+// EvalJSONIn holds the arguments of EvalJSON.
+type EvalJSONIn struct {
+	Scope api.EvalScope
+	Expr  string
+	Cfg   *api.LoadConfig
+}
+
+// EvalJSONOut holds the return values of EvalJSON.
+type EvalJSONOut struct {
+	Value json.RawMessage
+}
+
+// EvalJSON evaluates an expression in the specified context and returns
+// its value encoded as JSON, loaded as specified by arg.Cfg.
+//
+// Structs and maps are returned as JSON objects (maps whose keys aren't
+// numbers, strings or booleans as arrays of key/value objects), pointers
+// and interfaces are dereferenced and the parts of the value that could
+// not be loaded are replaced by objects with a single key: "$unreadable",
+// "$cycle", "$notLoaded" or "$more".
+func (s *RPCServer) EvalJSON(arg EvalJSONIn, out *EvalJSONOut) error {
+	cfg := arg.Cfg
+	if cfg == nil {
+		cfg = &api.LoadConfig{FollowPointers: true, MaxVariableRecurse: 1, MaxStringLen: 64, MaxArrayValues: 64, MaxStructFields: -1}
+	}
+	v, err := s.debugger.EvalVariableInScope(arg.Scope.GoroutineID, arg.Scope.Frame, arg.Scope.DeferredCall, arg.Expr, *api.LoadConfigToProc(cfg))
+	if err != nil {
+		return err
+	}
+	out.Value, err = api.ConvertVar(v).JSONValue("")
+	return err
+}
+
+// DiffIn holds the arguments of Diff.
+type DiffIn struct {
+	Scope api.EvalScope
+	Expr1 string
+	Expr2 string
+	Cfg   *api.LoadConfig
+}
+
+// DiffOut holds the return values of Diff.
+type DiffOut struct {
+	Diffs []api.VariableDiff
+}
+
+// Diff evaluates two expressions and compares their values field by field,
+// recursing through structs, arrays, slices, maps, pointers and interfaces
+// up to the limits specified by arg.Cfg. Values of different but compatible
+// types (for example int and int64, or two struct types) can be compared.
+// Returns the list of differences, empty if the values are equal.
+func (s *RPCServer) Diff(arg DiffIn, out *DiffOut) error {
+	cfg := arg.Cfg
+	if cfg == nil {
+		cfg = &api.LoadConfig{FollowPointers: true, MaxVariableRecurse: 1, MaxStringLen: 64, MaxArrayValues: 64, MaxStructFields: -1}
+	}
+	diffs, err := s.debugger.DiffVariablesInScope(arg.Scope.GoroutineID, arg.Scope.Frame, arg.Scope.DeferredCall, arg.Expr1, arg.Expr2, *api.LoadConfigToProc(cfg))
+	if err != nil {
+		return err
+	}
+	out.Diffs = api.ConvertVariableDiffs(diffs)
+	return nil
+}
+
 type SetIn struct {
 	Scope  api.EvalScope
 	Symbol string
@@ -497,8 +612,13 @@ type SetIn struct {
 type SetOut struct {
 }
 
-// Set sets the value of a variable. Only numerical types and
-// pointers are currently supported.
+// Set sets the value of a variable.
+// Numbers, booleans, pointers, strings, variables of the same type and
+// composite literals can be assigned, assignments to map elements can add
+// new keys to the map.
+// When memory needs to be allocated for the new value (literal strings,
+// slice literals, new map elements) a function call is injected in the
+// target process, see the documentation of the 'call' command.
 func (s *RPCServer) Set(arg SetIn, out *SetOut) error {
 	return s.debugger.SetVariableInScope(arg.Scope.GoroutineID, arg.Scope.Frame, arg.Scope.DeferredCall, arg.Symbol, arg.Value)
 }
@@ -640,6 +760,13 @@ type DisassembleIn struct {
 	Scope          api.EvalScope
 	StartPC, EndPC uint64
 	Flavour        api.AssemblyFlavour
+
+	// Source, if set, requests the text of the source line of each
+	// instruction and the inlined calls containing it.
+	Source bool
+	// SubstitutePathRules is used to find the source files when Source is
+	// set, see FindLocationIn.
+	SubstitutePathRules [][2]string
 }
 
 type DisassembleOut struct {
@@ -653,6 +780,8 @@ type DisassembleOut struct {
 // Scope is used to mark the instruction the specified goroutine is stopped at.
 //
 // Disassemble will also try to calculate the destination address of an absolute indirect CALL if it happens to be the instruction the selected goroutine is stopped at.
+//
+// If Source is set each instruction will also report the text of its source line and the inlined calls containing it.
 func (c *RPCServer) Disassemble(arg DisassembleIn, out *DisassembleOut) error {
 	var err error
 	insts, err := c.debugger.Disassemble(arg.Scope.GoroutineID, arg.StartPC, arg.EndPC)
@@ -663,6 +792,141 @@ func (c *RPCServer) Disassemble(arg DisassembleIn, out *DisassembleOut) error {
 	for i := range insts {
 		out.Disassemble[i] = api.ConvertAsmInstruction(insts[i], c.debugger.AsmInstructionText(&insts[i], proc.AssemblyFlavour(arg.Flavour)))
 	}
+	if arg.Source {
+		c.debugger.AsmInstructionsSource(out.Disassemble, arg.SubstitutePathRules)
+	}
+	return nil
+}
+
+type TraceInstructionsIn struct {
+	// StopPCs are the addresses where tracing stops.
+	StopPCs []uint64
+	// MaxInstructions is the maximum number of instructions executed.
+	MaxInstructions int
+	// Registers requests the registers changed by each instruction.
+	Registers bool
+	// Memory requests the memory written by each instruction.
+	Memory  bool
+	Flavour api.AssemblyFlavour
+}
+
+type TraceInstructionsOut struct {
+	Instructions []api.TracedInstruction
+	// Reached is true if one of StopPCs was reached.
+	Reached bool
+}
+
+// TraceInstructions single-steps the thread running the selected goroutine
+// until one of StopPCs is reached, after executing at least one
+// instruction, or MaxInstructions instructions are executed, returning the
+// executed instructions.
+//
+// Memory writes are only reported on x86 architectures, for the
+// destination operand of each instruction and the stack slot written by
+// PUSH and CALL instructions.
+func (s *RPCServer) TraceInstructions(arg TraceInstructionsIn, out *TraceInstructionsOut) error {
+	var flags proc.TraceInstructionsFlags
+	if arg.Registers {
+		flags |= proc.TraceRegisters
+	}
+	if arg.Memory {
+		flags |= proc.TraceMemoryWrites
+	}
+	insts, reached, err := s.debugger.TraceInstructions(arg.StopPCs, arg.MaxInstructions, flags)
+	out.Reached = reached
+	out.Instructions = make([]api.TracedInstruction, len(insts))
+	for i := range insts {
+		ti := &out.Instructions[i]
+		ti.Inst = api.ConvertAsmInstruction(insts[i].Inst, s.debugger.AsmInstructionText(&insts[i].Inst, proc.AssemblyFlavour(arg.Flavour)))
+		if insts[i].Regs != nil {
+			ti.Registers = api.ConvertRegisters(insts[i].Regs, s.debugger.DwarfRegisterToString, false)
+		}
+		for _, w := range insts[i].MemWrites {
+			ti.MemoryWrites = append(ti.MemoryWrites, api.MemoryWrite{Addr: w.Addr, Data: w.Data})
+		}
+	}
+	return err
+}
+
+type StartCoverageIn struct {
+	// Packages are the import paths of the packages to record, a path
+	// ending in "/..." also selects all the packages below it.
+	Packages []string
+}
+
+type StartCoverageOut struct {
+	// Lines is the number of statement lines recorded.
+	Lines int
+}
+
+// StartCoverage starts recording which statement lines of the selected
+// packages are executed.
+//
+// A breakpoint is set on every instruction marked as the beginning of a
+// statement in the selected packages, the first time a line is reached
+// its breakpoints are removed, the target is resumed without reporting
+// the breakpoint and the line is recorded as executed. Use Coverage to
+// retrieve the recorded lines.
+func (s *RPCServer) StartCoverage(arg StartCoverageIn, out *StartCoverageOut) error {
+	n, err := s.debugger.StartCoverage(arg.Packages)
+	if err != nil {
+		return err
+	}
+	out.Lines = n
+	return nil
+}
+
+type CoverageIn struct {
+}
+
+type CoverageOut struct {
+	Lines []api.CoverLine
+}
+
+// Coverage returns the statement lines recorded since StartCoverage was
+// called, sorted by package, file and line.
+func (s *RPCServer) Coverage(arg CoverageIn, out *CoverageOut) error {
+	out.Lines = s.debugger.Coverage()
+	return nil
+}
+
+type ControlFlowGraphIn struct {
+	Scope   api.EvalScope
+	PC      uint64
+	Flavour api.AssemblyFlavour
+	// SubstitutePathRules is used to find the source files of the
+	// instructions, see FindLocationIn.
+	SubstitutePathRules [][2]string
+}
+
+type ControlFlowGraphOut struct {
+	Blocks []api.BasicBlock
+}
+
+// ControlFlowGraph disassembles the function containing PC and splits it
+// into basic blocks.
+//
+// Blocks end after jump, call and return instructions and before the
+// destinations of jumps, the first block is the entry point of the
+// function. Instructions are annotated as they are by Disassemble with
+// Source set.
+func (c *RPCServer) ControlFlowGraph(arg ControlFlowGraphIn, out *ControlFlowGraphOut) error {
+	blocks, err := c.debugger.ControlFlowGraph(arg.Scope.GoroutineID, arg.PC)
+	if err != nil {
+		return err
+	}
+	var insts api.AsmInstructions
+	for _, block := range blocks {
+		for i := range block.Insts {
+			insts = append(insts, api.ConvertAsmInstruction(block.Insts[i], c.debugger.AsmInstructionText(&block.Insts[i], proc.AssemblyFlavour(arg.Flavour))))
+		}
+	}
+	c.debugger.AsmInstructionsSource(insts, arg.SubstitutePathRules)
+	out.Blocks = make([]api.BasicBlock, len(blocks))
+	for i, block := range blocks {
+		out.Blocks[i] = api.BasicBlock{Insts: insts[:len(block.Insts)], Succs: api.ConvertBasicBlockEdges(block.Succs)}
+		insts = insts[len(block.Insts):]
+	}
 	return nil
 }
 
@@ -853,6 +1117,63 @@ func (s *RPCServer) ExamineMemory(arg ExamineMemoryIn, out *ExaminedMemoryOut) e
 	return nil
 }
 
+// ListMemoryRegionsIn holds the arguments of ListMemoryRegions.
+type ListMemoryRegionsIn struct {
+	// Classify requests the classification of each memory region using the
+	// state of the Go runtime (heap, goroutine stacks, module data, text).
+	Classify bool
+}
+
+// ListMemoryRegionsOut holds the return values of ListMemoryRegions.
+type ListMemoryRegionsOut struct {
+	Regions []api.MemoryRegion
+}
+
+// ListMemoryRegions lists the memory mappings of the target process.
+// Listing memory mappings is supported by the native backend on Linux, on
+// Linux core files and by gdbserver stubs implementing qMemoryRegionInfo.
+func (s *RPCServer) ListMemoryRegions(arg ListMemoryRegionsIn, out *ListMemoryRegionsOut) error {
+	regions, err := s.debugger.MemoryRegions(arg.Classify)
+	if err != nil {
+		return err
+	}
+	out.Regions = regions
+	return nil
+}
+
+// SearchMemoryIn holds the arguments of SearchMemory.
+type SearchMemoryIn struct {
+	// Pattern is the sequence of bytes to search for.
+	Pattern []byte
+	// Scope selects the memory searched, Start and End are used by the
+	// MemorySearchRange and MemorySearchMapping scopes.
+	Scope      api.MemorySearchScope
+	Start, End uint64
+	// MaxResults is the maximum number of results returned, 0 means no
+	// limit.
+	MaxResults int
+}
+
+// SearchMemoryOut holds the return values of SearchMemory.
+type SearchMemoryOut struct {
+	Results []api.MemorySearchResult
+}
+
+// SearchMemory searches the memory of the target process for a sequence
+// of bytes. The memory searched is either a range of addresses, the memory
+// mapping containing an address, the Go heap or all readable memory
+// mappings.
+// Listing memory mappings is supported by the native backend on Linux, on
+// Linux core files and by gdbserver stubs implementing qMemoryRegionInfo.
+func (s *RPCServer) SearchMemory(arg SearchMemoryIn, out *SearchMemoryOut) error {
+	results, err := s.debugger.SearchMemory(arg.Scope, arg.Start, arg.End, arg.Pattern, arg.MaxResults)
+	if err != nil {
+		return err
+	}
+	out.Results = results
+	return nil
+}
+
 type StopRecordingIn struct {
 }
 
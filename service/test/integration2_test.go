@@ -319,6 +319,32 @@ func TestClientServer_step(t *testing.T) {
 	})
 }
 
+func TestClientServer_breakpointHitTime(t *testing.T) {
+	// The time at which a breakpoint is hit is measured by the debugger.
+	withTestClient2("testprog", t, func(c service.Client) {
+		_, err := c.CreateBreakpoint(&api.Breakpoint{FunctionName: "main.helloworld", Line: -1})
+		assertNoError(err, t, "CreateBreakpoint()")
+
+		before := time.Now()
+		state := <-c.Continue()
+		after := time.Now()
+		assertNoError(state.Err, t, "Continue()")
+
+		hitTime := state.CurrentThread.BreakpointHitTime
+		if hitTime.Before(before) || hitTime.After(after) {
+			t.Fatalf("breakpoint hit at %v, expected between %v and %v", hitTime, before, after)
+		}
+
+		threads, err := c.ListThreads()
+		assertNoError(err, t, "ListThreads()")
+		for _, th := range threads {
+			if th.Breakpoint == nil && !th.BreakpointHitTime.IsZero() {
+				t.Errorf("hit time set for thread %d not stopped at a breakpoint", th.ID)
+			}
+		}
+	})
+}
+
 func TestClientServer_stepout(t *testing.T) {
 	protest.AllowRecording(t)
 	withTestClient2("testnextprog", t, func(c service.Client) {
@@ -1763,6 +1789,66 @@ func TestClientServerFunctionCall(t *testing.T) {
 	})
 }
 
+func TestClientServerSetVariableWithCalls(t *testing.T) {
+	// Assignments that need memory to be allocated in the target process
+	// are executed by injecting function calls.
+	protest.MustSupportFunctionCalls(t, testBackend)
+	withTestClient2("fncall", t, func(c service.Client) {
+		mustHaveDebugCalls(t, c)
+		state := <-c.Continue()
+		assertNoError(state.Err, t, "Continue()")
+
+		scope := api.EvalScope{GoroutineID: -1}
+		testcases := []struct {
+			name, value, expr, tgt string
+		}{
+			{"str", `"a new string"`, "str", `"a new string"`},
+			{"a", "astruct{X: 10}", "a.X", "10"},
+			{"sl", "[]astruct{{X: 1}, {X: 2}}", "len(sl)", "2"},
+			{"sl", "[]astruct{{X: 1}, {X: 2}}", "sl[1].X", "2"},
+			{"pa2", "&astruct{X: 11}", "pa2.X", "11"},
+			{`m["one"]`, "3", `m["one"]`, "3"},
+			{`m["two"]`, "2", `m["two"]`, "2"},
+			{`m["two"]`, "2", "len(m)", "2"},
+		}
+
+		for _, tc := range testcases {
+			assertNoError(c.SetVariable(scope, tc.name, tc.value), t, fmt.Sprintf("SetVariable(%s, %s)", tc.name, tc.value))
+			v, err := c.EvalVariable(scope, tc.expr, normalLoadConfig)
+			assertNoError(err, t, fmt.Sprintf("EvalVariable(%s)", tc.expr))
+			if v.SinglelineString() != tc.tgt {
+				t.Errorf("after set %s = %s: %s is %s, expected %s", tc.name, tc.value, tc.expr, v.SinglelineString(), tc.tgt)
+			}
+		}
+
+		// failed assignments must leave the variable unchanged
+		errcases := []struct {
+			name, value, err string
+		}{
+			{`nilm["one"]`, "1", "assignment to entry in nil map"},
+			{"sl", "[]astruct{{X: 3}, {X: nonexistent}}", "could not find symbol value for nonexistent"},
+			{`m["three"]`, "nonexistent", "could not find symbol value for nonexistent"},
+			{`m["three"]`, "astruct{X: 3}", "can not use astruct literal as value of type int"},
+		}
+		for _, tc := range errcases {
+			err := c.SetVariable(scope, tc.name, tc.value)
+			if err == nil || err.Error() != tc.err {
+				t.Errorf("SetVariable(%s, %s): expected error %q got %v", tc.name, tc.value, tc.err, err)
+			}
+		}
+		v, err := c.EvalVariable(scope, "sl", normalLoadConfig)
+		assertNoError(err, t, "EvalVariable(sl)")
+		if s := v.SinglelineString(); s != "[]main.astruct len: 2, cap: 2, [{X: 1},{X: 2}]" {
+			t.Errorf("sl changed by failed assignment: %s", s)
+		}
+		v, err = c.EvalVariable(scope, "len(m)", normalLoadConfig)
+		assertNoError(err, t, "EvalVariable(len(m))")
+		if v.Value != "2" {
+			t.Errorf("element added to m by failed assignment: len(m) = %s", v.Value)
+		}
+	})
+}
+
 func TestClientServerFunctionCallBadPos(t *testing.T) {
 	protest.MustSupportFunctionCalls(t, testBackend)
 	if goversion.VersionAfterOrEqual(runtime.Version(), 1, 12) {
@@ -2056,6 +2142,158 @@ func TestClearLogicalBreakpoint(t *testing.T) {
 	})
 }
 
+func TestDisassembleSource(t *testing.T) {
+	// Disassembling with source must annotate instructions of inlined
+	// functions with the call site they were inlined at.
+	withTestClient2Extended("testinline", t, protest.EnableInlining, [3]string{}, func(c service.Client, fixture protest.Fixture) {
+		locs, err := c.FindLocation(api.EvalScope{GoroutineID: -1}, "main.main", false, nil)
+		assertNoError(err, t, "FindLocation()")
+		if len(locs) != 1 {
+			t.Fatalf("wrong number of locations for main.main: %d", len(locs))
+		}
+		insts, err := c.DisassembleSource(api.EvalScope{GoroutineID: -1}, locs[0].PC, 0, api.IntelFlavour, nil)
+		assertNoError(err, t, "DisassembleSource()")
+		callLines := map[int]bool{}
+		for _, inst := range insts {
+			if inst.Source == "" {
+				t.Errorf("no source for instruction at %#x (%s:%d)", inst.Loc.PC, inst.Loc.File, inst.Loc.Line)
+			}
+			if len(inst.InlinedCalls) == 0 {
+				continue
+			}
+			call := inst.InlinedCalls[0]
+			if call.Function == nil {
+				t.Errorf("no function for inlined call at %#x", inst.Loc.PC)
+				continue
+			}
+			if call.Function.Name() == "main.inlineThis" {
+				callLines[call.Line] = true
+			}
+		}
+		if !callLines[18] || !callLines[19] {
+			t.Fatalf("missing inlined calls to main.inlineThis at lines 18 and 19: %v", callLines)
+		}
+	})
+}
+
+func TestControlFlowGraph(t *testing.T) {
+	withTestClient2("testinline", t, func(c service.Client) {
+		locs, err := c.FindLocation(api.EvalScope{GoroutineID: -1}, "main.main", false, nil)
+		assertNoError(err, t, "FindLocation()")
+		blocks, err := c.ControlFlowGraph(api.EvalScope{GoroutineID: -1}, locs[0].PC, api.IntelFlavour, nil)
+		assertNoError(err, t, "ControlFlowGraph()")
+		if len(blocks) < 2 {
+			t.Fatalf("wrong number of blocks: %d", len(blocks))
+		}
+		fn := blocks[0].Insts[0].Loc.Function
+		if fn == nil || fn.Name() != "main.main" || blocks[0].Insts[0].Loc.PC != fn.Value {
+			t.Errorf("first block does not start at the entry point of main.main: %#v", blocks[0].Insts[0].Loc)
+		}
+		rets := 0
+		for i, block := range blocks {
+			if len(block.Insts) == 0 {
+				t.Fatalf("empty block %d", i)
+			}
+			if block.Insts[0].Source == "" {
+				t.Errorf("no source for block %d", i)
+			}
+			for _, edge := range block.Succs {
+				if edge.To < 0 || edge.To >= len(blocks) {
+					t.Errorf("block %d: bad edge %#v", i, edge)
+				}
+			}
+			if len(block.Succs) == 0 {
+				rets++
+			}
+		}
+		if rets == 0 {
+			t.Errorf("no block leaves main.main")
+		}
+	})
+}
+
+func TestTraceInstructions(t *testing.T) {
+	if runtime.GOOS != "linux" {
+		t.Skip("the process is not stopped at its entry point")
+	}
+	protest.AllowRecording(t)
+	withTestClient2("testnextprog", t, func(c service.Client) {
+		state, err := c.GetState()
+		assertNoError(err, t, "GetState()")
+		start := state.CurrentThread.PC
+		locs, err := c.FindLocation(api.EvalScope{GoroutineID: -1}, "runtime.rt0_go", true, nil)
+		assertNoError(err, t, "FindLocation()")
+
+		insts, reached, err := c.TraceInstructions([]uint64{locs[0].PC}, 1000, true, false, api.IntelFlavour)
+		assertNoError(err, t, "TraceInstructions()")
+		if !reached {
+			t.Fatalf("runtime.rt0_go not reached after %d instructions", len(insts))
+		}
+		if len(insts) == 0 || insts[0].Inst.Loc.PC != start {
+			t.Fatalf("trace does not start at %#x", start)
+		}
+		regs := 0
+		for _, inst := range insts {
+			if inst.Inst.Text == "" {
+				t.Errorf("no text for instruction at %#x", inst.Inst.Loc.PC)
+			}
+			regs += len(inst.Registers)
+		}
+		if regs == 0 {
+			t.Errorf("no register changes recorded")
+		}
+
+		state, err = c.GetState()
+		assertNoError(err, t, "GetState()")
+		if state.CurrentThread.PC != locs[0].PC {
+			t.Errorf("stopped at %#x, expected %#x", state.CurrentThread.PC, locs[0].PC)
+		}
+
+		insts, reached, err = c.TraceInstructions([]uint64{start}, 5, false, false, api.IntelFlavour)
+		assertNoError(err, t, "TraceInstructions()")
+		if reached || len(insts) != 5 {
+			t.Errorf("limit not respected: %d instructions (reached %v)", len(insts), reached)
+		}
+	})
+
+	// with memory writes, the prologue of runtime.rt0_go stores argc and argv
+	// on the stack
+	withTestClient2("testnextprog", t, func(c service.Client) {
+		locs, err := c.FindLocation(api.EvalScope{GoroutineID: -1}, "runtime.rt0_go", true, nil)
+		assertNoError(err, t, "FindLocation()")
+		_, _, err = c.TraceInstructions([]uint64{locs[0].PC}, 1000, false, false, api.IntelFlavour)
+		assertNoError(err, t, "TraceInstructions()")
+
+		insts, _, err := c.TraceInstructions(nil, 50, true, true, api.IntelFlavour)
+		assertNoError(err, t, "TraceInstructions()")
+		writes := 0
+		var first *api.TracedInstruction
+		for i, inst := range insts {
+			if first == nil && len(inst.MemoryWrites) > 0 {
+				first = &insts[i]
+			}
+			text := strings.ToLower(inst.Inst.Text)
+			for _, op := range []string{"cmp ", "test ", "div ", "idiv ", "mul ", "imul ", "lea "} {
+				if strings.HasPrefix(text, op) && len(inst.MemoryWrites) > 0 {
+					t.Errorf("memory write reported for %s", inst.Inst.Text)
+				}
+			}
+			for _, w := range inst.MemoryWrites {
+				if len(w.Data) == 0 {
+					t.Errorf("empty memory write for %s", inst.Inst.Text)
+				}
+			}
+			writes += len(inst.MemoryWrites)
+		}
+		if writes == 0 {
+			t.Fatalf("no memory writes recorded")
+		}
+		if !strings.HasPrefix(first.Inst.Text, "mov qword ptr [rsp") || len(first.MemoryWrites[0].Data) != 8 {
+			t.Errorf("store of argc not recorded: %s %v", first.Inst.Text, first.MemoryWrites)
+		}
+	})
+}
+
 func TestRedirects(t *testing.T) {
 	const (
 		infile  = "redirect-input.txt"
@@ -255,6 +255,10 @@ func TestSetVariable(t *testing.T) {
 
 		{"s3", "[]int", `[]int len: 0, cap: 6, []`, "s4[2:5]", "[]int len: 3, cap: 3, [3,4,5]"},
 		{"s3", "[]int", "[]int len: 3, cap: 3, [3,4,5]", "arr1[:]", "[]int len: 4, cap: 4, [0,1,2,3]"},
+
+		{"as1", "main.astruct", "main.astruct {A: 2, B: 3}", "main.astruct{B: 5}", "main.astruct {A: 0, B: 5}"},
+		{"as1", "main.astruct", "main.astruct {A: 0, B: 5}", "main.astruct{6, 7}", "main.astruct {A: 6, B: 7}"},
+		{"arr1", "[4]int", "[4]int [0,1,2,3]", "[4]int{3: 7, 1: 9}", "[4]int [0,9,0,7]"},
 	}
 
 	withTestProcess("testvariables2", t, func(p *proc.Target, fixture protest.Fixture) {
@@ -1198,6 +1202,9 @@ func TestCallFunction(t *testing.T) {
 
 		{"fn2nil()", nil, errors.New("nil pointer dereference")},
 
+		{`w.Write(bs)`, []string{":int:5", ":error:error nil"}, nil},  // indirect call of method on interface / resolved through the itab
+		{`fnstruct.fn(3)`, []string{`:string:"1 + 6 + 3 = 10"`}, nil}, // indirect call of func value / closure stored in a struct field
+
 		{"ga.PRcvr(2)", []string{`:string:"2 - 0 = 2"`}, nil},
 
 		{"x.CallMe()", nil, nil},